@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nilesh0729/Notes/internal/cache"
+	Database "github.com/nilesh0729/Notes/internal/db/Result"
+	"github.com/nilesh0729/Notes/internal/util"
+	"github.com/redis/go-redis/v9"
+)
+
+// newCache creates the cache config.CacheBackend selects, or returns nil if
+// caching is off. A Redis cache is checked to be reachable, so that a
+// wrong REDIS_URL fails at startup.
+func newCache(ctx context.Context, config util.Config) (Database.Cache, error) {
+	switch config.CacheBackend {
+	case "none":
+		return nil, nil
+	case "memory":
+		return cache.NewLRU(config.CacheSize), nil
+	case "redis":
+		client, err := openRedis(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+		return cache.NewRedis(client), nil
+	}
+	return nil, fmt.Errorf("unknown CACHE_BACKEND %q", config.CacheBackend)
+}
+
+// openRedis connects to the Redis server at config.RedisURL.
+func openRedis(ctx context.Context, config util.Config) (*redis.Client, error) {
+	if config.RedisURL == "" {
+		return nil, fmt.Errorf("REDIS_URL is not set")
+	}
+
+	options, err := redis.ParseURL(config.RedisURL)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse REDIS_URL: %w", err)
+	}
+
+	client := redis.NewClient(options)
+	err = client.Ping(ctx).Err()
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("cannot reach Redis: %w", err)
+	}
+	return client, nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/nilesh0729/Notes/internal/util"
+)
+
+// runConfig runs the config command with args, as described in usage. The
+// config is printed even if it is invalid, to help fix it, and the problems
+// are returned afterwards.
+func runConfig(w io.Writer, args []string) error {
+	redact, err := parseConfig(args)
+	if err != nil {
+		return err
+	}
+
+	config, loadErr := util.LoadConfig(".")
+
+	err = config.WriteEnv(w, redact)
+	if err != nil {
+		return err
+	}
+	return loadErr
+}
+
+// parseConfig returns whether the config command with args prints the
+// config redacted.
+func parseConfig(args []string) (redact bool, err error) {
+	switch {
+	case len(args) == 1 && args[0] == "print":
+		return false, nil
+	case len(args) == 2 && args[0] == "print" && args[1] == "--redacted":
+		return true, nil
+	}
+	return false, errUsage
+}
+
+// fatalConfig reports the problems of an invalid config, one per line,
+// and exits.
+func fatalConfig(err error) {
+	fmt.Fprintln(os.Stderr, "invalid config:")
+	for _, line := range strings.Split(err.Error(), "\n") {
+		fmt.Fprintln(os.Stderr, "  "+line)
+	}
+	os.Exit(1)
+}
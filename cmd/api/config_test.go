@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfig(t *testing.T) {
+	redact, err := parseConfig([]string{"print"})
+	require.NoError(t, err)
+	require.False(t, redact)
+
+	redact, err = parseConfig([]string{"print", "--redacted"})
+	require.NoError(t, err)
+	require.True(t, redact)
+
+	usageErrors := [][]string{
+		{},
+		{"show"},
+		{"print", "--secret"},
+		{"print", "--redacted", "x"},
+	}
+	for _, args := range usageErrors {
+		_, err := parseConfig(args)
+		require.ErrorIs(t, err, errUsage, args)
+	}
+}
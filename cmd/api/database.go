@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nilesh0729/Notes/internal/api"
+	"github.com/nilesh0729/Notes/internal/db"
+	Database "github.com/nilesh0729/Notes/internal/db/Result"
+	"github.com/nilesh0729/Notes/internal/db/sqlite"
+	"github.com/nilesh0729/Notes/internal/metrics"
+	"github.com/nilesh0729/Notes/internal/util"
+)
+
+// database is the storage backend config.DBDriver selects.
+type database struct {
+	store Database.Store
+
+	// newMigrator creates a migrator for the embedded migrations of the
+	// backend.
+	newMigrator func(ctx context.Context) (*db.Migrator, error)
+
+	// registerStats exports the stats of the connection pool.
+	registerStats func() error
+
+	close func()
+}
+
+// openDatabase connects to the database of config.
+func openDatabase(ctx context.Context, config util.Config) (*database, error) {
+	switch config.DBDriver {
+	case db.DriverPostgres:
+		return openPostgres(ctx, config)
+
+	case db.DriverSQLite:
+		if config.DBReplicaSource != "" {
+			return nil, errors.New("DB_REPLICA_SOURCE needs the postgres driver")
+		}
+
+		conn, err := sqlite.Open(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+		return &database{
+			store: sqlite.NewStore(conn),
+			newMigrator: func(ctx context.Context) (*db.Migrator, error) {
+				return db.NewSQLiteMigrator(ctx, conn)
+			},
+			registerStats: func() error { return metrics.RegisterSQLDBStats(conn) },
+			close:         func() { conn.Close() },
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown DB_DRIVER %q", config.DBDriver)
+}
+
+// openPostgres connects to the Postgres database of config, and to its read
+// replica if config.DBReplicaSource is set.
+func openPostgres(ctx context.Context, config util.Config) (*database, error) {
+	pool, err := Database.Open(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	database := &database{
+		store: Database.ServerConn(pool),
+		newMigrator: func(ctx context.Context) (*db.Migrator, error) {
+			return db.NewMigrator(ctx, pool)
+		},
+		registerStats: func() error { return metrics.RegisterDBStats(pool, metrics.PoolPrimary) },
+		close:         pool.Close,
+	}
+	if config.DBReplicaSource == "" {
+		return database, nil
+	}
+
+	replicaConfig := config
+	replicaConfig.DBSource = config.DBReplicaSource
+	replica, err := Database.Open(ctx, replicaConfig)
+	if err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("cannot connect to replica: %w", err)
+	}
+
+	database.store = Database.RouteToReplica(
+		database.store,
+		Database.ServerConn(replica),
+		config.DBReplicaStickiness,
+		api.RequestUsername,
+	)
+	database.registerStats = func() error {
+		return errors.Join(
+			metrics.RegisterDBStats(pool, metrics.PoolPrimary),
+			metrics.RegisterDBStats(replica, metrics.PoolReplica),
+		)
+	}
+	database.close = func() {
+		replica.Close()
+		pool.Close()
+	}
+	return database, nil
+}
+
+// migrateUp applies all pending migrations.
+func (database *database) migrateUp(ctx context.Context) error {
+	migrator, err := database.newMigrator(ctx)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+
+	return migrator.Up(0)
+}
@@ -2,33 +2,141 @@ package main
 // Cache Buster: 2025-12-28-v2
 
 import (
-	"database/sql"
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
 
-	_ "github.com/lib/pq"
+	"github.com/gin-gonic/gin"
 	"github.com/nilesh0729/Notes/internal/api"
 	Database "github.com/nilesh0729/Notes/internal/db/Result"
+	"github.com/nilesh0729/Notes/internal/metrics"
+	"github.com/nilesh0729/Notes/internal/tracing"
 	"github.com/nilesh0729/Notes/internal/util"
+	"go.opentelemetry.io/otel"
 )
 
+const usage = `usage: api [serve]
+       api migrate up [N] | down N | down -all | version | force V
+       api config print [--redacted]
+
+serve (the default) runs the API server. migrate applies, reverts or
+inspects the embedded schema migrations:
+
+  up [N]       apply all pending migrations, or the next N
+  down N       revert the last N migrations
+  down -all    revert every migration
+  version      print the current schema version
+  force V      set the schema version to V without migrating
+
+config print writes the loaded config as environment variables and checks
+it; --redacted hides the secrets.`
+
 func main() {
+	args := os.Args[1:]
+	if len(args) > 0 && args[0] == "config" {
+		err := runConfig(os.Stdout, args[1:])
+		if errors.Is(err, errUsage) {
+			fmt.Fprintln(os.Stderr, usage)
+			os.Exit(2)
+		}
+		if err != nil {
+			fatalConfig(err)
+		}
+		return
+	}
+
 	config, err := util.LoadConfig(".")
 	if err != nil {
-		log.Fatal("Cannot Load Config", err)
+		fatalConfig(err)
 	}
 
-	conn, err := sql.Open(config.DBDriver, config.DBSource)
+	logger, err := util.NewLogger(config, os.Stdout)
 	if err != nil {
-		log.Fatal("cannot connect to DB", err)
+		fatal("cannot create logger", err)
+	}
+	slog.SetDefault(logger)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	switch {
+	case len(args) == 0 || args[0] == "serve":
+		err = serve(ctx, config)
+	case args[0] == "migrate":
+		err = runMigrate(ctx, config, args[1:])
+	default:
+		err = errUsage
 	}
-	store := Database.ServerConn(conn)
+	if errors.Is(err, errUsage) {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fatal("command failed", err)
+	}
+}
+
+// serve runs the API server until ctx is done.
+func serve(ctx context.Context, config util.Config) error {
+	shutdownTracing, err := tracing.Setup(ctx, config)
+	if err != nil {
+		return fmt.Errorf("cannot set up tracing: %w", err)
+	}
+	defer shutdownTracing(context.Background())
+
+	if config.LogLevel != "debug" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	database, err := openDatabase(ctx, config)
+	if err != nil {
+		return fmt.Errorf("cannot connect to DB: %w", err)
+	}
+	defer database.close()
+
+	if config.AutoMigrate {
+		err = database.migrateUp(ctx)
+		if err != nil {
+			return fmt.Errorf("cannot migrate DB: %w", err)
+		}
+	}
+
+	err = database.registerStats()
+	if err != nil {
+		return fmt.Errorf("cannot export DB stats: %w", err)
+	}
+
+	cache, err := newCache(ctx, config)
+	if err != nil {
+		return fmt.Errorf("cannot create cache: %w", err)
+	}
+
+	store := database.store
+	if cache != nil {
+		store = Database.CacheStore(store, cache, config.CacheTTL, api.RequestUsername)
+	}
+	store = metrics.InstrumentStore(store)
+	store = tracing.TraceStore(store, otel.GetTracerProvider())
 	server, err := api.NewServer(config, store)
 	if err != nil {
-		log.Fatal("cannot create server: ", err)
+		return fmt.Errorf("cannot create server: %w", err)
 	}
 
-	err = server.Start(config.ServerAddress)
+	slog.Info("starting server", slog.String("address", config.ServerAddress))
+	err = server.Start(ctx, config.ServerAddress)
 	if err != nil {
-		log.Fatal("Cannot Start Server : ", err)
+		return err
 	}
+	slog.Info("server stopped")
+	return nil
+}
+
+// fatal logs err and exits.
+func fatal(msg string, err error) {
+	slog.Error(msg, slog.Any("error", err))
+	os.Exit(1)
 }
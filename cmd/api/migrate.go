@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/nilesh0729/Notes/internal/db"
+	"github.com/nilesh0729/Notes/internal/util"
+)
+
+// errUsage is returned for a command line that is not understood.
+var errUsage = errors.New("invalid arguments")
+
+// runMigrate runs the migrate command with args, as described in usage.
+func runMigrate(ctx context.Context, config util.Config, args []string) error {
+	action, err := parseMigrate(args)
+	if err != nil {
+		return err
+	}
+
+	database, err := openDatabase(ctx, config)
+	if err != nil {
+		return err
+	}
+	defer database.close()
+
+	migrator, err := database.newMigrator(ctx)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+
+	err = action(migrator)
+	if err != nil {
+		return err
+	}
+
+	version, dirty, err := migrator.Version()
+	if err != nil {
+		return err
+	}
+	slog.Info("schema version",
+		slog.Uint64("version", uint64(version)),
+		slog.Bool("dirty", dirty),
+		slog.Uint64("latest", uint64(db.LatestVersion(config.DBDriver))),
+	)
+	return nil
+}
+
+// parseMigrate returns what the migrate command with args does to the
+// database. The version command changes nothing; it only prints it.
+func parseMigrate(args []string) (func(*db.Migrator) error, error) {
+	if len(args) == 0 {
+		return nil, errUsage
+	}
+
+	switch command, rest := args[0], args[1:]; {
+	case command == "up" && len(rest) == 0:
+		return func(migrator *db.Migrator) error { return migrator.Up(0) }, nil
+	case command == "up" && len(rest) == 1:
+		n, err := parseCount(rest[0])
+		if err != nil {
+			return nil, err
+		}
+		return func(migrator *db.Migrator) error { return migrator.Up(n) }, nil
+	case command == "down" && len(rest) == 1 && rest[0] == "-all":
+		return (*db.Migrator).DownAll, nil
+	case command == "down" && len(rest) == 1:
+		n, err := parseCount(rest[0])
+		if err != nil {
+			return nil, err
+		}
+		return func(migrator *db.Migrator) error { return migrator.Down(n) }, nil
+	case command == "version" && len(rest) == 0:
+		return func(*db.Migrator) error { return nil }, nil
+	case command == "force" && len(rest) == 1:
+		version, err := strconv.Atoi(rest[0])
+		if err != nil || version < -1 {
+			return nil, fmt.Errorf("%q is not a migration version", rest[0])
+		}
+		return func(migrator *db.Migrator) error { return migrator.Force(version) }, nil
+	}
+	return nil, errUsage
+}
+
+func parseCount(arg string) (int, error) {
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("%q is not a positive number of migrations", arg)
+	}
+	return n, nil
+}
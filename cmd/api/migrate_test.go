@@ -0,0 +1,49 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMigrate(t *testing.T) {
+	valid := [][]string{
+		{"up"},
+		{"up", "2"},
+		{"down", "1"},
+		{"down", "-all"},
+		{"version"},
+		{"force", "3"},
+		{"force", "-1"},
+	}
+	for _, args := range valid {
+		action, err := parseMigrate(args)
+		require.NoError(t, err, args)
+		require.NotNil(t, action, args)
+	}
+
+	usageErrors := [][]string{
+		{},
+		{"sideways"},
+		{"down"},
+		{"up", "1", "2"},
+		{"version", "1"},
+		{"force"},
+	}
+	for _, args := range usageErrors {
+		_, err := parseMigrate(args)
+		require.ErrorIs(t, err, errUsage, args)
+	}
+
+	badNumbers := [][]string{
+		{"up", "0"},
+		{"up", "many"},
+		{"down", "-1"},
+		{"force", "v3"},
+	}
+	for _, args := range badNumbers {
+		_, err := parseMigrate(args)
+		require.Error(t, err, args)
+		require.NotErrorIs(t, err, errUsage, args)
+	}
+}
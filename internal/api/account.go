@@ -0,0 +1,254 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	Database "github.com/nilesh0729/Notes/internal/db/Result"
+	"github.com/nilesh0729/Notes/internal/mail"
+	"github.com/nilesh0729/Notes/internal/tokens"
+	"github.com/nilesh0729/Notes/internal/util"
+)
+
+var (
+	errInvalidUserToken     = newAPIError(http.StatusBadRequest, CodeBadRequest, "token is invalid or has expired")
+	errEmailAlreadyVerified = newAPIError(http.StatusConflict, CodeConflict, "email is already verified")
+)
+
+// issueUserToken stores the hash of a fresh single-use token for user and
+// returns the token itself.
+func (server *Server) issueUserToken(ctx context.Context, user Database.User, purpose string, duration time.Duration) (string, error) {
+	token, tokenHash, err := util.NewOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	_, err = server.store.CreateUserToken(ctx, Database.CreateUserTokenParams{
+		Username:  user.Username,
+		Purpose:   purpose,
+		TokenHash: tokenHash,
+		Email:     user.Email,
+		ExpiresAt: time.Now().Add(duration),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+func (server *Server) appLink(path, token string) string {
+	return fmt.Sprintf("%s%s?token=%s", server.config.AppBaseURL, path, url.QueryEscape(token))
+}
+
+func (server *Server) sendVerificationEmail(ctx *gin.Context, user Database.User) error {
+	token, err := server.issueUserToken(ctx, user, Database.TokenPurposeVerifyEmail, server.config.VerifyEmailTokenDuration)
+	if err != nil {
+		return err
+	}
+
+	return server.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf(
+			"Hello %s,\n\nPlease confirm your email address by opening the link below:\n\n%s\n\nThe link expires in %s.\n",
+			user.Username, server.appLink("/verify-email", token), server.config.VerifyEmailTokenDuration,
+		),
+	})
+}
+
+func (server *Server) sendPasswordResetEmail(ctx context.Context, user Database.User) error {
+	token, err := server.issueUserToken(ctx, user, Database.TokenPurposeResetPassword, server.config.ResetPasswordTokenDuration)
+	if err != nil {
+		return err
+	}
+
+	return server.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Hello %s,\n\nSomeone asked to reset the password of your account. If it was you, open the link below:\n\n%s\n\nThe link expires in %s. If you did not ask for this, you can ignore this email.\n",
+			user.Username, server.appLink("/password/reset", token), server.config.ResetPasswordTokenDuration,
+		),
+	})
+}
+
+// ResendVerificationEmail sends a new verification link to the authenticated
+// user's current email address.
+func (server *Server) ResendVerificationEmail(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*tokens.Payload)
+
+	user, err := server.store.GetUser(ctx, authPayload.Username)
+	if err != nil {
+		if err == Database.ErrRecordNotFound {
+			abortWithError(ctx, errUserNotFound)
+			return
+		}
+		abortWithError(ctx, err)
+		return
+	}
+
+	if user.EmailVerified {
+		abortWithError(ctx, errEmailAlreadyVerified)
+		return
+	}
+
+	err = server.sendVerificationEmail(ctx, user)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, gin.H{"message": "verification email sent"})
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+func (server *Server) VerifyEmail(ctx *gin.Context) {
+	var req VerifyEmailRequest
+
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	arg := Database.VerifyEmailTxParams{
+		TokenHash: util.HashToken(req.Token),
+	}
+	user, err := server.store.VerifyEmailTx(ctx, arg)
+	if err != nil {
+		if err == Database.ErrRecordNotFound {
+			abortWithError(ctx, errInvalidUserToken)
+			return
+		}
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, UserResponse(user))
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// passwordResetTimeout bounds the background work of a ForgotPassword call.
+const passwordResetTimeout = time.Minute
+
+// ForgotPassword mails a reset link to every account that uses, and has
+// verified, the given address.
+// The accounts are looked up and mailed after the response is sent, so that
+// it is the same, and takes as long, whether or not such an account exists.
+func (server *Server) ForgotPassword(ctx *gin.Context) {
+	var req ForgotPasswordRequest
+
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	// The request context is cancelled once the response is sent; keep its
+	// values, such as the trace span, but not its cancellation.
+	mailCtx := context.WithoutCancel(ctx.Request.Context())
+	logger := requestLogger(ctx)
+	server.goBackground(func() {
+		server.sendPasswordResetEmails(mailCtx, logger, req.Email)
+	})
+
+	ctx.JSON(http.StatusAccepted, gin.H{"message": "if an account uses this email, a reset link has been sent"})
+}
+
+func (server *Server) sendPasswordResetEmails(ctx context.Context, logger *slog.Logger, email string) {
+	ctx, cancel := context.WithTimeout(ctx, passwordResetTimeout)
+	defer cancel()
+
+	users, err := server.store.ListUsersByEmail(ctx, email)
+	if err != nil {
+		logger.ErrorContext(ctx, "cannot look up accounts for password reset", slog.Any("error", err))
+		return
+	}
+
+	for _, user := range users {
+		// Anyone can sign up with any address, so a reset link only goes to
+		// an address the account has shown it owns.
+		if !user.EmailVerified {
+			continue
+		}
+
+		err = server.sendPasswordResetEmail(ctx, user)
+		if err != nil {
+			logger.WarnContext(ctx, "cannot send password reset email", slog.String("username", user.Username), slog.Any("error", err))
+		}
+	}
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+func (server *Server) ResetPassword(ctx *gin.Context) {
+	var req ResetPasswordRequest
+
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	// The token is only consumed by ResetPasswordTx; it is looked up first
+	// to check the new password against the account it belongs to.
+	tokenHash := util.HashToken(req.Token)
+	token, err := server.store.GetActiveUserToken(ctx, Database.GetActiveUserTokenParams{
+		TokenHash: tokenHash,
+		Purpose:   Database.TokenPurposeResetPassword,
+	})
+	if err != nil {
+		if err == Database.ErrRecordNotFound {
+			abortWithError(ctx, errInvalidUserToken)
+			return
+		}
+		abortWithError(ctx, err)
+		return
+	}
+
+	if !server.checkPassword(ctx, "new_password", req.NewPassword, token.Username, token.Email) {
+		return
+	}
+
+	hashedPassword, err := server.passwordHasher.Hash(req.NewPassword)
+	if err != nil {
+		if errors.Is(err, util.ErrPasswordTooLong) {
+			abortWithError(ctx, validationError([]FieldError{passwordTooLongField("new_password")}))
+			return
+		}
+		abortWithError(ctx, err)
+		return
+	}
+
+	arg := Database.ResetPasswordTxParams{
+		TokenHash:      tokenHash,
+		HashedPassword: hashedPassword,
+	}
+	_, err = server.store.ResetPasswordTx(ctx, arg)
+	if err != nil {
+		if err == Database.ErrRecordNotFound {
+			abortWithError(ctx, errInvalidUserToken)
+			return
+		}
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "password has been reset"})
+}
@@ -0,0 +1,458 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockDB "github.com/nilesh0729/Notes/internal/db/Mock"
+	Database "github.com/nilesh0729/Notes/internal/db/Result"
+	"github.com/nilesh0729/Notes/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+var mailedTokenPattern = regexp.MustCompile(`token=([A-Za-z0-9_-]+)`)
+
+// mailedTokens returns every token that was linked in mail written by the
+// test server's log mailer.
+func mailedTokens(t *testing.T, server *Server) []string {
+	data, err := os.ReadFile(server.config.MailLogFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	require.NoError(t, err)
+
+	var tokens []string
+	for _, match := range mailedTokenPattern.FindAllStringSubmatch(string(data), -1) {
+		tokens = append(tokens, match[1])
+	}
+	return tokens
+}
+
+func TestVerifyEmailAPI(t *testing.T) {
+	_, user := RandomUser(t)
+	token, tokenHash, err := util.NewOpaqueToken()
+	require.NoError(t, err)
+
+	verified := user
+	verified.EmailVerified = true
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockDB.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"token": token},
+			buildStubs: func(store *mockDB.MockStore) {
+				arg := Database.VerifyEmailTxParams{TokenHash: tokenHash}
+				store.EXPECT().
+					VerifyEmailTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(verified, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				UserBodyMatching(t, recorder.Body, verified)
+			},
+		},
+		{
+			name: "MissingToken",
+			body: gin.H{},
+			buildStubs: func(store *mockDB.MockStore) {
+				store.EXPECT().
+					VerifyEmailTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidOrUsedToken",
+			body: gin.H{"token": token},
+			buildStubs: func(store *mockDB.MockStore) {
+				store.EXPECT().
+					VerifyEmailTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(Database.User{}, Database.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InternalServerError",
+			body: gin.H{"token": token},
+			buildStubs: func(store *mockDB.MockStore) {
+				store.EXPECT().
+					VerifyEmailTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(Database.User{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockDB.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, _ := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/verify-email", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestForgotPasswordAPI(t *testing.T) {
+	_, user := RandomUser(t)
+	user.EmailVerified = true
+
+	_, unverifiedUser := RandomUser(t)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockDB.MockStore, storedHashes *[][]byte)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, mailed []string, storedHashes [][]byte)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"email": user.Email},
+			buildStubs: func(store *mockDB.MockStore, storedHashes *[][]byte) {
+				store.EXPECT().
+					ListUsersByEmail(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return([]Database.User{user}, nil)
+				store.EXPECT().
+					CreateUserToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg Database.CreateUserTokenParams) (Database.UserToken, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, Database.TokenPurposeResetPassword, arg.Purpose)
+						require.True(t, arg.ExpiresAt.After(time.Now()))
+						*storedHashes = append(*storedHashes, arg.TokenHash)
+						return Database.UserToken{}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, mailed []string, storedHashes [][]byte) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+				require.Len(t, mailed, 1)
+				require.Len(t, storedHashes, 1)
+				require.Equal(t, util.HashToken(mailed[0]), storedHashes[0])
+			},
+		},
+		{
+			name: "UnverifiedEmail",
+			body: gin.H{"email": unverifiedUser.Email},
+			buildStubs: func(store *mockDB.MockStore, storedHashes *[][]byte) {
+				store.EXPECT().
+					ListUsersByEmail(gomock.Any(), gomock.Eq(unverifiedUser.Email)).
+					Times(1).
+					Return([]Database.User{unverifiedUser}, nil)
+				store.EXPECT().
+					CreateUserToken(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, mailed []string, storedHashes [][]byte) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+				require.Empty(t, mailed)
+			},
+		},
+		{
+			name: "UnknownEmail",
+			body: gin.H{"email": util.RandomEmail()},
+			buildStubs: func(store *mockDB.MockStore, storedHashes *[][]byte) {
+				store.EXPECT().
+					ListUsersByEmail(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]Database.User{}, nil)
+				store.EXPECT().
+					CreateUserToken(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, mailed []string, storedHashes [][]byte) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+				require.Empty(t, mailed)
+			},
+		},
+		{
+			name: "InvalidEmail",
+			body: gin.H{"email": "nope"},
+			buildStubs: func(store *mockDB.MockStore, storedHashes *[][]byte) {
+				store.EXPECT().
+					ListUsersByEmail(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, mailed []string, storedHashes [][]byte) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			// Accounts are looked up after the response is sent, so a
+			// failure does not change it either.
+			name: "StoreError",
+			body: gin.H{"email": user.Email},
+			buildStubs: func(store *mockDB.MockStore, storedHashes *[][]byte) {
+				store.EXPECT().
+					ListUsersByEmail(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, mailed []string, storedHashes [][]byte) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+				require.Empty(t, mailed)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var storedHashes [][]byte
+			store := mockDB.NewMockStore(ctrl)
+			tc.buildStubs(store, &storedHashes)
+
+			server, _ := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/password/forgot", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			server.background.Wait()
+			tc.checkResponse(t, recorder, mailedTokens(t, server), storedHashes)
+		})
+	}
+}
+
+func TestResetPasswordAPI(t *testing.T) {
+	_, user := RandomUser(t)
+	token, tokenHash, err := util.NewOpaqueToken()
+	require.NoError(t, err)
+	newPassword := util.RandomString(10)
+
+	resetToken := Database.UserToken{
+		Username: user.Username,
+		Purpose:  Database.TokenPurposeResetPassword,
+		Email:    user.Email,
+	}
+	tokenArg := Database.GetActiveUserTokenParams{
+		TokenHash: tokenHash,
+		Purpose:   Database.TokenPurposeResetPassword,
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockDB.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"token": token, "new_password": newPassword},
+			buildStubs: func(store *mockDB.MockStore) {
+				store.EXPECT().
+					GetActiveUserToken(gomock.Any(), gomock.Eq(tokenArg)).
+					Times(1).
+					Return(resetToken, nil)
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg Database.ResetPasswordTxParams) (Database.User, error) {
+						require.Equal(t, tokenHash, arg.TokenHash)
+						require.NoError(t, util.CheckPassword(newPassword, arg.HashedPassword))
+						return user, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "PasswordTooShort",
+			body: gin.H{"token": token, "new_password": "short"},
+			buildStubs: func(store *mockDB.MockStore) {
+				store.EXPECT().
+					GetActiveUserToken(gomock.Any(), gomock.Eq(tokenArg)).
+					Times(1).
+					Return(resetToken, nil)
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireFieldErrors(t, recorder, "new_password", util.PasswordTooShort)
+			},
+		},
+		{
+			name: "PasswordContainsEmail",
+			body: gin.H{"token": token, "new_password": user.Email + "1"},
+			buildStubs: func(store *mockDB.MockStore) {
+				store.EXPECT().
+					GetActiveUserToken(gomock.Any(), gomock.Eq(tokenArg)).
+					Times(1).
+					Return(resetToken, nil)
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireFieldErrors(t, recorder, "new_password", util.PasswordContainsEmail)
+			},
+		},
+		{
+			name: "InvalidOrExpiredToken",
+			body: gin.H{"token": token, "new_password": newPassword},
+			buildStubs: func(store *mockDB.MockStore) {
+				store.EXPECT().
+					GetActiveUserToken(gomock.Any(), gomock.Eq(tokenArg)).
+					Times(1).
+					Return(Database.UserToken{}, Database.ErrRecordNotFound)
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireBodyError(t, recorder, errInvalidUserToken)
+			},
+		},
+		{
+			// Another request used the token after it was looked up.
+			name: "TokenConsumedConcurrently",
+			body: gin.H{"token": token, "new_password": newPassword},
+			buildStubs: func(store *mockDB.MockStore) {
+				store.EXPECT().
+					GetActiveUserToken(gomock.Any(), gomock.Eq(tokenArg)).
+					Times(1).
+					Return(resetToken, nil)
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(Database.User{}, Database.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireBodyError(t, recorder, errInvalidUserToken)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockDB.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, _ := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/password/reset", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRequireVerifiedEmail(t *testing.T) {
+	_, user := RandomUser(t)
+
+	testCases := []struct {
+		name          string
+		verified      bool
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "Verified",
+			verified: true,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "Unverified",
+			verified: false,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			current := user
+			current.EmailVerified = tc.verified
+
+			store := mockDB.NewMockStore(ctrl)
+			store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(current, nil)
+			store.EXPECT().
+				ListTags(gomock.Any(), gomock.Any()).
+				MaxTimes(1).
+				Return([]Database.Tag{}, nil)
+			allowSessions(store)
+
+			config := newTestConfig(t)
+			config.RequireVerifiedEmail = true
+			server, err := NewServer(config, store)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, "/tags?page_size=5", nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, AuthorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	Database "github.com/nilesh0729/Notes/internal/db/Result"
+	"github.com/nilesh0729/Notes/internal/tokens"
+	"github.com/nilesh0729/Notes/internal/util"
+)
+
+// Scopes an API key can be created with. Read-only keys can list and read
+// notes and tags but not change them.
+const (
+	APIKeyScopeReadOnly  = "read-only"
+	APIKeyScopeReadWrite = "read-write"
+)
+
+const (
+	apiKeyPrefix       = "nk_"
+	apiKeyPrefixLength = len(apiKeyPrefix) + 8
+)
+
+var (
+	errInvalidAPIKey  = newAPIError(http.StatusUnauthorized, CodeUnauthorized, "api key is invalid or has expired")
+	errAPIKeyNotFound = newAPIError(http.StatusNotFound, CodeNotFound, "api key not found")
+)
+
+type APIKeyResponseFormat struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scope      string     `json:"scope"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func APIKeyResponse(key Database.ApiKey) APIKeyResponseFormat {
+	res := APIKeyResponseFormat{
+		ID:        key.ID,
+		Name:      key.Name,
+		Prefix:    key.Prefix,
+		Scope:     key.Scope,
+		ExpiresAt: key.ExpiresAt,
+		CreatedAt: key.CreatedAt,
+	}
+	if key.LastUsedAt.Valid {
+		res.LastUsedAt = &key.LastUsedAt.Time
+	}
+	return res
+}
+
+type CreateAPIKeyRequest struct {
+	Name          string `json:"name" binding:"required,max=100"`
+	Scope         string `json:"scope" binding:"required,oneof=read-only read-write"`
+	ExpiresInDays int    `json:"expires_in_days" binding:"required,min=1,max=365"`
+}
+
+type CreateAPIKeyResponse struct {
+	Key    string               `json:"key"`
+	APIKey APIKeyResponseFormat `json:"api_key"`
+}
+
+// CreateAPIKey issues a personal API key. The key itself is only part of
+// this response; afterwards just its hash is known.
+func (server *Server) CreateAPIKey(ctx *gin.Context) {
+	var req CreateAPIKeyRequest
+
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*tokens.Payload)
+
+	secret, _, err := util.NewOpaqueToken()
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+	key := apiKeyPrefix + secret
+
+	arg := Database.CreateAPIKeyParams{
+		Username:  authPayload.Username,
+		Name:      req.Name,
+		Prefix:    key[:apiKeyPrefixLength],
+		KeyHash:   util.HashToken(key),
+		Scope:     req.Scope,
+		ExpiresAt: time.Now().AddDate(0, 0, req.ExpiresInDays),
+	}
+	apiKey, err := server.store.CreateAPIKey(ctx, arg)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, CreateAPIKeyResponse{
+		Key:    key,
+		APIKey: APIKeyResponse(apiKey),
+	})
+}
+
+func (server *Server) ListAPIKeys(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*tokens.Payload)
+
+	keys, err := server.store.ListAPIKeys(ctx, authPayload.Username)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	res := make([]APIKeyResponseFormat, len(keys))
+	for i, key := range keys {
+		res[i] = APIKeyResponse(key)
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+type DeleteAPIKeyRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) DeleteAPIKey(ctx *gin.Context) {
+	var req DeleteAPIKeyRequest
+
+	err := ctx.ShouldBindUri(&req)
+	if err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*tokens.Payload)
+
+	_, err = server.store.DeleteAPIKey(ctx, Database.DeleteAPIKeyParams{
+		ID:       req.ID,
+		Username: authPayload.Username,
+	})
+	if err != nil {
+		if err == Database.ErrRecordNotFound {
+			abortWithError(ctx, errAPIKeyNotFound)
+			return
+		}
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "api key deleted"})
+}
+
+// apiKeyScopes returns the token scopes granted by an API key scope. Keys
+// never get ScopeAccount, so they cannot change the account they belong to.
+func apiKeyScopes(scope string) []string {
+	if scope == APIKeyScopeReadWrite {
+		return []string{ScopeNotesRead, ScopeNotesWrite}
+	}
+	return []string{ScopeNotesRead}
+}
+
+func isAPIKey(key string) bool {
+	return strings.HasPrefix(key, apiKeyPrefix) && len(key) > apiKeyPrefixLength
+}
@@ -0,0 +1,316 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	mockDB "github.com/nilesh0729/Notes/internal/db/Mock"
+	Database "github.com/nilesh0729/Notes/internal/db/Result"
+	"github.com/nilesh0729/Notes/internal/tokens"
+	"github.com/nilesh0729/Notes/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func randomAPIKey(username, scope string) Database.ApiKey {
+	return Database.ApiKey{
+		ID:        util.RandomInt(1, 1000),
+		Username:  username,
+		Name:      util.RandomString(8),
+		Prefix:    apiKeyPrefix + util.RandomString(8),
+		KeyHash:   util.HashToken(util.RandomString(32)),
+		Scope:     scope,
+		ExpiresAt: time.Now().Add(24 * time.Hour),
+		CreatedAt: time.Now(),
+	}
+}
+
+func TestCreateAPIKeyAPI(t *testing.T) {
+	_, user := RandomUser(t)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker tokens.Maker)
+		buildStubs    func(store *mockDB.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"name":            "backup script",
+				"scope":           APIKeyScopeReadOnly,
+				"expires_in_days": 30,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker tokens.Maker) {
+				addAuthorization(t, request, tokenMaker, AuthorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockDB.MockStore) {
+				store.EXPECT().
+					CreateAPIKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg Database.CreateAPIKeyParams) (Database.ApiKey, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, "backup script", arg.Name)
+						require.Equal(t, APIKeyScopeReadOnly, arg.Scope)
+						require.Len(t, arg.Prefix, apiKeyPrefixLength)
+						require.WithinDuration(t, time.Now().AddDate(0, 0, 30), arg.ExpiresAt, time.Minute)
+						return Database.ApiKey{
+							ID:        1,
+							Username:  arg.Username,
+							Name:      arg.Name,
+							Prefix:    arg.Prefix,
+							KeyHash:   arg.KeyHash,
+							Scope:     arg.Scope,
+							ExpiresAt: arg.ExpiresAt,
+						}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res CreateAPIKeyResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &res)
+				require.NoError(t, err)
+				require.True(t, isAPIKey(res.Key))
+				require.Equal(t, res.Key[:apiKeyPrefixLength], res.APIKey.Prefix)
+				require.Nil(t, res.APIKey.LastUsedAt)
+				require.NotContains(t, recorder.Body.String(), "key_hash")
+			},
+		},
+		{
+			name: "InvalidScope",
+			body: gin.H{
+				"name":            "backup script",
+				"scope":           "admin",
+				"expires_in_days": 30,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker tokens.Maker) {
+				addAuthorization(t, request, tokenMaker, AuthorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockDB.MockStore) {
+				store.EXPECT().
+					CreateAPIKey(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "ExpiryTooLong",
+			body: gin.H{
+				"name":            "backup script",
+				"scope":           APIKeyScopeReadWrite,
+				"expires_in_days": 1000,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker tokens.Maker) {
+				addAuthorization(t, request, tokenMaker, AuthorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockDB.MockStore) {
+				store.EXPECT().
+					CreateAPIKey(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "AuthenticatedWithAPIKey",
+			body: gin.H{
+				"name":            "backup script",
+				"scope":           APIKeyScopeReadWrite,
+				"expires_in_days": 30,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker tokens.Maker) {
+				request.Header.Set(AuthorizationHeaderKey, "ApiKey "+apiKeyPrefix+util.RandomString(43))
+			},
+			buildStubs: func(store *mockDB.MockStore) {
+				store.EXPECT().
+					GetAPIKeyByHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(randomAPIKey(user.Username, APIKeyScopeReadWrite), nil)
+				store.EXPECT().
+					TouchAPIKey(gomock.Any(), gomock.Any()).
+					Times(1)
+				store.EXPECT().
+					CreateAPIKey(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "InternalServerError",
+			body: gin.H{
+				"name":            "backup script",
+				"scope":           APIKeyScopeReadWrite,
+				"expires_in_days": 30,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker tokens.Maker) {
+				addAuthorization(t, request, tokenMaker, AuthorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockDB.MockStore) {
+				store.EXPECT().
+					CreateAPIKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(Database.ApiKey{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockDB.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			allowSessions(store)
+
+			server, _ := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/me/api-keys", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListAPIKeysAPI(t *testing.T) {
+	_, user := RandomUser(t)
+
+	keys := []Database.ApiKey{
+		randomAPIKey(user.Username, APIKeyScopeReadOnly),
+		randomAPIKey(user.Username, APIKeyScopeReadWrite),
+	}
+	keys[1].LastUsedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockDB.NewMockStore(ctrl)
+	store.EXPECT().
+		ListAPIKeys(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		Return(keys, nil)
+	allowSessions(store)
+
+	server, _ := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/me/api-keys", nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, AuthorizationTypeBearer, user.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var res []APIKeyResponseFormat
+	err = json.Unmarshal(recorder.Body.Bytes(), &res)
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	require.Equal(t, keys[0].ID, res[0].ID)
+	require.Nil(t, res[0].LastUsedAt)
+	require.NotNil(t, res[1].LastUsedAt)
+}
+
+func TestDeleteAPIKeyAPI(t *testing.T) {
+	_, user := RandomUser(t)
+	key := randomAPIKey(user.Username, APIKeyScopeReadWrite)
+
+	testCases := []struct {
+		name          string
+		id            int64
+		buildStubs    func(store *mockDB.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			id:   key.ID,
+			buildStubs: func(store *mockDB.MockStore) {
+				arg := Database.DeleteAPIKeyParams{
+					ID:       key.ID,
+					Username: user.Username,
+				}
+				store.EXPECT().
+					DeleteAPIKey(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(key, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "NotFound",
+			id:   key.ID,
+			buildStubs: func(store *mockDB.MockStore) {
+				store.EXPECT().
+					DeleteAPIKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(Database.ApiKey{}, Database.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidID",
+			id:   0,
+			buildStubs: func(store *mockDB.MockStore) {
+				store.EXPECT().
+					DeleteAPIKey(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockDB.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			allowSessions(store)
+
+			server, _ := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/me/api-keys/%d", tc.id)
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, AuthorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	Database "github.com/nilesh0729/Notes/internal/db/Result"
+	"github.com/nilesh0729/Notes/internal/tokens"
+)
+
+// Codes of APIError. Clients should branch on these, not on messages.
+const (
+	CodeBadRequest         = "bad_request"
+	CodeValidationFailed   = "validation_failed"
+	CodeUnauthorized       = "unauthorized"
+	CodeTokenExpired       = "token_expired"
+	CodeInvalidCredentials = "invalid_credentials"
+	CodeForbidden          = "forbidden"
+	CodeNotFound           = "not_found"
+	CodeConflict           = "conflict"
+	CodeInvalidReference   = "invalid_reference"
+	CodePayloadTooLarge    = "payload_too_large"
+	CodeQuotaExceeded      = "quota_exceeded"
+	CodeTooManyRequests    = "too_many_requests"
+	CodeInternal           = "internal_error"
+)
+
+// APIError is what a failed request answers with, wrapped in an "error"
+// object. Details lists the rejected fields of a request that failed
+// validation, and RequestID lets a client report the request that failed.
+type APIError struct {
+	Status    int          `json:"status"`
+	Code      string       `json:"code"`
+	Message   string       `json:"message"`
+	Details   []FieldError `json:"details,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+
+	// cause is logged, but never sent to the client.
+	cause error
+}
+
+func newAPIError(status int, code, message string) *APIError {
+	return &APIError{Status: status, Code: code, Message: message}
+}
+
+func (apiErr *APIError) Error() string {
+	return apiErr.Message
+}
+
+func (apiErr *APIError) Unwrap() error {
+	return apiErr.cause
+}
+
+// ErrorResponse is the body of every error response.
+type ErrorResponse struct {
+	Error *APIError `json:"error"`
+}
+
+var (
+	errNotFound         = newAPIError(http.StatusNotFound, CodeNotFound, "resource not found")
+	errAlreadyExists    = newAPIError(http.StatusConflict, CodeConflict, "resource already exists")
+	errInvalidReference = newAPIError(http.StatusUnprocessableEntity, CodeInvalidReference, "request refers to a resource that does not exist")
+	errTokenExpired     = newAPIError(http.StatusUnauthorized, CodeTokenExpired, "access token has expired")
+	errTokenInvalid     = newAPIError(http.StatusUnauthorized, CodeUnauthorized, "access token is invalid")
+	errInternal         = newAPIError(http.StatusInternalServerError, CodeInternal, "internal server error")
+)
+
+// toAPIError maps err to the error the client is told about. Errors without
+// a mapping become an internal error, so their text is never leaked.
+func toAPIError(err error) *APIError {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		res := *apiErr
+		return &res
+	}
+
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		return validationError(fieldErrors(validationErrs))
+	}
+
+	switch Database.ErrorCode(err) {
+	case Database.UniqueViolation:
+		return withCause(errAlreadyExists, err)
+	case Database.ForeignKeyViolation:
+		return withCause(errInvalidReference, err)
+	}
+
+	switch {
+	case errors.Is(err, Database.ErrRecordNotFound):
+		return withCause(errNotFound, err)
+	case errors.Is(err, tokens.ErrExpiredToken):
+		return withCause(errTokenExpired, err)
+	case errors.Is(err, tokens.ErrInvalidToken), errors.Is(err, tokens.ErrTokenNotValidYet):
+		return withCause(errTokenInvalid, err)
+	}
+
+	return withCause(errInternal, err)
+}
+
+// withCause returns a copy of apiErr caused by err.
+func withCause(apiErr *APIError, err error) *APIError {
+	res := *apiErr
+	res.cause = err
+	return &res
+}
+
+// bindingError maps an error from binding a request body, URI or query.
+// Failed validation rules are reported per field; anything else means the
+// request could not be decoded at all.
+func bindingError(err error) *APIError {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		return toAPIError(err)
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		message := fmt.Sprintf("request body is larger than %d bytes", maxBytesErr.Limit)
+		return withCause(newAPIError(http.StatusRequestEntityTooLarge, CodePayloadTooLarge, message), err)
+	}
+
+	message := "request is malformed"
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var numErr *strconv.NumError
+	switch {
+	case errors.Is(err, io.EOF):
+		message = "request body is empty"
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		message = "request body is not valid JSON"
+	case errors.As(err, &typeErr):
+		message = fmt.Sprintf("%s must be a %s", typeErr.Field, typeErr.Type)
+	case errors.As(err, &numErr):
+		message = fmt.Sprintf("%q is not a valid number", numErr.Num)
+	}
+
+	return withCause(newAPIError(http.StatusBadRequest, CodeBadRequest, message), err)
+}
+
+// abortWithError answers the request with the API error err maps to and
+// stops the handler chain. Internal errors are logged with their cause.
+func abortWithError(ctx *gin.Context, err error) {
+	apiErr := toAPIError(err)
+	apiErr.RequestID = requestID(ctx)
+
+	if apiErr.Status >= http.StatusInternalServerError {
+		requestLogger(ctx).ErrorContext(ctx, "request failed",
+			slog.String("method", ctx.Request.Method),
+			slog.String("path", ctx.Request.URL.Path),
+			slog.Any("error", err),
+		)
+	}
+
+	_ = ctx.Error(err)
+	ctx.AbortWithStatusJSON(apiErr.Status, ErrorResponse{Error: apiErr})
+}
@@ -0,0 +1,189 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgconn"
+	Database "github.com/nilesh0729/Notes/internal/db/Result"
+	"github.com/nilesh0729/Notes/internal/tokens"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToAPIError(t *testing.T) {
+	testCases := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{
+			name:   "APIError",
+			err:    fmt.Errorf("wrapped: %w", errNoteNotFound),
+			status: http.StatusNotFound,
+			code:   CodeNotFound,
+		},
+		{
+			name:   "NoRows",
+			err:    fmt.Errorf("get note: %w", Database.ErrRecordNotFound),
+			status: http.StatusNotFound,
+			code:   CodeNotFound,
+		},
+		{
+			name:   "UniqueViolation",
+			err:    &pgconn.PgError{Code: Database.UniqueViolation},
+			status: http.StatusConflict,
+			code:   CodeConflict,
+		},
+		{
+			name:   "ForeignKeyViolation",
+			err:    fmt.Errorf("add tag: %w", &pgconn.PgError{Code: Database.ForeignKeyViolation}),
+			status: http.StatusUnprocessableEntity,
+			code:   CodeInvalidReference,
+		},
+		{
+			name:   "OtherPgError",
+			err:    &pgconn.PgError{Code: "42601"},
+			status: http.StatusInternalServerError,
+			code:   CodeInternal,
+		},
+		{
+			name:   "ExpiredToken",
+			err:    tokens.ErrExpiredToken,
+			status: http.StatusUnauthorized,
+			code:   CodeTokenExpired,
+		},
+		{
+			name:   "InvalidToken",
+			err:    tokens.ErrInvalidToken,
+			status: http.StatusUnauthorized,
+			code:   CodeUnauthorized,
+		},
+		{
+			name:   "Unknown",
+			err:    errors.New("connection refused to 10.0.0.1"),
+			status: http.StatusInternalServerError,
+			code:   CodeInternal,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			apiErr := toAPIError(tc.err)
+			require.Equal(t, tc.status, apiErr.Status)
+			require.Equal(t, tc.code, apiErr.Code)
+			require.NotContains(t, apiErr.Message, "10.0.0.1")
+		})
+	}
+}
+
+func TestToAPIErrorCopiesSentinel(t *testing.T) {
+	apiErr := toAPIError(errNoteNotFound)
+	apiErr.RequestID = "request"
+
+	require.Empty(t, errNoteNotFound.RequestID)
+	require.NotSame(t, errNoteNotFound, apiErr)
+}
+
+func TestBindingError(t *testing.T) {
+	type request struct {
+		Title string `json:"title" binding:"required"`
+		Count int    `json:"count"`
+	}
+
+	testCases := []struct {
+		name    string
+		body    string
+		code    string
+		message string
+	}{
+		{
+			name:    "EmptyBody",
+			body:    "",
+			code:    CodeBadRequest,
+			message: "request body is empty",
+		},
+		{
+			name:    "InvalidJSON",
+			body:    `{"title":`,
+			code:    CodeBadRequest,
+			message: "request body is not valid JSON",
+		},
+		{
+			name:    "WrongType",
+			body:    `{"title":"a","count":"many"}`,
+			code:    CodeBadRequest,
+			message: "count must be a int",
+		},
+		{
+			name:    "MissingField",
+			body:    `{"count":1}`,
+			code:    CodeValidationFailed,
+			message: "request validation failed",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			registerFieldNames()
+
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.body))
+
+			var req request
+			err := ctx.ShouldBindJSON(&req)
+			require.Error(t, err)
+
+			apiErr := bindingError(err)
+			require.Equal(t, http.StatusBadRequest, apiErr.Status)
+			require.Equal(t, tc.code, apiErr.Code)
+			require.Equal(t, tc.message, apiErr.Message)
+			if tc.code == CodeValidationFailed {
+				require.Equal(t, []FieldError{{Field: "title", Code: "required", Message: "is required"}}, apiErr.Details)
+			}
+		})
+	}
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	testCases := []struct {
+		name   string
+		header string
+		keep   bool
+	}{
+		{name: "Generated", header: "", keep: false},
+		{name: "Honoured", header: "client-request-1", keep: true},
+		{name: "TooLong", header: strings.Repeat("a", maxRequestIDLength+1), keep: false},
+		{name: "Unprintable", header: "bad\x01id", keep: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(requestIDMiddleware())
+			router.GET("/", func(ctx *gin.Context) {
+				abortWithError(ctx, errNotFound)
+			})
+
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.header != "" {
+				request.Header.Set(RequestIDHeaderKey, tc.header)
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			apiErr := requireAPIError(t, recorder, CodeNotFound)
+			if tc.keep {
+				require.Equal(t, tc.header, apiErr.RequestID)
+			} else {
+				require.NotEqual(t, tc.header, apiErr.RequestID)
+			}
+		})
+	}
+}
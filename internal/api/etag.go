@@ -0,0 +1,48 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondWithETag answers with obj as JSON, tagged with an ETag derived
+// from the body. If the request's If-None-Match names that ETag, the
+// client's copy is current and the answer is 304 Not Modified instead.
+func respondWithETag(ctx *gin.Context, obj any) {
+	body, err := json.Marshal(obj)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`
+
+	// The response depends on the authenticated user, and clients have to
+	// check with the server before reusing it.
+	ctx.Header("ETag", etag)
+	ctx.Header("Cache-Control", "private, no-cache")
+
+	if etagMatches(ctx.GetHeader("If-None-Match"), etag) {
+		ctx.Status(http.StatusNotModified)
+		return
+	}
+	ctx.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
+
+// etagMatches reports whether the If-None-Match header ifNoneMatch names
+// etag. As RFC 9110 asks, weak ETags match their strong equivalent.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
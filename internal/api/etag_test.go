@@ -0,0 +1,102 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockDB "github.com/nilesh0729/Notes/internal/db/Mock"
+	Database "github.com/nilesh0729/Notes/internal/db/Result"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetNoteETag(t *testing.T) {
+	note := RandomNotes()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockDB.NewMockStore(ctrl)
+	allowSessions(store)
+	store.EXPECT().GetNoteById(gomock.Any(), note.NoteID).Times(2).Return(note, nil)
+	store.EXPECT().GetTagsForNote(gomock.Any(), note.NoteID).Times(2).Return([]Database.GetTagsForNoteRow{}, nil)
+
+	server, _ := newTestServer(t, store)
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/notes/%d", note.NoteID), nil)
+		addAuthorization(t, request, server.tokenMaker, AuthorizationTypeBearer, note.Owner, time.Minute)
+		if ifNoneMatch != "" {
+			request.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	recorder := get("")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	etag := recorder.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	NoteBodyMatching(t, recorder.Body, note)
+
+	recorder = get(`"stale", W/` + etag)
+	require.Equal(t, http.StatusNotModified, recorder.Code)
+	require.Equal(t, etag, recorder.Header().Get("ETag"))
+	require.Empty(t, recorder.Body.Bytes())
+
+	// Someone else's note is not found out through its ETag.
+	otherNote := note
+	otherNote.Owner = "other"
+	store.EXPECT().GetNoteById(gomock.Any(), note.NoteID).Return(otherNote, nil)
+	recorder = get(etag)
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+func TestListTagsETag(t *testing.T) {
+	tags := []Database.Tag{RandomTag(), RandomTag()}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockDB.NewMockStore(ctrl)
+	allowSessions(store)
+	gomock.InOrder(
+		store.EXPECT().ListTags(gomock.Any(), gomock.Any()).Times(2).Return(tags, nil),
+		store.EXPECT().ListTags(gomock.Any(), gomock.Any()).Return(tags[:1], nil),
+	)
+
+	server, _ := newTestServer(t, store)
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, "/tags?tag_id=0&page_size=5", nil)
+		addAuthorization(t, request, server.tokenMaker, AuthorizationTypeBearer, "user", time.Minute)
+		request.Header.Set("If-None-Match", ifNoneMatch)
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	recorder := get("")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	etag := recorder.Header().Get("ETag")
+	TagsResponseMatching(t, recorder.Body, tags)
+
+	recorder = get(etag)
+	require.Equal(t, http.StatusNotModified, recorder.Code)
+
+	// Once the tags change, so does the ETag.
+	recorder = get(etag)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.NotEqual(t, etag, recorder.Header().Get("ETag"))
+}
+
+func TestETagMatches(t *testing.T) {
+	require.True(t, etagMatches(`"a"`, `"a"`))
+	require.True(t, etagMatches(`"b", "a"`, `"a"`))
+	require.True(t, etagMatches(`W/"a"`, `"a"`))
+	require.True(t, etagMatches(`*`, `"a"`))
+	require.False(t, etagMatches(``, `"a"`))
+	require.False(t, etagMatches(`"b"`, `"a"`))
+}
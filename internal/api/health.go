@@ -0,0 +1,78 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nilesh0729/Notes/internal/db"
+)
+
+// readinessTimeout bounds the database checks of GET /readyz, so a stuck
+// database fails the probe instead of hanging it.
+const readinessTimeout = 2 * time.Second
+
+// Statuses reported by the health endpoints.
+const (
+	HealthOK          = "ok"
+	HealthUnavailable = "unavailable"
+)
+
+// HealthResponse is the body of GET /healthz and GET /readyz. Checks maps
+// each dependency checked to "ok" or to why it failed.
+type HealthResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// Healthz answers as long as the process can serve requests at all.
+func (server *Server) Healthz(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, HealthResponse{Status: HealthOK})
+}
+
+// Readyz reports whether the server should be sent traffic: the database
+// must be reachable and migrated to the schema the queries expect.
+func (server *Server) Readyz(ctx *gin.Context) {
+	checkCtx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	checks := map[string]string{"database": HealthOK}
+
+	err := server.store.Ping(checkCtx)
+	if err != nil {
+		requestLogger(ctx).WarnContext(ctx, "database ping failed", slog.Any("error", err))
+		checks["database"] = "unreachable"
+		checks["migrations"] = "unknown"
+	} else {
+		checks["migrations"] = server.checkMigrations(checkCtx)
+	}
+	ready := checks["database"] == HealthOK && checks["migrations"] == HealthOK
+
+	res := HealthResponse{Status: HealthOK, Checks: checks}
+	status := http.StatusOK
+	if !ready {
+		res.Status = HealthUnavailable
+		status = http.StatusServiceUnavailable
+	}
+	ctx.JSON(status, res)
+}
+
+// checkMigrations returns HealthOK if the schema is at least at the version
+// of the newest embedded migration and its last migration did not fail.
+func (server *Server) checkMigrations(ctx context.Context) string {
+	want := int64(db.LatestVersion(server.config.DBDriver))
+
+	version, dirty, err := server.store.MigrationVersion(ctx)
+	switch {
+	case err != nil:
+		return "cannot read migration version"
+	case dirty:
+		return fmt.Sprintf("migration %d failed", version)
+	case version < want:
+		return fmt.Sprintf("schema version %d, want %d", version, want)
+	}
+	return HealthOK
+}
@@ -0,0 +1,189 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/nilesh0729/Notes/internal/db"
+	mockDB "github.com/nilesh0729/Notes/internal/db/Mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthz(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockDB.NewMockStore(ctrl)
+	store.EXPECT().Ping(gomock.Any()).Times(0)
+
+	server, _ := newTestServer(t, store)
+
+	recorder := httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var res HealthResponse
+	err := json.Unmarshal(recorder.Body.Bytes(), &res)
+	require.NoError(t, err)
+	require.Equal(t, HealthOK, res.Status)
+}
+
+func TestReadyz(t *testing.T) {
+	schemaVersion := int64(db.LatestVersion(db.DriverPostgres))
+
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockDB.MockStore)
+		status     int
+		checks     map[string]string
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockDB.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(schemaVersion, false, nil)
+			},
+			status: http.StatusOK,
+			checks: map[string]string{"database": HealthOK, "migrations": HealthOK},
+		},
+		{
+			name: "NewerSchema",
+			buildStubs: func(store *mockDB.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(schemaVersion+1, false, nil)
+			},
+			status: http.StatusOK,
+			checks: map[string]string{"database": HealthOK, "migrations": HealthOK},
+		},
+		{
+			name: "DatabaseUnreachable",
+			buildStubs: func(store *mockDB.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(errors.New("connection refused"))
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(0)
+			},
+			status: http.StatusServiceUnavailable,
+			checks: map[string]string{"database": "unreachable", "migrations": "unknown"},
+		},
+		{
+			name: "SchemaBehind",
+			buildStubs: func(store *mockDB.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(schemaVersion-1, false, nil)
+			},
+			status: http.StatusServiceUnavailable,
+			checks: map[string]string{
+				"database":   HealthOK,
+				"migrations": fmt.Sprintf("schema version %d, want %d", schemaVersion-1, schemaVersion),
+			},
+		},
+		{
+			name: "DirtyMigration",
+			buildStubs: func(store *mockDB.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(schemaVersion, true, nil)
+			},
+			status: http.StatusServiceUnavailable,
+			checks: map[string]string{
+				"database":   HealthOK,
+				"migrations": fmt.Sprintf("migration %d failed", schemaVersion),
+			},
+		},
+		{
+			name: "MigrationVersionError",
+			buildStubs: func(store *mockDB.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(int64(0), false, errors.New("relation does not exist"))
+			},
+			status: http.StatusServiceUnavailable,
+			checks: map[string]string{"database": HealthOK, "migrations": "cannot read migration version"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockDB.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, _ := newTestServer(t, store)
+
+			recorder := httptest.NewRecorder()
+			server.router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			require.Equal(t, tc.status, recorder.Code)
+
+			var res HealthResponse
+			err := json.Unmarshal(recorder.Body.Bytes(), &res)
+			require.NoError(t, err)
+			require.Equal(t, tc.checks, res.Checks)
+			if tc.status == http.StatusOK {
+				require.Equal(t, HealthOK, res.Status)
+			} else {
+				require.Equal(t, HealthUnavailable, res.Status)
+			}
+		})
+	}
+}
+
+func TestStartDrainsRequests(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server, _ := newTestServer(t, mockDB.NewMockStore(ctrl))
+	server.config.ShutdownTimeout = 5 * time.Second
+
+	started := make(chan struct{})
+	server.router.GET("/slow", func(ctx *gin.Context) {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		ctx.String(http.StatusOK, "done")
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- server.Start(ctx, address)
+	}()
+
+	// Retry until the server is listening.
+	responses := make(chan *http.Response, 1)
+	go func() {
+		for {
+			res, err := http.Get("http://" + address + "/slow")
+			if err == nil {
+				responses <- res
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+
+	<-started
+	cancel()
+
+	// The request in flight when the server was told to stop still succeeds.
+	res := <-responses
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.NoError(t, <-stopped)
+
+	_, err = http.Get("http://" + address + "/healthz")
+	require.Error(t, err)
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nilesh0729/Notes/internal/tokens"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const loggerKey = "logger"
+
+// accessLogMiddleware logs every request once it has been handled, with the
+// user it was made by if it was authenticated. It also gives the handlers a
+// logger tagged with the request and trace IDs, see requestLogger.
+func (server *Server) accessLogMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		logger := server.logger.With(slog.String("request_id", requestID(ctx)))
+		if spanContext := trace.SpanContextFromContext(ctx.Request.Context()); spanContext.IsValid() {
+			logger = logger.With(slog.String("trace_id", spanContext.TraceID().String()))
+		}
+		ctx.Set(loggerKey, logger)
+
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		attrs := []slog.Attr{
+			slog.String("method", ctx.Request.Method),
+			slog.String("path", ctx.Request.URL.Path),
+			slog.String("route", ctx.FullPath()),
+			slog.Int("status", status),
+			slog.Duration("latency", time.Since(start)),
+			slog.Int("bytes", ctx.Writer.Size()),
+			slog.String("client_ip", ctx.ClientIP()),
+			slog.String("user_agent", ctx.Request.UserAgent()),
+		}
+		if payload, ok := ctx.Get(AuthorizationPayloadKey); ok {
+			attrs = append(attrs, slog.String("username", payload.(*tokens.Payload).Username))
+		}
+		if len(ctx.Errors) > 0 {
+			attrs = append(attrs, slog.String("error", ctx.Errors.String()))
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		case isProbeRoute(ctx.FullPath()):
+			// Probes would drown out everything else.
+			level = slog.LevelDebug
+		}
+		logger.LogAttrs(ctx, level, "request", attrs...)
+	}
+}
+
+// recoveryMiddleware answers a request whose handler panicked with an
+// internal error and logs the panic with its stack.
+func recoveryMiddleware() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(ctx *gin.Context, recovered any) {
+		requestLogger(ctx).ErrorContext(ctx, "handler panicked",
+			slog.Any("panic", recovered),
+			slog.String("stack", string(debug.Stack())),
+		)
+		abortWithError(ctx, errInternal)
+	})
+}
+
+// requestLogger returns the logger for messages about the request, which
+// carries its request ID.
+func requestLogger(ctx *gin.Context) *slog.Logger {
+	if logger, ok := ctx.Get(loggerKey); ok {
+		return logger.(*slog.Logger)
+	}
+	return slog.Default().With(slog.String("request_id", requestID(ctx)))
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockDB "github.com/nilesh0729/Notes/internal/db/Mock"
+	"github.com/stretchr/testify/require"
+)
+
+// captureLogs makes server log JSON into the returned buffer.
+func captureLogs(server *Server) *bytes.Buffer {
+	var buf bytes.Buffer
+	server.logger = slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	return &buf
+}
+
+// logEntries decodes the JSON log lines in buf.
+func logEntries(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	var entries []map[string]any
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		var entry map[string]any
+		err := json.Unmarshal(scanner.Bytes(), &entry)
+		require.NoError(t, err)
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestAccessLog(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockDB.NewMockStore(ctrl)
+	allowSessions(store)
+
+	server, tokenMaker := newTestServer(t, store)
+	logs := captureLogs(server)
+
+	request, err := http.NewRequest(http.MethodGet, "/notes/0", nil)
+	require.NoError(t, err)
+	request.Header.Set(RequestIDHeaderKey, "access-log-test")
+	addAuthorization(t, request, tokenMaker, AuthorizationTypeBearer, "alice", time.Minute)
+
+	recorder := httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	entries := logEntries(t, logs)
+	require.Len(t, entries, 1)
+
+	entry := entries[0]
+	require.Equal(t, "request", entry["msg"])
+	require.Equal(t, "WARN", entry["level"])
+	require.Equal(t, "access-log-test", entry["request_id"])
+	require.Equal(t, http.MethodGet, entry["method"])
+	require.Equal(t, "/notes/0", entry["path"])
+	require.Equal(t, "/notes/:id", entry["route"])
+	require.EqualValues(t, http.StatusBadRequest, entry["status"])
+	require.Equal(t, "alice", entry["username"])
+	require.Contains(t, entry, "latency")
+	require.Contains(t, entry, "error")
+}
+
+func TestAccessLogAnonymous(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server, _ := newTestServer(t, mockDB.NewMockStore(ctrl))
+	logs := captureLogs(server)
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/notes", nil)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	entries := logEntries(t, logs)
+	require.Len(t, entries, 1)
+	require.NotContains(t, entries[0], "username")
+	require.Equal(t, recorder.Header().Get(RequestIDHeaderKey), entries[0]["request_id"])
+}
+
+func TestRecoveryLogsPanic(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server, _ := newTestServer(t, mockDB.NewMockStore(ctrl))
+	logs := captureLogs(server)
+
+	server.router.GET("/panic", func(ctx *gin.Context) {
+		panic("boom")
+	})
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/panic", nil)
+	server.router.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusInternalServerError, recorder.Code)
+	apiErr := requireAPIError(t, recorder, CodeInternal)
+
+	entries := logEntries(t, logs)
+	require.NotEmpty(t, entries)
+
+	var panicked, accessLogged bool
+	for _, entry := range entries {
+		require.Equal(t, apiErr.RequestID, entry["request_id"])
+		switch entry["msg"] {
+		case "handler panicked":
+			panicked = true
+			require.Equal(t, "boom", entry["panic"])
+			require.NotEmpty(t, entry["stack"])
+		case "request":
+			accessLogged = true
+			require.Equal(t, "ERROR", entry["level"])
+			require.EqualValues(t, http.StatusInternalServerError, entry["status"])
+		}
+	}
+	require.True(t, panicked)
+	require.True(t, accessLogged)
+}
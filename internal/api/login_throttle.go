@@ -0,0 +1,134 @@
+package api
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nilesh0729/Notes/internal/util"
+)
+
+// loginThrottle keeps track of failed logins per username and per client IP.
+// Every failure doubles the time the next attempt has to wait, and once a key
+// reaches its attempt limit it is locked out for the configured duration.
+type loginThrottle struct {
+	mu      sync.Mutex
+	entries map[string]*loginFailures
+	now     func() time.Time
+
+	userMaxAttempts int
+	ipMaxAttempts   int
+	backoffBase     time.Duration
+	backoffMax      time.Duration
+	lockout         time.Duration
+	lastSweep       time.Time
+}
+
+type loginFailures struct {
+	count        int
+	last         time.Time
+	blockedUntil time.Time
+}
+
+func newLoginThrottle(config util.Config) *loginThrottle {
+	return &loginThrottle{
+		entries:         make(map[string]*loginFailures),
+		now:             time.Now,
+		userMaxAttempts: config.LoginMaxAttempts,
+		ipMaxAttempts:   config.LoginIPMaxAttempts,
+		backoffBase:     config.LoginBackoffBase,
+		backoffMax:      config.LoginBackoffMax,
+		lockout:         config.LoginLockoutDuration,
+	}
+}
+
+func userThrottleKey(username string) string {
+	return "user:" + strings.ToLower(username)
+}
+
+func ipThrottleKey(ip string) string {
+	return "ip:" + ip
+}
+
+// retryAfter reports how long a login for username from ip has to wait. Zero
+// means the attempt may go ahead.
+func (throttle *loginThrottle) retryAfter(username, ip string) time.Duration {
+	throttle.mu.Lock()
+	defer throttle.mu.Unlock()
+
+	now := throttle.now()
+	wait := time.Duration(0)
+	for _, key := range []string{userThrottleKey(username), ipThrottleKey(ip)} {
+		entry, ok := throttle.entries[key]
+		if !ok {
+			continue
+		}
+		if d := entry.blockedUntil.Sub(now); d > wait {
+			wait = d
+		}
+	}
+	return wait
+}
+
+func (throttle *loginThrottle) recordFailure(username, ip string) {
+	throttle.mu.Lock()
+	defer throttle.mu.Unlock()
+
+	now := throttle.now()
+	throttle.fail(userThrottleKey(username), throttle.userMaxAttempts, now)
+	throttle.fail(ipThrottleKey(ip), throttle.ipMaxAttempts, now)
+	throttle.sweep(now)
+}
+
+// recordSuccess forgets the failures of username. Failures of the client IP
+// are kept, so logging into one account does not reset guessing at others.
+func (throttle *loginThrottle) recordSuccess(username string) {
+	throttle.mu.Lock()
+	defer throttle.mu.Unlock()
+
+	delete(throttle.entries, userThrottleKey(username))
+}
+
+func (throttle *loginThrottle) fail(key string, maxAttempts int, now time.Time) {
+	entry, ok := throttle.entries[key]
+	if !ok || throttle.expired(entry, now) {
+		entry = &loginFailures{}
+		throttle.entries[key] = entry
+	}
+
+	entry.count++
+	entry.last = now
+
+	if maxAttempts > 0 && entry.count >= maxAttempts {
+		entry.blockedUntil = now.Add(throttle.lockout)
+		return
+	}
+
+	if throttle.backoffBase > 0 {
+		delay := throttle.backoffBase << min(entry.count-1, 30)
+		if throttle.backoffMax > 0 && delay > throttle.backoffMax {
+			delay = throttle.backoffMax
+		}
+		entry.blockedUntil = now.Add(delay)
+	}
+}
+
+// expired reports whether entry is old enough to start counting from zero.
+func (throttle *loginThrottle) expired(entry *loginFailures, now time.Time) bool {
+	window := max(throttle.lockout, throttle.backoffMax)
+	return now.After(entry.blockedUntil) && now.Sub(entry.last) > window
+}
+
+// sweep drops expired entries at most once a minute to bound memory use.
+func (throttle *loginThrottle) sweep(now time.Time) {
+	if now.Sub(throttle.lastSweep) < time.Minute {
+		return
+	}
+	throttle.lastSweep = now
+
+	for key, entry := range throttle.entries {
+		if throttle.expired(entry, now) {
+			delete(throttle.entries, key)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockDB "github.com/nilesh0729/Notes/internal/db/Mock"
+	Database "github.com/nilesh0729/Notes/internal/db/Result"
+	"github.com/nilesh0729/Notes/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLoginThrottle(now *time.Time) *loginThrottle {
+	throttle := newLoginThrottle(util.Config{
+		LoginMaxAttempts:     3,
+		LoginIPMaxAttempts:   5,
+		LoginBackoffBase:     time.Second,
+		LoginBackoffMax:      time.Minute,
+		LoginLockoutDuration: 15 * time.Minute,
+	})
+	throttle.now = func() time.Time { return *now }
+	return throttle
+}
+
+func TestLoginThrottleBackoff(t *testing.T) {
+	now := time.Now()
+	throttle := newTestLoginThrottle(&now)
+
+	require.Zero(t, throttle.retryAfter("alice", "10.0.0.1"))
+
+	throttle.recordFailure("alice", "10.0.0.1")
+	require.Equal(t, time.Second, throttle.retryAfter("alice", "10.0.0.1"))
+
+	now = now.Add(time.Second)
+	require.Zero(t, throttle.retryAfter("alice", "10.0.0.1"))
+
+	throttle.recordFailure("alice", "10.0.0.1")
+	require.Equal(t, 2*time.Second, throttle.retryAfter("alice", "10.0.0.1"))
+
+	// Usernames are case-insensitive, and the backoff follows the account
+	// to other client IPs.
+	require.Equal(t, 2*time.Second, throttle.retryAfter("ALICE", "10.0.0.2"))
+}
+
+func TestLoginThrottleLockout(t *testing.T) {
+	now := time.Now()
+	throttle := newTestLoginThrottle(&now)
+
+	for i := 0; i < 3; i++ {
+		throttle.recordFailure("alice", "10.0.0.1")
+	}
+	require.Equal(t, 15*time.Minute, throttle.retryAfter("alice", "10.0.0.2"))
+
+	now = now.Add(15 * time.Minute)
+	require.Zero(t, throttle.retryAfter("alice", "10.0.0.2"))
+}
+
+func TestLoginThrottleIPLockout(t *testing.T) {
+	now := time.Now()
+	throttle := newTestLoginThrottle(&now)
+
+	for i := 0; i < 5; i++ {
+		throttle.recordFailure(util.RandomOwner(), "10.0.0.1")
+	}
+	require.Equal(t, 15*time.Minute, throttle.retryAfter(util.RandomOwner(), "10.0.0.1"))
+	require.Zero(t, throttle.retryAfter(util.RandomOwner(), "10.0.0.2"))
+}
+
+func TestLoginThrottleSuccess(t *testing.T) {
+	now := time.Now()
+	throttle := newTestLoginThrottle(&now)
+
+	throttle.recordFailure("alice", "10.0.0.1")
+	throttle.recordSuccess("alice")
+
+	require.Zero(t, throttle.retryAfter("alice", "10.0.0.2"))
+	require.Equal(t, time.Second, throttle.retryAfter("bob", "10.0.0.1"))
+}
+
+func TestLoginThrottleExpiry(t *testing.T) {
+	now := time.Now()
+	throttle := newTestLoginThrottle(&now)
+
+	throttle.recordFailure("alice", "10.0.0.1")
+	throttle.recordFailure("alice", "10.0.0.1")
+
+	now = now.Add(time.Hour)
+	throttle.recordFailure("alice", "10.0.0.1")
+	require.Equal(t, time.Second, throttle.retryAfter("alice", "10.0.0.1"))
+	require.Len(t, throttle.entries, 2)
+}
+
+func TestLoginUserThrottled(t *testing.T) {
+	_, user := RandomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockDB.NewMockStore(ctrl)
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		Return(user, nil)
+
+	server, _ := newTestServer(t, store)
+
+	login := func(username string) *httptest.ResponseRecorder {
+		data, err := json.Marshal(gin.H{
+			"username": username,
+			"password": "wrongpassword",
+		})
+		require.NoError(t, err)
+
+		request, err := http.NewRequest(http.MethodPost, "/login", bytes.NewReader(data))
+		require.NoError(t, err)
+
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	recorder := login(user.Username)
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	// The first failure makes the next attempt wait, without the store
+	// being asked about the user again.
+	recorder = login(user.Username)
+	require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	require.Equal(t, "1", recorder.Header().Get("Retry-After"))
+}
+
+func TestLoginUserUnknownMatchesWrongPassword(t *testing.T) {
+	_, user := RandomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockDB.NewMockStore(ctrl)
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		Return(user, nil)
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq("unknown")).
+		Times(1).
+		Return(Database.User{}, Database.ErrRecordNotFound)
+
+	server, _ := newTestServer(t, store)
+
+	// Each attempt comes from its own IP, so the first failure does not
+	// throttle the second.
+	bodies := make([]string, 0, 2)
+	for i, username := range []string{user.Username, "unknown"} {
+		data, err := json.Marshal(gin.H{
+			"username": username,
+			"password": "wrongpassword",
+		})
+		require.NoError(t, err)
+
+		request, err := http.NewRequest(http.MethodPost, "/login", bytes.NewReader(data))
+		require.NoError(t, err)
+		request.RemoteAddr = fmt.Sprintf("10.0.0.%d:1234", i+1)
+		request.Header.Set(RequestIDHeaderKey, "login-attempt")
+
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, request)
+		require.Equal(t, http.StatusUnauthorized, recorder.Code)
+		bodies = append(bodies, recorder.Body.String())
+	}
+	require.Equal(t, bodies[0], bodies[1])
+}
+
+func TestLoginIPThrottleIgnoresForwardedFor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockDB.NewMockStore(ctrl)
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(Database.User{}, Database.ErrRecordNotFound)
+
+	server, _ := newTestServer(t, store)
+
+	// Each attempt is for another username and claims another client IP,
+	// but comes from the same address, so the failure of the first one
+	// still throttles the second.
+	for i, code := range []int{http.StatusUnauthorized, http.StatusTooManyRequests} {
+		data, err := json.Marshal(gin.H{
+			"username": util.RandomOwner(),
+			"password": "wrongpassword",
+		})
+		require.NoError(t, err)
+
+		request, err := http.NewRequest(http.MethodPost, "/login", bytes.NewReader(data))
+		require.NoError(t, err)
+		request.RemoteAddr = "10.0.0.1:1234"
+		request.Header.Set("X-Forwarded-For", fmt.Sprintf("192.0.2.%d", i+1))
+		request.Header.Set("X-Real-IP", fmt.Sprintf("192.0.2.%d", i+1))
+
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, request)
+		require.Equal(t, code, recorder.Code)
+	}
+}
@@ -1,24 +1,61 @@
 package api
 
 import (
+	"context"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	mockDB "github.com/nilesh0729/Notes/internal/db/Mock"
 	Database "github.com/nilesh0729/Notes/internal/db/Result"
 	"github.com/nilesh0729/Notes/internal/tokens"
 	"github.com/nilesh0729/Notes/internal/util"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
 	"fmt"
 	"net/http"
 )
 
-func newTestServer(t *testing.T, store Database.Store) (*Server, tokens.Maker) {
-	config := util.Config{
-		Secret:              util.RandomString(32),
-		AccessTokenDuration: time.Minute,
-		ServerAddress:       "0.0.0.0:8080",
+func newTestConfig(t *testing.T) util.Config {
+	return util.Config{
+		DBDriver:                   "postgres",
+		Secret:                     util.RandomString(32),
+		AccessTokenDuration:        time.Minute,
+		TokenIssuer:                "notes",
+		TokenAudience:              "notes",
+		TokenLeeway:                time.Second,
+		LogLevel:                   "error",
+		TracingSampleRatio:         1,
+		PasswordHashAlgorithm:      util.PasswordHashBcrypt,
+		BcryptCost:                 bcrypt.MinCost,
+		PasswordMinLength:          8,
+		PasswordMaxLength:          128,
+		ServerAddress:              "0.0.0.0:8080",
+		AppBaseURL:                 "http://localhost",
+		VerifyEmailTokenDuration:   time.Hour,
+		ResetPasswordTokenDuration: time.Hour,
+		LoginChallengeDuration:     time.Minute,
+		LoginChallengeMaxAttempts:  3,
+		TOTPIssuer:                 "Notes",
+		LoginMaxAttempts:           5,
+		LoginIPMaxAttempts:         20,
+		LoginBackoffBase:           time.Second,
+		LoginBackoffMax:            time.Minute,
+		LoginLockoutDuration:       15 * time.Minute,
+		MailFrom:                   "no-reply@localhost",
+		MailLogFile:                filepath.Join(t.TempDir(), "mail.log"),
+	}
+}
+
+// newTestServer creates a server with newTestConfig, changed by configure.
+func newTestServer(t *testing.T, store Database.Store, configure ...func(config *util.Config)) (*Server, tokens.Maker) {
+	config := newTestConfig(t)
+	for _, change := range configure {
+		change(&config)
 	}
 
 	server, err := NewServer(config, store)
@@ -35,13 +72,38 @@ func addAuthorization(
 	username string,
 	duration time.Duration,
 ) {
-	token, err := tokenMaker.CreateToken(username, duration)
+	token, payload, err := tokenMaker.CreateToken(username, duration, sessionScopes...)
 	require.NoError(t, err)
+	require.NotEmpty(t, payload)
 
 	authorizationHeader := fmt.Sprintf("%s %s", authorizationType, token)
 	request.Header.Set(AuthorizationHeaderKey, authorizationHeader)
 }
 
+// allowSessions stubs the session lookup done by authMiddleware, so every
+// token minted by addAuthorization belongs to a live session.
+func allowSessions(store *mockDB.MockStore) {
+	store.EXPECT().
+		GetSession(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		DoAndReturn(func(_ context.Context, id uuid.UUID) (Database.Session, error) {
+			return Database.Session{ID: id, ExpiresAt: time.Now().Add(time.Minute)}, nil
+		})
+}
+
+// allowQuotas stubs the quota checks of note and tag writes: no user has
+// overrides and everyone stores nothing yet.
+func allowQuotas(store *mockDB.MockStore) {
+	store.EXPECT().
+		GetUserQuota(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		Return(Database.UserQuota{}, Database.ErrRecordNotFound)
+	store.EXPECT().
+		GetUserUsage(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		Return(Database.GetUserUsageRow{}, nil)
+}
+
 func TestMain(m *testing.M) {
 	gin.SetMode(gin.TestMode)
 
@@ -0,0 +1,158 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	mockDB "github.com/nilesh0729/Notes/internal/db/Mock"
+	Database "github.com/nilesh0729/Notes/internal/db/Result"
+	"github.com/nilesh0729/Notes/internal/metrics"
+	"github.com/nilesh0729/Notes/internal/tokens"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsEndpoint(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server, _ := newTestServer(t, mockDB.NewMockStore(ctrl))
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/notes", nil)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	// Metrics are not served with the API but on a listener of their own.
+	recorder = httptest.NewRecorder()
+	request = httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	request = httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	metricsHandler().ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	body := recorder.Body.String()
+	require.Contains(t, body, `notes_http_requests_total{method="GET",route="/notes",status="401"}`)
+	require.Contains(t, body, `notes_http_request_duration_seconds_bucket{method="GET",route="/notes",status="401"`)
+	require.Contains(t, body, `notes_token_verification_failures_total{reason="missing"}`)
+}
+
+func TestTokenVerificationFailureMetrics(t *testing.T) {
+	testCases := []struct {
+		name       string
+		reason     string
+		setAuth    func(t *testing.T, request *http.Request, tokenMaker tokens.Maker)
+		buildStubs func(store *mockDB.MockStore)
+	}{
+		{
+			name:   "Malformed",
+			reason: metrics.TokenMalformed,
+			setAuth: func(t *testing.T, request *http.Request, tokenMaker tokens.Maker) {
+				request.Header.Set(AuthorizationHeaderKey, AuthorizationTypeBearer)
+			},
+			buildStubs: func(store *mockDB.MockStore) {},
+		},
+		{
+			name:   "Expired",
+			reason: metrics.TokenExpired,
+			setAuth: func(t *testing.T, request *http.Request, tokenMaker tokens.Maker) {
+				addAuthorization(t, request, tokenMaker, AuthorizationTypeBearer, "user", -time.Minute)
+			},
+			buildStubs: func(store *mockDB.MockStore) {},
+		},
+		{
+			name:   "SessionRevoked",
+			reason: metrics.TokenSessionRevoked,
+			setAuth: func(t *testing.T, request *http.Request, tokenMaker tokens.Maker) {
+				addAuthorization(t, request, tokenMaker, AuthorizationTypeBearer, "user", time.Minute)
+			},
+			buildStubs: func(store *mockDB.MockStore) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, id uuid.UUID) (Database.Session, error) {
+						return Database.Session{ID: id, IsBlocked: true}, nil
+					})
+			},
+		},
+		{
+			name:   "InvalidAPIKey",
+			reason: metrics.TokenInvalidAPIKey,
+			setAuth: func(t *testing.T, request *http.Request, tokenMaker tokens.Maker) {
+				request.Header.Set(AuthorizationHeaderKey, AuthorizationTypeAPIKey+" not-a-key")
+			},
+			buildStubs: func(store *mockDB.MockStore) {},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockDB.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, tokenMaker := newTestServer(t, store)
+
+			failures := metrics.TokenVerificationFailures.WithLabelValues(tc.reason)
+			before := testutil.ToFloat64(failures)
+
+			request := httptest.NewRequest(http.MethodGet, "/notes", nil)
+			tc.setAuth(t, request, tokenMaker)
+
+			recorder := httptest.NewRecorder()
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			require.Equal(t, before+1, testutil.ToFloat64(failures))
+		})
+	}
+}
+
+func TestLoginFailureMetrics(t *testing.T) {
+	_, user := RandomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockDB.NewMockStore(ctrl)
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		Return(user, nil)
+
+	server, _ := newTestServer(t, store)
+
+	wrongPassword := metrics.LoginFailures.WithLabelValues(metrics.LoginWrongPassword)
+	throttled := metrics.LoginFailures.WithLabelValues(metrics.LoginThrottled)
+	wrongPasswordBefore := testutil.ToFloat64(wrongPassword)
+	throttledBefore := testutil.ToFloat64(throttled)
+
+	for _, status := range []int{http.StatusUnauthorized, http.StatusTooManyRequests} {
+		data, err := json.Marshal(gin.H{
+			"username": user.Username,
+			"password": "wrongpassword",
+		})
+		require.NoError(t, err)
+
+		request, err := http.NewRequest(http.MethodPost, "/login", bytes.NewReader(data))
+		require.NoError(t, err)
+
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, request)
+		require.Equal(t, status, recorder.Code)
+	}
+
+	require.Equal(t, wrongPasswordBefore+1, testutil.ToFloat64(wrongPassword))
+	require.Equal(t, throttledBefore+1, testutil.ToFloat64(throttled))
+}
@@ -1,54 +1,310 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	Database "github.com/nilesh0729/Notes/internal/db/Result"
+	"github.com/nilesh0729/Notes/internal/metrics"
 	"github.com/nilesh0729/Notes/internal/tokens"
+	"github.com/nilesh0729/Notes/internal/util"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 const (
 	AuthorizationHeaderKey  = "authorization"
 	AuthorizationTypeBearer = "bearer"
+	AuthorizationTypeAPIKey = "apikey"
 	AuthorizationPayloadKey = "authorization_payload"
+	AuthorizationAPIKeyKey  = "authorization_api_key"
 )
 
-func authMiddleware(tokenMaker tokens.Maker) gin.HandlerFunc {
+// Scopes granted to access tokens and API keys, and required by the route
+// groups in NewServer.
+const (
+	ScopeAccount    = "account"
+	ScopeNotesRead  = "notes:read"
+	ScopeNotesWrite = "notes:write"
+)
+
+// sessionScopes are granted to access tokens minted at login.
+var sessionScopes = []string{ScopeAccount, ScopeNotesRead, ScopeNotesWrite}
+
+var (
+	errMissingAuthorization = newAPIError(http.StatusUnauthorized, CodeUnauthorized, "authorization header is not provided")
+	errInvalidAuthorization = newAPIError(http.StatusUnauthorized, CodeUnauthorized, "invalid authorization header format")
+	errSessionNotFound      = newAPIError(http.StatusUnauthorized, CodeUnauthorized, "session not found")
+	errSessionRevoked       = newAPIError(http.StatusUnauthorized, CodeUnauthorized, "session has been revoked")
+	errAccountNotFound      = newAPIError(http.StatusUnauthorized, CodeUnauthorized, "account does not exist")
+	errEmailNotVerified     = newAPIError(http.StatusForbidden, CodeForbidden, "email address has not been verified")
+)
+
+const (
+	RequestIDHeaderKey = "X-Request-ID"
+	RequestIDKey       = "request_id"
+	maxRequestIDLength = 128
+)
+
+// requestIDMiddleware gives every request an ID, echoed in the X-Request-ID
+// response header and in error responses. A well-formed ID sent by the
+// client or a proxy in the same header is kept, so requests can be traced
+// across services.
+func requestIDMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id := ctx.GetHeader(RequestIDHeaderKey)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+
+		ctx.Set(RequestIDKey, id)
+		ctx.Header(RequestIDHeaderKey, id)
+		ctx.Next()
+	}
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		if c <= ' ' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// requestID returns the ID requestIDMiddleware gave the request.
+func requestID(ctx *gin.Context) string {
+	return ctx.GetString(RequestIDKey)
+}
+
+func authMiddleware(tokenMaker tokens.Maker, store Database.Store) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authorizationHeader := ctx.GetHeader(AuthorizationHeaderKey)
 		if len(authorizationHeader) == 0 {
-			err := errors.New("authorization header is not provided")
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errResponse(err))
+			rejectCredentials(ctx, metrics.TokenMissing, errMissingAuthorization)
 			return
 		}
 
 		fields := strings.Fields(authorizationHeader)
 		if len(fields) < 2 {
-			err := errors.New("invalid authorization header format")
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errResponse(err))
+			rejectCredentials(ctx, metrics.TokenMalformed, errInvalidAuthorization)
 			return
 		}
 
 		authorizationType := strings.ToLower(fields[0])
 
-		if authorizationType != AuthorizationTypeBearer {
-			err := fmt.Errorf("unsupported authorization type %s", authorizationType)
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errResponse(err))
+		var payload *tokens.Payload
+		var ok bool
+		switch authorizationType {
+		case AuthorizationTypeBearer:
+			payload, ok = authenticateAccessToken(ctx, tokenMaker, store, fields[1])
+		case AuthorizationTypeAPIKey:
+			payload, ok = authenticateAPIKey(ctx, store, fields[1])
+		default:
+			message := fmt.Sprintf("unsupported authorization type %s", authorizationType)
+			rejectCredentials(ctx, metrics.TokenUnsupportedType, newAPIError(http.StatusUnauthorized, CodeUnauthorized, message))
+			return
+		}
+		if !ok {
 			return
 		}
 
-		accessToken := fields[1]
+		ctx.Set(AuthorizationPayloadKey, payload)
+		ctx.Next()
+	}
+}
+
+// RequestUsername returns the user the request of ctx is authenticated as,
+// or "" for an anonymous one. ctx is the *gin.Context of the request, or a
+// context derived from it, like those the Store is called with.
+func RequestUsername(ctx context.Context) string {
+	payload, ok := ctx.Value(AuthorizationPayloadKey).(*tokens.Payload)
+	if !ok {
+		return ""
+	}
+	return payload.Username
+}
+
+// authenticateAccessToken verifies a bearer token and the session it belongs
+// to. On failure it aborts the request and returns false.
+func authenticateAccessToken(ctx *gin.Context, tokenMaker tokens.Maker, store Database.Store, accessToken string) (*tokens.Payload, bool) {
+	payload, err := tokenMaker.VerifyToken(accessToken)
+	if err != nil {
+		reason := metrics.TokenInvalid
+		if errors.Is(err, tokens.ErrExpiredToken) {
+			reason = metrics.TokenExpired
+		}
+		rejectCredentials(ctx, reason, err)
+		return nil, false
+	}
+
+	// Every access token is bound to a session so it can be revoked
+	// (e.g. after a password change) before it expires.
+	sessionID, err := uuid.Parse(payload.ID)
+	if err != nil {
+		rejectCredentials(ctx, metrics.TokenInvalid, tokens.ErrInvalidToken)
+		return nil, false
+	}
+
+	session, err := store.GetSession(ctx, sessionID)
+	if err != nil {
+		if err == Database.ErrRecordNotFound {
+			rejectCredentials(ctx, metrics.TokenSessionNotFound, errSessionNotFound)
+			return nil, false
+		}
+		abortWithError(ctx, err)
+		return nil, false
+	}
+
+	if session.IsBlocked {
+		rejectCredentials(ctx, metrics.TokenSessionRevoked, errSessionRevoked)
+		return nil, false
+	}
+
+	return payload, true
+}
+
+// rejectCredentials answers a request whose credentials were rejected with
+// err and counts the failure by reason.
+func rejectCredentials(ctx *gin.Context, reason string, err error) {
+	metrics.TokenVerificationFailures.WithLabelValues(reason).Inc()
+	abortWithError(ctx, err)
+}
+
+// apiKeyTouchInterval limits how often last_used_at is written for a key
+// that is used over and over.
+const apiKeyTouchInterval = time.Minute
+
+// authenticateAPIKey looks up a personal API key. The key's scope is turned
+// into token scopes, which requireScopes checks like those of an access
+// token. On failure it aborts the request and returns false.
+func authenticateAPIKey(ctx *gin.Context, store Database.Store, key string) (*tokens.Payload, bool) {
+	if !isAPIKey(key) {
+		rejectCredentials(ctx, metrics.TokenInvalidAPIKey, errInvalidAPIKey)
+		return nil, false
+	}
+
+	apiKey, err := store.GetAPIKeyByHash(ctx, util.HashToken(key))
+	if err != nil {
+		if err == Database.ErrRecordNotFound {
+			rejectCredentials(ctx, metrics.TokenInvalidAPIKey, errInvalidAPIKey)
+			return nil, false
+		}
+		abortWithError(ctx, err)
+		return nil, false
+	}
+
+	now := time.Now()
+	if now.After(apiKey.ExpiresAt) {
+		rejectCredentials(ctx, metrics.TokenInvalidAPIKey, errInvalidAPIKey)
+		return nil, false
+	}
+
+	if !apiKey.LastUsedAt.Valid || now.Sub(apiKey.LastUsedAt.Time) > apiKeyTouchInterval {
+		err = store.TouchAPIKey(ctx, apiKey.ID)
+		if err != nil {
+			abortWithError(ctx, err)
+			return nil, false
+		}
+	}
+
+	// Handlers only look at the username, so the key is described with the
+	// same payload an access token would carry.
+	payload := &tokens.Payload{
+		Username: apiKey.Username,
+		Scopes:   apiKeyScopes(apiKey.Scope),
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(apiKey.CreatedAt),
+			ExpiresAt: jwt.NewNumericDate(apiKey.ExpiresAt),
+		},
+	}
+	ctx.Set(AuthorizationAPIKeyKey, apiKey)
+
+	return payload, true
+}
+
+// requireScopes rejects requests whose token lacks any of scopes. It must
+// run after authMiddleware.
+func requireScopes(scopes ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(AuthorizationPayloadKey).(*tokens.Payload)
+
+		for _, scope := range scopes {
+			if !authPayload.HasScope(scope) {
+				message := fmt.Sprintf("token is missing the %s scope", scope)
+				abortWithError(ctx, newAPIError(http.StatusForbidden, CodeForbidden, message))
+				return
+			}
+		}
+
+		ctx.Next()
+	}
+}
+
+// requireVerifiedEmail rejects requests from users who have not confirmed
+// their email address yet. It must run after authMiddleware.
+func requireVerifiedEmail(store Database.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(AuthorizationPayloadKey).(*tokens.Payload)
 
-		payload, err := tokenMaker.VerifyToken(accessToken)
+		user, err := store.GetUser(ctx, authPayload.Username)
 		if err != nil {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errResponse(err))
+			if err == Database.ErrRecordNotFound {
+				abortWithError(ctx, errAccountNotFound)
+				return
+			}
+			abortWithError(ctx, err)
 			return
 		}
 
-		ctx.Set(AuthorizationPayloadKey, payload)
+		if !user.EmailVerified {
+			abortWithError(ctx, errEmailNotVerified)
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// metricsMiddleware records the count and latency of requests by route
+// template, so "/notes/1" and "/notes/2" are counted together.
+func metricsMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
 		ctx.Next()
+		metrics.ObserveHTTPRequest(ctx.Request.Method, ctx.FullPath(), ctx.Writer.Status(), time.Since(start))
+	}
+}
+
+// tracingMiddleware starts a server span for every request, continuing the
+// trace of a W3C traceparent header. Probes and metric scrapes are not
+// traced.
+func tracingMiddleware(config util.Config) gin.HandlerFunc {
+	serviceName := config.TracingServiceName
+	if serviceName == "" {
+		serviceName = "notes"
+	}
+
+	return otelgin.Middleware(serviceName, otelgin.WithGinFilter(func(ctx *gin.Context) bool {
+		return !isProbeRoute(ctx.FullPath())
+	}))
+}
+
+// isProbeRoute reports whether route is polled by the infrastructure rather
+// than called by users.
+func isProbeRoute(route string) bool {
+	switch route {
+	case "/healthz", "/readyz":
+		return true
 	}
+	return false
 }
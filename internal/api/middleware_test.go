@@ -1,13 +1,20 @@
 package api
 
 import (
+	"context"
+	"database/sql"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	mockDB "github.com/nilesh0729/Notes/internal/db/Mock"
+	Database "github.com/nilesh0729/Notes/internal/db/Result"
 	"github.com/nilesh0729/Notes/internal/tokens"
+	"github.com/nilesh0729/Notes/internal/util"
 	"github.com/stretchr/testify/require"
 )
 
@@ -17,6 +24,7 @@ func TestAuthMiddleware(t *testing.T) {
 	testCases := []struct {
 		name          string
 		setAuth       func(t *testing.T, request *http.Request, tokenMaker tokens.Maker)
+		buildStubs    func(store *mockDB.MockStore)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
 		{
@@ -24,6 +32,7 @@ func TestAuthMiddleware(t *testing.T) {
 			setAuth: func(t *testing.T, request *http.Request, tokenMaker tokens.Maker) {
 				addAuthorization(t, request, tokenMaker, AuthorizationTypeBearer, "user", time.Minute)
 			},
+			buildStubs: allowSessions,
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
 			},
@@ -33,6 +42,7 @@ func TestAuthMiddleware(t *testing.T) {
 			setAuth: func(t *testing.T, request *http.Request, tokenMaker tokens.Maker) {
 
 			},
+			buildStubs: allowSessions,
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
@@ -42,6 +52,7 @@ func TestAuthMiddleware(t *testing.T) {
 			setAuth: func(t *testing.T, request *http.Request, tokenMaker tokens.Maker) {
 				addAuthorization(t, request, tokenMaker, "UnSuppoted", "user", time.Minute)
 			},
+			buildStubs: allowSessions,
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
@@ -51,6 +62,7 @@ func TestAuthMiddleware(t *testing.T) {
 			setAuth: func(t *testing.T, request *http.Request, tokenMaker tokens.Maker) {
 				addAuthorization(t, request, tokenMaker, "", "user", time.Minute)
 			},
+			buildStubs: allowSessions,
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
@@ -60,22 +72,74 @@ func TestAuthMiddleware(t *testing.T) {
 			setAuth: func(t *testing.T, request *http.Request, tokenMaker tokens.Maker) {
 				addAuthorization(t, request, tokenMaker, AuthorizationTypeBearer, "user", -time.Minute)
 			},
+			buildStubs: allowSessions,
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
 		},
+		{
+			name: "SessionNotFound",
+			setAuth: func(t *testing.T, request *http.Request, tokenMaker tokens.Maker) {
+				addAuthorization(t, request, tokenMaker, AuthorizationTypeBearer, "user", time.Minute)
+			},
+			buildStubs: func(store *mockDB.MockStore) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(Database.Session{}, Database.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "BlockedSession",
+			setAuth: func(t *testing.T, request *http.Request, tokenMaker tokens.Maker) {
+				addAuthorization(t, request, tokenMaker, AuthorizationTypeBearer, "user", time.Minute)
+			},
+			buildStubs: func(store *mockDB.MockStore) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(Database.Session{Username: "user", IsBlocked: true}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "SessionLookupError",
+			setAuth: func(t *testing.T, request *http.Request, tokenMaker tokens.Maker) {
+				addAuthorization(t, request, tokenMaker, AuthorizationTypeBearer, "user", time.Minute)
+			},
+			buildStubs: func(store *mockDB.MockStore) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(Database.Session{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
 	}
 
 	for i := range testCases {
 		tc := testCases[i]
 
 		t.Run(tc.name, func(t *testing.T) {
-			server, _ := newTestServer(t, nil)
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockDB.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, _ := newTestServer(t, store)
 
 			authPath := "/auth"
 			server.router.GET(
 				authPath,
-				authMiddleware(server.tokenMaker),
+				authMiddleware(server.tokenMaker, server.store),
 				func(ctx *gin.Context) {
 					ctx.JSON(http.StatusOK, gin.H{})
 				},
@@ -92,4 +156,310 @@ func TestAuthMiddleware(t *testing.T) {
 		})
 	}
 
-}
\ No newline at end of file
+}
+
+func TestRequestUsername(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockDB.NewMockStore(ctrl)
+	allowSessions(store)
+
+	server, _ := newTestServer(t, store)
+
+	var usernames []string
+	record := func(ctx *gin.Context) {
+		// The Store gets contexts derived from the request's.
+		usernames = append(usernames, RequestUsername(context.WithValue(ctx, struct{}{}, 1)))
+		ctx.JSON(http.StatusOK, gin.H{})
+	}
+	server.router.GET("/anonymous", record)
+	server.router.GET("/auth", authMiddleware(server.tokenMaker, server.store), record)
+
+	request := httptest.NewRequest(http.MethodGet, "/anonymous", nil)
+	server.router.ServeHTTP(httptest.NewRecorder(), request)
+
+	request = httptest.NewRequest(http.MethodGet, "/auth", nil)
+	addAuthorization(t, request, server.tokenMaker, AuthorizationTypeBearer, "user", time.Minute)
+	server.router.ServeHTTP(httptest.NewRecorder(), request)
+
+	require.Equal(t, []string{"", "user"}, usernames)
+}
+
+func TestAuthMiddlewareAPIKey(t *testing.T) {
+	key := apiKeyPrefix + util.RandomString(43)
+
+	testCases := []struct {
+		name          string
+		method        string
+		key           string
+		buildStubs    func(store *mockDB.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK",
+			method: http.MethodPost,
+			key:    key,
+			buildStubs: func(store *mockDB.MockStore) {
+				store.EXPECT().
+					GetAPIKeyByHash(gomock.Any(), gomock.Eq(util.HashToken(key))).
+					Times(1).
+					Return(Database.ApiKey{ID: 1, Username: "user", Scope: APIKeyScopeReadWrite, ExpiresAt: time.Now().Add(time.Hour)}, nil)
+				store.EXPECT().
+					TouchAPIKey(gomock.Any(), gomock.Eq(int64(1))).
+					Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "RecentlyUsed",
+			method: http.MethodGet,
+			key:    key,
+			buildStubs: func(store *mockDB.MockStore) {
+				store.EXPECT().
+					GetAPIKeyByHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(Database.ApiKey{
+						ID:         1,
+						Username:   "user",
+						Scope:      APIKeyScopeReadOnly,
+						ExpiresAt:  time.Now().Add(time.Hour),
+						LastUsedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+					}, nil)
+				store.EXPECT().
+					TouchAPIKey(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "ReadOnlyScope",
+			method: http.MethodPost,
+			key:    key,
+			buildStubs: func(store *mockDB.MockStore) {
+				store.EXPECT().
+					GetAPIKeyByHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(Database.ApiKey{ID: 1, Username: "user", Scope: APIKeyScopeReadOnly, ExpiresAt: time.Now().Add(time.Hour)}, nil)
+				store.EXPECT().
+					TouchAPIKey(gomock.Any(), gomock.Any()).
+					Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireAPIError(t, recorder, CodeForbidden)
+			},
+		},
+		{
+			name:   "ExpiredKey",
+			method: http.MethodGet,
+			key:    key,
+			buildStubs: func(store *mockDB.MockStore) {
+				store.EXPECT().
+					GetAPIKeyByHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(Database.ApiKey{ID: 1, Username: "user", Scope: APIKeyScopeReadWrite, ExpiresAt: time.Now().Add(-time.Minute)}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "UnknownKey",
+			method: http.MethodGet,
+			key:    key,
+			buildStubs: func(store *mockDB.MockStore) {
+				store.EXPECT().
+					GetAPIKeyByHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(Database.ApiKey{}, Database.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "MalformedKey",
+			method: http.MethodGet,
+			key:    "not-a-key",
+			buildStubs: func(store *mockDB.MockStore) {
+				store.EXPECT().
+					GetAPIKeyByHash(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "LookupError",
+			method: http.MethodGet,
+			key:    key,
+			buildStubs: func(store *mockDB.MockStore) {
+				store.EXPECT().
+					GetAPIKeyByHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(Database.ApiKey{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockDB.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, _ := newTestServer(t, store)
+
+			// Like the note routes, reads need the read scope and writes
+			// the write scope.
+			scope := ScopeNotesRead
+			if tc.method != http.MethodGet {
+				scope = ScopeNotesWrite
+			}
+
+			authPath := "/auth"
+			server.router.Handle(
+				tc.method,
+				authPath,
+				authMiddleware(server.tokenMaker, server.store),
+				requireScopes(scope),
+				func(ctx *gin.Context) {
+					payload := ctx.MustGet(AuthorizationPayloadKey).(*tokens.Payload)
+					require.Equal(t, "user", payload.Username)
+					ctx.JSON(http.StatusOK, gin.H{})
+				},
+			)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(tc.method, authPath, nil)
+			require.NoError(t, err)
+
+			request.Header.Set(AuthorizationHeaderKey, "ApiKey "+tc.key)
+			server.router.ServeHTTP(recorder, request)
+
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRequireScopes(t *testing.T) {
+	testCases := []struct {
+		name          string
+		scopes        []string
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK",
+			scopes: []string{ScopeNotesRead, ScopeNotesWrite},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "MissingScope",
+			scopes: []string{ScopeNotesRead},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "NoScopes",
+			scopes: nil,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockDB.NewMockStore(ctrl)
+			allowSessions(store)
+
+			server, _ := newTestServer(t, store)
+
+			authPath := "/auth"
+			server.router.GET(
+				authPath,
+				authMiddleware(server.tokenMaker, server.store),
+				requireScopes(ScopeNotesRead, ScopeNotesWrite),
+				func(ctx *gin.Context) {
+					ctx.JSON(http.StatusOK, gin.H{})
+				},
+			)
+
+			token, _, err := server.tokenMaker.CreateToken("user", time.Minute, tc.scopes...)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, authPath, nil)
+			require.NoError(t, err)
+
+			request.Header.Set(AuthorizationHeaderKey, AuthorizationTypeBearer+" "+token)
+			server.router.ServeHTTP(recorder, request)
+
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAPIKeyCannotManageAccount(t *testing.T) {
+	// Even a read-write key is limited to notes and tags, so a leaked key
+	// cannot be used to take over the account.
+	routes := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodGet, "/me"},
+		{http.MethodPatch, "/me"},
+		{http.MethodPost, "/me/password"},
+		{http.MethodPost, "/me/2fa/setup"},
+		{http.MethodPost, "/me/2fa/enable"},
+		{http.MethodPost, "/me/api-keys"},
+		{http.MethodDelete, "/me/api-keys/1"},
+		{http.MethodGet, "/admin/users/user/usage"},
+	}
+
+	for _, route := range routes {
+		t.Run(route.method+" "+route.path, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockDB.NewMockStore(ctrl)
+			store.EXPECT().
+				GetAPIKeyByHash(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(Database.ApiKey{ID: 1, Username: "user", Scope: APIKeyScopeReadWrite, ExpiresAt: time.Now().Add(time.Hour)}, nil)
+			store.EXPECT().
+				TouchAPIKey(gomock.Any(), gomock.Any()).
+				Times(1)
+
+			server, _ := newTestServer(t, store)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(route.method, route.path, nil)
+			require.NoError(t, err)
+
+			request.Header.Set(AuthorizationHeaderKey, "ApiKey "+apiKeyPrefix+util.RandomString(43))
+			server.router.ServeHTTP(recorder, request)
+			requireAPIError(t, recorder, CodeForbidden)
+		})
+	}
+}
@@ -1,8 +1,6 @@
 package api
 
 import (
-	"database/sql"
-	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -20,7 +18,7 @@ func (server *Server) AddTagToNote(ctx *gin.Context) {
 
 	err := ctx.ShouldBindJSON(&req)
 	if err!=nil{
-		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		abortWithError(ctx, bindingError(err))
 		return
 	}
 
@@ -30,32 +28,30 @@ func (server *Server) AddTagToNote(ctx *gin.Context) {
 	// Check Note Ownership
 	note, err := server.store.GetNoteById(ctx, req.NoteId)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errResponse(err))
+		if err == Database.ErrRecordNotFound {
+			abortWithError(ctx, errNoteNotFound)
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		abortWithError(ctx, err)
 		return
 	}
-	if note.Owner.String != authPayload.Username {
-		err := errors.New("note doesn't belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errResponse(err))
+	if note.Owner != authPayload.Username {
+		abortWithError(ctx, errNoteNotOwned)
 		return
 	}
 	
 	// Check Tag Ownership
 	tag, err := server.store.GetTag(ctx, req.TagId)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errResponse(err))
+		if err == Database.ErrRecordNotFound {
+			abortWithError(ctx, errTagNotFound)
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		abortWithError(ctx, err)
 		return
 	}
-	if tag.Owner.String != authPayload.Username {
-		err := errors.New("tag doesn't belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errResponse(err))
+	if tag.Owner != authPayload.Username {
+		abortWithError(ctx, errTagNotOwned)
 		return
 	}
 
@@ -65,7 +61,7 @@ func (server *Server) AddTagToNote(ctx *gin.Context) {
 	}
 	notetag, err := server.store.AddTagToNote(ctx, arg)
 	if err != nil{
-		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		abortWithError(ctx, err)
 		return
 	}
 
@@ -81,7 +77,7 @@ type ListNotesForTagRequest struct {
 func (server *Server) ListNotesForTag(ctx *gin.Context) {
 	var req ListNotesForTagRequest
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		abortWithError(ctx, bindingError(err))
 		return
 	}
 
@@ -89,15 +85,15 @@ func (server *Server) ListNotesForTag(ctx *gin.Context) {
 	
 	arg := Database.GetNotesForTagParams{
 		TagID: req.TagID,
-		Owner: sql.NullString{String: authPayload.Username, Valid: true},
+		Owner: authPayload.Username,
 	}
 	notes, err := server.store.GetNotesForTag(ctx, arg)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errResponse(err))
+		if err == Database.ErrRecordNotFound {
+			abortWithError(ctx, errTagNotFound)
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		abortWithError(ctx, err)
 		return
 	}
 
@@ -24,11 +24,11 @@ func TestAddTagToNote(t *testing.T) {
 	
 	// Create separate Note and Tag objects with owner "user" for mocking
 	note := RandomNotes()
-	note.Owner = sql.NullString{String: "user", Valid: true}
+	note.Owner = "user"
 	note.NoteID = notetag.NoteID
 	
 	tag := RandomTag()
-	tag.Owner = sql.NullString{String: "user", Valid: true}
+	tag.Owner = "user"
 	tag.TagID = notetag.TagID
 	testcase := []struct {
 		name          string
@@ -126,6 +126,7 @@ func TestAddTagToNote(t *testing.T) {
 
 			store := mockDB.NewMockStore(ctrl)
 			tc.buildstubs(store)
+			allowSessions(store)
 
 			server, _ := newTestServer(t, store)
 			recorder := httptest.NewRecorder()
@@ -3,17 +3,21 @@ package api
 
 
 import (
-	"database/sql"
 	"fmt"
 	"net/http"
 	"time"
 
-	"errors"
 	"github.com/gin-gonic/gin"
 	Database "github.com/nilesh0729/Notes/internal/db/Result"
 	"github.com/nilesh0729/Notes/internal/tokens"
 )
 
+var (
+	errNoteNotFound  = newAPIError(http.StatusNotFound, CodeNotFound, "note not found")
+	errNoteNotOwned  = newAPIError(http.StatusUnauthorized, CodeUnauthorized, "note doesn't belong to the authenticated user")
+	errInvalidNoteID = newAPIError(http.StatusBadRequest, CodeBadRequest, "invalid note id")
+)
+
 type ResponseFormat struct {
 	NoteId    int32     `json:"note_id"`
 	Title     string    `json:"title"`
@@ -28,12 +32,12 @@ type ResponseFormat struct {
 func ResponseFormating(note Database.Note, tags []TagResponseFormat) ResponseFormat {
 	return ResponseFormat{
 		NoteId:    note.NoteID,
-		Title:     note.Title.String,
-		Content:   note.Content.String,
-		Pinned:    note.Pinned.Bool,
-		Archived:  note.Archived.Bool,
-		CreatedAt: note.CreatedAt.Time,
-		UpdatedAt: note.UpdatedAt.Time,
+		Title:     note.Title,
+		Content:   note.Content,
+		Pinned:    note.Pinned,
+		Archived:  note.Archived,
+		CreatedAt: note.CreatedAt,
+		UpdatedAt: note.UpdatedAt,
 		Tags:      tags,
 	}
 }
@@ -68,21 +72,34 @@ type CreateNoteRequest struct {
 func (server *Server) CreateNote(ctx *gin.Context) {
 	var req CreateNoteRequest
 
-	err := ctx.ShouldBindJSON(&req)
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*tokens.Payload)
+	quota, err := server.quotaFor(ctx, authPayload.Username)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		abortWithError(ctx, err)
+		return
+	}
+
+	limitNoteBody(ctx, quota)
+	err = ctx.ShouldBindJSON(&req)
+	if err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	err = server.checkNoteQuota(ctx, authPayload.Username, quota, -1, noteSize(req.Title, req.Content))
+	if err != nil {
+		abortWithError(ctx, err)
 		return
 	}
-	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*tokens.Payload)
 
 	arg := Database.CreateNoteParams{
-		Owner:   sql.NullString{String: authPayload.Username, Valid: true},
-		Title:   sql.NullString{String: req.Title, Valid: true},
-		Content: sql.NullString{String: req.Content, Valid: true},
+		Owner:   authPayload.Username,
+		Title:   req.Title,
+		Content: req.Content,
 	}
 	note, err := server.store.CreateNote(ctx, arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		abortWithError(ctx, err)
 		return
 	}
 
@@ -98,29 +115,28 @@ func (server *Server) GetNoteById(ctx *gin.Context) {
 
 	err := ctx.ShouldBindUri(&req)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		abortWithError(ctx, bindingError(err))
 		return
 	}
 
 	note, err := server.store.GetNoteById(ctx, (req.NoteID))
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errResponse(err))
+		if err == Database.ErrRecordNotFound {
+			abortWithError(ctx, errNoteNotFound)
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		abortWithError(ctx, err)
 		return
 	}
 
 	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*tokens.Payload)
-	if note.Owner.String != authPayload.Username {
-		err := errors.New("note doesn't belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errResponse(err))
+	if note.Owner != authPayload.Username {
+		abortWithError(ctx, errNoteNotOwned)
 		return
 	}
 
 	tags, _ := server.store.GetTagsForNote(ctx, req.NoteID)
-	ctx.JSON(http.StatusOK, ResponseFormating(note, transformTagRows(tags)))
+	respondWithETag(ctx, ResponseFormating(note, transformTagRows(tags)))
 
 }
 
@@ -135,7 +151,7 @@ func (server *Server) ListNotes(ctx *gin.Context) {
 
 	err := ctx.ShouldBindQuery(&req)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		abortWithError(ctx, bindingError(err))
 		return
 	}
 
@@ -144,10 +160,10 @@ func (server *Server) ListNotes(ctx *gin.Context) {
 	if req.Search != "" {
 		// Use SearchNotes query
 		arg := Database.SearchNotesParams{
-			Column1: sql.NullString{String: req.Search, Valid: true},
+			Search:  req.Search,
 			Limit:   req.PageSize,
 			Offset:  req.Cursor, // Cursor acts as Offset for search
-			Owner:   sql.NullString{String: ctx.MustGet(AuthorizationPayloadKey).(*tokens.Payload).Username, Valid: true},
+			Owner:   ctx.MustGet(AuthorizationPayloadKey).(*tokens.Payload).Username,
 		}
 		notes, err = server.store.SearchNotes(ctx, arg)
 	} else {
@@ -155,13 +171,13 @@ func (server *Server) ListNotes(ctx *gin.Context) {
 		arg := Database.ListNotesParams{
 			NoteID: req.Cursor,
 			Limit:  req.PageSize,
-			Owner:  sql.NullString{String: ctx.MustGet(AuthorizationPayloadKey).(*tokens.Payload).Username, Valid: true},
+			Owner:  ctx.MustGet(AuthorizationPayloadKey).(*tokens.Payload).Username,
 		}
 		notes, err = server.store.ListNotes(ctx, arg)
 	}
 
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		abortWithError(ctx, err)
 		return
 	}
 
@@ -175,9 +191,18 @@ type UpdateNoteRequest struct {
 
 func (server *Server) UpdateNote(ctx *gin.Context) {
 	var req UpdateNoteRequest
-	err := ctx.ShouldBindJSON(&req)
+
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*tokens.Payload)
+	quota, err := server.quotaFor(ctx, authPayload.Username)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		abortWithError(ctx, err)
+		return
+	}
+
+	limitNoteBody(ctx, quota)
+	err = ctx.ShouldBindJSON(&req)
+	if err != nil {
+		abortWithError(ctx, bindingError(err))
 		return
 	}
 
@@ -186,40 +211,45 @@ func (server *Server) UpdateNote(ctx *gin.Context) {
 	// simple atoi
 	fmt.Sscanf(noteIdStr, "%d", &noteId)
 	if noteId == 0 {
-		ctx.JSON(http.StatusBadRequest, errResponse(fmt.Errorf("invalid note id")))
+		abortWithError(ctx, errInvalidNoteID)
 		return
 	}
 
-	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*tokens.Payload)
 	existingNote, err := server.store.GetNoteById(ctx, noteId)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errResponse(err))
+		if err == Database.ErrRecordNotFound {
+			abortWithError(ctx, errNoteNotFound)
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		abortWithError(ctx, err)
+		return
+	}
+
+	if existingNote.Owner != authPayload.Username {
+		abortWithError(ctx, errNoteNotOwned)
 		return
 	}
 
-	if existingNote.Owner.String != authPayload.Username {
-		err := errors.New("note doesn't belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errResponse(err))
+	oldSize := noteSize(existingNote.Title, existingNote.Content)
+	err = server.checkNoteQuota(ctx, authPayload.Username, quota, oldSize, noteSize(req.Title, req.Content))
+	if err != nil {
+		abortWithError(ctx, err)
 		return
 	}
 
 	arg := Database.UpdateNoteParams{
 		NoteID:  noteId,
-		Title:   sql.NullString{String: req.Title, Valid: true},
-		Content: sql.NullString{String: req.Content, Valid: true},
+		Title:   req.Title,
+		Content: req.Content,
 	}
 
 	note, err := server.store.UpdateNote(ctx, arg)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errResponse(err))
+		if err == Database.ErrRecordNotFound {
+			abortWithError(ctx, errNoteNotFound)
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		abortWithError(ctx, err)
 		return
 	}
 
@@ -235,30 +265,22 @@ func (server *Server) DeleteNote(ctx *gin.Context) {
 	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*tokens.Payload)
 	existingNote, err := server.store.GetNoteById(ctx, noteId)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errResponse(err))
+		if err == Database.ErrRecordNotFound {
+			abortWithError(ctx, errNoteNotFound)
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		abortWithError(ctx, err)
 		return
 	}
 
-	if existingNote.Owner.String != authPayload.Username {
-		err := errors.New("note doesn't belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errResponse(err))
-		return
-	}
-	
-	// Manually cascade delete
-	err = server.store.DeleteNoteTagsByNoteId(ctx, noteId)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+	if existingNote.Owner != authPayload.Username {
+		abortWithError(ctx, errNoteNotOwned)
 		return
 	}
 
 	err = server.store.DeleteNote(ctx, noteId)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		abortWithError(ctx, err)
 		return
 	}
 	
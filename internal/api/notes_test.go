@@ -39,11 +39,11 @@ func TestCreateNoteApi(t *testing.T) {
 		{
 			name: "OK",
 			body: gin.H{
-				"title":   note.Title.String,
-				"content": note.Content.String,
+				"title":   note.Title,
+				"content": note.Content,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker tokens.Maker) {
-				addAuthorization(t, request, tokenMaker, AuthorizationTypeBearer, note.Owner.String, time.Minute)
+				addAuthorization(t, request, tokenMaker, AuthorizationTypeBearer, note.Owner, time.Minute)
 			},
 			buildStubs: func(store *mockDB.MockStore) {
 				store.EXPECT().
@@ -59,10 +59,10 @@ func TestCreateNoteApi(t *testing.T) {
 		{
 			name: "BadRequest",
 			body: gin.H{
-				"content": note.Content.String,
+				"content": note.Content,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker tokens.Maker) {
-				addAuthorization(t, request, tokenMaker, AuthorizationTypeBearer, note.Owner.String, time.Minute)
+				addAuthorization(t, request, tokenMaker, AuthorizationTypeBearer, note.Owner, time.Minute)
 			},
 			buildStubs: func(store *mockDB.MockStore) {
 				store.EXPECT().
@@ -76,17 +76,17 @@ func TestCreateNoteApi(t *testing.T) {
 		{
 			name: "InternalServerError",
 			body: gin.H{
-				"title":   note.Title.String,
-				"content": note.Content.String,
+				"title":   note.Title,
+				"content": note.Content,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker tokens.Maker) {
-				addAuthorization(t, request, tokenMaker, AuthorizationTypeBearer, note.Owner.String, time.Minute)
+				addAuthorization(t, request, tokenMaker, AuthorizationTypeBearer, note.Owner, time.Minute)
 			},
 			buildStubs: func(store *mockDB.MockStore) {
 				store.EXPECT().
 					CreateNote(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
-					Return(Database.Note{}, sql.ErrNoRows)
+					Return(Database.Note{}, sql.ErrConnDone)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
@@ -104,6 +104,8 @@ func TestCreateNoteApi(t *testing.T) {
 
 			store := mockDB.NewMockStore(ctrl)
 			tc.buildStubs(store)
+			allowSessions(store)
+			allowQuotas(store)
 
 			server, _ := newTestServer(t, store)
 			recorder := httptest.NewRecorder()
@@ -140,7 +142,7 @@ func TestGetNotesApi(t *testing.T) {
 			name:   "OK",
 			noteId: note.NoteID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker tokens.Maker) {
-				addAuthorization(t, request, tokenMaker, AuthorizationTypeBearer, note.Owner.String, time.Minute)
+				addAuthorization(t, request, tokenMaker, AuthorizationTypeBearer, note.Owner, time.Minute)
 			},
 			buildStubs: func(store *mockDB.MockStore) {
 				store.EXPECT().
@@ -162,7 +164,7 @@ func TestGetNotesApi(t *testing.T) {
 			name:   "BadRequest",
 			noteId: 0,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker tokens.Maker) {
-				addAuthorization(t, request, tokenMaker, AuthorizationTypeBearer, note.Owner.String, time.Minute)
+				addAuthorization(t, request, tokenMaker, AuthorizationTypeBearer, note.Owner, time.Minute)
 			},
 			buildStubs: func(store *mockDB.MockStore) {
 				store.EXPECT().
@@ -177,13 +179,13 @@ func TestGetNotesApi(t *testing.T) {
 			name:   "NotFound",
 			noteId: note.NoteID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker tokens.Maker) {
-				addAuthorization(t, request, tokenMaker, AuthorizationTypeBearer, note.Owner.String, time.Minute)
+				addAuthorization(t, request, tokenMaker, AuthorizationTypeBearer, note.Owner, time.Minute)
 			},
 			buildStubs: func(store *mockDB.MockStore) {
 				store.EXPECT().
 					GetNoteById(gomock.Any(), gomock.Eq(note.NoteID)).
 					Times(1).
-					Return(Database.Note{}, sql.ErrNoRows)
+					Return(Database.Note{}, Database.ErrRecordNotFound)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusNotFound, recorder.Code)
@@ -193,7 +195,7 @@ func TestGetNotesApi(t *testing.T) {
 			name:   "InternalServerError",
 			noteId: note.NoteID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker tokens.Maker) {
-				addAuthorization(t, request, tokenMaker, AuthorizationTypeBearer, note.Owner.String, time.Minute)
+				addAuthorization(t, request, tokenMaker, AuthorizationTypeBearer, note.Owner, time.Minute)
 			},
 			buildStubs: func(store *mockDB.MockStore) {
 				store.EXPECT().
@@ -217,6 +219,7 @@ func TestGetNotesApi(t *testing.T) {
 
 			store := mockDB.NewMockStore(ctrl)
 			tc.buildStubs(store)
+			allowSessions(store)
 
 			server, _ := newTestServer(t, store)
 			recorder := httptest.NewRecorder()
@@ -265,7 +268,7 @@ func TestListNotes(t *testing.T) {
 				arg := Database.ListNotesParams{
 					NoteID: query.cursor,
 					Limit:  query.page_size,
-					Owner:  sql.NullString{String: "user", Valid: true},
+					Owner:  "user",
 				}
 				store.EXPECT().
 					ListNotes(gomock.Any(), gomock.Eq(arg)).
@@ -297,7 +300,7 @@ func TestListNotes(t *testing.T) {
 				arg := Database.ListNotesParams{
 					NoteID: query.cursor,
 					Limit:  query.page_size,
-					Owner:  sql.NullString{String: "user", Valid: true},
+					Owner:  "user",
 				}
 				store.EXPECT().
 					ListNotes(gomock.Any(), gomock.Eq(arg)).
@@ -321,7 +324,7 @@ func TestListNotes(t *testing.T) {
 				arg := Database.ListNotesParams{
 					NoteID: query.cursor,
 					Limit:  query.page_size,
-					Owner:  sql.NullString{String: "user", Valid: true},
+					Owner:  "user",
 				}
 				store.EXPECT().
 					ListNotes(gomock.Any(), gomock.Eq(arg)).
@@ -343,6 +346,7 @@ func TestListNotes(t *testing.T) {
 
 			store := mockDB.NewMockStore(ctrl)
 			tc.buildStubs(store, tc.query)
+			allowSessions(store)
 
 			server, _ := newTestServer(t, store)
 			recorder := httptest.NewRecorder()
@@ -363,11 +367,11 @@ func TestListNotes(t *testing.T) {
 func RandomNotes() Database.Note {
 	return Database.Note{
 		NoteID:   int32(util.RandomInt(1, 100)),
-		Owner:    sql.NullString{String: util.RandomString(5), Valid: true},
-		Title:    sql.NullString{String: util.RandomString(5), Valid: true},
-		Content:  sql.NullString{String: util.RandomString(8), Valid: true},
-		Pinned:   sql.NullBool{Bool: false, Valid: true},
-		Archived: sql.NullBool{Bool: false, Valid: true},
+		Owner:    util.RandomString(5),
+		Title:    util.RandomString(5),
+		Content:  util.RandomString(8),
+		Pinned:   false,
+		Archived: false,
 	}
 }
 
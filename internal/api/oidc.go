@@ -0,0 +1,387 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/gin-gonic/gin"
+	Database "github.com/nilesh0729/Notes/internal/db/Result"
+	"github.com/nilesh0729/Notes/internal/util"
+	"golang.org/x/oauth2"
+)
+
+const (
+	oidcUsernameMaxLength = 32
+	oidcUsernameAttempts  = 5
+	oidcDiscoveryTimeout  = 10 * time.Second
+	// oidcLoginCodeDuration is how long the SPA has to exchange the code it
+	// is redirected with after a successful login.
+	oidcLoginCodeDuration = time.Minute
+	oidcStateCookie       = "oidc_state"
+)
+
+var (
+	errInvalidOIDCState       = newAPIError(http.StatusBadRequest, CodeBadRequest, "login state is invalid or has expired")
+	errInvalidOIDCToken       = newAPIError(http.StatusUnauthorized, CodeUnauthorized, "identity provider returned an invalid ID token")
+	errOIDCCodeExchange       = newAPIError(http.StatusUnauthorized, CodeUnauthorized, "cannot redeem authorization code")
+	errOIDCCodeMissing        = newAPIError(http.StatusBadRequest, CodeBadRequest, "authorization code is missing")
+	errOIDCEmailNotVerified   = newAPIError(http.StatusForbidden, CodeForbidden, "identity provider did not return a verified email address")
+	errOIDCEmailAmbiguous     = newAPIError(http.StatusConflict, CodeConflict, "email address belongs to more than one account")
+	errOIDCUsernameUnassigned = errors.New("cannot find a free username")
+)
+
+// oidcProvider holds what the login flow needs from an OpenID Connect
+// provider. Its endpoints and signing keys come from discovery.
+type oidcProvider struct {
+	oauth2   oauth2.Config
+	verifier *oidc.IDTokenVerifier
+}
+
+func newOIDCProvider(ctx context.Context, config util.Config) (*oidcProvider, error) {
+	ctx, cancel := context.WithTimeout(ctx, oidcDiscoveryTimeout)
+	defer cancel()
+
+	provider, err := oidc.NewProvider(ctx, config.OIDCIssuerURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &oidcProvider{
+		oauth2: oauth2.Config{
+			ClientID:     config.OIDCClientID,
+			ClientSecret: config.OIDCClientSecret,
+			Endpoint:     provider.Endpoint(),
+			RedirectURL:  config.OIDCRedirectURL,
+			Scopes:       config.OIDCScopes,
+		},
+		verifier: provider.Verifier(&oidc.Config{ClientID: config.OIDCClientID}),
+	}, nil
+}
+
+// OIDCLogin sends the browser to the identity provider. The state, nonce and
+// PKCE verifier are kept in the database until the provider redirects back.
+// A cookie holding the hash of the state ties the login to this browser, so
+// a callback URL cannot be used to sign someone else in.
+func (server *Server) OIDCLogin(ctx *gin.Context) {
+	state, stateHash, err := util.NewOpaqueToken()
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+	nonce, _, err := util.NewOpaqueToken()
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	_, err = server.store.CreateOIDCState(ctx, Database.CreateOIDCStateParams{
+		StateHash:    stateHash,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(server.config.OIDCStateDuration),
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	http.SetCookie(ctx.Writer, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    base64.RawURLEncoding.EncodeToString(stateHash),
+		Path:     "/",
+		MaxAge:   int(server.config.OIDCStateDuration / time.Second),
+		Secure:   isHTTPS(ctx, server.trustedProxies),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	url := server.oidc.oauth2.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier))
+	ctx.Redirect(http.StatusFound, url)
+}
+
+type OIDCCallbackRequest struct {
+	State            string `form:"state" binding:"required"`
+	Code             string `form:"code"`
+	Error            string `form:"error"`
+	ErrorDescription string `form:"error_description"`
+}
+
+type oidcClaims struct {
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	PreferredUsername string `json:"preferred_username"`
+}
+
+// OIDCCallback finishes the login started by OIDCLogin: it redeems the
+// authorization code and verifies the ID token. The browser is then sent back
+// to the app with a short-lived code, which the app exchanges for a session
+// at POST /oidc/exchange.
+func (server *Server) OIDCCallback(ctx *gin.Context) {
+	var req OIDCCallbackRequest
+
+	err := ctx.ShouldBindQuery(&req)
+	if err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	stateHash := util.HashToken(req.State)
+	if !server.oidcStateCookieMatches(ctx, stateHash) {
+		abortWithError(ctx, errInvalidOIDCState)
+		return
+	}
+
+	state, err := server.store.ConsumeOIDCState(ctx, stateHash)
+	if err != nil {
+		if err == Database.ErrRecordNotFound {
+			abortWithError(ctx, errInvalidOIDCState)
+			return
+		}
+		abortWithError(ctx, err)
+		return
+	}
+
+	if req.Error != "" {
+		msg := "identity provider denied the login: " + req.Error
+		if req.ErrorDescription != "" {
+			msg += ": " + req.ErrorDescription
+		}
+		abortWithError(ctx, newAPIError(http.StatusUnauthorized, CodeUnauthorized, msg))
+		return
+	}
+	if req.Code == "" {
+		abortWithError(ctx, errOIDCCodeMissing)
+		return
+	}
+
+	token, err := server.oidc.oauth2.Exchange(ctx, req.Code, oauth2.VerifierOption(state.CodeVerifier))
+	if err != nil {
+		abortWithError(ctx, withCause(errOIDCCodeExchange, err))
+		return
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		abortWithError(ctx, errInvalidOIDCToken)
+		return
+	}
+	idToken, err := server.oidc.verifier.Verify(ctx, rawIDToken)
+	if err != nil || idToken.Nonce != state.Nonce {
+		abortWithError(ctx, errInvalidOIDCToken)
+		return
+	}
+
+	var claims oidcClaims
+	err = idToken.Claims(&claims)
+	if err != nil {
+		abortWithError(ctx, errInvalidOIDCToken)
+		return
+	}
+
+	user, err := server.oidcUser(ctx, idToken.Issuer, idToken.Subject, claims)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	code, err := server.issueUserToken(ctx, user, Database.TokenPurposeOIDCLogin, oidcLoginCodeDuration)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.Redirect(http.StatusFound, server.appLink("/login/oidc", code))
+}
+
+// oidcStateCookieMatches reports whether the browser holds the cookie set
+// by OIDCLogin for stateHash. The cookie is cleared either way.
+func (server *Server) oidcStateCookieMatches(ctx *gin.Context, stateHash []byte) bool {
+	cookie, err := ctx.Request.Cookie(oidcStateCookie)
+	if err != nil {
+		return false
+	}
+
+	http.SetCookie(ctx.Writer, &http.Cookie{
+		Name:     oidcStateCookie,
+		Path:     "/",
+		MaxAge:   -1,
+		Secure:   isHTTPS(ctx, server.trustedProxies),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	expected := base64.RawURLEncoding.EncodeToString(stateHash)
+	return subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(expected)) == 1
+}
+
+type OIDCExchangeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// OIDCExchange trades the code handed out by OIDCCallback for a session, or
+// for a login challenge if the user has two-factor authentication enabled.
+func (server *Server) OIDCExchange(ctx *gin.Context) {
+	var req OIDCExchangeRequest
+
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	token, err := server.store.ConsumeUserToken(ctx, Database.ConsumeUserTokenParams{
+		TokenHash: util.HashToken(req.Code),
+		Purpose:   Database.TokenPurposeOIDCLogin,
+	})
+	if err != nil {
+		if err == Database.ErrRecordNotFound {
+			abortWithError(ctx, errInvalidUserToken)
+			return
+		}
+		abortWithError(ctx, err)
+		return
+	}
+
+	user, err := server.store.GetUser(ctx, token.Username)
+	if err != nil {
+		if err == Database.ErrRecordNotFound {
+			abortWithError(ctx, errInvalidUserToken)
+			return
+		}
+		abortWithError(ctx, err)
+		return
+	}
+
+	if user.TotpEnabled {
+		server.startTwoFactorLogin(ctx, user)
+		return
+	}
+
+	accessToken, err := server.createSession(ctx, user.Username)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, LoginUserResponse{
+		AccessToken: accessToken,
+		User:        UserResponse(user),
+	})
+}
+
+// oidcUser finds the local user for an external account. Accounts seen before
+// are looked up by issuer and subject. New ones are linked to the user with
+// the same verified email address, or get a user of their own if there is
+// none. Unverified local addresses are never linked, since whoever registered
+// them may not own the mailbox.
+func (server *Server) oidcUser(ctx *gin.Context, issuer, subject string, claims oidcClaims) (Database.User, error) {
+	identity, err := server.store.GetUserIdentity(ctx, Database.GetUserIdentityParams{
+		Issuer:  issuer,
+		Subject: subject,
+	})
+	if err == nil {
+		return server.store.GetUser(ctx, identity.Username)
+	}
+	if err != Database.ErrRecordNotFound {
+		return Database.User{}, err
+	}
+
+	if claims.Email == "" || !claims.EmailVerified {
+		return Database.User{}, errOIDCEmailNotVerified
+	}
+
+	users, err := server.store.ListUsersByEmail(ctx, claims.Email)
+	if err != nil {
+		return Database.User{}, err
+	}
+
+	var verified []Database.User
+	for _, user := range users {
+		if user.EmailVerified {
+			verified = append(verified, user)
+		}
+	}
+
+	switch len(verified) {
+	case 0:
+		return server.createOIDCUser(ctx, issuer, subject, claims)
+	case 1:
+		_, err = server.store.CreateUserIdentity(ctx, Database.CreateUserIdentityParams{
+			Issuer:   issuer,
+			Subject:  subject,
+			Username: verified[0].Username,
+			Email:    claims.Email,
+		})
+		return verified[0], err
+	default:
+		return Database.User{}, errOIDCEmailAmbiguous
+	}
+}
+
+// createOIDCUser registers a user for an external account. The username is
+// derived from the provider's claims, with digits appended if it is taken.
+// The password is random, so it can only be used after a password reset.
+func (server *Server) createOIDCUser(ctx *gin.Context, issuer, subject string, claims oidcClaims) (Database.User, error) {
+	password, _, err := util.NewOpaqueToken()
+	if err != nil {
+		return Database.User{}, err
+	}
+	hashedPassword, err := server.passwordHasher.Hash(password)
+	if err != nil {
+		return Database.User{}, err
+	}
+
+	base := oidcUsernameBase(claims)
+	for attempt := 0; attempt < oidcUsernameAttempts; attempt++ {
+		username := base
+		if attempt > 0 || len(username) < 6 {
+			username = fmt.Sprintf("%s%04d", base, util.RandomInt(0, 9999))
+		}
+
+		user, err := server.store.CreateOIDCUserTx(ctx, Database.CreateOIDCUserTxParams{
+			Username:       username,
+			HashedPassword: hashedPassword,
+			Email:          claims.Email,
+			Issuer:         issuer,
+			Subject:        subject,
+		})
+		if Database.ErrorCode(err) == Database.UniqueViolation {
+			continue
+		}
+		return user, err
+	}
+
+	return Database.User{}, errOIDCUsernameUnassigned
+}
+
+// oidcUsernameBase turns the preferred username, or else the local part of
+// the email address, into an alphanumeric username.
+func oidcUsernameBase(claims oidcClaims) string {
+	name := claims.PreferredUsername
+	if name == "" {
+		name, _, _ = strings.Cut(claims.Email, "@")
+	}
+
+	var sb strings.Builder
+	for _, r := range name {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			sb.WriteRune(r)
+		}
+		if sb.Len() == oidcUsernameMaxLength {
+			break
+		}
+	}
+
+	if sb.Len() == 0 {
+		return "user"
+	}
+	return sb.String()
+}
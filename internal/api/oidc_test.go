@@ -0,0 +1,678 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/golang/mock/gomock"
+	mockDB "github.com/nilesh0729/Notes/internal/db/Mock"
+	Database "github.com/nilesh0729/Notes/internal/db/Result"
+	"github.com/nilesh0729/Notes/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	mockOIDCClientID = "notes"
+	mockOIDCKeyID    = "test-key"
+)
+
+// mockOIDCProvider is a minimal OpenID Connect provider serving discovery,
+// JWKS and a token endpoint that checks the PKCE verifier.
+type mockOIDCProvider struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu     sync.Mutex
+	grants map[string]mockOIDCGrant
+}
+
+type mockOIDCGrant struct {
+	challenge string
+	claims    jwt.MapClaims
+}
+
+func newMockOIDCProvider(t *testing.T) *mockOIDCProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	provider := &mockOIDCProvider{
+		key:    key,
+		grants: make(map[string]mockOIDCGrant),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", provider.discovery)
+	mux.HandleFunc("/keys", provider.keys)
+	mux.HandleFunc("/token", provider.token)
+
+	provider.server = httptest.NewServer(mux)
+	t.Cleanup(provider.server.Close)
+
+	return provider
+}
+
+func (provider *mockOIDCProvider) issuer() string {
+	return provider.server.URL
+}
+
+func (provider *mockOIDCProvider) discovery(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"issuer":                                provider.issuer(),
+		"authorization_endpoint":                provider.issuer() + "/authorize",
+		"token_endpoint":                        provider.issuer() + "/token",
+		"jwks_uri":                              provider.issuer() + "/keys",
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+func (provider *mockOIDCProvider) keys(w http.ResponseWriter, r *http.Request) {
+	pub := provider.key.PublicKey
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"alg": "RS256",
+			"use": "sig",
+			"kid": mockOIDCKeyID,
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+func (provider *mockOIDCProvider) token(w http.ResponseWriter, r *http.Request) {
+	provider.mu.Lock()
+	grant, ok := provider.grants[r.PostFormValue("code")]
+	delete(provider.grants, r.PostFormValue("code"))
+	provider.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(sum[:]) != grant.challenge {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, grant.claims)
+	idToken.Header["kid"] = mockOIDCKeyID
+	signed, err := idToken.SignedString(provider.key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token": util.RandomString(16),
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     signed,
+	})
+}
+
+// authorize plays the user signing in at the provider: it accepts the
+// authorization request and returns the code the provider would redirect
+// back with. claims are added to the standard ID token claims.
+func (provider *mockOIDCProvider) authorize(t *testing.T, authURL *url.URL, claims jwt.MapClaims) string {
+	query := authURL.Query()
+	require.Equal(t, mockOIDCClientID, query.Get("client_id"))
+	require.Equal(t, "S256", query.Get("code_challenge_method"))
+
+	idClaims := jwt.MapClaims{
+		"iss":   provider.issuer(),
+		"aud":   mockOIDCClientID,
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Minute).Unix(),
+		"nonce": query.Get("nonce"),
+	}
+	for name, value := range claims {
+		idClaims[name] = value
+	}
+
+	code := util.RandomString(16)
+
+	provider.mu.Lock()
+	provider.grants[code] = mockOIDCGrant{
+		challenge: query.Get("code_challenge"),
+		claims:    idClaims,
+	}
+	provider.mu.Unlock()
+
+	return code
+}
+
+// configure points config at the provider.
+func (provider *mockOIDCProvider) configure(config *util.Config) {
+	config.OIDCIssuerURL = provider.issuer()
+	config.OIDCClientID = mockOIDCClientID
+	config.OIDCClientSecret = util.RandomString(16)
+	config.OIDCRedirectURL = "http://localhost/oidc/callback"
+	config.OIDCScopes = []string{"openid", "email"}
+	config.OIDCStateDuration = time.Minute
+}
+
+func TestOIDCLoginAPI(t *testing.T) {
+	_, user := RandomUser(t)
+	user.EmailVerified = true
+
+	unverified := user
+	unverified.Username = util.RandomOwner()
+	unverified.EmailVerified = false
+
+	subject := util.RandomString(12)
+
+	testCases := []struct {
+		name string
+		// claims are added to the ID token issued by the provider.
+		claims jwt.MapClaims
+		// tamper changes the pending login state before it is handed back
+		// to the callback.
+		tamper        func(state *Database.OidcState)
+		buildStubs    func(store *mockDB.MockStore, issuer string)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "KnownIdentity",
+			claims: jwt.MapClaims{"sub": subject},
+			buildStubs: func(store *mockDB.MockStore, issuer string) {
+				store.EXPECT().
+					GetUserIdentity(gomock.Any(), gomock.Eq(Database.GetUserIdentityParams{Issuer: issuer, Subject: subject})).
+					Times(1).
+					Return(Database.UserIdentity{Issuer: issuer, Subject: subject, Username: user.Username}, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				expectOIDCLoginCode(t, store, user.Username)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireOIDCRedirect(t, recorder)
+			},
+		},
+		{
+			name:   "LinkByVerifiedEmail",
+			claims: jwt.MapClaims{"sub": subject, "email": user.Email, "email_verified": true},
+			buildStubs: func(store *mockDB.MockStore, issuer string) {
+				store.EXPECT().
+					GetUserIdentity(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(Database.UserIdentity{}, Database.ErrRecordNotFound)
+				store.EXPECT().
+					ListUsersByEmail(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return([]Database.User{unverified, user}, nil)
+				arg := Database.CreateUserIdentityParams{
+					Issuer:   issuer,
+					Subject:  subject,
+					Username: user.Username,
+					Email:    user.Email,
+				}
+				store.EXPECT().
+					CreateUserIdentity(gomock.Any(), gomock.Eq(arg)).
+					Times(1)
+				expectOIDCLoginCode(t, store, user.Username)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireOIDCRedirect(t, recorder)
+			},
+		},
+		{
+			name:   "CreateUser",
+			claims: jwt.MapClaims{"sub": subject, "email": "jane.doe@example.com", "email_verified": true},
+			buildStubs: func(store *mockDB.MockStore, issuer string) {
+				store.EXPECT().
+					GetUserIdentity(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(Database.UserIdentity{}, Database.ErrRecordNotFound)
+				// An unverified local account with the same address must
+				// not be taken over.
+				store.EXPECT().
+					ListUsersByEmail(gomock.Any(), gomock.Eq("jane.doe@example.com")).
+					Times(1).
+					Return([]Database.User{unverified}, nil)
+				store.EXPECT().
+					CreateUserIdentity(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateOIDCUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg Database.CreateOIDCUserTxParams) (Database.User, error) {
+						require.Equal(t, "janedoe", arg.Username)
+						require.Equal(t, "jane.doe@example.com", arg.Email)
+						require.Equal(t, issuer, arg.Issuer)
+						require.Equal(t, subject, arg.Subject)
+						require.NotEmpty(t, arg.HashedPassword)
+						return Database.User{Username: arg.Username, Email: arg.Email, EmailVerified: true}, nil
+					})
+				expectOIDCLoginCode(t, store, "janedoe")
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireOIDCRedirect(t, recorder)
+			},
+		},
+		{
+			name:   "UnverifiedEmailClaim",
+			claims: jwt.MapClaims{"sub": subject, "email": user.Email, "email_verified": false},
+			buildStubs: func(store *mockDB.MockStore, issuer string) {
+				store.EXPECT().
+					GetUserIdentity(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(Database.UserIdentity{}, Database.ErrRecordNotFound)
+				store.EXPECT().
+					ListUsersByEmail(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateUserToken(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "WrongNonce",
+			claims: jwt.MapClaims{"sub": subject},
+			tamper: func(state *Database.OidcState) {
+				state.Nonce = util.RandomString(16)
+			},
+			buildStubs: func(store *mockDB.MockStore, issuer string) {
+				store.EXPECT().
+					GetUserIdentity(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "WrongCodeVerifier",
+			claims: jwt.MapClaims{"sub": subject},
+			tamper: func(state *Database.OidcState) {
+				state.CodeVerifier = util.RandomString(43)
+			},
+			buildStubs: func(store *mockDB.MockStore, issuer string) {
+				store.EXPECT().
+					GetUserIdentity(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "WrongAudience",
+			claims: jwt.MapClaims{"sub": subject, "aud": "someone-else"},
+			buildStubs: func(store *mockDB.MockStore, issuer string) {
+				store.EXPECT().
+					GetUserIdentity(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			provider := newMockOIDCProvider(t)
+
+			store := mockDB.NewMockStore(ctrl)
+			tc.buildStubs(store, provider.issuer())
+
+			var pending Database.OidcState
+			store.EXPECT().
+				CreateOIDCState(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(_ context.Context, arg Database.CreateOIDCStateParams) (Database.OidcState, error) {
+					pending = Database.OidcState{
+						StateHash:    arg.StateHash,
+						Nonce:        arg.Nonce,
+						CodeVerifier: arg.CodeVerifier,
+						ExpiresAt:    arg.ExpiresAt,
+					}
+					return pending, nil
+				})
+			store.EXPECT().
+				ConsumeOIDCState(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(_ context.Context, stateHash []byte) (Database.OidcState, error) {
+					require.Equal(t, pending.StateHash, stateHash)
+					if tc.tamper != nil {
+						tc.tamper(&pending)
+					}
+					return pending, nil
+				})
+
+			server, _ := newTestServer(t, store, provider.configure)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, "/oidc/login", nil)
+			require.NoError(t, err)
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusFound, recorder.Code)
+
+			authURL, err := url.Parse(recorder.Header().Get("Location"))
+			require.NoError(t, err)
+			require.Equal(t, provider.issuer()+"/authorize", authURL.Scheme+"://"+authURL.Host+authURL.Path)
+
+			code := provider.authorize(t, authURL, tc.claims)
+
+			callback := url.Values{
+				"state": {authURL.Query().Get("state")},
+				"code":  {code},
+			}
+			recorder = httptest.NewRecorder()
+			request, err = http.NewRequest(http.MethodGet, "/oidc/callback?"+callback.Encode(), nil)
+			require.NoError(t, err)
+			addOIDCStateCookie(request, authURL.Query().Get("state"))
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestOIDCLoginSetsStateCookie(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	provider := newMockOIDCProvider(t)
+
+	var stateHash []byte
+	store := mockDB.NewMockStore(ctrl)
+	store.EXPECT().
+		CreateOIDCState(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg Database.CreateOIDCStateParams) (Database.OidcState, error) {
+			stateHash = arg.StateHash
+			return Database.OidcState{}, nil
+		})
+
+	server, _ := newTestServer(t, store, provider.configure)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/oidc/login", nil)
+	require.NoError(t, err)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusFound, recorder.Code)
+
+	cookies := recorder.Result().Cookies()
+	require.Len(t, cookies, 1)
+	require.Equal(t, oidcStateCookie, cookies[0].Name)
+	require.Equal(t, base64.RawURLEncoding.EncodeToString(stateHash), cookies[0].Value)
+	require.True(t, cookies[0].HttpOnly)
+	require.Equal(t, http.SameSiteLaxMode, cookies[0].SameSite)
+	require.Equal(t, 60, cookies[0].MaxAge)
+}
+
+func TestOIDCCallbackStateCookie(t *testing.T) {
+	testCases := []struct {
+		name   string
+		cookie func(request *http.Request)
+	}{
+		{
+			name:   "NoCookie",
+			cookie: func(request *http.Request) {},
+		},
+		{
+			// The browser started a login of its own, e.g. when an attacker
+			// sends a victim the callback URL of their login.
+			name: "OtherLogin",
+			cookie: func(request *http.Request) {
+				addOIDCStateCookie(request, util.RandomString(32))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			provider := newMockOIDCProvider(t)
+
+			store := mockDB.NewMockStore(ctrl)
+			store.EXPECT().
+				ConsumeOIDCState(gomock.Any(), gomock.Any()).
+				Times(0)
+
+			server, _ := newTestServer(t, store, provider.configure)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, "/oidc/callback?state=victim&code=abc", nil)
+			require.NoError(t, err)
+			tc.cookie(request)
+			server.router.ServeHTTP(recorder, request)
+			requireAPIError(t, recorder, CodeBadRequest)
+		})
+	}
+}
+
+func TestOIDCCallbackInvalidState(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	provider := newMockOIDCProvider(t)
+
+	store := mockDB.NewMockStore(ctrl)
+	store.EXPECT().
+		ConsumeOIDCState(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(Database.OidcState{}, Database.ErrRecordNotFound)
+
+	server, _ := newTestServer(t, store, provider.configure)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/oidc/callback?state=unknown&code=abc", nil)
+	require.NoError(t, err)
+	addOIDCStateCookie(request, "unknown")
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestOIDCExchangeAPI(t *testing.T) {
+	_, user := RandomUser(t)
+
+	twoFactorUser := user
+	twoFactorUser.TotpEnabled = true
+
+	code := util.RandomString(32)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockDB.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"code": code},
+			buildStubs: func(store *mockDB.MockStore) {
+				arg := Database.ConsumeUserTokenParams{
+					TokenHash: util.HashToken(code),
+					Purpose:   Database.TokenPurposeOIDCLogin,
+				}
+				store.EXPECT().
+					ConsumeUserToken(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(Database.UserToken{Username: user.Username}, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireLoginResponse(t, recorder, user.Username)
+			},
+		},
+		{
+			name: "TwoFactorEnabled",
+			body: gin.H{"code": code},
+			buildStubs: func(store *mockDB.MockStore) {
+				store.EXPECT().
+					ConsumeUserToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(Database.UserToken{Username: user.Username}, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(twoFactorUser, nil)
+				store.EXPECT().
+					CreateUserToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg Database.CreateUserTokenParams) (Database.UserToken, error) {
+						require.Equal(t, Database.TokenPurposeLoginChallenge, arg.Purpose)
+						return Database.UserToken{}, nil
+					})
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidCode",
+			body: gin.H{"code": code},
+			buildStubs: func(store *mockDB.MockStore) {
+				store.EXPECT().
+					ConsumeUserToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(Database.UserToken{}, Database.ErrRecordNotFound)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireAPIError(t, recorder, CodeBadRequest)
+			},
+		},
+		{
+			name: "MissingCode",
+			body: gin.H{},
+			buildStubs: func(store *mockDB.MockStore) {
+				store.EXPECT().
+					ConsumeUserToken(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			provider := newMockOIDCProvider(t)
+
+			store := mockDB.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, _ := newTestServer(t, store, provider.configure)
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodPost, "/oidc/exchange", bytes.NewReader(data))
+			require.NoError(t, err)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestOIDCRoutesDisabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server, _ := newTestServer(t, mockDB.NewMockStore(ctrl))
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/oidc/login", nil)
+	require.NoError(t, err)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestOIDCUsernameBase(t *testing.T) {
+	require.Equal(t, "jdoe", oidcUsernameBase(oidcClaims{PreferredUsername: "j.doe", Email: "jane@example.com"}))
+	require.Equal(t, "janedoenotes", oidcUsernameBase(oidcClaims{Email: "jane.doe+notes@example.com"}))
+	require.Equal(t, "user", oidcUsernameBase(oidcClaims{Email: "@example.com"}))
+}
+
+// addOIDCStateCookie adds the cookie OIDCLogin sets for state to request.
+func addOIDCStateCookie(request *http.Request, state string) {
+	request.AddCookie(&http.Cookie{
+		Name:  oidcStateCookie,
+		Value: base64.RawURLEncoding.EncodeToString(util.HashToken(state)),
+	})
+}
+
+// expectOIDCLoginCode expects the callback to hand out a login code for
+// username.
+func expectOIDCLoginCode(t *testing.T, store *mockDB.MockStore, username string) {
+	store.EXPECT().
+		CreateUserToken(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ interface{}, arg Database.CreateUserTokenParams) (Database.UserToken, error) {
+			require.Equal(t, Database.TokenPurposeOIDCLogin, arg.Purpose)
+			require.Equal(t, username, arg.Username)
+			return Database.UserToken{}, nil
+		})
+	store.EXPECT().
+		CreateSession(gomock.Any(), gomock.Any()).
+		Times(0)
+}
+
+// requireOIDCRedirect checks that the callback sent the browser back to the
+// app with a login code and cleared the state cookie.
+func requireOIDCRedirect(t *testing.T, recorder *httptest.ResponseRecorder) {
+	require.Equal(t, http.StatusFound, recorder.Code)
+
+	location, err := url.Parse(recorder.Header().Get("Location"))
+	require.NoError(t, err)
+	require.Equal(t, "http://localhost/login/oidc", location.Scheme+"://"+location.Host+location.Path)
+	require.NotEmpty(t, location.Query().Get("token"))
+
+	cookies := recorder.Result().Cookies()
+	require.Len(t, cookies, 1)
+	require.Equal(t, oidcStateCookie, cookies[0].Name)
+	require.Negative(t, cookies[0].MaxAge)
+}
+
+func requireLoginResponse(t *testing.T, recorder *httptest.ResponseRecorder, username string) {
+	var res LoginUserResponse
+	err := json.Unmarshal(recorder.Body.Bytes(), &res)
+	require.NoError(t, err)
+	require.NotEmpty(t, res.AccessToken)
+	require.Equal(t, username, res.User.Username)
+}
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	Database "github.com/nilesh0729/Notes/internal/db/Result"
+	"github.com/nilesh0729/Notes/internal/tokens"
+	"github.com/nilesh0729/Notes/internal/util"
+)
+
+func (server *Server) GetCurrentUser(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*tokens.Payload)
+
+	user, err := server.store.GetUser(ctx, authPayload.Username)
+	if err != nil {
+		if err == Database.ErrRecordNotFound {
+			abortWithError(ctx, errUserNotFound)
+			return
+		}
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, UserResponse(user))
+}
+
+type UpdateCurrentUserRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	Email           string `json:"email" binding:"required,email"`
+}
+
+// UpdateCurrentUser changes the email address of the authenticated user,
+// who has to confirm their current password first, as the address is where
+// password reset links go. The new address starts out unverified and has to
+// be confirmed again, and links mailed to the old one stop working.
+func (server *Server) UpdateCurrentUser(ctx *gin.Context) {
+	var req UpdateCurrentUserRequest
+
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*tokens.Payload)
+
+	user, err := server.store.GetUser(ctx, authPayload.Username)
+	if err != nil {
+		if err == Database.ErrRecordNotFound {
+			abortWithError(ctx, errUserNotFound)
+			return
+		}
+		abortWithError(ctx, err)
+		return
+	}
+
+	err = server.passwordHasher.Verify(req.CurrentPassword, user.HashedPassword)
+	if err != nil {
+		abortWithError(ctx, errWrongPassword)
+		return
+	}
+
+	arg := Database.ChangeEmailTxParams{
+		Username: user.Username,
+		Email:    req.Email,
+	}
+	user, err = server.store.ChangeEmailTx(ctx, arg)
+	if err != nil {
+		if err == Database.ErrRecordNotFound {
+			abortWithError(ctx, errUserNotFound)
+			return
+		}
+		abortWithError(ctx, err)
+		return
+	}
+
+	err = server.sendVerificationEmail(ctx, user)
+	if err != nil {
+		requestLogger(ctx).WarnContext(ctx, "cannot send verification email", slog.String("username", user.Username), slog.Any("error", err))
+	}
+
+	ctx.JSON(http.StatusOK, UserResponse(user))
+}
+
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+// ChangePassword replaces the password of the authenticated user and revokes
+// all of their sessions, including the one used for this request, and their
+// API keys.
+func (server *Server) ChangePassword(ctx *gin.Context) {
+	var req ChangePasswordRequest
+
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*tokens.Payload)
+
+	user, err := server.store.GetUser(ctx, authPayload.Username)
+	if err != nil {
+		if err == Database.ErrRecordNotFound {
+			abortWithError(ctx, errUserNotFound)
+			return
+		}
+		abortWithError(ctx, err)
+		return
+	}
+
+	err = server.passwordHasher.Verify(req.OldPassword, user.HashedPassword)
+	if err != nil {
+		abortWithError(ctx, errWrongPassword)
+		return
+	}
+
+	if !server.checkPassword(ctx, "new_password", req.NewPassword, user.Username, user.Email) {
+		return
+	}
+
+	hashedPassword, err := server.passwordHasher.Hash(req.NewPassword)
+	if err != nil {
+		if errors.Is(err, util.ErrPasswordTooLong) {
+			abortWithError(ctx, validationError([]FieldError{passwordTooLongField("new_password")}))
+			return
+		}
+		abortWithError(ctx, err)
+		return
+	}
+
+	arg := Database.ChangePasswordTxParams{
+		Username:       user.Username,
+		HashedPassword: hashedPassword,
+	}
+	user, err = server.store.ChangePasswordTx(ctx, arg)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, UserResponse(user))
+}
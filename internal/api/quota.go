@@ -0,0 +1,267 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	Database "github.com/nilesh0729/Notes/internal/db/Result"
+	"github.com/nilesh0729/Notes/internal/tokens"
+)
+
+var errNotAdmin = newAPIError(http.StatusForbidden, CodeForbidden, "only administrators can do this")
+
+// Quota is the limits a user is held to. A limit of 0 means none.
+type Quota struct {
+	MaxNoteBytes  int64 `json:"max_note_bytes"`
+	MaxNotes      int64 `json:"max_notes"`
+	MaxTags       int64 `json:"max_tags"`
+	MaxTotalBytes int64 `json:"max_total_bytes"`
+}
+
+// Usage is what a user currently stores. TotalBytes counts the titles and
+// contents of their notes.
+type Usage struct {
+	Notes      int64 `json:"notes"`
+	Tags       int64 `json:"tags"`
+	TotalBytes int64 `json:"total_bytes"`
+}
+
+type UsageResponse struct {
+	Username string `json:"username"`
+	Usage    Usage  `json:"usage"`
+	Limits   Quota  `json:"limits"`
+}
+
+// noteSize is the number of bytes a note counts for against quotas.
+func noteSize(title, content string) int64 {
+	return int64(len(title) + len(content))
+}
+
+// quotaFor returns the limits of username: the configured ones, with the
+// overrides an administrator set for the user.
+func (server *Server) quotaFor(ctx context.Context, username string) (Quota, error) {
+	quota := Quota{
+		MaxNoteBytes:  server.config.QuotaMaxNoteBytes,
+		MaxNotes:      server.config.QuotaMaxNotes,
+		MaxTags:       server.config.QuotaMaxTags,
+		MaxTotalBytes: server.config.QuotaMaxTotalBytes,
+	}
+
+	override, err := server.store.GetUserQuota(ctx, username)
+	if errors.Is(err, Database.ErrRecordNotFound) {
+		return quota, nil
+	}
+	if err != nil {
+		return Quota{}, err
+	}
+
+	overrideLimit(&quota.MaxNoteBytes, override.MaxNoteBytes)
+	overrideLimit(&quota.MaxNotes, override.MaxNotes)
+	overrideLimit(&quota.MaxTags, override.MaxTags)
+	overrideLimit(&quota.MaxTotalBytes, override.MaxTotalBytes)
+	return quota, nil
+}
+
+func overrideLimit(limit *int64, override *int64) {
+	if override != nil {
+		*limit = *override
+	}
+}
+
+func (server *Server) usageOf(ctx context.Context, username string) (Usage, error) {
+	usage, err := server.store.GetUserUsage(ctx, username)
+	if err != nil {
+		return Usage{}, err
+	}
+	return Usage{Notes: usage.Notes, Tags: usage.Tags, TotalBytes: usage.TotalBytes}, nil
+}
+
+// noteBodyOverhead is what a note request may take up besides its title and
+// content: braces, field names and quotes.
+const noteBodyOverhead = 1024
+
+// limitNoteBody stops reading the body of a note request once it is larger
+// than a note within quota can be, so an oversized note is turned down
+// before all of it is read. Every byte of a note can take up to 6 bytes of
+// JSON when escaped as \u00XX.
+func limitNoteBody(ctx *gin.Context, quota Quota) {
+	if quota.MaxNoteBytes <= 0 {
+		return
+	}
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, 6*quota.MaxNoteBytes+noteBodyOverhead)
+}
+
+// checkNoteQuota returns an API error if username, with quota, cannot store
+// a note of newSize bytes. oldSize is the size of the note it replaces, or -1
+// for a new note. The check is not atomic with the write that follows, so
+// concurrent requests can overshoot a limit by a few notes.
+func (server *Server) checkNoteQuota(ctx context.Context, username string, quota Quota, oldSize, newSize int64) error {
+	if quota.MaxNoteBytes > 0 && newSize > quota.MaxNoteBytes {
+		message := fmt.Sprintf("note is %d bytes, the limit is %d", newSize, quota.MaxNoteBytes)
+		return newAPIError(http.StatusRequestEntityTooLarge, CodePayloadTooLarge, message)
+	}
+
+	isNew := oldSize < 0
+	checkCount := isNew && quota.MaxNotes > 0
+	checkBytes := quota.MaxTotalBytes > 0 && newSize > oldSize
+	if !checkCount && !checkBytes {
+		return nil
+	}
+
+	usage, err := server.usageOf(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	if checkCount && usage.Notes >= quota.MaxNotes {
+		message := fmt.Sprintf("note limit of %d reached", quota.MaxNotes)
+		return newAPIError(http.StatusUnprocessableEntity, CodeQuotaExceeded, message)
+	}
+	if checkBytes && usage.TotalBytes-max(oldSize, 0)+newSize > quota.MaxTotalBytes {
+		message := fmt.Sprintf("storage limit of %d bytes reached", quota.MaxTotalBytes)
+		return newAPIError(http.StatusUnprocessableEntity, CodeQuotaExceeded, message)
+	}
+	return nil
+}
+
+// checkTagQuota returns an API error if username cannot create another tag.
+func (server *Server) checkTagQuota(ctx context.Context, username string) error {
+	quota, err := server.quotaFor(ctx, username)
+	if err != nil {
+		return err
+	}
+	if quota.MaxTags <= 0 {
+		return nil
+	}
+
+	usage, err := server.usageOf(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	if usage.Tags >= quota.MaxTags {
+		message := fmt.Sprintf("tag limit of %d reached", quota.MaxTags)
+		return newAPIError(http.StatusUnprocessableEntity, CodeQuotaExceeded, message)
+	}
+	return nil
+}
+
+// usageResponse reports the usage and limits of username.
+func (server *Server) usageResponse(ctx *gin.Context, username string) {
+	quota, err := server.quotaFor(ctx, username)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	usage, err := server.usageOf(ctx, username)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, UsageResponse{Username: username, Usage: usage, Limits: quota})
+}
+
+// GetCurrentUsage reports how much the authenticated user stores, and the
+// limits they are held to.
+func (server *Server) GetCurrentUsage(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*tokens.Payload)
+	server.usageResponse(ctx, authPayload.Username)
+}
+
+// requireAdmin rejects requests from users not listed in
+// config.AdminUsers. It must run after authMiddleware.
+func (server *Server) requireAdmin() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(AuthorizationPayloadKey).(*tokens.Payload)
+
+		if !slices.Contains(server.config.AdminUsers, authPayload.Username) {
+			abortWithError(ctx, errNotAdmin)
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+type AdminUserRequest struct {
+	Username string `uri:"username" binding:"required"`
+}
+
+// GetUserUsage reports the usage and limits of any user.
+func (server *Server) GetUserUsage(ctx *gin.Context) {
+	var req AdminUserRequest
+
+	err := ctx.ShouldBindUri(&req)
+	if err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	_, err = server.store.GetUser(ctx, req.Username)
+	if err != nil {
+		if errors.Is(err, Database.ErrRecordNotFound) {
+			abortWithError(ctx, errUserNotFound)
+			return
+		}
+		abortWithError(ctx, err)
+		return
+	}
+
+	server.usageResponse(ctx, req.Username)
+}
+
+// SetUserQuotaRequest replaces the overrides of a user. A missing or null
+// limit falls back to the configured one, and 0 lifts the limit.
+type SetUserQuotaRequest struct {
+	MaxNoteBytes  *int64 `json:"max_note_bytes" binding:"omitempty,min=0"`
+	MaxNotes      *int64 `json:"max_notes" binding:"omitempty,min=0"`
+	MaxTags       *int64 `json:"max_tags" binding:"omitempty,min=0"`
+	MaxTotalBytes *int64 `json:"max_total_bytes" binding:"omitempty,min=0"`
+}
+
+// SetUserQuota overrides the limits of a user.
+func (server *Server) SetUserQuota(ctx *gin.Context) {
+	var uri AdminUserRequest
+	err := ctx.ShouldBindUri(&uri)
+	if err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	var req SetUserQuotaRequest
+	err = ctx.ShouldBindJSON(&req)
+	if err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	_, err = server.store.GetUser(ctx, uri.Username)
+	if err != nil {
+		if errors.Is(err, Database.ErrRecordNotFound) {
+			abortWithError(ctx, errUserNotFound)
+			return
+		}
+		abortWithError(ctx, err)
+		return
+	}
+
+	_, err = server.store.UpsertUserQuota(ctx, Database.UpsertUserQuotaParams{
+		Username:      uri.Username,
+		MaxNoteBytes:  req.MaxNoteBytes,
+		MaxNotes:      req.MaxNotes,
+		MaxTags:       req.MaxTags,
+		MaxTotalBytes: req.MaxTotalBytes,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	server.usageResponse(ctx, uri.Username)
+}
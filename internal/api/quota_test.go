@@ -0,0 +1,440 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockDB "github.com/nilesh0729/Notes/internal/db/Mock"
+	Database "github.com/nilesh0729/Notes/internal/db/Result"
+	"github.com/nilesh0729/Notes/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+// quotaLimits allows 2 notes, 1 tag, notes of 20 bytes and 30 bytes in
+// total, and makes "admin" an administrator.
+func quotaLimits(config *util.Config) {
+	config.QuotaMaxNoteBytes = 20
+	config.QuotaMaxNotes = 2
+	config.QuotaMaxTags = 1
+	config.QuotaMaxTotalBytes = 30
+	config.AdminUsers = []string{"admin"}
+}
+
+func TestCreateNoteQuota(t *testing.T) {
+	username := util.RandomOwner()
+	noLimit := int64(0)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		override      Database.UserQuota
+		overrideErr   error
+		usage         Database.GetUserUsageRow
+		created       bool
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "OK",
+			body:        gin.H{"title": "title", "content": "content"},
+			overrideErr: Database.ErrRecordNotFound,
+			usage:       Database.GetUserUsageRow{Notes: 1, TotalBytes: 10},
+			created:     true,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:        "NoteTooLarge",
+			body:        gin.H{"title": "title", "content": "a note that is too long"},
+			overrideErr: Database.ErrRecordNotFound,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+				requireAPIError(t, recorder, CodePayloadTooLarge)
+			},
+		},
+		{
+			// A body no note within quota fits into is not read to the end.
+			name:        "BodyTooLarge",
+			body:        gin.H{"title": "title", "content": strings.Repeat("a", 6*20+noteBodyOverhead)},
+			overrideErr: Database.ErrRecordNotFound,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+				requireAPIError(t, recorder, CodePayloadTooLarge)
+				require.Contains(t, recorder.Body.String(), "request body is larger than")
+			},
+		},
+		{
+			name:        "TooManyNotes",
+			body:        gin.H{"title": "title", "content": "content"},
+			overrideErr: Database.ErrRecordNotFound,
+			usage:       Database.GetUserUsageRow{Notes: 2, TotalBytes: 10},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireAPIError(t, recorder, CodeQuotaExceeded)
+			},
+		},
+		{
+			name:        "StorageFull",
+			body:        gin.H{"title": "title", "content": "content"},
+			overrideErr: Database.ErrRecordNotFound,
+			usage:       Database.GetUserUsageRow{Notes: 1, TotalBytes: 20},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireAPIError(t, recorder, CodeQuotaExceeded)
+			},
+		},
+		{
+			name: "OverrideLiftsLimit",
+			body: gin.H{"title": "title", "content": "content"},
+			override: Database.UserQuota{
+				Username: username,
+				MaxNotes: &noLimit,
+			},
+			usage:   Database.GetUserUsageRow{Notes: 2, TotalBytes: 10},
+			created: true,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockDB.NewMockStore(ctrl)
+			allowSessions(store)
+
+			store.EXPECT().
+				GetUserQuota(gomock.Any(), gomock.Eq(username)).
+				Times(1).
+				Return(tc.override, tc.overrideErr)
+			store.EXPECT().
+				GetUserUsage(gomock.Any(), gomock.Eq(username)).
+				AnyTimes().
+				Return(tc.usage, nil)
+			store.EXPECT().
+				CreateNote(gomock.Any(), gomock.Any()).
+				Times(btoi(tc.created)).
+				Return(Database.Note{Owner: username}, nil)
+
+			server, _ := newTestServer(t, store, quotaLimits)
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/notes", bytes.NewReader(data))
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, AuthorizationTypeBearer, username, time.Minute)
+
+			recorder := httptest.NewRecorder()
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestUpdateNoteQuota(t *testing.T) {
+	username := util.RandomOwner()
+	note := Database.Note{NoteID: 1, Owner: username, Title: "t", Content: "a longer content"}
+
+	testCases := []struct {
+		name    string
+		content string
+		updated bool
+		status  int
+	}{
+		{
+			// Shrinking a note is allowed even when over the storage limit.
+			name:    "Shrink",
+			content: "short",
+			updated: true,
+			status:  http.StatusOK,
+		},
+		{
+			name:    "Grow",
+			content: "a longer content..",
+			status:  http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockDB.NewMockStore(ctrl)
+			allowSessions(store)
+
+			store.EXPECT().
+				GetNoteById(gomock.Any(), gomock.Eq(note.NoteID)).
+				Times(1).
+				Return(note, nil)
+			store.EXPECT().
+				GetUserQuota(gomock.Any(), gomock.Eq(username)).
+				Times(1).
+				Return(Database.UserQuota{}, Database.ErrRecordNotFound)
+			store.EXPECT().
+				GetUserUsage(gomock.Any(), gomock.Eq(username)).
+				AnyTimes().
+				Return(Database.GetUserUsageRow{Notes: 2, TotalBytes: 40}, nil)
+			store.EXPECT().
+				UpdateNote(gomock.Any(), gomock.Any()).
+				Times(btoi(tc.updated)).
+				Return(note, nil)
+			store.EXPECT().
+				GetTagsForNote(gomock.Any(), gomock.Any()).
+				AnyTimes().
+				Return([]Database.GetTagsForNoteRow{}, nil)
+
+			server, _ := newTestServer(t, store, quotaLimits)
+
+			data, err := json.Marshal(gin.H{"title": note.Title, "content": tc.content})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPut, "/notes/1", bytes.NewReader(data))
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, AuthorizationTypeBearer, username, time.Minute)
+
+			recorder := httptest.NewRecorder()
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, tc.status, recorder.Code)
+		})
+	}
+}
+
+func TestCreateTagQuota(t *testing.T) {
+	username := util.RandomOwner()
+
+	ctrl := gomock.NewController(t)
+	store := mockDB.NewMockStore(ctrl)
+	allowSessions(store)
+
+	store.EXPECT().
+		GetUserQuota(gomock.Any(), gomock.Eq(username)).
+		Times(1).
+		Return(Database.UserQuota{}, Database.ErrRecordNotFound)
+	store.EXPECT().
+		GetUserUsage(gomock.Any(), gomock.Eq(username)).
+		Times(1).
+		Return(Database.GetUserUsageRow{Tags: 1}, nil)
+	store.EXPECT().
+		CreateTags(gomock.Any(), gomock.Any()).
+		Times(0)
+
+	server, _ := newTestServer(t, store, quotaLimits)
+
+	data, err := json.Marshal(gin.H{"name": "tag"})
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(http.MethodPost, "/tags", bytes.NewReader(data))
+	require.NoError(t, err)
+	addAuthorization(t, request, server.tokenMaker, AuthorizationTypeBearer, username, time.Minute)
+
+	recorder := httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	requireAPIError(t, recorder, CodeQuotaExceeded)
+}
+
+func TestGetCurrentUsage(t *testing.T) {
+	username := util.RandomOwner()
+	maxTags := int64(10)
+
+	ctrl := gomock.NewController(t)
+	store := mockDB.NewMockStore(ctrl)
+	allowSessions(store)
+
+	store.EXPECT().
+		GetUserQuota(gomock.Any(), gomock.Eq(username)).
+		Times(1).
+		Return(Database.UserQuota{
+			Username: username,
+			MaxTags:  &maxTags,
+		}, nil)
+	store.EXPECT().
+		GetUserUsage(gomock.Any(), gomock.Eq(username)).
+		Times(1).
+		Return(Database.GetUserUsageRow{Notes: 1, Tags: 2, TotalBytes: 12}, nil)
+
+	server, _ := newTestServer(t, store, quotaLimits)
+
+	request, err := http.NewRequest(http.MethodGet, "/me/usage", nil)
+	require.NoError(t, err)
+	addAuthorization(t, request, server.tokenMaker, AuthorizationTypeBearer, username, time.Minute)
+
+	recorder := httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var res UsageResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+	require.Equal(t, UsageResponse{
+		Username: username,
+		Usage:    Usage{Notes: 1, Tags: 2, TotalBytes: 12},
+		Limits:   Quota{MaxNoteBytes: 20, MaxNotes: 2, MaxTags: 10, MaxTotalBytes: 30},
+	}, res)
+}
+
+func TestAdminQuota(t *testing.T) {
+	username := util.RandomOwner()
+	maxNotes, noLimit := int64(100), int64(0)
+
+	testCases := []struct {
+		name          string
+		admin         string
+		method        string
+		url           string
+		body          gin.H
+		buildStubs    func(store *mockDB.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "NotAdmin",
+			admin:  username,
+			method: http.MethodGet,
+			url:    "/admin/users/" + username + "/usage",
+			buildStubs: func(store *mockDB.MockStore) {
+				store.EXPECT().GetUserUsage(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireAPIError(t, recorder, CodeForbidden)
+			},
+		},
+		{
+			name:   "GetUsage",
+			admin:  "admin",
+			method: http.MethodGet,
+			url:    "/admin/users/" + username + "/usage",
+			buildStubs: func(store *mockDB.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(username)).
+					Times(1).
+					Return(Database.User{Username: username}, nil)
+				store.EXPECT().
+					GetUserQuota(gomock.Any(), gomock.Eq(username)).
+					Times(1).
+					Return(Database.UserQuota{}, Database.ErrRecordNotFound)
+				store.EXPECT().
+					GetUserUsage(gomock.Any(), gomock.Eq(username)).
+					Times(1).
+					Return(Database.GetUserUsageRow{Notes: 3}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res UsageResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, username, res.Username)
+				require.Equal(t, int64(3), res.Usage.Notes)
+			},
+		},
+		{
+			name:   "UnknownUser",
+			admin:  "admin",
+			method: http.MethodGet,
+			url:    "/admin/users/" + username + "/usage",
+			buildStubs: func(store *mockDB.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(username)).
+					Times(1).
+					Return(Database.User{}, Database.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireAPIError(t, recorder, CodeNotFound)
+			},
+		},
+		{
+			name:   "SetQuota",
+			admin:  "admin",
+			method: http.MethodPut,
+			url:    "/admin/users/" + username + "/quota",
+			body:   gin.H{"max_notes": 100, "max_total_bytes": 0},
+			buildStubs: func(store *mockDB.MockStore) {
+				arg := Database.UpsertUserQuotaParams{
+					Username:      username,
+					MaxNotes:      &maxNotes,
+					MaxTotalBytes: &noLimit,
+				}
+				quota := Database.UserQuota{
+					Username:      username,
+					MaxNotes:      arg.MaxNotes,
+					MaxTotalBytes: arg.MaxTotalBytes,
+				}
+
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(username)).
+					Times(1).
+					Return(Database.User{Username: username}, nil)
+				store.EXPECT().
+					UpsertUserQuota(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(quota, nil)
+				store.EXPECT().
+					GetUserQuota(gomock.Any(), gomock.Eq(username)).
+					Times(1).
+					Return(quota, nil)
+				store.EXPECT().
+					GetUserUsage(gomock.Any(), gomock.Eq(username)).
+					Times(1).
+					Return(Database.GetUserUsageRow{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res UsageResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, Quota{MaxNoteBytes: 20, MaxNotes: 100, MaxTags: 1, MaxTotalBytes: 0}, res.Limits)
+			},
+		},
+		{
+			name:   "NegativeLimit",
+			admin:  "admin",
+			method: http.MethodPut,
+			url:    "/admin/users/" + username + "/quota",
+			body:   gin.H{"max_notes": -1},
+			buildStubs: func(store *mockDB.MockStore) {
+				store.EXPECT().UpsertUserQuota(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireAPIError(t, recorder, CodeValidationFailed)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockDB.NewMockStore(ctrl)
+			allowSessions(store)
+			tc.buildStubs(store)
+
+			server, _ := newTestServer(t, store, quotaLimits)
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(tc.method, tc.url, bytes.NewReader(data))
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, AuthorizationTypeBearer, tc.admin, time.Minute)
+
+			recorder := httptest.NewRecorder()
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func btoi(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nilesh0729/Notes/internal/metrics"
+	"github.com/nilesh0729/Notes/internal/ratelimit"
+	"github.com/nilesh0729/Notes/internal/tokens"
+	"github.com/nilesh0729/Notes/internal/util"
+	"github.com/redis/go-redis/v9"
+)
+
+// Route groups with a rate limit of their own.
+const (
+	rateLimitPublic     = "public"
+	rateLimitAccount    = "account"
+	rateLimitNotesRead  = "notes_read"
+	rateLimitNotesWrite = "notes_write"
+)
+
+var errRateLimited = newAPIError(http.StatusTooManyRequests, CodeTooManyRequests, "too many requests, try again later")
+
+// newRateLimiter creates the limiter config.RateLimitBackend selects, or
+// returns nil if requests are not limited, and the limit of each route
+// group.
+func newRateLimiter(config util.Config) (ratelimit.Limiter, map[string]ratelimit.Limit, error) {
+	limits := make(map[string]ratelimit.Limit)
+	for group, s := range map[string]string{
+		rateLimitPublic:     config.RateLimitPublic,
+		rateLimitAccount:    config.RateLimitAccount,
+		rateLimitNotesRead:  config.RateLimitNotesRead,
+		rateLimitNotesWrite: config.RateLimitNotesWrite,
+	} {
+		limit, err := ratelimit.ParseLimit(s)
+		if err != nil {
+			return nil, nil, err
+		}
+		limits[group] = limit
+	}
+
+	switch config.RateLimitBackend {
+	case "", "none":
+		return nil, limits, nil
+	case "memory":
+		return ratelimit.NewMemory(), limits, nil
+	case "redis":
+		options, err := redis.ParseURL(config.RedisURL)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot parse REDIS_URL: %w", err)
+		}
+		return ratelimit.NewRedis(redis.NewClient(options)), limits, nil
+	}
+	return nil, nil, fmt.Errorf("unknown RATE_LIMIT_BACKEND %q", config.RateLimitBackend)
+}
+
+// rateLimit returns a middleware counting the requests of group against
+// the bucket key returns. It tells the client its budget in X-RateLimit-*
+// headers, and answers with 429 once it is used up. Requests are let
+// through if the limiter fails, so that it cannot take the API down.
+func (server *Server) rateLimit(group string, key func(ctx *gin.Context) string) gin.HandlerFunc {
+	limit := server.rateLimits[group]
+	if server.rateLimiter == nil || !limit.Enabled() {
+		return func(*gin.Context) {}
+	}
+
+	return func(ctx *gin.Context) {
+		res, err := server.rateLimiter.Allow(ctx, group+":"+key(ctx), limit)
+		if err != nil {
+			requestLogger(ctx).WarnContext(ctx, "cannot check rate limit",
+				slog.String("group", group),
+				slog.Any("error", err),
+			)
+			return
+		}
+
+		ctx.Header("X-RateLimit-Limit", strconv.Itoa(limit.Requests))
+		ctx.Header("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		ctx.Header("X-RateLimit-Reset", ceilSeconds(res.ResetAfter))
+
+		if !res.Allowed {
+			ctx.Header("Retry-After", ceilSeconds(res.RetryAfter))
+			metrics.RateLimitedRequests.WithLabelValues(group).Inc()
+			abortWithError(ctx, errRateLimited)
+		}
+	}
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+// byClientIP counts anonymous requests per client IP.
+func byClientIP(ctx *gin.Context) string {
+	return "ip:" + ctx.ClientIP()
+}
+
+// byUsername counts authenticated requests per user, whichever token or
+// API key they use.
+func byUsername(ctx *gin.Context) string {
+	return "user:" + ctx.MustGet(AuthorizationPayloadKey).(*tokens.Payload).Username
+}
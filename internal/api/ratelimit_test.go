@@ -0,0 +1,181 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockDB "github.com/nilesh0729/Notes/internal/db/Mock"
+	Database "github.com/nilesh0729/Notes/internal/db/Result"
+	"github.com/nilesh0729/Notes/internal/ratelimit"
+	"github.com/nilesh0729/Notes/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+// rateLimited allows 2 anonymous requests per client IP and 1 note or tag
+// read per user a minute.
+func rateLimited(config *util.Config) {
+	config.RateLimitBackend = "memory"
+	config.RateLimitPublic = "2/1m"
+	config.RateLimitNotesRead = "1/1m"
+}
+
+func TestRateLimitByClientIP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server, _ := newTestServer(t, mockDB.NewMockStore(ctrl), rateLimited)
+	login := func(remoteAddr string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, "/login", nil)
+		request.RemoteAddr = remoteAddr
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	recorder := login("10.0.0.1:1234")
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+	require.Equal(t, "2", recorder.Header().Get("X-RateLimit-Limit"))
+	require.Equal(t, "1", recorder.Header().Get("X-RateLimit-Remaining"))
+	require.Equal(t, "30", recorder.Header().Get("X-RateLimit-Reset"))
+
+	recorder = login("10.0.0.1:1234")
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+	require.Equal(t, "0", recorder.Header().Get("X-RateLimit-Remaining"))
+
+	recorder = login("10.0.0.1:5678")
+	require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	require.Equal(t, "30", recorder.Header().Get("Retry-After"))
+	requireAPIError(t, recorder, CodeTooManyRequests)
+
+	// Every client IP has a budget of its own.
+	recorder = login("10.0.0.2:1234")
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestRateLimitTrustedProxies(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	login := func(server *Server, remoteAddr, forwardedFor string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, "/login", nil)
+		request.RemoteAddr = remoteAddr
+		request.Header.Set("X-Forwarded-For", forwardedFor)
+		request.Header.Set("X-Real-IP", forwardedFor)
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	// A client cannot get a new budget by making up the forwarding headers.
+	server, _ := newTestServer(t, mockDB.NewMockStore(ctrl), rateLimited)
+	for i, forwardedFor := range []string{"192.0.2.1", "192.0.2.2"} {
+		recorder := login(server, "10.0.0.1:1234", forwardedFor)
+		require.Equal(t, http.StatusBadRequest, recorder.Code, i)
+	}
+	recorder := login(server, "10.0.0.1:1234", "192.0.2.3")
+	require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+
+	// Behind a trusted proxy, each client it forwards has a budget.
+	config := newTestConfig(t)
+	config.RateLimitBackend = "memory"
+	config.RateLimitPublic = "1/1m"
+	config.TrustedProxies = []string{"10.0.0.0/8"}
+	server, err := NewServer(config, mockDB.NewMockStore(ctrl))
+	require.NoError(t, err)
+
+	recorder = login(server, "10.0.0.1:1234", "192.0.2.1")
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+	recorder = login(server, "10.0.0.1:1234", "192.0.2.2")
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+	recorder = login(server, "10.0.0.1:1234", "192.0.2.1")
+	require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+}
+
+func TestRateLimitByUsername(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockDB.NewMockStore(ctrl)
+	allowSessions(store)
+	store.EXPECT().ListTags(gomock.Any(), gomock.Any()).Times(2).Return([]Database.Tag{}, nil)
+
+	server, _ := newTestServer(t, store, rateLimited)
+	listTags := func(username string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, "/tags?page_size=5", nil)
+		addAuthorization(t, request, server.tokenMaker, AuthorizationTypeBearer, username, time.Minute)
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	recorder := listTags("alice")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "0", recorder.Header().Get("X-RateLimit-Remaining"))
+
+	recorder = listTags("alice")
+	require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	require.Equal(t, "60", recorder.Header().Get("Retry-After"))
+
+	recorder = listTags("bob")
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	// Write routes have a budget of their own, unlimited here.
+	request := httptest.NewRequest(http.MethodPost, "/tags", nil)
+	addAuthorization(t, request, server.tokenMaker, AuthorizationTypeBearer, "alice", time.Minute)
+	recorder = httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+	require.Empty(t, recorder.Header().Get("X-RateLimit-Limit"))
+}
+
+type failingLimiter struct{}
+
+func (failingLimiter) Allow(context.Context, string, ratelimit.Limit) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("limiter down")
+}
+
+func TestRateLimitFailsOpen(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server, _ := newTestServer(t, mockDB.NewMockStore(ctrl), rateLimited)
+	server.rateLimiter = failingLimiter{}
+
+	for range 3 {
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/login", nil))
+		require.Equal(t, http.StatusBadRequest, recorder.Code)
+		require.Empty(t, recorder.Header().Get("X-RateLimit-Limit"))
+	}
+}
+
+func TestNewRateLimiter(t *testing.T) {
+	config := newTestConfig(t)
+	config.RateLimitBackend = "memory"
+	config.RateLimitAccount = "60/1m"
+
+	limiter, limits, err := newRateLimiter(config)
+	require.NoError(t, err)
+	require.NotNil(t, limiter)
+	require.Equal(t, ratelimit.Limit{Requests: 60, Period: time.Minute}, limits[rateLimitAccount])
+	require.False(t, limits[rateLimitPublic].Enabled())
+
+	config.RateLimitBackend = "none"
+	limiter, _, err = newRateLimiter(config)
+	require.NoError(t, err)
+	require.Nil(t, limiter)
+
+	config.RateLimitBackend = "carrier-pigeon"
+	_, _, err = newRateLimiter(config)
+	require.Error(t, err)
+
+	config.RateLimitBackend = "memory"
+	config.RateLimitNotesWrite = "lots"
+	_, _, err = newRateLimiter(config)
+	require.Error(t, err)
+}
@@ -0,0 +1,178 @@
+package api
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+	"github.com/nilesh0729/Notes/internal/util"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// corsExposedHeaders are the response headers browsers let clients read.
+var corsExposedHeaders = []string{
+	"Content-Length", "ETag", "Retry-After", RequestIDHeaderKey,
+	"X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset",
+}
+
+// newCORSConfig returns the CORS policy of config. Without configured
+// origins only the origin of config.AppBaseURL, where the frontend is
+// served, may call the API.
+func newCORSConfig(config util.Config) (cors.Config, error) {
+	origins := config.CORSAllowedOrigins
+	if len(origins) == 0 {
+		appURL, err := url.Parse(config.AppBaseURL)
+		if err != nil || appURL.Scheme == "" || appURL.Host == "" {
+			return cors.Config{}, fmt.Errorf("no origins configured and APP_BASE_URL %q is not an absolute URL", config.AppBaseURL)
+		}
+		origins = []string{appURL.Scheme + "://" + appURL.Host}
+	}
+
+	corsConfig := cors.Config{
+		AllowMethods:     config.CORSAllowedMethods,
+		AllowHeaders:     config.CORSAllowedHeaders,
+		ExposeHeaders:    corsExposedHeaders,
+		AllowCredentials: config.CORSAllowCredentials,
+		MaxAge:           config.CORSMaxAge,
+	}
+
+	if slices.Contains(origins, "*") {
+		if config.Environment == util.EnvProduction {
+			return cors.Config{}, errors.New(`origin "*" is not allowed in production`)
+		}
+		// Browsers refuse credentials for any origin, so it is a mistake.
+		if config.CORSAllowCredentials {
+			return cors.Config{}, errors.New(`origin "*" cannot be combined with credentials`)
+		}
+		corsConfig.AllowAllOrigins = true
+	} else {
+		corsConfig.AllowOrigins = origins
+		corsConfig.AllowWildcard = true
+	}
+
+	err := corsConfig.Validate()
+	if err != nil {
+		return cors.Config{}, err
+	}
+	return corsConfig, nil
+}
+
+// securityHeadersMiddleware sets the headers that keep browsers from
+// sniffing, framing or running content of the API. HSTS is only sent on
+// requests made over HTTPS, either to the server or to a trusted proxy in
+// front of it, as browsers ignore it on plain HTTP.
+func securityHeadersMiddleware(config util.Config, proxies trustedProxies) gin.HandlerFunc {
+	hsts := ""
+	if config.HSTSMaxAge > 0 {
+		hsts = "max-age=" + strconv.Itoa(int(config.HSTSMaxAge.Seconds())) + "; includeSubDomains"
+	}
+
+	return func(ctx *gin.Context) {
+		header := ctx.Writer.Header()
+		header.Set("X-Content-Type-Options", "nosniff")
+		header.Set("X-Frame-Options", "DENY")
+		header.Set("Referrer-Policy", "no-referrer")
+		if config.ContentSecurityPolicy != "" {
+			header.Set("Content-Security-Policy", config.ContentSecurityPolicy)
+		}
+		if hsts != "" && isHTTPS(ctx, proxies) {
+			header.Set("Strict-Transport-Security", hsts)
+		}
+
+		ctx.Next()
+	}
+}
+
+// isHTTPS reports whether the request was made over HTTPS. X-Forwarded-Proto
+// is only believed when it comes from one of proxies, as any client can send it.
+func isHTTPS(ctx *gin.Context, proxies trustedProxies) bool {
+	if ctx.Request.TLS != nil {
+		return true
+	}
+	return proxies.contains(ctx.RemoteIP()) && ctx.GetHeader("X-Forwarded-Proto") == "https"
+}
+
+// trustedProxies are the networks of the TrustedProxies, whose forwarding
+// headers gin also takes the client IP from.
+type trustedProxies []netip.Prefix
+
+func newTrustedProxies(proxies []string) (trustedProxies, error) {
+	prefixes := make(trustedProxies, 0, len(proxies))
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			addr, err := netip.ParseAddr(proxy)
+			if err != nil {
+				return nil, err
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// contains reports whether ip is in one of the networks.
+func (proxies trustedProxies) contains(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+
+	for _, prefix := range proxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// newTLSConfig returns the TLS config the server is served with, or nil if
+// it speaks plain HTTP.
+func newTLSConfig(config util.Config) (*tls.Config, error) {
+	hasFiles := config.TLSCertFile != "" || config.TLSKeyFile != ""
+	hasAutocert := len(config.TLSAutocertDomains) > 0
+
+	switch {
+	case hasFiles && hasAutocert:
+		return nil, errors.New("TLS_CERT_FILE and TLS_AUTOCERT_DOMAINS cannot both be set")
+
+	case hasFiles:
+		cert, err := tls.LoadX509KeyPair(config.TLSCertFile, config.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load TLS certificate: %w", err)
+		}
+		return &tls.Config{
+			MinVersion:   tls.VersionTLS12,
+			Certificates: []tls.Certificate{cert},
+		}, nil
+
+	case hasAutocert:
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(config.TLSAutocertDomains...),
+			Cache:      autocert.DirCache(config.TLSAutocertCacheDir),
+		}
+		if config.TLSAutocertDirectoryURL != "" {
+			manager.Client = &acme.Client{DirectoryURL: config.TLSAutocertDirectoryURL}
+		}
+		tlsConfig := manager.TLSConfig()
+		tlsConfig.MinVersion = tls.VersionTLS12
+		return tlsConfig, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,265 @@
+package api
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockDB "github.com/nilesh0729/Notes/internal/db/Mock"
+	"github.com/nilesh0729/Notes/internal/util"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/acme"
+)
+
+func TestNewCORSConfig(t *testing.T) {
+	testCases := []struct {
+		name          string
+		configure     func(config *util.Config)
+		checkOrigins  func(t *testing.T, allowed func(origin string) bool)
+		expectedError string
+	}{
+		{
+			name: "AppBaseURL",
+			configure: func(config *util.Config) {
+				config.AppBaseURL = "https://notes.example.com/app"
+			},
+			checkOrigins: func(t *testing.T, allowed func(origin string) bool) {
+				require.True(t, allowed("https://notes.example.com"))
+				require.False(t, allowed("https://evil.example.com"))
+				require.False(t, allowed("http://notes.example.com"))
+			},
+		},
+		{
+			name: "Configured",
+			configure: func(config *util.Config) {
+				config.CORSAllowedOrigins = []string{"https://a.example.com", "https://*.example.org"}
+			},
+			checkOrigins: func(t *testing.T, allowed func(origin string) bool) {
+				require.True(t, allowed("https://a.example.com"))
+				require.True(t, allowed("https://b.example.org"))
+				require.False(t, allowed("https://b.example.com"))
+				require.False(t, allowed("http://localhost"))
+			},
+		},
+		{
+			name: "AnyOriginInDevelopment",
+			configure: func(config *util.Config) {
+				config.CORSAllowedOrigins = []string{"*"}
+			},
+			checkOrigins: func(t *testing.T, allowed func(origin string) bool) {
+				require.True(t, allowed("https://anything.example.com"))
+			},
+		},
+		{
+			name: "AnyOriginInProduction",
+			configure: func(config *util.Config) {
+				config.Environment = util.EnvProduction
+				config.CORSAllowedOrigins = []string{"*"}
+			},
+			expectedError: "not allowed in production",
+		},
+		{
+			name: "AnyOriginWithCredentials",
+			configure: func(config *util.Config) {
+				config.CORSAllowedOrigins = []string{"*"}
+				config.CORSAllowCredentials = true
+			},
+			expectedError: "cannot be combined with credentials",
+		},
+		{
+			name: "RelativeAppBaseURL",
+			configure: func(config *util.Config) {
+				config.AppBaseURL = "/app"
+			},
+			expectedError: "not an absolute URL",
+		},
+		{
+			name: "BadOrigin",
+			configure: func(config *util.Config) {
+				config.CORSAllowedOrigins = []string{"example.com"}
+			},
+			expectedError: "bad origin",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := newTestConfig(t)
+			config.CORSAllowedMethods = []string{http.MethodGet}
+			tc.configure(&config)
+
+			server, err := NewServer(config, mockDB.NewMockStore(gomock.NewController(t)))
+			if tc.expectedError != "" {
+				require.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+
+			tc.checkOrigins(t, func(origin string) bool {
+				request := httptest.NewRequest(http.MethodOptions, "/notes", nil)
+				request.Header.Set("Origin", origin)
+				request.Header.Set("Access-Control-Request-Method", http.MethodGet)
+
+				recorder := httptest.NewRecorder()
+				server.router.ServeHTTP(recorder, request)
+				return recorder.Header().Get("Access-Control-Allow-Origin") != ""
+			})
+		})
+	}
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	config := newTestConfig(t)
+	config.ContentSecurityPolicy = "default-src 'none'"
+	config.HSTSMaxAge = 24 * time.Hour
+
+	server, err := NewServer(config, mockDB.NewMockStore(gomock.NewController(t)))
+	require.NoError(t, err)
+
+	request := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	recorder := httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, request)
+
+	require.Equal(t, "nosniff", recorder.Header().Get("X-Content-Type-Options"))
+	require.Equal(t, "DENY", recorder.Header().Get("X-Frame-Options"))
+	require.Equal(t, "default-src 'none'", recorder.Header().Get("Content-Security-Policy"))
+	// HSTS is meaningless over plain HTTP.
+	require.Empty(t, recorder.Header().Get("Strict-Transport-Security"))
+
+	request = httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	request.TLS = &tls.ConnectionState{}
+	recorder = httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, request)
+
+	require.Equal(t, "max-age=86400; includeSubDomains", recorder.Header().Get("Strict-Transport-Security"))
+}
+
+func TestSecurityHeadersForwardedProto(t *testing.T) {
+	config := newTestConfig(t)
+	config.HSTSMaxAge = 24 * time.Hour
+	config.TrustedProxies = []string{"10.0.0.0/8"}
+
+	server, err := NewServer(config, mockDB.NewMockStore(gomock.NewController(t)))
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name       string
+		remoteAddr string
+		hsts       string
+	}{
+		{
+			name:       "TrustedProxy",
+			remoteAddr: "10.0.0.1:1234",
+			hsts:       "max-age=86400; includeSubDomains",
+		},
+		{
+			// Anyone can claim the request was made over HTTPS.
+			name:       "UntrustedPeer",
+			remoteAddr: "192.0.2.1:1234",
+			hsts:       "",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+			request.RemoteAddr = tc.remoteAddr
+			request.Header.Set("X-Forwarded-Proto", "https")
+			recorder := httptest.NewRecorder()
+			server.router.ServeHTTP(recorder, request)
+
+			require.Equal(t, tc.hsts, recorder.Header().Get("Strict-Transport-Security"))
+		})
+	}
+}
+
+// writeCertificate writes a self-signed certificate for localhost and its
+// key, and returns their paths.
+func writeCertificate(t *testing.T) (certFile, keyFile string) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv6loopback, net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	config := newTestConfig(t)
+	tlsConfig, err := newTLSConfig(config)
+	require.NoError(t, err)
+	require.Nil(t, tlsConfig)
+
+	config.TLSCertFile, config.TLSKeyFile = writeCertificate(t)
+	tlsConfig, err = newTLSConfig(config)
+	require.NoError(t, err)
+	require.Len(t, tlsConfig.Certificates, 1)
+
+	config.TLSAutocertDomains = []string{"notes.example.com"}
+	_, err = newTLSConfig(config)
+	require.ErrorContains(t, err, "cannot both be set")
+
+	config.TLSCertFile = ""
+	config.TLSKeyFile = ""
+	config.TLSAutocertCacheDir = t.TempDir()
+	config.TLSAutocertDirectoryURL = "https://acme.invalid/directory"
+	tlsConfig, err = newTLSConfig(config)
+	require.NoError(t, err)
+	require.NotNil(t, tlsConfig.GetCertificate)
+	require.Contains(t, tlsConfig.NextProtos, acme.ALPNProto)
+
+	config.TLSAutocertDomains = nil
+	config.TLSKeyFile = filepath.Join(t.TempDir(), "missing.pem")
+	_, err = newTLSConfig(config)
+	require.ErrorContains(t, err, "cannot load TLS certificate")
+}
+
+func TestServeTLS(t *testing.T) {
+	config := newTestConfig(t)
+	config.TLSCertFile, config.TLSKeyFile = writeCertificate(t)
+
+	server, err := NewServer(config, mockDB.NewMockStore(gomock.NewController(t)))
+	require.NoError(t, err)
+
+	// Start serves with the same TLS config.
+	httpServer := httptest.NewUnstartedServer(server.router)
+	httpServer.TLS = server.tlsConfig
+	httpServer.StartTLS()
+	defer httpServer.Close()
+
+	response, err := httpServer.Client().Get(httpServer.URL + "/healthz")
+	require.NoError(t, err)
+	defer response.Body.Close()
+	require.NotNil(t, response.TLS)
+	require.Equal(t, []string{"localhost"}, response.TLS.PeerCertificates[0].DNSNames)
+}
@@ -1,12 +1,20 @@
 package api
 
 import (
+	"context"
+	"crypto/tls"
 	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	Database "github.com/nilesh0729/Notes/internal/db/Result"
+	"github.com/nilesh0729/Notes/internal/mail"
+	"github.com/nilesh0729/Notes/internal/metrics"
+	"github.com/nilesh0729/Notes/internal/ratelimit"
 	"github.com/nilesh0729/Notes/internal/tokens"
 	"github.com/nilesh0729/Notes/internal/util"
 )
@@ -15,61 +23,315 @@ type Server struct {
 	config     util.Config
 	store      Database.Store
 	tokenMaker tokens.Maker
+	mailer     mail.Mailer
 	router     *gin.Engine
+	oidc       *oidcProvider
+	logger     *slog.Logger
+
+	passwordHasher    util.PasswordHasher
+	passwordPolicy    *util.PasswordPolicy
+	dummyPasswordHash func() string
+	loginThrottle     *loginThrottle
+	rateLimiter       ratelimit.Limiter
+	rateLimits        map[string]ratelimit.Limit
+	tlsConfig         *tls.Config
+	trustedProxies    trustedProxies
+
+	// background counts the tasks started by goBackground.
+	background sync.WaitGroup
 }
 
+// NewServer creates a server for config. It logs through slog.Default,
+// which main sets up from config before creating the server.
 func NewServer(config util.Config, store Database.Store) (*Server, error) {
-	tokenMaker, err := tokens.NewPasetoMaker(config.Secret)
+	tokenMaker, err := newTokenMaker(config)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create token maker: %w", err)
 	}
 
+	passwordHasher, err := util.NewPasswordHasher(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create password hasher: %w", err)
+	}
+
+	passwordPolicy, err := util.NewPasswordPolicy(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create password policy: %w", err)
+	}
+
+	mailer, err := mail.NewMailer(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create mailer: %w", err)
+	}
+
+	rateLimiter, rateLimits, err := newRateLimiter(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create rate limiter: %w", err)
+	}
+
+	corsConfig, err := newCORSConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot configure CORS: %w", err)
+	}
+
+	tlsConfig, err := newTLSConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot configure TLS: %w", err)
+	}
+
+	trustedProxies, err := newTrustedProxies(config.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("cannot configure trusted proxies: %w", err)
+	}
+
 	server := &Server{
 		config:     config,
 		store:      store,
 		tokenMaker: tokenMaker,
+		mailer:     mailer,
+		logger:     slog.Default(),
+
+		passwordHasher: passwordHasher,
+		passwordPolicy: passwordPolicy,
+		loginThrottle:  newLoginThrottle(config),
+		rateLimiter:    rateLimiter,
+		rateLimits:     rateLimits,
+		tlsConfig:      tlsConfig,
+		trustedProxies: trustedProxies,
+	}
+
+	// dummyPasswordHash is checked against when the username is unknown. It
+	// is made by the configured hasher, so it takes as long as a real one.
+	server.dummyPasswordHash = sync.OnceValue(func() string {
+		hash, err := passwordHasher.Hash(util.RandomString(16))
+		if err != nil {
+			panic(err)
+		}
+		return hash
+	})
+
+	if config.OIDCIssuerURL != "" {
+		server.oidc, err = newOIDCProvider(context.Background(), config)
+		if err != nil {
+			return nil, fmt.Errorf("cannot discover OIDC provider: %w", err)
+		}
+	}
+
+	registerFieldNames()
+	router := gin.New()
+	// Store calls are made with the gin context; this lets them see the
+	// trace span and cancellation of the request.
+	router.ContextWithFallback = true
+	// ClientIP trusts the forwarding headers of any peer unless told
+	// otherwise, which would let clients pick the IP they are limited by.
+	err = router.SetTrustedProxies(config.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("cannot configure trusted proxies: %w", err)
+	}
+	router.Use(
+		requestIDMiddleware(),
+		tracingMiddleware(config),
+		server.accessLogMiddleware(),
+		metricsMiddleware(),
+		recoveryMiddleware(),
+		securityHeadersMiddleware(config, trustedProxies),
+	)
+
+	router.GET("/healthz", server.Healthz)
+	router.GET("/readyz", server.Readyz)
+
+	router.Use(cors.New(corsConfig))
+
+	if maker, ok := server.tokenMaker.(tokens.PublicKeyMaker); ok {
+		router.GET("/.well-known/jwks.json", jwksHandler(maker.KeyRing()))
+	}
+
+	publicRoutes := router.Group("/", server.rateLimit(rateLimitPublic, byClientIP))
+
+	publicRoutes.POST("/user", server.CreateUser)
+	publicRoutes.POST("/login", server.LoginUser)
+	publicRoutes.POST("/login/2fa", server.LoginTwoFactor)
+	publicRoutes.POST("/verify-email", server.VerifyEmail)
+	publicRoutes.POST("/password/forgot", server.ForgotPassword)
+	publicRoutes.POST("/password/reset", server.ResetPassword)
+
+	if server.oidc != nil {
+		publicRoutes.GET("/oidc/login", server.OIDCLogin)
+		publicRoutes.GET("/oidc/callback", server.OIDCCallback)
+		publicRoutes.POST("/oidc/exchange", server.OIDCExchange)
+	}
+
+	authRoutes := router.Group("/", authMiddleware(server.tokenMaker, server.store))
+
+	accountRoutes := authRoutes.Group("/", requireScopes(ScopeAccount), server.rateLimit(rateLimitAccount, byUsername))
+
+	accountRoutes.GET("/me", server.GetCurrentUser)
+	accountRoutes.PATCH("/me", server.UpdateCurrentUser)
+	accountRoutes.POST("/me/password", server.ChangePassword)
+	accountRoutes.POST("/me/verify-email", server.ResendVerificationEmail)
+	accountRoutes.POST("/me/2fa/setup", server.SetupTwoFactor)
+	accountRoutes.POST("/me/2fa/enable", server.EnableTwoFactor)
+	accountRoutes.POST("/me/api-keys", server.CreateAPIKey)
+	accountRoutes.GET("/me/api-keys", server.ListAPIKeys)
+	accountRoutes.DELETE("/me/api-keys/:id", server.DeleteAPIKey)
+	accountRoutes.GET("/me/usage", server.GetCurrentUsage)
+
+	adminRoutes := accountRoutes.Group("/admin", server.requireAdmin())
+
+	adminRoutes.GET("/users/:username/usage", server.GetUserUsage)
+	adminRoutes.PUT("/users/:username/quota", server.SetUserQuota)
+
+	// Unverified users can still manage their account, but notes and tags
+	// are only available once the email address is confirmed.
+	noteRoutes := authRoutes.Group("/")
+	if config.RequireVerifiedEmail {
+		noteRoutes.Use(requireVerifiedEmail(server.store))
 	}
-	router := gin.Default()
-
-	// Configure CORS to allow requests from frontend
-	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"*"}, // Allow all origins, can be restricted to specific domain
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
-		MaxAge:           12 * time.Hour,
-	}))
-
-	router.POST("/user", server.CreateUser)
-	router.POST("/login", server.LoginUser)
-
-	authRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker))
-
-	authRoutes.POST("/notes", server.CreateNote)
-	authRoutes.GET("/notes/:id", server.GetNoteById)
-	authRoutes.GET("/notes", server.ListNotes)
-	authRoutes.PUT("/notes/:id", server.UpdateNote)
-	authRoutes.DELETE("/notes/:id", server.DeleteNote)
-
-	authRoutes.POST("/tags", server.CreateTags)
-	authRoutes.GET("/tags/:id", server.GetTag)
-	authRoutes.GET("/tags", server.ListTags)
-	authRoutes.DELETE("/tags/:id", server.DeleteTag)
+
+	readRoutes := noteRoutes.Group("/", requireScopes(ScopeNotesRead), server.rateLimit(rateLimitNotesRead, byUsername))
+	writeRoutes := noteRoutes.Group("/", requireScopes(ScopeNotesWrite), server.rateLimit(rateLimitNotesWrite, byUsername))
+
+	writeRoutes.POST("/notes", server.CreateNote)
+	readRoutes.GET("/notes/:id", server.GetNoteById)
+	readRoutes.GET("/notes", server.ListNotes)
+	writeRoutes.PUT("/notes/:id", server.UpdateNote)
+	writeRoutes.DELETE("/notes/:id", server.DeleteNote)
+
+	writeRoutes.POST("/tags", server.CreateTags)
+	readRoutes.GET("/tags/:id", server.GetTag)
+	readRoutes.GET("/tags", server.ListTags)
+	writeRoutes.DELETE("/tags/:id", server.DeleteTag)
 	
-	authRoutes.GET("/tags/:id/notes", server.ListNotesForTag)
+	readRoutes.GET("/tags/:id/notes", server.ListNotesForTag)
 
-	authRoutes.POST("/note_tags", server.AddTagToNote)
+	writeRoutes.POST("/note_tags", server.AddTagToNote)
 
 	server.router = router
 
 	return server, nil
 }
 
-func (server *Server) Start(address string) error{
-	return server.router.Run(address)
+// newTokenMaker creates the access token maker selected by config.TokenType.
+func newTokenMaker(config util.Config) (tokens.Maker, error) {
+	claims := tokens.ClaimsConfig{
+		Issuer:   config.TokenIssuer,
+		Audience: config.TokenAudience,
+		Leeway:   config.TokenLeeway,
+	}
+
+	switch config.TokenType {
+	case "", tokens.TypePaseto:
+		return tokens.NewPasetoMaker(config.Secret, claims)
+	case tokens.TypeJWT:
+		return tokens.NewJWTMaker(config.Secret, claims)
+	case tokens.TypePasetoPublic, tokens.TypeJWTPublic:
+		ring, err := tokens.LoadKeyRing(config.TokenPrivateKeyFile, config.TokenPreviousKeyFiles...)
+		if err != nil {
+			return nil, err
+		}
+		if config.TokenType == tokens.TypePasetoPublic {
+			return tokens.NewPasetoPublicMaker(ring, claims)
+		}
+		return tokens.NewJWTPublicMaker(ring, claims)
+	default:
+		return nil, fmt.Errorf("unsupported token type %q", config.TokenType)
+	}
+}
+
+// jwksHandler publishes the public keys access tokens can be verified with.
+func jwksHandler(ring *tokens.KeyRing) gin.HandlerFunc {
+	jwks := ring.JWKS()
+	return func(ctx *gin.Context) {
+		ctx.Header("Cache-Control", "public, max-age=300")
+		ctx.JSON(http.StatusOK, jwks)
+	}
+}
+
+// goBackground runs task without holding up the response. Start waits for
+// the tasks still running when the server shuts down.
+func (server *Server) goBackground(task func()) {
+	server.background.Add(1)
+	go func() {
+		defer server.background.Done()
+		task()
+	}()
 }
 
-func errResponse (err error)gin.H{
-	return gin.H{"error" : err.Error() }
-}
\ No newline at end of file
+// metricsHandler serves the metrics on config.MetricsAddress.
+func metricsHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", metrics.Handler())
+	return mux
+}
+
+// Start serves requests on address until ctx is done, then stops accepting
+// connections and waits up to config.ShutdownTimeout for in-flight requests
+// and background tasks to finish. Requests are served over HTTPS if TLS is
+// configured. Metrics are served on config.MetricsAddress, if set.
+func (server *Server) Start(ctx context.Context, address string) error {
+	httpServer := &http.Server{
+		Addr:              address,
+		Handler:           server.router,
+		ReadHeaderTimeout: 10 * time.Second,
+		TLSConfig:         server.tlsConfig,
+	}
+
+	serveErr := make(chan error, 2)
+	go func() {
+		if server.tlsConfig != nil {
+			// The certificates come from TLSConfig.
+			serveErr <- httpServer.ListenAndServeTLS("", "")
+			return
+		}
+		serveErr <- httpServer.ListenAndServe()
+	}()
+
+	var metricsServer *http.Server
+	if server.config.MetricsAddress != "" {
+		metricsServer = &http.Server{
+			Addr:              server.config.MetricsAddress,
+			Handler:           metricsHandler(),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		go func() {
+			serveErr <- fmt.Errorf("cannot serve metrics: %w", metricsServer.ListenAndServe())
+		}()
+	}
+
+	select {
+	case err := <-serveErr:
+		httpServer.Close()
+		if metricsServer != nil {
+			metricsServer.Close()
+		}
+		return err
+	case <-ctx.Done():
+	}
+
+	server.logger.Info("shutting down", slog.Duration("timeout", server.config.ShutdownTimeout))
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), server.config.ShutdownTimeout)
+	defer cancel()
+
+	if metricsServer != nil {
+		// Scrapes are short and nothing is lost if one is cut off.
+		metricsServer.Close()
+	}
+
+	err := httpServer.Shutdown(shutdownCtx)
+	if err != nil {
+		return fmt.Errorf("cannot drain requests: %w", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		server.background.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-shutdownCtx.Done():
+		return fmt.Errorf("cannot finish background tasks: %w", shutdownCtx.Err())
+	}
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/golang/mock/gomock"
+	mockDB "github.com/nilesh0729/Notes/internal/db/Mock"
+	"github.com/nilesh0729/Notes/internal/tokens"
+	"github.com/stretchr/testify/require"
+)
+
+func writeEd25519KeyFile(t *testing.T) string {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	file := filepath.Join(t.TempDir(), "token.pem")
+	err = os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600)
+	require.NoError(t, err)
+	return file
+}
+
+func TestNewTokenMaker(t *testing.T) {
+	config := newTestConfig(t)
+
+	for _, tokenType := range []string{"", tokens.TypePaseto, tokens.TypeJWT} {
+		config.TokenType = tokenType
+		maker, err := newTokenMaker(config)
+		require.NoError(t, err)
+		_, ok := maker.(tokens.PublicKeyMaker)
+		require.False(t, ok)
+	}
+
+	config.TokenType = tokens.TypePasetoPublic
+	config.TokenPrivateKeyFile = writeEd25519KeyFile(t)
+	maker, err := newTokenMaker(config)
+	require.NoError(t, err)
+	_, ok := maker.(tokens.PublicKeyMaker)
+	require.True(t, ok)
+
+	// The Ed25519 key cannot sign JWTs.
+	config.TokenType = tokens.TypeJWTPublic
+	_, err = newTokenMaker(config)
+	require.Error(t, err)
+
+	config.TokenType = "unknown"
+	_, err = newTokenMaker(config)
+	require.Error(t, err)
+}
+
+func TestJWKSAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockDB.NewMockStore(ctrl)
+	allowSessions(store)
+
+	config := newTestConfig(t)
+	config.TokenType = tokens.TypePasetoPublic
+	config.TokenPrivateKeyFile = writeEd25519KeyFile(t)
+
+	server, err := NewServer(config, store)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	require.NoError(t, err)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var jwks jose.JSONWebKeySet
+	err = json.Unmarshal(recorder.Body.Bytes(), &jwks)
+	require.NoError(t, err)
+	require.Len(t, jwks.Keys, 1)
+
+	ring := server.tokenMaker.(tokens.PublicKeyMaker).KeyRing()
+	require.Equal(t, ring.Current().ID, jwks.Keys[0].KeyID)
+	require.True(t, jwks.Keys[0].IsPublic())
+
+	// Tokens from the public key maker work with authMiddleware like any other.
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest(http.MethodGet, "/auth", nil)
+	require.NoError(t, err)
+	server.router.GET("/auth", authMiddleware(server.tokenMaker, server.store), func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, gin.H{})
+	})
+	addAuthorization(t, request, server.tokenMaker, AuthorizationTypeBearer, "user", time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestJWKSDisabledForSymmetricTokens(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server, _ := newTestServer(t, mockDB.NewMockStore(ctrl))
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	require.NoError(t, err)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+}
@@ -1,8 +1,6 @@
 package api
 
 import (
-	"database/sql"
-	"errors"
 	"fmt"
 	"net/http"
 
@@ -10,6 +8,12 @@ import (
 	Database "github.com/nilesh0729/Notes/internal/db/Result"
 	"github.com/nilesh0729/Notes/internal/tokens"
 )
+
+var (
+	errTagNotFound = newAPIError(http.StatusNotFound, CodeNotFound, "tag not found")
+	errTagNotOwned = newAPIError(http.StatusUnauthorized, CodeUnauthorized, "tag doesn't belong to the authenticated user")
+)
+
 type TagResponseFormat struct{
 	TagId int32 `json:"tag_id"`
 	Name string `json:"name"`
@@ -41,20 +45,26 @@ func (server *Server) CreateTags(ctx *gin.Context) {
 	var req CreateTagsRequest
 	err := ctx.ShouldBindJSON(&req)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		abortWithError(ctx, bindingError(err))
 		return
 	}
 	
 	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*tokens.Payload)
 
+	err = server.checkTagQuota(ctx, authPayload.Username)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
 	arg := Database.CreateTagsParams{
-		Owner: sql.NullString{String: authPayload.Username, Valid: true},
+		Owner: authPayload.Username,
 		Name: req.Name,
 	}
 
 	tag, err := server.store.CreateTags(ctx,arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		abortWithError(ctx, err)
 		return
 	}
 
@@ -70,20 +80,19 @@ func (server *Server) GetTag(ctx *gin.Context) {
 
 	err := ctx.ShouldBindUri(&req)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		abortWithError(ctx, bindingError(err))
 		return
 	}
 
 	tag, err := server.store.GetTag(ctx, req.TagId)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		abortWithError(ctx, err)
 		return
 	}
 
 	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*tokens.Payload)
-	if tag.Owner.String != authPayload.Username {
-		err := errors.New("tag doesn't belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errResponse(err))
+	if tag.Owner != authPayload.Username {
+		abortWithError(ctx, errTagNotOwned)
 		return
 	}
 
@@ -100,22 +109,22 @@ func (server *Server) ListTags(ctx *gin.Context) {
 
 	err := ctx.ShouldBindQuery(&req)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		abortWithError(ctx, bindingError(err))
 		return
 	}
 
 	arg := Database.ListTagsParams{
 		TagID: req.TagId,
 		Limit: req.PageSize,
-		Owner: sql.NullString{String: ctx.MustGet(AuthorizationPayloadKey).(*tokens.Payload).Username, Valid: true },
+		Owner: ctx.MustGet(AuthorizationPayloadKey).(*tokens.Payload).Username,
 	}
 	tags, err := server.store.ListTags(ctx, arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		abortWithError(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, formatManytags(tags))
+	respondWithETag(ctx, formatManytags(tags))
 }
 
 func (server *Server) DeleteTag(ctx *gin.Context) {
@@ -126,30 +135,22 @@ func (server *Server) DeleteTag(ctx *gin.Context) {
 	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*tokens.Payload)
 	existingTag, err := server.store.GetTag(ctx, tagId)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errResponse(err))
+		if err == Database.ErrRecordNotFound {
+			abortWithError(ctx, errTagNotFound)
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		abortWithError(ctx, err)
 		return
 	}
 
-	if existingTag.Owner.String != authPayload.Username {
-		err := errors.New("tag doesn't belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errResponse(err))
-		return
-	}
-
-	// Manually cascade delete
-	err = server.store.DeleteNoteTagsByTagId(ctx, tagId)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+	if existingTag.Owner != authPayload.Username {
+		abortWithError(ctx, errTagNotOwned)
 		return
 	}
 
 	err = server.store.DeleteTag(ctx, tagId)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		abortWithError(ctx, err)
 		return
 	}
 
@@ -39,11 +39,11 @@ func TestCreateTag(t *testing.T) {
 		{
 			name: "Ok",
 			body: gin.H{
-				"owner": tag.Owner.String,
+				"owner": tag.Owner,
 				"name":  tag.Name,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker tokens.Maker) {
-				addAuthorization(t, request, tokenMaker, AuthorizationTypeBearer, tag.Owner.String, time.Minute)
+				addAuthorization(t, request, tokenMaker, AuthorizationTypeBearer, tag.Owner, time.Minute)
 			},
 			buildStubs: func(store *mockDB.MockStore) {
 				store.EXPECT().
@@ -62,7 +62,7 @@ func TestCreateTag(t *testing.T) {
 				"name": tag.TagID,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker tokens.Maker) {
-				addAuthorization(t, request, tokenMaker, AuthorizationTypeBearer, tag.Owner.String, time.Minute)
+				addAuthorization(t, request, tokenMaker, AuthorizationTypeBearer, tag.Owner, time.Minute)
 			},
 			buildStubs: func(store *mockDB.MockStore) {
 				store.EXPECT().
@@ -76,11 +76,11 @@ func TestCreateTag(t *testing.T) {
 		{
 			name: "InternalServerError",
 			body: gin.H{
-				"owner": tag.Owner.String,
+				"owner": tag.Owner,
 				"name":  tag.Name,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker tokens.Maker) {
-				addAuthorization(t, request, tokenMaker, AuthorizationTypeBearer, tag.Owner.String, time.Minute)
+				addAuthorization(t, request, tokenMaker, AuthorizationTypeBearer, tag.Owner, time.Minute)
 			},
 			buildStubs: func(store *mockDB.MockStore) {
 				store.EXPECT().
@@ -102,6 +102,8 @@ func TestCreateTag(t *testing.T) {
 
 			store := mockDB.NewMockStore(ctrl)
 			tc.buildStubs(store)
+			allowSessions(store)
+			allowQuotas(store)
 
 			server, _ := newTestServer(t, store)
 			recorder := httptest.NewRecorder()
@@ -138,7 +140,7 @@ func TestGetTag(t *testing.T) {
 			name:  "OK",
 			tagId: tag.TagID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker tokens.Maker) {
-				addAuthorization(t, request, tokenMaker, AuthorizationTypeBearer, tag.Owner.String, time.Minute)
+				addAuthorization(t, request, tokenMaker, AuthorizationTypeBearer, tag.Owner, time.Minute)
 			},
 			buildstubs: func(store *mockDB.MockStore) {
 				store.EXPECT().
@@ -155,7 +157,7 @@ func TestGetTag(t *testing.T) {
 			name:  "BadRequest",
 			tagId: 0,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker tokens.Maker) {
-				addAuthorization(t, request, tokenMaker, AuthorizationTypeBearer, tag.Owner.String, time.Minute)
+				addAuthorization(t, request, tokenMaker, AuthorizationTypeBearer, tag.Owner, time.Minute)
 			},
 			buildstubs: func(store *mockDB.MockStore) {
 				store.EXPECT().
@@ -170,7 +172,7 @@ func TestGetTag(t *testing.T) {
 			name:  "InternalServerError",
 			tagId: tag.TagID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker tokens.Maker) {
-				addAuthorization(t, request, tokenMaker, AuthorizationTypeBearer, tag.Owner.String, time.Minute)
+				addAuthorization(t, request, tokenMaker, AuthorizationTypeBearer, tag.Owner, time.Minute)
 			},
 			buildstubs: func(store *mockDB.MockStore) {
 				store.EXPECT().
@@ -192,6 +194,7 @@ func TestGetTag(t *testing.T) {
 
 			store := mockDB.NewMockStore(ctrl)
 			tc.buildstubs(store)
+			allowSessions(store)
 
 			server, _ := newTestServer(t, store)
 			recorder := httptest.NewRecorder()
@@ -241,7 +244,7 @@ func TestListTags(t *testing.T) {
 				arg := Database.ListTagsParams{
 					TagID: query.TagId,
 					Limit: query.PageSize,
-					Owner: sql.NullString{String: "user", Valid: true},
+					Owner: "user",
 				}
 				store.EXPECT().
 					ListTags(gomock.Any(), gomock.Eq(arg)).
@@ -266,7 +269,7 @@ func TestListTags(t *testing.T) {
 				arg := Database.ListTagsParams{
 					TagID: 0,
 					Limit: 2,
-					Owner: sql.NullString{String: "user", Valid: true},
+					Owner: "user",
 				}
 				store.EXPECT().
 					ListTags(gomock.Any(), gomock.Eq(arg)).
@@ -289,7 +292,7 @@ func TestListTags(t *testing.T) {
 				arg := Database.ListTagsParams{
 					TagID: query.TagId,
 					Limit: int32(n),
-					Owner: sql.NullString{String: "user", Valid: true},
+					Owner: "user",
 				}
 				store.EXPECT().
 					ListTags(gomock.Any(), gomock.Eq(arg)).
@@ -310,6 +313,7 @@ func TestListTags(t *testing.T) {
 
 			store := mockDB.NewMockStore(ctrl)
 			tc.buildstubs(store, tc.query)
+			allowSessions(store)
 
 			server, _ := newTestServer(t, store)
 			recorder := httptest.NewRecorder()
@@ -356,7 +360,7 @@ func TagsResponseMatching(t *testing.T, body *bytes.Buffer, tags []Database.Tag)
 func RandomTag() Database.Tag {
 	return Database.Tag{
 		TagID: int32(util.RandomInt(1, 10)),
-		Owner: sql.NullString{String: util.RandomString(5), Valid: true},
+		Owner: util.RandomString(5),
 		Name:  util.RandomString(4),
 	}
 }
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockDB "github.com/nilesh0729/Notes/internal/db/Mock"
+	Database "github.com/nilesh0729/Notes/internal/db/Result"
+	"github.com/nilesh0729/Notes/internal/tracing"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// useTestTracing installs a global tracer provider recording spans in memory
+// for the rest of the test.
+func useTestTracing(t *testing.T) (*sdktrace.TracerProvider, *tracetest.InMemoryExporter) {
+	previousProvider := otel.GetTracerProvider()
+	previousPropagator := otel.GetTextMapPropagator()
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := tracing.NewTracerProvider(newTestConfig(t), sdktrace.WithSyncer(exporter))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+		require.NoError(t, provider.Shutdown(context.Background()))
+	})
+	return provider, exporter
+}
+
+func TestTracingListNotes(t *testing.T) {
+	provider, exporter := useTestTracing(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	notes := []Database.Note{RandomNotes(), RandomNotes()}
+	notes[1].NoteID = notes[0].NoteID + 1
+
+	store := mockDB.NewMockStore(ctrl)
+	allowSessions(store)
+	store.EXPECT().
+		ListNotes(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(notes, nil)
+	for _, note := range notes {
+		store.EXPECT().
+			GetTagsForNote(gomock.Any(), gomock.Eq(note.NoteID)).
+			Times(1).
+			Return([]Database.GetTagsForNoteRow{}, nil)
+	}
+
+	server, tokenMaker := newTestServer(t, tracing.TraceStore(store, provider))
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	request := httptest.NewRequest(http.MethodGet, "/notes?page_size=5", nil)
+	request.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	addAuthorization(t, request, tokenMaker, AuthorizationTypeBearer, "user", time.Minute)
+
+	recorder := httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var serverSpan tracetest.SpanStub
+	queries := make(map[string]int)
+	for _, span := range exporter.GetSpans() {
+		require.Equal(t, traceID, span.SpanContext.TraceID().String())
+		if span.SpanKind == trace.SpanKindServer {
+			serverSpan = span
+			continue
+		}
+		queries[span.Name]++
+	}
+
+	require.True(t, serverSpan.SpanContext.IsValid())
+	require.Equal(t, "00f067aa0ba902b7", serverSpan.Parent.SpanID().String())
+	require.True(t, serverSpan.Parent.IsRemote())
+
+	require.Equal(t, map[string]int{
+		"GetSession":     1,
+		"ListNotes":      1,
+		"GetTagsForNote": 2,
+	}, queries)
+
+	for _, span := range exporter.GetSpans() {
+		if span.SpanKind == trace.SpanKindClient {
+			require.Equal(t, serverSpan.SpanContext.SpanID(), span.Parent.SpanID())
+		}
+	}
+}
+
+func TestTracingSkipsProbes(t *testing.T) {
+	_, exporter := useTestTracing(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server, _ := newTestServer(t, mockDB.NewMockStore(ctrl))
+
+	recorder := httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Empty(t, exporter.GetSpans())
+}
@@ -0,0 +1,341 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	Database "github.com/nilesh0729/Notes/internal/db/Result"
+	"github.com/nilesh0729/Notes/internal/metrics"
+	"github.com/nilesh0729/Notes/internal/tokens"
+	"github.com/nilesh0729/Notes/internal/util"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+const recoveryCodeCount = 10
+
+// TOTP codes change every totpPeriod seconds. A code is also accepted
+// totpSkew steps early or late, to allow for clock drift between the server
+// and the authenticator app.
+const (
+	totpPeriod = 30
+	totpSkew   = 1
+)
+
+var (
+	errInvalidTwoFactorCode  = newAPIError(http.StatusUnauthorized, CodeInvalidCredentials, "two-factor code is invalid")
+	errInvalidSetupCode      = newAPIError(http.StatusBadRequest, CodeBadRequest, "two-factor code is invalid")
+	errInvalidLoginChallenge = newAPIError(http.StatusUnauthorized, CodeUnauthorized, "login challenge is invalid or has expired")
+	errTwoFactorEnabled      = newAPIError(http.StatusConflict, CodeConflict, "two-factor authentication is already enabled")
+	errTwoFactorNotSetUp     = newAPIError(http.StatusBadRequest, CodeBadRequest, "two-factor setup has not been started")
+)
+
+type SetupTwoFactorResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// SetupTwoFactor generates a new TOTP secret for the authenticated user. The
+// secret only takes effect once it is confirmed through EnableTwoFactor.
+func (server *Server) SetupTwoFactor(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*tokens.Payload)
+
+	user, err := server.store.GetUser(ctx, authPayload.Username)
+	if err != nil {
+		if err == Database.ErrRecordNotFound {
+			abortWithError(ctx, errUserNotFound)
+			return
+		}
+		abortWithError(ctx, err)
+		return
+	}
+
+	if user.TotpEnabled {
+		abortWithError(ctx, errTwoFactorEnabled)
+		return
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      server.config.TOTPIssuer,
+		AccountName: user.Username,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	arg := Database.SetTOTPSecretParams{
+		Username:   user.Username,
+		TotpSecret: key.Secret(),
+	}
+	_, err = server.store.SetTOTPSecret(ctx, arg)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SetupTwoFactorResponse{
+		Secret:     key.Secret(),
+		OTPAuthURI: key.URL(),
+	})
+}
+
+type EnableTwoFactorRequest struct {
+	Code string `json:"code" binding:"required,numeric,len=6"`
+}
+
+type EnableTwoFactorResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// EnableTwoFactor confirms the secret from SetupTwoFactor with a code from the
+// authenticator app, and hands out one-time recovery codes.
+func (server *Server) EnableTwoFactor(ctx *gin.Context) {
+	var req EnableTwoFactorRequest
+
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*tokens.Payload)
+
+	user, err := server.store.GetUser(ctx, authPayload.Username)
+	if err != nil {
+		if err == Database.ErrRecordNotFound {
+			abortWithError(ctx, errUserNotFound)
+			return
+		}
+		abortWithError(ctx, err)
+		return
+	}
+
+	if user.TotpEnabled {
+		abortWithError(ctx, errTwoFactorEnabled)
+		return
+	}
+	if user.TotpSecret == "" {
+		abortWithError(ctx, errTwoFactorNotSetUp)
+		return
+	}
+
+	ok, err := server.useTOTPCode(ctx, user, req.Code)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+	if !ok {
+		abortWithError(ctx, errInvalidSetupCode)
+		return
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([][]byte, recoveryCodeCount)
+	for i := range codes {
+		codes[i], err = util.NewRecoveryCode()
+		if err != nil {
+			abortWithError(ctx, err)
+			return
+		}
+		hashes[i] = util.HashToken(codes[i])
+	}
+
+	arg := Database.EnableTOTPTxParams{
+		Username:           user.Username,
+		RecoveryCodeHashes: hashes,
+	}
+	_, err = server.store.EnableTOTPTx(ctx, arg)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, EnableTwoFactorResponse{RecoveryCodes: codes})
+}
+
+type LoginChallengeResponse struct {
+	TwoFactorRequired  bool      `json:"two_factor_required"`
+	ChallengeToken     string    `json:"challenge_token"`
+	ChallengeExpiresAt time.Time `json:"challenge_expires_at"`
+}
+
+// startTwoFactorLogin answers a correct password for a user with 2FA enabled.
+// Instead of an access token the client gets a short-lived challenge token to
+// exchange, together with a TOTP or recovery code, at POST /login/2fa.
+func (server *Server) startTwoFactorLogin(ctx *gin.Context, user Database.User) {
+	expiresAt := time.Now().Add(server.config.LoginChallengeDuration)
+
+	challenge, err := server.issueUserToken(ctx, user, Database.TokenPurposeLoginChallenge, server.config.LoginChallengeDuration)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, LoginChallengeResponse{
+		TwoFactorRequired:  true,
+		ChallengeToken:     challenge,
+		ChallengeExpiresAt: expiresAt,
+	})
+}
+
+type LoginTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
+
+func (server *Server) LoginTwoFactor(ctx *gin.Context) {
+	var req LoginTwoFactorRequest
+
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	challengeArg := Database.GetActiveUserTokenParams{
+		TokenHash: util.HashToken(req.ChallengeToken),
+		Purpose:   Database.TokenPurposeLoginChallenge,
+	}
+	challenge, err := server.store.GetActiveUserToken(ctx, challengeArg)
+	if err != nil {
+		if err == Database.ErrRecordNotFound {
+			abortWithError(ctx, errInvalidLoginChallenge)
+			return
+		}
+		abortWithError(ctx, err)
+		return
+	}
+
+	if !server.allowLoginAttempt(ctx, challenge.Username, ctx.ClientIP()) {
+		return
+	}
+
+	// Every code counts against the challenge, so that it cannot be used to
+	// guess for as long as it is valid.
+	_, err = server.store.ClaimUserTokenAttempt(ctx, Database.ClaimUserTokenAttemptParams{
+		TokenHash:   challengeArg.TokenHash,
+		Purpose:     challengeArg.Purpose,
+		MaxAttempts: int32(server.config.LoginChallengeMaxAttempts),
+	})
+	if err != nil {
+		if err == Database.ErrRecordNotFound {
+			abortWithError(ctx, errInvalidLoginChallenge)
+			return
+		}
+		abortWithError(ctx, err)
+		return
+	}
+
+	user, err := server.store.GetUser(ctx, challenge.Username)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ok, err := server.checkSecondFactor(ctx, user, req.Code)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+	if !ok {
+		server.loginThrottle.recordFailure(user.Username, ctx.ClientIP())
+		metrics.LoginFailures.WithLabelValues(metrics.LoginWrongTwoFactor).Inc()
+		abortWithError(ctx, errInvalidTwoFactorCode)
+		return
+	}
+
+	// The challenge is only burnt after a correct code or once its attempts
+	// are used up, so a typo does not force the user to enter their password
+	// again.
+	_, err = server.store.ConsumeUserToken(ctx, Database.ConsumeUserTokenParams(challengeArg))
+	if err != nil {
+		if err == Database.ErrRecordNotFound {
+			abortWithError(ctx, errInvalidLoginChallenge)
+			return
+		}
+		abortWithError(ctx, err)
+		return
+	}
+
+	server.loginThrottle.recordSuccess(user.Username)
+
+	accessToken, err := server.createSession(ctx, user.Username)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, LoginUserResponse{
+		AccessToken: accessToken,
+		User:        UserResponse(user),
+	})
+}
+
+// checkSecondFactor accepts either a current TOTP code or an unused recovery
+// code, which is consumed in the process.
+func (server *Server) checkSecondFactor(ctx *gin.Context, user Database.User, code string) (bool, error) {
+	if !user.TotpEnabled {
+		return false, nil
+	}
+
+	if len(code) == 6 {
+		return server.useTOTPCode(ctx, user, code)
+	}
+
+	_, err := server.store.ConsumeRecoveryCode(ctx, Database.ConsumeRecoveryCodeParams{
+		Username: user.Username,
+		CodeHash: util.HashToken(util.NormalizeRecoveryCode(code)),
+	})
+	if err != nil {
+		if err == Database.ErrRecordNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// useTOTPCode accepts a current TOTP code of user, at most once: the time
+// step of the code is recorded, and codes of that or an earlier step are
+// rejected from then on, so that an observed code cannot be replayed.
+func (server *Server) useTOTPCode(ctx context.Context, user Database.User, code string) (bool, error) {
+	step, ok := validateTOTP(code, user.TotpSecret, time.Now())
+	if !ok {
+		return false, nil
+	}
+
+	rows, err := server.store.RecordTOTPStep(ctx, Database.RecordTOTPStepParams{
+		Username: user.Username,
+		Step:     step,
+	})
+	if err != nil {
+		return false, err
+	}
+	return rows == 1, nil
+}
+
+// validateTOTP returns the time step code belongs to if it is valid for
+// secret at time t.
+func validateTOTP(code, secret string, t time.Time) (int64, bool) {
+	opts := totp.ValidateOpts{
+		Period:    totpPeriod,
+		Digits:    otp.DigitsSix,
+		Algorithm: otp.AlgorithmSHA1,
+	}
+
+	current := t.Unix() / totpPeriod
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		expected, err := totp.GenerateCodeCustom(secret, time.Unix(step*totpPeriod, 0), opts)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(code), []byte(expected)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
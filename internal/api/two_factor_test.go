@@ -0,0 +1,571 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockDB "github.com/nilesh0729/Notes/internal/db/Mock"
+	Database "github.com/nilesh0729/Notes/internal/db/Result"
+	"github.com/nilesh0729/Notes/internal/util"
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/require"
+)
+
+func randomTOTPSecret(t *testing.T) string {
+	key, err := totp.Generate(totp.GenerateOpts{Issuer: "Notes", AccountName: util.RandomOwner()})
+	require.NoError(t, err)
+	return key.Secret()
+}
+
+func currentTOTPCode(t *testing.T, secret string) string {
+	code, err := totp.GenerateCode(secret, time.Now())
+	require.NoError(t, err)
+	return code
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret := randomTOTPSecret(t)
+	now := time.Unix(1_700_000_000, 0)
+	step := now.Unix() / totpPeriod
+
+	code, err := totp.GenerateCode(secret, now)
+	require.NoError(t, err)
+
+	got, ok := validateTOTP(code, secret, now)
+	require.True(t, ok)
+	require.Equal(t, step, got)
+
+	// The code of the previous step is still accepted, for clock drift, and
+	// reports its own step.
+	got, ok = validateTOTP(code, secret, now.Add(totpPeriod*time.Second))
+	require.True(t, ok)
+	require.Equal(t, step, got)
+
+	_, ok = validateTOTP(code, secret, now.Add(2*totpPeriod*time.Second))
+	require.False(t, ok)
+	_, ok = validateTOTP(code, randomTOTPSecret(t), now)
+	require.False(t, ok)
+}
+
+func TestSetupTwoFactorAPI(t *testing.T) {
+	_, user := RandomUser(t)
+
+	enabled := user
+	enabled.TotpSecret = randomTOTPSecret(t)
+	enabled.TotpEnabled = true
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockDB.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockDB.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					SetTOTPSecret(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg Database.SetTOTPSecretParams) (Database.User, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.NotEmpty(t, arg.TotpSecret)
+						return user, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res SetupTwoFactorResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.NotEmpty(t, res.Secret)
+				require.Contains(t, res.OTPAuthURI, "otpauth://totp/")
+				require.Contains(t, res.OTPAuthURI, "secret="+res.Secret)
+			},
+		},
+		{
+			name: "AlreadyEnabled",
+			buildStubs: func(store *mockDB.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(enabled, nil)
+				store.EXPECT().
+					SetTOTPSecret(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "InternalServerError",
+			buildStubs: func(store *mockDB.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					SetTOTPSecret(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(Database.User{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockDB.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			allowSessions(store)
+
+			server, _ := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, "/me/2fa/setup", nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, AuthorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestEnableTwoFactorAPI(t *testing.T) {
+	_, user := RandomUser(t)
+
+	pending := user
+	pending.TotpSecret = randomTOTPSecret(t)
+
+	testCases := []struct {
+		name          string
+		code          func(t *testing.T) string
+		buildStubs    func(store *mockDB.MockStore, hashes *[][]byte)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, hashes [][]byte)
+	}{
+		{
+			name: "OK",
+			code: func(t *testing.T) string {
+				return currentTOTPCode(t, pending.TotpSecret)
+			},
+			buildStubs: func(store *mockDB.MockStore, hashes *[][]byte) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(pending, nil)
+				store.EXPECT().
+					RecordTOTPStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg Database.RecordTOTPStepParams) (int64, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.InDelta(t, time.Now().Unix()/totpPeriod, arg.Step, totpSkew)
+						return 1, nil
+					})
+				store.EXPECT().
+					EnableTOTPTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg Database.EnableTOTPTxParams) (Database.User, error) {
+						require.Equal(t, user.Username, arg.Username)
+						*hashes = arg.RecoveryCodeHashes
+						return pending, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, hashes [][]byte) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res EnableTwoFactorResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Len(t, res.RecoveryCodes, recoveryCodeCount)
+				require.Len(t, hashes, recoveryCodeCount)
+				for i, code := range res.RecoveryCodes {
+					require.Equal(t, util.HashToken(code), hashes[i])
+				}
+			},
+		},
+		{
+			name: "WrongCode",
+			code: func(t *testing.T) string {
+				return currentTOTPCode(t, randomTOTPSecret(t))
+			},
+			buildStubs: func(store *mockDB.MockStore, hashes *[][]byte) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(pending, nil)
+				store.EXPECT().
+					EnableTOTPTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, hashes [][]byte) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "ReplayedCode",
+			code: func(t *testing.T) string {
+				return currentTOTPCode(t, pending.TotpSecret)
+			},
+			buildStubs: func(store *mockDB.MockStore, hashes *[][]byte) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(pending, nil)
+				store.EXPECT().
+					RecordTOTPStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), nil)
+				store.EXPECT().
+					EnableTOTPTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, hashes [][]byte) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "SetupNotStarted",
+			code: func(t *testing.T) string {
+				return "123456"
+			},
+			buildStubs: func(store *mockDB.MockStore, hashes *[][]byte) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					EnableTOTPTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, hashes [][]byte) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "MalformedCode",
+			code: func(t *testing.T) string {
+				return "12ab"
+			},
+			buildStubs: func(store *mockDB.MockStore, hashes *[][]byte) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, hashes [][]byte) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var hashes [][]byte
+			store := mockDB.NewMockStore(ctrl)
+			tc.buildStubs(store, &hashes)
+			allowSessions(store)
+
+			server, _ := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"code": tc.code(t)})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/me/2fa/enable", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, AuthorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder, hashes)
+		})
+	}
+}
+
+func TestLoginWithTwoFactorReturnsChallenge(t *testing.T) {
+	password, user := RandomUser(t)
+	user.TotpSecret = randomTOTPSecret(t)
+	user.TotpEnabled = true
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var challengeHash []byte
+	store := mockDB.NewMockStore(ctrl)
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		Return(user, nil)
+	store.EXPECT().
+		CreateUserToken(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ interface{}, arg Database.CreateUserTokenParams) (Database.UserToken, error) {
+			require.Equal(t, Database.TokenPurposeLoginChallenge, arg.Purpose)
+			challengeHash = arg.TokenHash
+			return Database.UserToken{}, nil
+		})
+	store.EXPECT().
+		CreateSession(gomock.Any(), gomock.Any()).
+		Times(0)
+
+	server, _ := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	data, err := json.Marshal(gin.H{"username": user.Username, "password": password})
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(http.MethodPost, "/login", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusAccepted, recorder.Code)
+
+	var res LoginChallengeResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+	require.True(t, res.TwoFactorRequired)
+	require.Equal(t, util.HashToken(res.ChallengeToken), challengeHash)
+	require.NotContains(t, recorder.Body.String(), "access_token")
+}
+
+func TestLoginTwoFactorAPI(t *testing.T) {
+	_, user := RandomUser(t)
+	user.TotpSecret = randomTOTPSecret(t)
+	user.TotpEnabled = true
+
+	challenge, challengeHash, err := util.NewOpaqueToken()
+	require.NoError(t, err)
+	recoveryCode, err := util.NewRecoveryCode()
+	require.NoError(t, err)
+
+	challengeRow := Database.UserToken{
+		Username:  user.Username,
+		Purpose:   Database.TokenPurposeLoginChallenge,
+		TokenHash: challengeHash,
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+
+	expectChallenge := func(store *mockDB.MockStore) {
+		store.EXPECT().
+			GetActiveUserToken(gomock.Any(), gomock.Eq(Database.GetActiveUserTokenParams{
+				TokenHash: challengeHash,
+				Purpose:   Database.TokenPurposeLoginChallenge,
+			})).
+			Times(1).
+			Return(challengeRow, nil)
+		store.EXPECT().
+			ClaimUserTokenAttempt(gomock.Any(), gomock.Eq(Database.ClaimUserTokenAttemptParams{
+				TokenHash:   challengeHash,
+				Purpose:     Database.TokenPurposeLoginChallenge,
+				MaxAttempts: 3,
+			})).
+			Times(1).
+			Return(challengeRow, nil)
+		store.EXPECT().
+			GetUser(gomock.Any(), gomock.Eq(user.Username)).
+			Times(1).
+			Return(user, nil)
+	}
+	expectLogin := func(store *mockDB.MockStore) {
+		store.EXPECT().
+			ConsumeUserToken(gomock.Any(), gomock.Any()).
+			Times(1).
+			Return(challengeRow, nil)
+		store.EXPECT().
+			CreateSession(gomock.Any(), gomock.Any()).
+			Times(1).
+			Return(Database.Session{}, nil)
+	}
+
+	testCases := []struct {
+		name          string
+		body          func(t *testing.T) gin.H
+		buildStubs    func(store *mockDB.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "TOTPCode",
+			body: func(t *testing.T) gin.H {
+				return gin.H{"challenge_token": challenge, "code": currentTOTPCode(t, user.TotpSecret)}
+			},
+			buildStubs: func(store *mockDB.MockStore) {
+				expectChallenge(store)
+				store.EXPECT().
+					RecordTOTPStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(1), nil)
+				expectLogin(store)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				data, err := io.ReadAll(recorder.Body)
+				require.NoError(t, err)
+				var res LoginUserResponse
+				require.NoError(t, json.Unmarshal(data, &res))
+				require.NotEmpty(t, res.AccessToken)
+			},
+		},
+		{
+			name: "RecoveryCode",
+			body: func(t *testing.T) gin.H {
+				return gin.H{"challenge_token": challenge, "code": recoveryCode}
+			},
+			buildStubs: func(store *mockDB.MockStore) {
+				expectChallenge(store)
+				store.EXPECT().
+					ConsumeRecoveryCode(gomock.Any(), gomock.Eq(Database.ConsumeRecoveryCodeParams{
+						Username: user.Username,
+						CodeHash: util.HashToken(recoveryCode),
+					})).
+					Times(1).
+					Return(Database.RecoveryCode{}, nil)
+				expectLogin(store)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "UsedRecoveryCode",
+			body: func(t *testing.T) gin.H {
+				return gin.H{"challenge_token": challenge, "code": recoveryCode}
+			},
+			buildStubs: func(store *mockDB.MockStore) {
+				expectChallenge(store)
+				store.EXPECT().
+					ConsumeRecoveryCode(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(Database.RecoveryCode{}, Database.ErrRecordNotFound)
+				store.EXPECT().
+					ConsumeUserToken(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "WrongTOTPCode",
+			body: func(t *testing.T) gin.H {
+				return gin.H{"challenge_token": challenge, "code": currentTOTPCode(t, randomTOTPSecret(t))}
+			},
+			buildStubs: func(store *mockDB.MockStore) {
+				expectChallenge(store)
+				store.EXPECT().
+					ConsumeUserToken(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			// The code was accepted before, e.g. on a phishing proxy.
+			name: "ReplayedTOTPCode",
+			body: func(t *testing.T) gin.H {
+				return gin.H{"challenge_token": challenge, "code": currentTOTPCode(t, user.TotpSecret)}
+			},
+			buildStubs: func(store *mockDB.MockStore) {
+				expectChallenge(store)
+				store.EXPECT().
+					RecordTOTPStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), nil)
+				store.EXPECT().
+					ConsumeUserToken(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireAPIError(t, recorder, CodeInvalidCredentials)
+			},
+		},
+		{
+			name: "AttemptsUsedUp",
+			body: func(t *testing.T) gin.H {
+				return gin.H{"challenge_token": challenge, "code": currentTOTPCode(t, user.TotpSecret)}
+			},
+			buildStubs: func(store *mockDB.MockStore) {
+				store.EXPECT().
+					GetActiveUserToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(challengeRow, nil)
+				store.EXPECT().
+					ClaimUserTokenAttempt(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(Database.UserToken{}, Database.ErrRecordNotFound)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireAPIError(t, recorder, CodeUnauthorized)
+			},
+		},
+		{
+			name: "InvalidChallenge",
+			body: func(t *testing.T) gin.H {
+				return gin.H{"challenge_token": challenge, "code": currentTOTPCode(t, user.TotpSecret)}
+			},
+			buildStubs: func(store *mockDB.MockStore) {
+				store.EXPECT().
+					GetActiveUserToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(Database.UserToken{}, Database.ErrRecordNotFound)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockDB.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, _ := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body(t))
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/login/2fa", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
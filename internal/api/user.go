@@ -1,47 +1,68 @@
 package api
 
 import (
-	"database/sql"
+	"errors"
+	"log/slog"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/lib/pq"
+	"github.com/google/uuid"
 	Database "github.com/nilesh0729/Notes/internal/db/Result"
+	"github.com/nilesh0729/Notes/internal/metrics"
 	"github.com/nilesh0729/Notes/internal/util"
 )
 
 type UserResponseFormat struct {
-	Username string `json:"username" binding:"required,alphanum,min=6"`
-	Password string `json:"password" binding:"required,min=8"`
-	Email    string `json:"email" binding:"required,email"`
+	Username          string    `json:"username" binding:"required,alphanum,min=6"`
+	Password          string    `json:"password" binding:"required,min=8"`
+	Email             string    `json:"email" binding:"required,email"`
+	EmailVerified     bool      `json:"email_verified"`
+	TwoFactorEnabled  bool      `json:"two_factor_enabled"`
+	PasswordChangedAt time.Time `json:"password_changed_at"`
+	CreatedAt         time.Time `json:"created_at"`
 }
 
 func UserResponse(user Database.User) UserResponseFormat {
 	return UserResponseFormat{
-		Username: user.Username,
-		Password: "********",
-		Email:    "******@gmail.com",
+		Username:          user.Username,
+		Password:          "********",
+		Email:             user.Email,
+		EmailVerified:     user.EmailVerified,
+		TwoFactorEnabled:  user.TotpEnabled,
+		PasswordChangedAt: user.PasswordChangedAt,
+		CreatedAt:         user.CreatedAt,
 	}
 }
 
 type CreateUserRequest struct {
-	Username string `json:"Username" binding:"required,alphanum"`
-	Password string `json:"Password" binding:"required,min=8"`
-	Email    string `json:"Email" binding:"required,email"`
+	Username string `json:"username" binding:"required,alphanum"`
+	Password string `json:"password" binding:"required"`
+	Email    string `json:"email" binding:"required,email"`
 }
 
 func (server *Server) CreateUser(ctx *gin.Context) {
 	var req CreateUserRequest
 
-	err := ctx.BindJSON(&req)
+	err := ctx.ShouldBindJSON(&req)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	if !server.checkPassword(ctx, "password", req.Password, req.Username, req.Email) {
 		return
 	}
 
-	hashedPassword, err := util.HashedPassword(req.Password)
-	if err!= nil{
-		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+	hashedPassword, err := server.passwordHasher.Hash(req.Password)
+	if err != nil {
+		if errors.Is(err, util.ErrPasswordTooLong) {
+			abortWithError(ctx, validationError([]FieldError{passwordTooLongField("password")}))
+			return
+		}
+		abortWithError(ctx, err)
 		return
 	}
 
@@ -52,23 +73,37 @@ func (server *Server) CreateUser(ctx *gin.Context) {
 	}
 	user, err := server.store.CreateUser(ctx, arg)
 	if err != nil {
-		if pqErr, ok := err.(*pq.Error); ok {
-			switch pqErr.Code.Name() {
-			case "unique_violation":
-				ctx.JSON(http.StatusForbidden, errResponse(err))
-				return
-			}
+		if Database.ErrorCode(err) == Database.UniqueViolation {
+			abortWithError(ctx, errUsernameTaken)
+			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		abortWithError(ctx, err)
 		return
 	}
 
+	// The account exists at this point; a lost email can be re-requested
+	// through POST /me/verify-email, so delivery failures are not fatal.
+	err = server.sendVerificationEmail(ctx, user)
+	if err != nil {
+		requestLogger(ctx).WarnContext(ctx, "cannot send verification email", slog.String("username", user.Username), slog.Any("error", err))
+	}
+
 	ctx.JSON(http.StatusOK, UserResponse(user))
 }
 
+var (
+	errInvalidCredentials   = newAPIError(http.StatusUnauthorized, CodeInvalidCredentials, "invalid username or password")
+	errWrongPassword        = newAPIError(http.StatusUnauthorized, CodeInvalidCredentials, "current password is incorrect")
+	errUserNotFound         = newAPIError(http.StatusNotFound, CodeNotFound, "user not found")
+	errUsernameTaken        = newAPIError(http.StatusConflict, CodeConflict, "username is already taken")
+	errTooManyLoginAttempts = newAPIError(http.StatusTooManyRequests, CodeTooManyRequests, "too many failed login attempts, try again later")
+)
+
+// LoginUserRequest does not apply the password policy, which may have
+// changed since the password was set.
 type LoginUserRequest struct {
 	Username string `json:"username" binding:"required,alphanum,min=6"`
-	Password string `json:"password" binding:"required,min=8"`
+	Password string `json:"password" binding:"required"`
 }
 
 type LoginUserResponse struct{
@@ -81,30 +116,49 @@ func (server *Server) LoginUser(ctx *gin.Context) {
 
 	err := ctx.ShouldBindJSON(&req)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		abortWithError(ctx, bindingError(err))
 		return
 	}
+
+	// The IP is only taken from forwarding headers sent by a trusted proxy,
+	// so clients cannot spread their attempts over made-up addresses.
+	clientIP := ctx.ClientIP()
+	if !server.allowLoginAttempt(ctx, req.Username, clientIP) {
+		return
+	}
+
 	user, err := server.store.GetUser(ctx, req.Username)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errResponse(err))
+		if err == Database.ErrRecordNotFound {
+			// Spend the same time as for a wrong password, so the response
+			// does not tell whether the username exists.
+			server.passwordHasher.Verify(req.Password, server.dummyPasswordHash())
+			server.loginThrottle.recordFailure(req.Username, clientIP)
+			metrics.LoginFailures.WithLabelValues(metrics.LoginUnknownUser).Inc()
+			abortWithError(ctx, errInvalidCredentials)
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		abortWithError(ctx, err)
 		return
 	}
-	err = util.CheckPassword(req.Password, user.HashedPassword)
+	err = server.passwordHasher.Verify(req.Password, user.HashedPassword)
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, errResponse(err))
+		server.loginThrottle.recordFailure(req.Username, clientIP)
+		metrics.LoginFailures.WithLabelValues(metrics.LoginWrongPassword).Inc()
+		abortWithError(ctx, errInvalidCredentials)
 		return
 	}
+	server.rehashPassword(ctx, user, req.Password)
 
-	accessToken, err := server.tokenMaker.CreateToken(
-		user.Username,
-		server.config.AccessTokenDuration,
-	)
+	if user.TotpEnabled {
+		server.startTwoFactorLogin(ctx, user)
+		return
+	}
+	server.loginThrottle.recordSuccess(user.Username)
+
+	accessToken, err := server.createSession(ctx, user.Username)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		abortWithError(ctx, err)
 		return
 	}
 
@@ -114,4 +168,74 @@ func (server *Server) LoginUser(ctx *gin.Context) {
 	}
 
 	ctx.JSON(http.StatusOK, res)
-}
\ No newline at end of file
+}
+
+// createSession mints an access token for username and records the session
+// it belongs to, so authMiddleware can later revoke it.
+func (server *Server) createSession(ctx *gin.Context, username string) (string, error) {
+	accessToken, payload, err := server.tokenMaker.CreateToken(
+		username,
+		server.config.AccessTokenDuration,
+		sessionScopes...,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	sessionID, err := uuid.Parse(payload.ID)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = server.store.CreateSession(ctx, Database.CreateSessionParams{
+		ID:        sessionID,
+		Username:  username,
+		UserAgent: ctx.Request.UserAgent(),
+		ClientIp:  ctx.ClientIP(),
+		ExpiresAt: payload.ExpiresAt.Time,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return accessToken, nil
+}
+
+// rehashPassword upgrades the stored hash of user once password is known to
+// match it, if the hash was made with another algorithm or weaker parameters
+// than configured. A failure does not fail the login; the next one retries.
+func (server *Server) rehashPassword(ctx *gin.Context, user Database.User, password string) {
+	if !server.passwordHasher.NeedsRehash(user.HashedPassword) {
+		return
+	}
+
+	hashedPassword, err := server.passwordHasher.Hash(password)
+	if err != nil {
+		requestLogger(ctx).ErrorContext(ctx, "cannot rehash password", slog.String("username", user.Username), slog.Any("error", err))
+		return
+	}
+
+	// Matching on the old hash keeps a concurrent password change intact.
+	_, err = server.store.RehashUserPassword(ctx, Database.RehashUserPasswordParams{
+		NewHashedPassword: hashedPassword,
+		Username:          user.Username,
+		OldHashedPassword: user.HashedPassword,
+	})
+	if err != nil {
+		requestLogger(ctx).ErrorContext(ctx, "cannot rehash password", slog.String("username", user.Username), slog.Any("error", err))
+	}
+}
+
+// allowLoginAttempt answers with 429 and returns false while failed attempts
+// for username or clientIP are still backing off.
+func (server *Server) allowLoginAttempt(ctx *gin.Context, username, clientIP string) bool {
+	wait := server.loginThrottle.retryAfter(username, clientIP)
+	if wait <= 0 {
+		return true
+	}
+
+	ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	metrics.LoginFailures.WithLabelValues(metrics.LoginThrottled).Inc()
+	abortWithError(ctx, errTooManyLoginAttempts)
+	return false
+}
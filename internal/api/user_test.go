@@ -2,6 +2,7 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -9,11 +10,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
-	"github.com/lib/pq"
+	"github.com/jackc/pgx/v5/pgconn"
 
 	mockDB "github.com/nilesh0729/Notes/internal/db/Mock"
 	Database "github.com/nilesh0729/Notes/internal/db/Result"
@@ -51,7 +53,11 @@ func EqCreateUserParams(arg Database.CreateUserParams, password string) gomock.M
 
 func TestCreateUser(t *testing.T) {
 	password, user1 := RandomUser(t)
-	arg := Database.CreateUserParams(user1)
+	arg := Database.CreateUserParams{
+		Username:       user1.Username,
+		HashedPassword: user1.HashedPassword,
+		Email:          user1.Email,
+	}
 	testcases := []struct {
 		name          string
 		body          gin.H
@@ -71,6 +77,10 @@ func TestCreateUser(t *testing.T) {
 					CreateUser(gomock.Any(), EqCreateUserParams(arg, password)).
 					Times(1).
 					Return(user1, nil)
+				store.EXPECT().
+					CreateUserToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(Database.UserToken{}, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
@@ -121,10 +131,11 @@ func TestCreateUser(t *testing.T) {
 				store.EXPECT().
 					CreateUser(gomock.Any(), gomock.Any()).
 					Times(1).
-					Return(Database.User{}, &pq.Error{Code: "23505"})
+					Return(Database.User{}, &pgconn.PgError{Code: Database.UniqueViolation})
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusForbidden, recorder.Code)
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireBodyError(t, recorder, errUsernameTaken)
 			},
 		},
 		{
@@ -143,6 +154,62 @@ func TestCreateUser(t *testing.T) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
+		{
+			// bcrypt would ignore everything past 72 bytes.
+			name: "PasswordTooLongForBcrypt",
+			body: gin.H{
+				"username": user1.Username,
+				"password": util.RandomString(73),
+				"email":    user1.Email,
+			},
+			buildstubbs: func(store *mockDB.MockStore) {
+				store.EXPECT().
+					CreateUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireFieldErrors(t, recorder, "password", util.PasswordTooLong)
+			},
+		},
+		{
+			name: "PasswordPolicyViolation",
+			body: gin.H{
+				"username": user1.Username,
+				"password": "my" + user1.Username + "1",
+				"email":    user1.Email,
+			},
+			buildstubbs: func(store *mockDB.MockStore) {
+				store.EXPECT().
+					CreateUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireFieldErrors(t, recorder, "password", util.PasswordContainsUsername)
+			},
+		},
+		{
+			name: "InvalidFields",
+			body: gin.H{
+				"username": "not valid",
+				"password": password,
+			},
+			buildstubbs: func(store *mockDB.MockStore) {
+				store.EXPECT().
+					CreateUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+				apiErr := requireAPIError(t, recorder, CodeValidationFailed)
+				require.ElementsMatch(t, []FieldError{
+					{Field: "username", Code: "alphanum", Message: "must contain only letters and digits"},
+					{Field: "email", Code: "required", Message: "is required"},
+				}, apiErr.Details)
+			},
+		},
 	}
 
 	for i := range testcases {
@@ -212,11 +279,35 @@ func TestLoginUser(t *testing.T) {
 					GetUser(gomock.Any(), gomock.Eq(user.Username)).
 					Times(1).
 					Return(user, nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(Database.Session{}, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
 			},
 		},
+		{
+			name: "CreateSessionError",
+			body: gin.H{
+				"username": user.Username,
+				"password": password,
+			},
+			buildStubs: func(store *mockDB.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(Database.Session{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
 		{
 			name: "UserNotFound",
 			body: gin.H{
@@ -227,10 +318,11 @@ func TestLoginUser(t *testing.T) {
 				store.EXPECT().
 					GetUser(gomock.Any(), gomock.Eq("NotFound")).
 					Times(1).
-					Return(Database.User{}, sql.ErrNoRows)
+					Return(Database.User{}, Database.ErrRecordNotFound)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusNotFound, recorder.Code)
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				requireBodyError(t, recorder, errInvalidCredentials)
 			},
 		},
 		{
@@ -247,6 +339,7 @@ func TestLoginUser(t *testing.T) {
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				requireBodyError(t, recorder, errInvalidCredentials)
 			},
 		},
 		{
@@ -292,6 +385,127 @@ func TestLoginUser(t *testing.T) {
 	}
 }
 
+func TestLoginUserRehashesPassword(t *testing.T) {
+	password, user := RandomUser(t)
+
+	config := newTestConfig(t)
+	config.PasswordHashAlgorithm = util.PasswordHashArgon2id
+	config.Argon2Memory = 64
+	config.Argon2Iterations = 1
+	config.Argon2Parallelism = 1
+
+	testCases := []struct {
+		name       string
+		user       func(t *testing.T) Database.User
+		buildStubs func(store *mockDB.MockStore, user Database.User)
+	}{
+		{
+			name: "BcryptUpgradedToArgon2id",
+			user: func(t *testing.T) Database.User {
+				return user
+			},
+			buildStubs: func(store *mockDB.MockStore, user Database.User) {
+				store.EXPECT().
+					RehashUserPassword(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg Database.RehashUserPasswordParams) (int64, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, user.HashedPassword, arg.OldHashedPassword)
+						require.True(t, strings.HasPrefix(arg.NewHashedPassword, "$argon2id$v=19$m=64,t=1,p=1$"))
+						require.NoError(t, util.CheckPassword(password, arg.NewHashedPassword))
+						return 1, nil
+					})
+			},
+		},
+		{
+			name: "WeakerArgon2idParameters",
+			user: func(t *testing.T) Database.User {
+				hasher := &util.Argon2idHasher{Memory: 32, Iterations: 1, Parallelism: 1}
+				hashedPassword, err := hasher.Hash(password)
+				require.NoError(t, err)
+
+				weak := user
+				weak.HashedPassword = hashedPassword
+				return weak
+			},
+			buildStubs: func(store *mockDB.MockStore, user Database.User) {
+				store.EXPECT().
+					RehashUserPassword(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(1), nil)
+			},
+		},
+		{
+			name: "CurrentHashKept",
+			user: func(t *testing.T) Database.User {
+				hasher, err := util.NewPasswordHasher(config)
+				require.NoError(t, err)
+				hashedPassword, err := hasher.Hash(password)
+				require.NoError(t, err)
+
+				current := user
+				current.HashedPassword = hashedPassword
+				return current
+			},
+			buildStubs: func(store *mockDB.MockStore, user Database.User) {
+				store.EXPECT().
+					RehashUserPassword(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+		},
+		{
+			// The hash is upgraded on a later login instead.
+			name: "RehashErrorIgnored",
+			user: func(t *testing.T) Database.User {
+				return user
+			},
+			buildStubs: func(store *mockDB.MockStore, user Database.User) {
+				store.EXPECT().
+					RehashUserPassword(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), sql.ErrConnDone)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			user := tc.user(t)
+
+			store := mockDB.NewMockStore(ctrl)
+			store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(user, nil)
+			store.EXPECT().
+				CreateSession(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(Database.Session{}, nil)
+			tc.buildStubs(store, user)
+
+			server, err := NewServer(config, store)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{
+				"username": user.Username,
+				"password": password,
+			})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/login", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusOK, recorder.Code)
+		})
+	}
+}
+
 func UserBodyMatching(t *testing.T, body *bytes.Buffer, user Database.User) {
 	data, err := io.ReadAll(body)
 	require.NoError(t, err)
@@ -307,3 +521,36 @@ func UserBodyMatching(t *testing.T, body *bytes.Buffer, user Database.User) {
 	// Compare the two masked responses
 	require.Equal(t, expectedResponse, gotResponse)
 }
+
+// requireAPIError checks that the body is an error response with code and
+// returns the error.
+func requireAPIError(t *testing.T, recorder *httptest.ResponseRecorder, code string) *APIError {
+	var body ErrorResponse
+	err := json.Unmarshal(recorder.Body.Bytes(), &body)
+	require.NoError(t, err)
+	require.NotNil(t, body.Error)
+	require.Equal(t, code, body.Error.Code)
+	require.Equal(t, recorder.Code, body.Error.Status)
+	require.NotEmpty(t, body.Error.RequestID)
+	require.Equal(t, recorder.Header().Get(RequestIDHeaderKey), body.Error.RequestID)
+	return body.Error
+}
+
+// requireFieldErrors checks that the body reports exactly the given rule
+// codes, all for field.
+func requireFieldErrors(t *testing.T, recorder *httptest.ResponseRecorder, field string, codes ...string) {
+	apiErr := requireAPIError(t, recorder, CodeValidationFailed)
+
+	got := make([]string, len(apiErr.Details))
+	for i, fieldErr := range apiErr.Details {
+		require.Equal(t, field, fieldErr.Field)
+		require.NotEmpty(t, fieldErr.Message)
+		got[i] = fieldErr.Code
+	}
+	require.ElementsMatch(t, codes, got)
+}
+
+func requireBodyError(t *testing.T, recorder *httptest.ResponseRecorder, expected *APIError) {
+	apiErr := requireAPIError(t, recorder, expected.Code)
+	require.Equal(t, expected.Message, apiErr.Message)
+}
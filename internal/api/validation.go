@@ -0,0 +1,125 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/nilesh0729/Notes/internal/util"
+)
+
+// FieldError describes why one field of a request was rejected. Code is the
+// name of the failed rule, such as "required" or "too_short".
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// validationError reports the rejected fields of a request.
+func validationError(fields []FieldError) *APIError {
+	apiErr := newAPIError(http.StatusBadRequest, CodeValidationFailed, "request validation failed")
+	apiErr.Details = fields
+	return apiErr
+}
+
+// fieldErrors describes the failed validation rules of a bound request.
+func fieldErrors(validationErrs validator.ValidationErrors) []FieldError {
+	fields := make([]FieldError, len(validationErrs))
+	for i, fieldErr := range validationErrs {
+		fields[i] = FieldError{
+			Field:   fieldErr.Field(),
+			Code:    fieldErr.Tag(),
+			Message: validationMessage(fieldErr),
+		}
+	}
+	return fields
+}
+
+func validationMessage(fieldErr validator.FieldError) string {
+	isString := fieldErr.Kind() == reflect.String
+
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "alphanum":
+		return "must contain only letters and digits"
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", fieldErr.Param())
+	case "min":
+		if isString {
+			return fmt.Sprintf("must be at least %s characters long", fieldErr.Param())
+		}
+		return fmt.Sprintf("must be at least %s", fieldErr.Param())
+	case "max":
+		if isString {
+			return fmt.Sprintf("must be at most %s characters long", fieldErr.Param())
+		}
+		return fmt.Sprintf("must be at most %s", fieldErr.Param())
+	default:
+		return fmt.Sprintf("failed the %q rule", fieldErr.Tag())
+	}
+}
+
+// passwordTooLongField reports a password longer than the configured hasher
+// can take in full.
+func passwordTooLongField(field string) FieldError {
+	return FieldError{
+		Field:   field,
+		Code:    util.PasswordTooLong,
+		Message: util.ErrPasswordTooLong.Error(),
+	}
+}
+
+// checkPassword answers with field errors for field and returns false if
+// password breaks the password policy for the given account.
+func (server *Server) checkPassword(ctx *gin.Context, field, password, username, email string) bool {
+	violations := server.passwordPolicy.Validate(password, username, email)
+	if len(violations) == 0 {
+		return true
+	}
+
+	fields := make([]FieldError, len(violations))
+	for i, violation := range violations {
+		fields[i] = FieldError{
+			Field:   field,
+			Code:    violation.Code,
+			Message: violation.Message,
+		}
+	}
+	abortWithError(ctx, validationError(fields))
+	return false
+}
+
+var registerFieldNamesOnce sync.Once
+
+// registerFieldNames makes validation errors name fields the way clients
+// send them, by their json, uri or form tag.
+func registerFieldNames() {
+	registerFieldNamesOnce.Do(func() {
+		validate, ok := binding.Validator.Engine().(*validator.Validate)
+		if ok {
+			validate.RegisterTagNameFunc(jsonFieldName)
+		}
+	})
+}
+
+func jsonFieldName(field reflect.StructField) string {
+	for _, key := range []string{"json", "uri", "form"} {
+		name, _, _ := strings.Cut(field.Tag.Get(key), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
@@ -0,0 +1,89 @@
+// Package cache holds the backends of the Store cache: an LRU kept in
+// process, and Redis for servers running more than one instance.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// LRU is a cache kept in process. Once it holds size entries, adding one
+// evicts the least recently used.
+type LRU struct {
+	size int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewLRU creates an LRU holding up to size entries.
+func NewLRU(size int) *LRU {
+	return &LRU{
+		size:    size,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// Get returns the value stored for key, if it has not expired.
+func (cache *LRU) Get(_ context.Context, key string) ([]byte, bool, error) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	element, ok := cache.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	entry := element.Value.(*lruEntry)
+	if time.Now().After(entry.expiresAt) {
+		cache.remove(element)
+		return nil, false, nil
+	}
+
+	cache.order.MoveToFront(element)
+	return entry.value, true, nil
+}
+
+// Set stores value for key for ttl.
+func (cache *LRU) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	expiresAt := time.Now().Add(ttl)
+	if element, ok := cache.entries[key]; ok {
+		entry := element.Value.(*lruEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		cache.order.MoveToFront(element)
+		return nil
+	}
+
+	cache.entries[key] = cache.order.PushFront(&lruEntry{key: key, value: value, expiresAt: expiresAt})
+	for cache.order.Len() > cache.size {
+		cache.remove(cache.order.Back())
+	}
+	return nil
+}
+
+// Len returns the number of entries, including expired ones not evicted yet.
+func (cache *LRU) Len() int {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	return cache.order.Len()
+}
+
+func (cache *LRU) remove(element *list.Element) {
+	cache.order.Remove(element)
+	delete(cache.entries, element.Value.(*lruEntry).key)
+}
@@ -0,0 +1,52 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRU(t *testing.T) {
+	ctx := context.Background()
+	cache := NewLRU(2)
+
+	_, ok, err := cache.Get(ctx, "a")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	require.NoError(t, cache.Set(ctx, "a", []byte("1"), time.Minute))
+	require.NoError(t, cache.Set(ctx, "b", []byte("2"), time.Minute))
+
+	// Reading a makes b the least recently used, so c evicts it.
+	value, ok, err := cache.Get(ctx, "a")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, []byte("1"), value)
+
+	require.NoError(t, cache.Set(ctx, "c", []byte("3"), time.Minute))
+	require.Equal(t, 2, cache.Len())
+
+	_, ok, _ = cache.Get(ctx, "b")
+	require.False(t, ok)
+	_, ok, _ = cache.Get(ctx, "c")
+	require.True(t, ok)
+
+	require.NoError(t, cache.Set(ctx, "a", []byte("4"), time.Minute))
+	value, _, _ = cache.Get(ctx, "a")
+	require.Equal(t, []byte("4"), value)
+}
+
+func TestLRUExpiry(t *testing.T) {
+	ctx := context.Background()
+	cache := NewLRU(10)
+
+	require.NoError(t, cache.Set(ctx, "a", []byte("1"), 10*time.Millisecond))
+	time.Sleep(20 * time.Millisecond)
+
+	_, ok, err := cache.Get(ctx, "a")
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.Zero(t, cache.Len())
+}
@@ -0,0 +1,40 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// keyPrefix keeps the entries of the cache apart from other data in the
+// same Redis database.
+const keyPrefix = "notes:cache:"
+
+// Redis is a cache shared by every instance of the server.
+type Redis struct {
+	client redis.UniversalClient
+}
+
+// NewRedis creates a cache storing its entries with client.
+func NewRedis(client redis.UniversalClient) *Redis {
+	return &Redis{client: client}
+}
+
+// Get returns the value stored for key, if it has not expired.
+func (cache *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := cache.client.Get(ctx, keyPrefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// Set stores value for key for ttl.
+func (cache *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return cache.client.Set(ctx, keyPrefix+key, value, ttl).Err()
+}
@@ -0,0 +1,37 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedis(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	cache := NewRedis(redis.NewClient(&redis.Options{Addr: server.Addr(), MaxRetries: -1}))
+
+	_, ok, err := cache.Get(ctx, "a")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	require.NoError(t, cache.Set(ctx, "a", []byte("1"), time.Minute))
+	value, ok, err := cache.Get(ctx, "a")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, []byte("1"), value)
+	require.True(t, server.Exists(keyPrefix+"a"))
+
+	server.FastForward(2 * time.Minute)
+	_, ok, err = cache.Get(ctx, "a")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	server.Close()
+	_, _, err = cache.Get(ctx, "a")
+	require.Error(t, err)
+}
@@ -9,6 +9,7 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
 	Database "github.com/nilesh0729/Notes/internal/db/Result"
 )
 
@@ -50,6 +51,125 @@ func (mr *MockStoreMockRecorder) AddTagToNote(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTagToNote", reflect.TypeOf((*MockStore)(nil).AddTagToNote), arg0, arg1)
 }
 
+// BlockUserSessions mocks base method.
+func (m *MockStore) BlockUserSessions(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockUserSessions", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BlockUserSessions indicates an expected call of BlockUserSessions.
+func (mr *MockStoreMockRecorder) BlockUserSessions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockUserSessions", reflect.TypeOf((*MockStore)(nil).BlockUserSessions), arg0, arg1)
+}
+
+// ChangeEmailTx mocks base method.
+func (m *MockStore) ChangeEmailTx(arg0 context.Context, arg1 Database.ChangeEmailTxParams) (Database.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeEmailTx", arg0, arg1)
+	ret0, _ := ret[0].(Database.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangeEmailTx indicates an expected call of ChangeEmailTx.
+func (mr *MockStoreMockRecorder) ChangeEmailTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeEmailTx", reflect.TypeOf((*MockStore)(nil).ChangeEmailTx), arg0, arg1)
+}
+
+// ChangePasswordTx mocks base method.
+func (m *MockStore) ChangePasswordTx(arg0 context.Context, arg1 Database.ChangePasswordTxParams) (Database.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePasswordTx", arg0, arg1)
+	ret0, _ := ret[0].(Database.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangePasswordTx indicates an expected call of ChangePasswordTx.
+func (mr *MockStoreMockRecorder) ChangePasswordTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePasswordTx", reflect.TypeOf((*MockStore)(nil).ChangePasswordTx), arg0, arg1)
+}
+
+// ClaimUserTokenAttempt mocks base method.
+func (m *MockStore) ClaimUserTokenAttempt(arg0 context.Context, arg1 Database.ClaimUserTokenAttemptParams) (Database.UserToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimUserTokenAttempt", arg0, arg1)
+	ret0, _ := ret[0].(Database.UserToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimUserTokenAttempt indicates an expected call of ClaimUserTokenAttempt.
+func (mr *MockStoreMockRecorder) ClaimUserTokenAttempt(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimUserTokenAttempt", reflect.TypeOf((*MockStore)(nil).ClaimUserTokenAttempt), arg0, arg1)
+}
+
+// ConsumeOIDCState mocks base method.
+func (m *MockStore) ConsumeOIDCState(arg0 context.Context, arg1 []byte) (Database.OidcState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeOIDCState", arg0, arg1)
+	ret0, _ := ret[0].(Database.OidcState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeOIDCState indicates an expected call of ConsumeOIDCState.
+func (mr *MockStoreMockRecorder) ConsumeOIDCState(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeOIDCState", reflect.TypeOf((*MockStore)(nil).ConsumeOIDCState), arg0, arg1)
+}
+
+// ConsumeRecoveryCode mocks base method.
+func (m *MockStore) ConsumeRecoveryCode(arg0 context.Context, arg1 Database.ConsumeRecoveryCodeParams) (Database.RecoveryCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeRecoveryCode", arg0, arg1)
+	ret0, _ := ret[0].(Database.RecoveryCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeRecoveryCode indicates an expected call of ConsumeRecoveryCode.
+func (mr *MockStoreMockRecorder) ConsumeRecoveryCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeRecoveryCode", reflect.TypeOf((*MockStore)(nil).ConsumeRecoveryCode), arg0, arg1)
+}
+
+// ConsumeUserToken mocks base method.
+func (m *MockStore) ConsumeUserToken(arg0 context.Context, arg1 Database.ConsumeUserTokenParams) (Database.UserToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeUserToken", arg0, arg1)
+	ret0, _ := ret[0].(Database.UserToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeUserToken indicates an expected call of ConsumeUserToken.
+func (mr *MockStoreMockRecorder) ConsumeUserToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeUserToken", reflect.TypeOf((*MockStore)(nil).ConsumeUserToken), arg0, arg1)
+}
+
+// CreateAPIKey mocks base method.
+func (m *MockStore) CreateAPIKey(arg0 context.Context, arg1 Database.CreateAPIKeyParams) (Database.ApiKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", arg0, arg1)
+	ret0, _ := ret[0].(Database.ApiKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockStoreMockRecorder) CreateAPIKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockStore)(nil).CreateAPIKey), arg0, arg1)
+}
+
 // CreateNote mocks base method.
 func (m *MockStore) CreateNote(arg0 context.Context, arg1 Database.CreateNoteParams) (Database.Note, error) {
 	m.ctrl.T.Helper()
@@ -65,6 +185,65 @@ func (mr *MockStoreMockRecorder) CreateNote(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNote", reflect.TypeOf((*MockStore)(nil).CreateNote), arg0, arg1)
 }
 
+// CreateOIDCState mocks base method.
+func (m *MockStore) CreateOIDCState(arg0 context.Context, arg1 Database.CreateOIDCStateParams) (Database.OidcState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOIDCState", arg0, arg1)
+	ret0, _ := ret[0].(Database.OidcState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOIDCState indicates an expected call of CreateOIDCState.
+func (mr *MockStoreMockRecorder) CreateOIDCState(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOIDCState", reflect.TypeOf((*MockStore)(nil).CreateOIDCState), arg0, arg1)
+}
+
+// CreateOIDCUserTx mocks base method.
+func (m *MockStore) CreateOIDCUserTx(arg0 context.Context, arg1 Database.CreateOIDCUserTxParams) (Database.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOIDCUserTx", arg0, arg1)
+	ret0, _ := ret[0].(Database.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOIDCUserTx indicates an expected call of CreateOIDCUserTx.
+func (mr *MockStoreMockRecorder) CreateOIDCUserTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOIDCUserTx", reflect.TypeOf((*MockStore)(nil).CreateOIDCUserTx), arg0, arg1)
+}
+
+// CreateRecoveryCode mocks base method.
+func (m *MockStore) CreateRecoveryCode(arg0 context.Context, arg1 Database.CreateRecoveryCodeParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRecoveryCode", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateRecoveryCode indicates an expected call of CreateRecoveryCode.
+func (mr *MockStoreMockRecorder) CreateRecoveryCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRecoveryCode", reflect.TypeOf((*MockStore)(nil).CreateRecoveryCode), arg0, arg1)
+}
+
+// CreateSession mocks base method.
+func (m *MockStore) CreateSession(arg0 context.Context, arg1 Database.CreateSessionParams) (Database.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSession", arg0, arg1)
+	ret0, _ := ret[0].(Database.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSession indicates an expected call of CreateSession.
+func (mr *MockStoreMockRecorder) CreateSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockStore)(nil).CreateSession), arg0, arg1)
+}
+
 // CreateTags mocks base method.
 func (m *MockStore) CreateTags(arg0 context.Context, arg1 Database.CreateTagsParams) (Database.Tag, error) {
 	m.ctrl.T.Helper()
@@ -95,6 +274,51 @@ func (mr *MockStoreMockRecorder) CreateUser(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockStore)(nil).CreateUser), arg0, arg1)
 }
 
+// CreateUserIdentity mocks base method.
+func (m *MockStore) CreateUserIdentity(arg0 context.Context, arg1 Database.CreateUserIdentityParams) (Database.UserIdentity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUserIdentity", arg0, arg1)
+	ret0, _ := ret[0].(Database.UserIdentity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUserIdentity indicates an expected call of CreateUserIdentity.
+func (mr *MockStoreMockRecorder) CreateUserIdentity(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserIdentity", reflect.TypeOf((*MockStore)(nil).CreateUserIdentity), arg0, arg1)
+}
+
+// CreateUserToken mocks base method.
+func (m *MockStore) CreateUserToken(arg0 context.Context, arg1 Database.CreateUserTokenParams) (Database.UserToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUserToken", arg0, arg1)
+	ret0, _ := ret[0].(Database.UserToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUserToken indicates an expected call of CreateUserToken.
+func (mr *MockStoreMockRecorder) CreateUserToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserToken", reflect.TypeOf((*MockStore)(nil).CreateUserToken), arg0, arg1)
+}
+
+// DeleteAPIKey mocks base method.
+func (m *MockStore) DeleteAPIKey(arg0 context.Context, arg1 Database.DeleteAPIKeyParams) (Database.ApiKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAPIKey", arg0, arg1)
+	ret0, _ := ret[0].(Database.ApiKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteAPIKey indicates an expected call of DeleteAPIKey.
+func (mr *MockStoreMockRecorder) DeleteAPIKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAPIKey", reflect.TypeOf((*MockStore)(nil).DeleteAPIKey), arg0, arg1)
+}
+
 // DeleteNote mocks base method.
 func (m *MockStore) DeleteNote(arg0 context.Context, arg1 int32) error {
 	m.ctrl.T.Helper()
@@ -109,46 +333,120 @@ func (mr *MockStoreMockRecorder) DeleteNote(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteNote", reflect.TypeOf((*MockStore)(nil).DeleteNote), arg0, arg1)
 }
 
-// DeleteNoteTagsByNoteId mocks base method.
-func (m *MockStore) DeleteNoteTagsByNoteId(arg0 context.Context, arg1 int32) error {
+// DeleteRecoveryCodes mocks base method.
+func (m *MockStore) DeleteRecoveryCodes(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteNoteTagsByNoteId", arg0, arg1)
+	ret := m.ctrl.Call(m, "DeleteRecoveryCodes", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteNoteTagsByNoteId indicates an expected call of DeleteNoteTagsByNoteId.
-func (mr *MockStoreMockRecorder) DeleteNoteTagsByNoteId(arg0, arg1 interface{}) *gomock.Call {
+// DeleteRecoveryCodes indicates an expected call of DeleteRecoveryCodes.
+func (mr *MockStoreMockRecorder) DeleteRecoveryCodes(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteNoteTagsByNoteId", reflect.TypeOf((*MockStore)(nil).DeleteNoteTagsByNoteId), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRecoveryCodes", reflect.TypeOf((*MockStore)(nil).DeleteRecoveryCodes), arg0, arg1)
 }
 
-// DeleteNoteTagsByTagId mocks base method.
-func (m *MockStore) DeleteNoteTagsByTagId(arg0 context.Context, arg1 int32) error {
+// DeleteTag mocks base method.
+func (m *MockStore) DeleteTag(arg0 context.Context, arg1 int32) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteNoteTagsByTagId", arg0, arg1)
+	ret := m.ctrl.Call(m, "DeleteTag", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteNoteTagsByTagId indicates an expected call of DeleteNoteTagsByTagId.
-func (mr *MockStoreMockRecorder) DeleteNoteTagsByTagId(arg0, arg1 interface{}) *gomock.Call {
+// DeleteTag indicates an expected call of DeleteTag.
+func (mr *MockStoreMockRecorder) DeleteTag(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteNoteTagsByTagId", reflect.TypeOf((*MockStore)(nil).DeleteNoteTagsByTagId), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTag", reflect.TypeOf((*MockStore)(nil).DeleteTag), arg0, arg1)
 }
 
-// DeleteTag mocks base method.
-func (m *MockStore) DeleteTag(arg0 context.Context, arg1 int32) error {
+// DeleteUserAPIKeys mocks base method.
+func (m *MockStore) DeleteUserAPIKeys(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteTag", arg0, arg1)
+	ret := m.ctrl.Call(m, "DeleteUserAPIKeys", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteTag indicates an expected call of DeleteTag.
-func (mr *MockStoreMockRecorder) DeleteTag(arg0, arg1 interface{}) *gomock.Call {
+// DeleteUserAPIKeys indicates an expected call of DeleteUserAPIKeys.
+func (mr *MockStoreMockRecorder) DeleteUserAPIKeys(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTag", reflect.TypeOf((*MockStore)(nil).DeleteTag), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserAPIKeys", reflect.TypeOf((*MockStore)(nil).DeleteUserAPIKeys), arg0, arg1)
+}
+
+// DeleteUserTokens mocks base method.
+func (m *MockStore) DeleteUserTokens(arg0 context.Context, arg1 Database.DeleteUserTokensParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserTokens", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserTokens indicates an expected call of DeleteUserTokens.
+func (mr *MockStoreMockRecorder) DeleteUserTokens(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserTokens", reflect.TypeOf((*MockStore)(nil).DeleteUserTokens), arg0, arg1)
+}
+
+// EnableTOTP mocks base method.
+func (m *MockStore) EnableTOTP(arg0 context.Context, arg1 string) (Database.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableTOTP", arg0, arg1)
+	ret0, _ := ret[0].(Database.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnableTOTP indicates an expected call of EnableTOTP.
+func (mr *MockStoreMockRecorder) EnableTOTP(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableTOTP", reflect.TypeOf((*MockStore)(nil).EnableTOTP), arg0, arg1)
+}
+
+// EnableTOTPTx mocks base method.
+func (m *MockStore) EnableTOTPTx(arg0 context.Context, arg1 Database.EnableTOTPTxParams) (Database.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableTOTPTx", arg0, arg1)
+	ret0, _ := ret[0].(Database.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnableTOTPTx indicates an expected call of EnableTOTPTx.
+func (mr *MockStoreMockRecorder) EnableTOTPTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableTOTPTx", reflect.TypeOf((*MockStore)(nil).EnableTOTPTx), arg0, arg1)
+}
+
+// GetAPIKeyByHash mocks base method.
+func (m *MockStore) GetAPIKeyByHash(arg0 context.Context, arg1 []byte) (Database.ApiKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeyByHash", arg0, arg1)
+	ret0, _ := ret[0].(Database.ApiKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeyByHash indicates an expected call of GetAPIKeyByHash.
+func (mr *MockStoreMockRecorder) GetAPIKeyByHash(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeyByHash", reflect.TypeOf((*MockStore)(nil).GetAPIKeyByHash), arg0, arg1)
+}
+
+// GetActiveUserToken mocks base method.
+func (m *MockStore) GetActiveUserToken(arg0 context.Context, arg1 Database.GetActiveUserTokenParams) (Database.UserToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveUserToken", arg0, arg1)
+	ret0, _ := ret[0].(Database.UserToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveUserToken indicates an expected call of GetActiveUserToken.
+func (mr *MockStoreMockRecorder) GetActiveUserToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveUserToken", reflect.TypeOf((*MockStore)(nil).GetActiveUserToken), arg0, arg1)
 }
 
 // GetNoteById mocks base method.
@@ -181,6 +479,21 @@ func (mr *MockStoreMockRecorder) GetNotesForTag(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotesForTag", reflect.TypeOf((*MockStore)(nil).GetNotesForTag), arg0, arg1)
 }
 
+// GetSession mocks base method.
+func (m *MockStore) GetSession(arg0 context.Context, arg1 uuid.UUID) (Database.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSession", arg0, arg1)
+	ret0, _ := ret[0].(Database.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSession indicates an expected call of GetSession.
+func (mr *MockStoreMockRecorder) GetSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockStore)(nil).GetSession), arg0, arg1)
+}
+
 // GetTag mocks base method.
 func (m *MockStore) GetTag(arg0 context.Context, arg1 int32) (Database.Tag, error) {
 	m.ctrl.T.Helper()
@@ -226,6 +539,66 @@ func (mr *MockStoreMockRecorder) GetUser(arg0, arg1 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockStore)(nil).GetUser), arg0, arg1)
 }
 
+// GetUserIdentity mocks base method.
+func (m *MockStore) GetUserIdentity(arg0 context.Context, arg1 Database.GetUserIdentityParams) (Database.UserIdentity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserIdentity", arg0, arg1)
+	ret0, _ := ret[0].(Database.UserIdentity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserIdentity indicates an expected call of GetUserIdentity.
+func (mr *MockStoreMockRecorder) GetUserIdentity(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserIdentity", reflect.TypeOf((*MockStore)(nil).GetUserIdentity), arg0, arg1)
+}
+
+// GetUserQuota mocks base method.
+func (m *MockStore) GetUserQuota(arg0 context.Context, arg1 string) (Database.UserQuota, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserQuota", arg0, arg1)
+	ret0, _ := ret[0].(Database.UserQuota)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserQuota indicates an expected call of GetUserQuota.
+func (mr *MockStoreMockRecorder) GetUserQuota(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserQuota", reflect.TypeOf((*MockStore)(nil).GetUserQuota), arg0, arg1)
+}
+
+// GetUserUsage mocks base method.
+func (m *MockStore) GetUserUsage(arg0 context.Context, arg1 string) (Database.GetUserUsageRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserUsage", arg0, arg1)
+	ret0, _ := ret[0].(Database.GetUserUsageRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserUsage indicates an expected call of GetUserUsage.
+func (mr *MockStoreMockRecorder) GetUserUsage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserUsage", reflect.TypeOf((*MockStore)(nil).GetUserUsage), arg0, arg1)
+}
+
+// ListAPIKeys mocks base method.
+func (m *MockStore) ListAPIKeys(arg0 context.Context, arg1 string) ([]Database.ApiKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAPIKeys", arg0, arg1)
+	ret0, _ := ret[0].([]Database.ApiKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAPIKeys indicates an expected call of ListAPIKeys.
+func (mr *MockStoreMockRecorder) ListAPIKeys(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAPIKeys", reflect.TypeOf((*MockStore)(nil).ListAPIKeys), arg0, arg1)
+}
+
 // ListNotes mocks base method.
 func (m *MockStore) ListNotes(arg0 context.Context, arg1 Database.ListNotesParams) ([]Database.Note, error) {
 	m.ctrl.T.Helper()
@@ -256,6 +629,96 @@ func (mr *MockStoreMockRecorder) ListTags(arg0, arg1 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTags", reflect.TypeOf((*MockStore)(nil).ListTags), arg0, arg1)
 }
 
+// ListUsersByEmail mocks base method.
+func (m *MockStore) ListUsersByEmail(arg0 context.Context, arg1 string) ([]Database.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsersByEmail", arg0, arg1)
+	ret0, _ := ret[0].([]Database.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUsersByEmail indicates an expected call of ListUsersByEmail.
+func (mr *MockStoreMockRecorder) ListUsersByEmail(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsersByEmail", reflect.TypeOf((*MockStore)(nil).ListUsersByEmail), arg0, arg1)
+}
+
+// MarkEmailVerified mocks base method.
+func (m *MockStore) MarkEmailVerified(arg0 context.Context, arg1 Database.MarkEmailVerifiedParams) (Database.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkEmailVerified", arg0, arg1)
+	ret0, _ := ret[0].(Database.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkEmailVerified indicates an expected call of MarkEmailVerified.
+func (mr *MockStoreMockRecorder) MarkEmailVerified(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkEmailVerified", reflect.TypeOf((*MockStore)(nil).MarkEmailVerified), arg0, arg1)
+}
+
+// MigrationVersion mocks base method.
+func (m *MockStore) MigrationVersion(arg0 context.Context) (int64, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MigrationVersion", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// MigrationVersion indicates an expected call of MigrationVersion.
+func (mr *MockStoreMockRecorder) MigrationVersion(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MigrationVersion", reflect.TypeOf((*MockStore)(nil).MigrationVersion), arg0)
+}
+
+// Ping mocks base method.
+func (m *MockStore) Ping(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockStoreMockRecorder) Ping(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockStore)(nil).Ping), arg0)
+}
+
+// RecordTOTPStep mocks base method.
+func (m *MockStore) RecordTOTPStep(arg0 context.Context, arg1 Database.RecordTOTPStepParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordTOTPStep", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordTOTPStep indicates an expected call of RecordTOTPStep.
+func (mr *MockStoreMockRecorder) RecordTOTPStep(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordTOTPStep", reflect.TypeOf((*MockStore)(nil).RecordTOTPStep), arg0, arg1)
+}
+
+// RehashUserPassword mocks base method.
+func (m *MockStore) RehashUserPassword(arg0 context.Context, arg1 Database.RehashUserPasswordParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RehashUserPassword", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RehashUserPassword indicates an expected call of RehashUserPassword.
+func (mr *MockStoreMockRecorder) RehashUserPassword(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RehashUserPassword", reflect.TypeOf((*MockStore)(nil).RehashUserPassword), arg0, arg1)
+}
+
 // RemoveTagFromNote mocks base method.
 func (m *MockStore) RemoveTagFromNote(arg0 context.Context, arg1 Database.RemoveTagFromNoteParams) error {
 	m.ctrl.T.Helper()
@@ -270,6 +733,36 @@ func (mr *MockStoreMockRecorder) RemoveTagFromNote(arg0, arg1 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveTagFromNote", reflect.TypeOf((*MockStore)(nil).RemoveTagFromNote), arg0, arg1)
 }
 
+// ResetPasswordTx mocks base method.
+func (m *MockStore) ResetPasswordTx(arg0 context.Context, arg1 Database.ResetPasswordTxParams) (Database.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPasswordTx", arg0, arg1)
+	ret0, _ := ret[0].(Database.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResetPasswordTx indicates an expected call of ResetPasswordTx.
+func (mr *MockStoreMockRecorder) ResetPasswordTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPasswordTx", reflect.TypeOf((*MockStore)(nil).ResetPasswordTx), arg0, arg1)
+}
+
+// ResetUserPassword mocks base method.
+func (m *MockStore) ResetUserPassword(arg0 context.Context, arg1 Database.ResetUserPasswordParams) (Database.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetUserPassword", arg0, arg1)
+	ret0, _ := ret[0].(Database.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResetUserPassword indicates an expected call of ResetUserPassword.
+func (mr *MockStoreMockRecorder) ResetUserPassword(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetUserPassword", reflect.TypeOf((*MockStore)(nil).ResetUserPassword), arg0, arg1)
+}
+
 // SearchNotes mocks base method.
 func (m *MockStore) SearchNotes(arg0 context.Context, arg1 Database.SearchNotesParams) ([]Database.Note, error) {
 	m.ctrl.T.Helper()
@@ -285,6 +778,35 @@ func (mr *MockStoreMockRecorder) SearchNotes(arg0, arg1 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchNotes", reflect.TypeOf((*MockStore)(nil).SearchNotes), arg0, arg1)
 }
 
+// SetTOTPSecret mocks base method.
+func (m *MockStore) SetTOTPSecret(arg0 context.Context, arg1 Database.SetTOTPSecretParams) (Database.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTOTPSecret", arg0, arg1)
+	ret0, _ := ret[0].(Database.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetTOTPSecret indicates an expected call of SetTOTPSecret.
+func (mr *MockStoreMockRecorder) SetTOTPSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTOTPSecret", reflect.TypeOf((*MockStore)(nil).SetTOTPSecret), arg0, arg1)
+}
+
+// TouchAPIKey mocks base method.
+func (m *MockStore) TouchAPIKey(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchAPIKey", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchAPIKey indicates an expected call of TouchAPIKey.
+func (mr *MockStoreMockRecorder) TouchAPIKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchAPIKey", reflect.TypeOf((*MockStore)(nil).TouchAPIKey), arg0, arg1)
+}
+
 // UpdateNote mocks base method.
 func (m *MockStore) UpdateNote(arg0 context.Context, arg1 Database.UpdateNoteParams) (Database.Note, error) {
 	m.ctrl.T.Helper()
@@ -314,3 +836,63 @@ func (mr *MockStoreMockRecorder) UpdateTag(arg0, arg1 interface{}) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTag", reflect.TypeOf((*MockStore)(nil).UpdateTag), arg0, arg1)
 }
+
+// UpdateUserEmail mocks base method.
+func (m *MockStore) UpdateUserEmail(arg0 context.Context, arg1 Database.UpdateUserEmailParams) (Database.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserEmail", arg0, arg1)
+	ret0, _ := ret[0].(Database.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserEmail indicates an expected call of UpdateUserEmail.
+func (mr *MockStoreMockRecorder) UpdateUserEmail(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserEmail", reflect.TypeOf((*MockStore)(nil).UpdateUserEmail), arg0, arg1)
+}
+
+// UpdateUserPassword mocks base method.
+func (m *MockStore) UpdateUserPassword(arg0 context.Context, arg1 Database.UpdateUserPasswordParams) (Database.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserPassword", arg0, arg1)
+	ret0, _ := ret[0].(Database.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserPassword indicates an expected call of UpdateUserPassword.
+func (mr *MockStoreMockRecorder) UpdateUserPassword(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserPassword", reflect.TypeOf((*MockStore)(nil).UpdateUserPassword), arg0, arg1)
+}
+
+// UpsertUserQuota mocks base method.
+func (m *MockStore) UpsertUserQuota(arg0 context.Context, arg1 Database.UpsertUserQuotaParams) (Database.UserQuota, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertUserQuota", arg0, arg1)
+	ret0, _ := ret[0].(Database.UserQuota)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertUserQuota indicates an expected call of UpsertUserQuota.
+func (mr *MockStoreMockRecorder) UpsertUserQuota(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertUserQuota", reflect.TypeOf((*MockStore)(nil).UpsertUserQuota), arg0, arg1)
+}
+
+// VerifyEmailTx mocks base method.
+func (m *MockStore) VerifyEmailTx(arg0 context.Context, arg1 Database.VerifyEmailTxParams) (Database.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmailTx", arg0, arg1)
+	ret0, _ := ret[0].(Database.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyEmailTx indicates an expected call of VerifyEmailTx.
+func (mr *MockStoreMockRecorder) VerifyEmailTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmailTx", reflect.TypeOf((*MockStore)(nil).VerifyEmailTx), arg0, arg1)
+}
@@ -0,0 +1,434 @@
+package Database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Cache stores values for a while. Entries may disappear at any time.
+type Cache interface {
+	// Get returns the value stored for key, and whether there was one.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores value for key for ttl.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// CacheStore returns a Store that keeps the notes, the tags of notes and
+// the tag lists it reads from store in cache for ttl. user returns the user
+// a call is made for, or "" if there is none; reads without a user are not
+// cached.
+//
+// Entries are kept per owner, under a generation that every write to the
+// notes or tags of the owner replaces, so that a write invalidates all of
+// the owner's entries at once and no stale entry can be read back. Reads
+// served by a read replica (see RouteToReplica) are not cached, since the
+// replica may not have caught up with the write that started the current
+// generation yet.
+func CacheStore(store Store, cache Cache, ttl time.Duration, user func(ctx context.Context) string) Store {
+	return &cachedStore{next: store, cache: cache, ttl: ttl, user: user}
+}
+
+// cachedStore does not embed Store, so a new query fails to compile until
+// it is known whether it invalidates the cache.
+type cachedStore struct {
+	next  Store
+	cache Cache
+	ttl   time.Duration
+	user  func(ctx context.Context) string
+}
+
+var _ Store = (*cachedStore)(nil)
+
+func generationKey(owner string) string {
+	return "generation/" + owner
+}
+
+// generation returns the current generation of the entries of owner.
+func (store *cachedStore) generation(ctx context.Context, owner string) (string, error) {
+	generation, ok, err := store.cache.Get(ctx, generationKey(owner))
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		return string(generation), nil
+	}
+	return store.newGeneration(ctx, owner)
+}
+
+// newGeneration starts a new generation of the entries of owner. A
+// generation that was evicted is never reused, as the clock has moved on.
+func (store *cachedStore) newGeneration(ctx context.Context, owner string) (string, error) {
+	generation := strconv.FormatInt(time.Now().UnixNano(), 36)
+	err := store.cache.Set(ctx, generationKey(owner), []byte(generation), store.ttl)
+	return generation, err
+}
+
+// invalidate drops the entries of owners.
+func (store *cachedStore) invalidate(ctx context.Context, owners ...string) {
+	for i, owner := range owners {
+		if owner == "" || slices.Contains(owners[:i], owner) {
+			continue
+		}
+		_, err := store.newGeneration(ctx, owner)
+		if err != nil {
+			slog.WarnContext(ctx, "cannot invalidate cache", slog.String("owner", owner), slog.Any("error", err))
+		}
+	}
+}
+
+// noteOwner returns the owner of a note about to be changed. The API only
+// changes notes of the user it is called for, so it is looked up only for
+// calls made without one.
+func (store *cachedStore) noteOwner(ctx context.Context, noteID int32) string {
+	if username := store.user(ctx); username != "" {
+		return username
+	}
+	note, err := store.next.GetNoteById(ctx, noteID)
+	if err != nil {
+		return ""
+	}
+	return note.Owner
+}
+
+// tagOwner is noteOwner for tags.
+func (store *cachedStore) tagOwner(ctx context.Context, tagID int32) string {
+	if username := store.user(ctx); username != "" {
+		return username
+	}
+	tag, err := store.next.GetTag(ctx, tagID)
+	if err != nil {
+		return ""
+	}
+	return tag.Owner
+}
+
+// readThrough returns the entry of owner at key, or loads it and caches it
+// if keep allows and it did not come from a replica. The cache failing only
+// makes the read slower.
+func readThrough[T any](ctx context.Context, store *cachedStore, owner, key string, load func(ctx context.Context) (T, error), keep func(T) bool) (T, error) {
+	if owner == "" {
+		return load(ctx)
+	}
+
+	generation, err := store.generation(ctx, owner)
+	if err != nil {
+		slog.WarnContext(ctx, "cannot read cache", slog.Any("error", err))
+		return load(ctx)
+	}
+	key = fmt.Sprintf("%s/%s/%s", owner, generation, key)
+
+	value, ok, err := store.cache.Get(ctx, key)
+	if err != nil {
+		slog.WarnContext(ctx, "cannot read cache", slog.Any("error", err))
+	}
+	var res T
+	if ok && json.Unmarshal(value, &res) == nil {
+		return res, nil
+	}
+
+	loadCtx, reads := trackReplicaReads(ctx)
+	res, err = load(loadCtx)
+	if err != nil || !keep(res) || reads.served {
+		return res, err
+	}
+
+	value, err = json.Marshal(res)
+	if err == nil {
+		err = store.cache.Set(ctx, key, value, store.ttl)
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "cannot write cache", slog.Any("error", err))
+	}
+	return res, nil
+}
+
+func keepAll[T any](T) bool {
+	return true
+}
+
+func (store *cachedStore) GetNoteById(ctx context.Context, noteID int32) (Note, error) {
+	owner := store.user(ctx)
+	return readThrough(ctx, store, owner, fmt.Sprintf("note/%d", noteID),
+		func(ctx context.Context) (Note, error) { return store.next.GetNoteById(ctx, noteID) },
+		// Someone else's note is not cached, as its owner's writes would
+		// not invalidate it.
+		func(note Note) bool { return note.Owner == owner },
+	)
+}
+
+func (store *cachedStore) GetTagsForNote(ctx context.Context, noteID int32) ([]GetTagsForNoteRow, error) {
+	return readThrough(ctx, store, store.user(ctx), fmt.Sprintf("note/%d/tags", noteID),
+		func(ctx context.Context) ([]GetTagsForNoteRow, error) { return store.next.GetTagsForNote(ctx, noteID) },
+		keepAll,
+	)
+}
+
+func (store *cachedStore) ListTags(ctx context.Context, arg ListTagsParams) ([]Tag, error) {
+	return readThrough(ctx, store, arg.Owner, fmt.Sprintf("tags/%d/%d", arg.TagID, arg.Limit),
+		func(ctx context.Context) ([]Tag, error) { return store.next.ListTags(ctx, arg) },
+		keepAll,
+	)
+}
+
+func (store *cachedStore) CreateNote(ctx context.Context, arg CreateNoteParams) (Note, error) {
+	note, err := store.next.CreateNote(ctx, arg)
+	if err == nil {
+		store.invalidate(ctx, arg.Owner)
+	}
+	return note, err
+}
+
+func (store *cachedStore) UpdateNote(ctx context.Context, arg UpdateNoteParams) (Note, error) {
+	note, err := store.next.UpdateNote(ctx, arg)
+	if err == nil {
+		store.invalidate(ctx, store.user(ctx), note.Owner)
+	}
+	return note, err
+}
+
+func (store *cachedStore) DeleteNote(ctx context.Context, noteID int32) error {
+	owner := store.noteOwner(ctx, noteID)
+	err := store.next.DeleteNote(ctx, noteID)
+	if err == nil {
+		store.invalidate(ctx, owner)
+	}
+	return err
+}
+
+func (store *cachedStore) CreateTags(ctx context.Context, arg CreateTagsParams) (Tag, error) {
+	tag, err := store.next.CreateTags(ctx, arg)
+	if err == nil {
+		store.invalidate(ctx, arg.Owner)
+	}
+	return tag, err
+}
+
+func (store *cachedStore) UpdateTag(ctx context.Context, arg UpdateTagParams) (Tag, error) {
+	tag, err := store.next.UpdateTag(ctx, arg)
+	if err == nil {
+		store.invalidate(ctx, store.user(ctx), tag.Owner)
+	}
+	return tag, err
+}
+
+func (store *cachedStore) DeleteTag(ctx context.Context, tagID int32) error {
+	owner := store.tagOwner(ctx, tagID)
+	err := store.next.DeleteTag(ctx, tagID)
+	if err == nil {
+		store.invalidate(ctx, owner)
+	}
+	return err
+}
+
+func (store *cachedStore) AddTagToNote(ctx context.Context, arg AddTagToNoteParams) (NoteTag, error) {
+	owner := store.noteOwner(ctx, arg.NoteID)
+	noteTag, err := store.next.AddTagToNote(ctx, arg)
+	if err == nil {
+		store.invalidate(ctx, owner)
+	}
+	return noteTag, err
+}
+
+func (store *cachedStore) RemoveTagFromNote(ctx context.Context, arg RemoveTagFromNoteParams) error {
+	owner := store.noteOwner(ctx, arg.NoteID)
+	err := store.next.RemoveTagFromNote(ctx, arg)
+	if err == nil {
+		store.invalidate(ctx, owner)
+	}
+	return err
+}
+
+func (store *cachedStore) BlockUserSessions(ctx context.Context, username string) error {
+	return store.next.BlockUserSessions(ctx, username)
+}
+
+func (store *cachedStore) ConsumeOIDCState(ctx context.Context, stateHash []byte) (OidcState, error) {
+	return store.next.ConsumeOIDCState(ctx, stateHash)
+}
+
+func (store *cachedStore) ConsumeRecoveryCode(ctx context.Context, arg ConsumeRecoveryCodeParams) (RecoveryCode, error) {
+	return store.next.ConsumeRecoveryCode(ctx, arg)
+}
+
+func (store *cachedStore) ClaimUserTokenAttempt(ctx context.Context, arg ClaimUserTokenAttemptParams) (UserToken, error) {
+	return store.next.ClaimUserTokenAttempt(ctx, arg)
+}
+
+func (store *cachedStore) ConsumeUserToken(ctx context.Context, arg ConsumeUserTokenParams) (UserToken, error) {
+	return store.next.ConsumeUserToken(ctx, arg)
+}
+
+func (store *cachedStore) CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error) {
+	return store.next.CreateAPIKey(ctx, arg)
+}
+
+func (store *cachedStore) CreateOIDCState(ctx context.Context, arg CreateOIDCStateParams) (OidcState, error) {
+	return store.next.CreateOIDCState(ctx, arg)
+}
+
+func (store *cachedStore) CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) error {
+	return store.next.CreateRecoveryCode(ctx, arg)
+}
+
+func (store *cachedStore) CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error) {
+	return store.next.CreateSession(ctx, arg)
+}
+
+func (store *cachedStore) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
+	return store.next.CreateUser(ctx, arg)
+}
+
+func (store *cachedStore) CreateUserIdentity(ctx context.Context, arg CreateUserIdentityParams) (UserIdentity, error) {
+	return store.next.CreateUserIdentity(ctx, arg)
+}
+
+func (store *cachedStore) CreateUserToken(ctx context.Context, arg CreateUserTokenParams) (UserToken, error) {
+	return store.next.CreateUserToken(ctx, arg)
+}
+
+func (store *cachedStore) DeleteAPIKey(ctx context.Context, arg DeleteAPIKeyParams) (ApiKey, error) {
+	return store.next.DeleteAPIKey(ctx, arg)
+}
+
+func (store *cachedStore) DeleteUserAPIKeys(ctx context.Context, username string) error {
+	return store.next.DeleteUserAPIKeys(ctx, username)
+}
+
+func (store *cachedStore) DeleteUserTokens(ctx context.Context, arg DeleteUserTokensParams) error {
+	return store.next.DeleteUserTokens(ctx, arg)
+}
+
+func (store *cachedStore) DeleteRecoveryCodes(ctx context.Context, username string) error {
+	return store.next.DeleteRecoveryCodes(ctx, username)
+}
+
+func (store *cachedStore) EnableTOTP(ctx context.Context, username string) (User, error) {
+	return store.next.EnableTOTP(ctx, username)
+}
+
+func (store *cachedStore) GetAPIKeyByHash(ctx context.Context, keyHash []byte) (ApiKey, error) {
+	return store.next.GetAPIKeyByHash(ctx, keyHash)
+}
+
+func (store *cachedStore) GetActiveUserToken(ctx context.Context, arg GetActiveUserTokenParams) (UserToken, error) {
+	return store.next.GetActiveUserToken(ctx, arg)
+}
+
+func (store *cachedStore) GetNotesForTag(ctx context.Context, arg GetNotesForTagParams) ([]GetNotesForTagRow, error) {
+	return store.next.GetNotesForTag(ctx, arg)
+}
+
+func (store *cachedStore) GetSession(ctx context.Context, id uuid.UUID) (Session, error) {
+	return store.next.GetSession(ctx, id)
+}
+
+func (store *cachedStore) GetTag(ctx context.Context, tagID int32) (Tag, error) {
+	return store.next.GetTag(ctx, tagID)
+}
+
+func (store *cachedStore) GetUser(ctx context.Context, username string) (User, error) {
+	return store.next.GetUser(ctx, username)
+}
+
+func (store *cachedStore) GetUserIdentity(ctx context.Context, arg GetUserIdentityParams) (UserIdentity, error) {
+	return store.next.GetUserIdentity(ctx, arg)
+}
+
+func (store *cachedStore) GetUserQuota(ctx context.Context, username string) (UserQuota, error) {
+	return store.next.GetUserQuota(ctx, username)
+}
+
+func (store *cachedStore) GetUserUsage(ctx context.Context, username string) (GetUserUsageRow, error) {
+	return store.next.GetUserUsage(ctx, username)
+}
+
+func (store *cachedStore) ListAPIKeys(ctx context.Context, username string) ([]ApiKey, error) {
+	return store.next.ListAPIKeys(ctx, username)
+}
+
+func (store *cachedStore) ListNotes(ctx context.Context, arg ListNotesParams) ([]Note, error) {
+	return store.next.ListNotes(ctx, arg)
+}
+
+func (store *cachedStore) ListUsersByEmail(ctx context.Context, email string) ([]User, error) {
+	return store.next.ListUsersByEmail(ctx, email)
+}
+
+func (store *cachedStore) MarkEmailVerified(ctx context.Context, arg MarkEmailVerifiedParams) (User, error) {
+	return store.next.MarkEmailVerified(ctx, arg)
+}
+
+func (store *cachedStore) RecordTOTPStep(ctx context.Context, arg RecordTOTPStepParams) (int64, error) {
+	return store.next.RecordTOTPStep(ctx, arg)
+}
+
+func (store *cachedStore) RehashUserPassword(ctx context.Context, arg RehashUserPasswordParams) (int64, error) {
+	return store.next.RehashUserPassword(ctx, arg)
+}
+
+func (store *cachedStore) ResetUserPassword(ctx context.Context, arg ResetUserPasswordParams) (User, error) {
+	return store.next.ResetUserPassword(ctx, arg)
+}
+
+func (store *cachedStore) SearchNotes(ctx context.Context, arg SearchNotesParams) ([]Note, error) {
+	return store.next.SearchNotes(ctx, arg)
+}
+
+func (store *cachedStore) SetTOTPSecret(ctx context.Context, arg SetTOTPSecretParams) (User, error) {
+	return store.next.SetTOTPSecret(ctx, arg)
+}
+
+func (store *cachedStore) TouchAPIKey(ctx context.Context, id int64) error {
+	return store.next.TouchAPIKey(ctx, id)
+}
+
+func (store *cachedStore) UpdateUserEmail(ctx context.Context, arg UpdateUserEmailParams) (User, error) {
+	return store.next.UpdateUserEmail(ctx, arg)
+}
+
+func (store *cachedStore) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error) {
+	return store.next.UpdateUserPassword(ctx, arg)
+}
+
+func (store *cachedStore) UpsertUserQuota(ctx context.Context, arg UpsertUserQuotaParams) (UserQuota, error) {
+	return store.next.UpsertUserQuota(ctx, arg)
+}
+
+func (store *cachedStore) ChangePasswordTx(ctx context.Context, arg ChangePasswordTxParams) (User, error) {
+	return store.next.ChangePasswordTx(ctx, arg)
+}
+
+func (store *cachedStore) ChangeEmailTx(ctx context.Context, arg ChangeEmailTxParams) (User, error) {
+	return store.next.ChangeEmailTx(ctx, arg)
+}
+
+func (store *cachedStore) VerifyEmailTx(ctx context.Context, arg VerifyEmailTxParams) (User, error) {
+	return store.next.VerifyEmailTx(ctx, arg)
+}
+
+func (store *cachedStore) ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (User, error) {
+	return store.next.ResetPasswordTx(ctx, arg)
+}
+
+func (store *cachedStore) EnableTOTPTx(ctx context.Context, arg EnableTOTPTxParams) (User, error) {
+	return store.next.EnableTOTPTx(ctx, arg)
+}
+
+func (store *cachedStore) CreateOIDCUserTx(ctx context.Context, arg CreateOIDCUserTxParams) (User, error) {
+	return store.next.CreateOIDCUserTx(ctx, arg)
+}
+
+func (store *cachedStore) Ping(ctx context.Context) error {
+	return store.next.Ping(ctx)
+}
+
+func (store *cachedStore) MigrationVersion(ctx context.Context) (int64, bool, error) {
+	return store.next.MigrationVersion(ctx)
+}
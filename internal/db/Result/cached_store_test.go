@@ -0,0 +1,143 @@
+package Database_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/nilesh0729/Notes/internal/cache"
+	mockDB "github.com/nilesh0729/Notes/internal/db/Mock"
+	Database "github.com/nilesh0729/Notes/internal/db/Result"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheStore(t *testing.T) {
+	caches := map[string]func(t *testing.T) Database.Cache{
+		"LRU": func(t *testing.T) Database.Cache {
+			return cache.NewLRU(100)
+		},
+		"Redis": func(t *testing.T) Database.Cache {
+			server := miniredis.RunT(t)
+			return cache.NewRedis(redis.NewClient(&redis.Options{Addr: server.Addr()}))
+		},
+	}
+
+	for name, newCache := range caches {
+		t.Run(name, func(t *testing.T) {
+			testCacheStore(t, newCache(t))
+		})
+	}
+}
+
+func testCacheStore(t *testing.T, cache Database.Cache) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	next := mockDB.NewMockStore(ctrl)
+	store := Database.CacheStore(next, cache, time.Minute, requestUser)
+	alice := withUser("alice")
+
+	note := Database.Note{NoteID: 1, Owner: "alice", Title: "title", CreatedAt: time.Now().UTC()}
+	tags := []Database.Tag{{TagID: 1, Owner: "alice", Name: "tag"}}
+	listArg := Database.ListTagsParams{Owner: "alice", Limit: 5}
+
+	// Reads are served from the cache after the first.
+	next.EXPECT().GetNoteById(gomock.Any(), int32(1)).Return(note, nil)
+	next.EXPECT().ListTags(gomock.Any(), listArg).Return(tags, nil)
+	for range 2 {
+		res, err := store.GetNoteById(alice, 1)
+		require.NoError(t, err)
+		require.Equal(t, note, res)
+
+		resTags, err := store.ListTags(alice, listArg)
+		require.NoError(t, err)
+		require.Equal(t, tags, resTags)
+	}
+
+	// Other users have entries of their own, and someone else's note is
+	// never cached.
+	next.EXPECT().GetNoteById(gomock.Any(), int32(1)).Return(note, nil).Times(2)
+	for range 2 {
+		_, err := store.GetNoteById(withUser("bob"), 1)
+		require.NoError(t, err)
+	}
+
+	// Reads made without a user are not cached.
+	next.EXPECT().GetTagsForNote(gomock.Any(), int32(1)).Return(nil, nil).Times(2)
+	for range 2 {
+		_, err := store.GetTagsForNote(context.Background(), 1)
+		require.NoError(t, err)
+	}
+
+	// Errors are not cached.
+	next.EXPECT().GetNoteById(gomock.Any(), int32(2)).Return(Database.Note{}, Database.ErrRecordNotFound).Times(2)
+	for range 2 {
+		_, err := store.GetNoteById(alice, 2)
+		require.ErrorIs(t, err, Database.ErrRecordNotFound)
+	}
+
+	// A write invalidates every entry of its owner.
+	updated := note
+	updated.Title = "new title"
+	next.EXPECT().UpdateNote(gomock.Any(), gomock.Any()).Return(updated, nil)
+	_, err := store.UpdateNote(alice, Database.UpdateNoteParams{NoteID: 1, Title: "new title"})
+	require.NoError(t, err)
+
+	next.EXPECT().GetNoteById(gomock.Any(), int32(1)).Return(updated, nil)
+	next.EXPECT().ListTags(gomock.Any(), listArg).Return(tags, nil)
+	for range 2 {
+		res, err := store.GetNoteById(alice, 1)
+		require.NoError(t, err)
+		require.Equal(t, updated, res)
+
+		_, err = store.ListTags(alice, listArg)
+		require.NoError(t, err)
+	}
+
+	// Writes made without a user invalidate the entries of the owner they
+	// look up.
+	next.EXPECT().GetNoteById(gomock.Any(), int32(1)).Return(updated, nil)
+	next.EXPECT().AddTagToNote(gomock.Any(), gomock.Any()).Return(Database.NoteTag{NoteID: 1, TagID: 1}, nil)
+	_, err = store.AddTagToNote(context.Background(), Database.AddTagToNoteParams{NoteID: 1, TagID: 1})
+	require.NoError(t, err)
+
+	next.EXPECT().ListTags(gomock.Any(), listArg).Return(tags, nil)
+	_, err = store.ListTags(alice, listArg)
+	require.NoError(t, err)
+}
+
+func TestCacheStoreSkipsReplicaReads(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	primary := mockDB.NewMockStore(ctrl)
+	replica := mockDB.NewMockStore(ctrl)
+	routed := Database.RouteToReplica(primary, replica, time.Minute, requestUser)
+	store := Database.CacheStore(routed, cache.NewLRU(100), time.Minute, requestUser)
+	alice := withUser("alice")
+
+	note := Database.Note{NoteID: 1, Owner: "alice", Title: "title"}
+
+	// The replica may lag behind the generation, so its reads are not
+	// cached.
+	replica.EXPECT().GetNoteById(gomock.Any(), int32(1)).Return(note, nil).Times(2)
+	for range 2 {
+		_, err := store.GetNoteById(alice, 1)
+		require.NoError(t, err)
+	}
+
+	// Once alice writes, her reads go to the primary and are cached.
+	primary.EXPECT().CreateNote(gomock.Any(), gomock.Any()).Return(note, nil)
+	_, err := store.CreateNote(alice, Database.CreateNoteParams{Owner: "alice"})
+	require.NoError(t, err)
+
+	primary.EXPECT().GetNoteById(gomock.Any(), int32(1)).Return(note, nil)
+	for range 2 {
+		res, err := store.GetNoteById(alice, 1)
+		require.NoError(t, err)
+		require.Equal(t, note, res)
+	}
+}
@@ -0,0 +1,29 @@
+package Database
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrRecordNotFound is returned by queries expecting a row that find none.
+// Every Store backend returns it, whatever its driver reports.
+var ErrRecordNotFound = pgx.ErrNoRows
+
+// SQLSTATE codes of the constraint violations callers handle.
+const (
+	ForeignKeyViolation = "23503"
+	UniqueViolation     = "23505"
+)
+
+// ErrorCode returns the SQLSTATE code of err if it was reported by the
+// database, or "" otherwise. Postgres errors carry their code; other
+// backends report the violations above with an error that has a
+// SQLState method too.
+func ErrorCode(err error) string {
+	var stateErr interface{ SQLState() string }
+	if errors.As(err, &stateErr) {
+		return stateErr.SQLState()
+	}
+	return ""
+}
@@ -0,0 +1,86 @@
+package Database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nilesh0729/Notes/internal/util"
+)
+
+// Ping checks that the database accepts connections.
+func (store *RealStore) Ping(ctx context.Context) error {
+	return store.db.Ping(ctx)
+}
+
+// MigrationVersion returns the schema version golang-migrate recorded, and
+// whether the migration to it failed half way.
+func (store *RealStore) MigrationVersion(ctx context.Context) (version int64, dirty bool, err error) {
+	err = store.db.QueryRow(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, ErrRecordNotFound) {
+		// No migration has run yet.
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
+
+// Backoff between attempts to reach the database at startup.
+const (
+	connectBackoffBase = 250 * time.Millisecond
+	connectBackoffMax  = 5 * time.Second
+)
+
+// Open creates a connection pool for the database described by config and
+// waits for it to accept connections. Failed pings are retried with
+// exponential backoff until config.DBConnectTimeout has passed, so the
+// server can start before the database has.
+func Open(ctx context.Context, config util.Config) (*pgxpool.Pool, error) {
+	poolConfig, err := pgxpool.ParseConfig(config.DBSource)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.DBMaxOpenConns > 0 {
+		poolConfig.MaxConns = int32(config.DBMaxOpenConns)
+	}
+	poolConfig.MinConns = int32(config.DBMinConns)
+	if config.DBConnMaxLifetime > 0 {
+		poolConfig.MaxConnLifetime = config.DBConnMaxLifetime
+	}
+	if config.DBConnMaxIdleTime > 0 {
+		poolConfig.MaxConnIdleTime = config.DBConnMaxIdleTime
+	}
+
+	db, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, config.DBConnectTimeout)
+	defer cancel()
+
+	backoff := connectBackoffBase
+	for attempt := 1; ; attempt++ {
+		err = db.Ping(ctx)
+		if err == nil {
+			return db, nil
+		}
+
+		slog.WarnContext(ctx, "cannot reach database",
+			slog.Int("attempt", attempt),
+			slog.Duration("retry_in", backoff),
+			slog.Any("error", err),
+		)
+
+		select {
+		case <-ctx.Done():
+			db.Close()
+			return nil, fmt.Errorf("database not reachable after %d attempts: %w", attempt, err)
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, connectBackoffMax)
+	}
+}
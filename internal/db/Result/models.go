@@ -5,19 +5,35 @@
 package Database
 
 import (
-	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+// Personal API keys, stored as SHA-256 hashes
+type ApiKey struct {
+	ID         int64              `json:"id"`
+	Username   string             `json:"username"`
+	Name       string             `json:"name"`
+	Prefix     string             `json:"prefix"`
+	KeyHash    []byte             `json:"key_hash"`
+	Scope      string             `json:"scope"`
+	ExpiresAt  time.Time          `json:"expires_at"`
+	LastUsedAt pgtype.Timestamptz `json:"last_used_at"`
+	CreatedAt  time.Time          `json:"created_at"`
+}
+
 // Stores notes (can be created anonymously for now)
 type Note struct {
-	NoteID    int32          `json:"note_id"`
-	Owner     sql.NullString `json:"owner"`
-	Title     sql.NullString `json:"title"`
-	Content   sql.NullString `json:"content"`
-	Pinned    sql.NullBool   `json:"pinned"`
-	Archived  sql.NullBool   `json:"archived"`
-	CreatedAt sql.NullTime   `json:"created_at"`
-	UpdatedAt sql.NullTime   `json:"updated_at"`
+	NoteID    int32     `json:"note_id"`
+	Owner     string    `json:"owner"`
+	Title     string    `json:"title"`
+	Content   string    `json:"content"`
+	Pinned    bool      `json:"pinned"`
+	Archived  bool      `json:"archived"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 type NoteTag struct {
@@ -25,15 +41,82 @@ type NoteTag struct {
 	TagID  int32 `json:"tag_id"`
 }
 
+// Pending OpenID Connect logins, keyed by the SHA-256 hash of the state parameter
+type OidcState struct {
+	StateHash    []byte    `json:"state_hash"`
+	Nonce        string    `json:"nonce"`
+	CodeVerifier string    `json:"code_verifier"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// One-time 2FA recovery codes, stored as SHA-256 hashes
+type RecoveryCode struct {
+	ID        int64              `json:"id"`
+	Username  string             `json:"username"`
+	CodeHash  []byte             `json:"code_hash"`
+	UsedAt    pgtype.Timestamptz `json:"used_at"`
+	CreatedAt time.Time          `json:"created_at"`
+}
+
+type Session struct {
+	ID        uuid.UUID `json:"id"`
+	Username  string    `json:"username"`
+	UserAgent string    `json:"user_agent"`
+	ClientIp  string    `json:"client_ip"`
+	IsBlocked bool      `json:"is_blocked"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
 // Stores tags for categorizing notes
 type Tag struct {
-	TagID int32          `json:"tag_id"`
-	Owner sql.NullString `json:"owner"`
-	Name  string         `json:"name"`
+	TagID int32  `json:"tag_id"`
+	Owner string `json:"owner"`
+	Name  string `json:"name"`
 }
 
 type User struct {
-	Username       string `json:"username"`
-	HashedPassword string `json:"hashed_password"`
-	Email          string `json:"email"`
+	Username          string    `json:"username"`
+	HashedPassword    string    `json:"hashed_password"`
+	Email             string    `json:"email"`
+	EmailVerified     bool      `json:"email_verified"`
+	PasswordChangedAt time.Time `json:"password_changed_at"`
+	CreatedAt         time.Time `json:"created_at"`
+	TotpSecret        string    `json:"totp_secret"`
+	TotpEnabled       bool      `json:"totp_enabled"`
+	// Time step of the last accepted TOTP code; codes of this or an earlier step are rejected
+	TotpLastStep int64 `json:"totp_last_step"`
+}
+
+// External OpenID Connect accounts linked to local users
+type UserIdentity struct {
+	Issuer    string    `json:"issuer"`
+	Subject   string    `json:"subject"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Per-user overrides of the configured quotas; NULL keeps the default, 0 lifts the limit
+type UserQuota struct {
+	Username      string    `json:"username"`
+	MaxNoteBytes  *int64    `json:"max_note_bytes"`
+	MaxNotes      *int64    `json:"max_notes"`
+	MaxTags       *int64    `json:"max_tags"`
+	MaxTotalBytes *int64    `json:"max_total_bytes"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// Single-use email verification and password reset tokens, stored as SHA-256 hashes
+type UserToken struct {
+	ID        int64              `json:"id"`
+	Username  string             `json:"username"`
+	Purpose   string             `json:"purpose"`
+	TokenHash []byte             `json:"token_hash"`
+	Email     string             `json:"email"`
+	ExpiresAt time.Time          `json:"expires_at"`
+	UsedAt    pgtype.Timestamptz `json:"used_at"`
+	CreatedAt time.Time          `json:"created_at"`
+	Attempts  int32              `json:"attempts"`
 }
@@ -7,7 +7,7 @@ package Database
 
 import (
 	"context"
-	"database/sql"
+	"time"
 )
 
 const addTagToNote = `-- name: AddTagToNote :one
@@ -23,7 +23,7 @@ type AddTagToNoteParams struct {
 }
 
 func (q *Queries) AddTagToNote(ctx context.Context, arg AddTagToNoteParams) (NoteTag, error) {
-	row := q.db.QueryRowContext(ctx, addTagToNote, arg.NoteID, arg.TagID)
+	row := q.db.QueryRow(ctx, addTagToNote, arg.NoteID, arg.TagID)
 	var i NoteTag
 	err := row.Scan(&i.NoteID, &i.TagID)
 	return i, err
@@ -37,23 +37,23 @@ WHERE nt.tag_id = $1 AND n.owner = $2
 `
 
 type GetNotesForTagParams struct {
-	TagID int32          `json:"tag_id"`
-	Owner sql.NullString `json:"owner"`
+	TagID int32  `json:"tag_id"`
+	Owner string `json:"owner"`
 }
 
 type GetNotesForTagRow struct {
-	NoteID    int32          `json:"note_id"`
-	Title     sql.NullString `json:"title"`
-	Owner     sql.NullString `json:"owner"`
-	Content   sql.NullString `json:"content"`
-	Pinned    sql.NullBool   `json:"pinned"`
-	Archived  sql.NullBool   `json:"archived"`
-	CreatedAt sql.NullTime   `json:"created_at"`
-	UpdatedAt sql.NullTime   `json:"updated_at"`
+	NoteID    int32     `json:"note_id"`
+	Title     string    `json:"title"`
+	Owner     string    `json:"owner"`
+	Content   string    `json:"content"`
+	Pinned    bool      `json:"pinned"`
+	Archived  bool      `json:"archived"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 func (q *Queries) GetNotesForTag(ctx context.Context, arg GetNotesForTagParams) ([]GetNotesForTagRow, error) {
-	rows, err := q.db.QueryContext(ctx, getNotesForTag, arg.TagID, arg.Owner)
+	rows, err := q.db.Query(ctx, getNotesForTag, arg.TagID, arg.Owner)
 	if err != nil {
 		return nil, err
 	}
@@ -75,9 +75,6 @@ func (q *Queries) GetNotesForTag(ctx context.Context, arg GetNotesForTagParams)
 		}
 		items = append(items, i)
 	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
@@ -97,7 +94,7 @@ type GetTagsForNoteRow struct {
 }
 
 func (q *Queries) GetTagsForNote(ctx context.Context, noteID int32) ([]GetTagsForNoteRow, error) {
-	rows, err := q.db.QueryContext(ctx, getTagsForNote, noteID)
+	rows, err := q.db.Query(ctx, getTagsForNote, noteID)
 	if err != nil {
 		return nil, err
 	}
@@ -110,9 +107,6 @@ func (q *Queries) GetTagsForNote(ctx context.Context, noteID int32) ([]GetTagsFo
 		}
 		items = append(items, i)
 	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
@@ -130,6 +124,6 @@ type RemoveTagFromNoteParams struct {
 }
 
 func (q *Queries) RemoveTagFromNote(ctx context.Context, arg RemoveTagFromNoteParams) error {
-	_, err := q.db.ExecContext(ctx, removeTagFromNote, arg.NoteID, arg.TagID)
+	_, err := q.db.Exec(ctx, removeTagFromNote, arg.NoteID, arg.TagID)
 	return err
 }
@@ -7,7 +7,6 @@ package Database
 
 import (
 	"context"
-	"database/sql"
 )
 
 const createNote = `-- name: CreateNote :one
@@ -22,13 +21,13 @@ RETURNING note_id, owner, title, content, pinned, archived, created_at, updated_
 `
 
 type CreateNoteParams struct {
-	Owner   sql.NullString `json:"owner"`
-	Title   sql.NullString `json:"title"`
-	Content sql.NullString `json:"content"`
+	Owner   string `json:"owner"`
+	Title   string `json:"title"`
+	Content string `json:"content"`
 }
 
 func (q *Queries) CreateNote(ctx context.Context, arg CreateNoteParams) (Note, error) {
-	row := q.db.QueryRowContext(ctx, createNote, arg.Owner, arg.Title, arg.Content)
+	row := q.db.QueryRow(ctx, createNote, arg.Owner, arg.Title, arg.Content)
 	var i Note
 	err := row.Scan(
 		&i.NoteID,
@@ -49,17 +48,7 @@ WHERE note_id = $1
 `
 
 func (q *Queries) DeleteNote(ctx context.Context, noteID int32) error {
-	_, err := q.db.ExecContext(ctx, deleteNote, noteID)
-	return err
-}
-
-const deleteNoteTagsByNoteId = `-- name: DeleteNoteTagsByNoteId :exec
-DELETE FROM note_tags
-WHERE note_id = $1
-`
-
-func (q *Queries) DeleteNoteTagsByNoteId(ctx context.Context, noteID int32) error {
-	_, err := q.db.ExecContext(ctx, deleteNoteTagsByNoteId, noteID)
+	_, err := q.db.Exec(ctx, deleteNote, noteID)
 	return err
 }
 
@@ -70,7 +59,7 @@ LIMIT 1
 `
 
 func (q *Queries) GetNoteById(ctx context.Context, noteID int32) (Note, error) {
-	row := q.db.QueryRowContext(ctx, getNoteById, noteID)
+	row := q.db.QueryRow(ctx, getNoteById, noteID)
 	var i Note
 	err := row.Scan(
 		&i.NoteID,
@@ -93,13 +82,13 @@ LIMIT $2
 `
 
 type ListNotesParams struct {
-	NoteID int32          `json:"note_id"`
-	Limit  int32          `json:"limit"`
-	Owner  sql.NullString `json:"owner"`
+	NoteID int32  `json:"note_id"`
+	Limit  int32  `json:"limit"`
+	Owner  string `json:"owner"`
 }
 
 func (q *Queries) ListNotes(ctx context.Context, arg ListNotesParams) ([]Note, error) {
-	rows, err := q.db.QueryContext(ctx, listNotes, arg.NoteID, arg.Limit, arg.Owner)
+	rows, err := q.db.Query(ctx, listNotes, arg.NoteID, arg.Limit, arg.Owner)
 	if err != nil {
 		return nil, err
 	}
@@ -121,9 +110,6 @@ func (q *Queries) ListNotes(ctx context.Context, arg ListNotesParams) ([]Note, e
 		}
 		items = append(items, i)
 	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
@@ -132,24 +118,24 @@ func (q *Queries) ListNotes(ctx context.Context, arg ListNotesParams) ([]Note, e
 
 const searchNotes = `-- name: SearchNotes :many
 SELECT note_id, owner, title, content, pinned, archived, created_at, updated_at FROM notes
-WHERE (title ILIKE '%' || $1 || '%' OR content ILIKE '%' || $1 || '%') AND owner = $4
-ORDER BY created_at DESC
-LIMIT $2 OFFSET $3
+WHERE (title ILIKE '%' || $1::text || '%' OR content ILIKE '%' || $1::text || '%') AND owner = $2
+ORDER BY created_at DESC, note_id DESC
+LIMIT $4 OFFSET $3
 `
 
 type SearchNotesParams struct {
-	Column1 sql.NullString `json:"column_1"`
-	Limit   int32          `json:"limit"`
-	Offset  int32          `json:"offset"`
-	Owner   sql.NullString `json:"owner"`
+	Search string `json:"search"`
+	Owner  string `json:"owner"`
+	Offset int32  `json:"offset"`
+	Limit  int32  `json:"limit"`
 }
 
 func (q *Queries) SearchNotes(ctx context.Context, arg SearchNotesParams) ([]Note, error) {
-	rows, err := q.db.QueryContext(ctx, searchNotes,
-		arg.Column1,
-		arg.Limit,
-		arg.Offset,
+	rows, err := q.db.Query(ctx, searchNotes,
+		arg.Search,
 		arg.Owner,
+		arg.Offset,
+		arg.Limit,
 	)
 	if err != nil {
 		return nil, err
@@ -172,9 +158,6 @@ func (q *Queries) SearchNotes(ctx context.Context, arg SearchNotesParams) ([]Not
 		}
 		items = append(items, i)
 	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
@@ -190,13 +173,13 @@ RETURNING note_id, owner, title, content, pinned, archived, created_at, updated_
 `
 
 type UpdateNoteParams struct {
-	NoteID  int32          `json:"note_id"`
-	Title   sql.NullString `json:"title"`
-	Content sql.NullString `json:"content"`
+	NoteID  int32  `json:"note_id"`
+	Title   string `json:"title"`
+	Content string `json:"content"`
 }
 
 func (q *Queries) UpdateNote(ctx context.Context, arg UpdateNoteParams) (Note, error) {
-	row := q.db.QueryRowContext(ctx, updateNote, arg.NoteID, arg.Title, arg.Content)
+	row := q.db.QueryRow(ctx, updateNote, arg.NoteID, arg.Title, arg.Content)
 	var i Note
 	err := row.Scan(
 		&i.NoteID,
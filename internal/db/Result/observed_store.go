@@ -0,0 +1,438 @@
+package Database
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// QueryObserver is told about every call made through a store returned by
+// ObserveStore, e.g. to time it or to trace it.
+type QueryObserver interface {
+	// StartQuery is called before the Store method named query runs. The
+	// method runs with the returned context, and end is called with the
+	// error it returned.
+	StartQuery(ctx context.Context, query string) (_ context.Context, end func(err error))
+}
+
+// ObserveStore returns a Store that reports every call made to store to
+// observer. Queries are named after their sqlc query, and transactions
+// after their Store method.
+func ObserveStore(store Store, observer QueryObserver) Store {
+	return &observedStore{next: store, observer: observer}
+}
+
+// observedStore does not embed Store, so a new query fails to compile until
+// it is observed too.
+type observedStore struct {
+	next     Store
+	observer QueryObserver
+}
+
+var _ Store = (*observedStore)(nil)
+
+func (store *observedStore) AddTagToNote(ctx context.Context, arg AddTagToNoteParams) (NoteTag, error) {
+	ctx, end := store.observer.StartQuery(ctx, "AddTagToNote")
+	res, err := store.next.AddTagToNote(ctx, arg)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) BlockUserSessions(ctx context.Context, username string) error {
+	ctx, end := store.observer.StartQuery(ctx, "BlockUserSessions")
+	err := store.next.BlockUserSessions(ctx, username)
+	end(err)
+	return err
+}
+
+func (store *observedStore) ConsumeOIDCState(ctx context.Context, stateHash []byte) (OidcState, error) {
+	ctx, end := store.observer.StartQuery(ctx, "ConsumeOIDCState")
+	res, err := store.next.ConsumeOIDCState(ctx, stateHash)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) ConsumeRecoveryCode(ctx context.Context, arg ConsumeRecoveryCodeParams) (RecoveryCode, error) {
+	ctx, end := store.observer.StartQuery(ctx, "ConsumeRecoveryCode")
+	res, err := store.next.ConsumeRecoveryCode(ctx, arg)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) ClaimUserTokenAttempt(ctx context.Context, arg ClaimUserTokenAttemptParams) (UserToken, error) {
+	ctx, end := store.observer.StartQuery(ctx, "ClaimUserTokenAttempt")
+	res, err := store.next.ClaimUserTokenAttempt(ctx, arg)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) ConsumeUserToken(ctx context.Context, arg ConsumeUserTokenParams) (UserToken, error) {
+	ctx, end := store.observer.StartQuery(ctx, "ConsumeUserToken")
+	res, err := store.next.ConsumeUserToken(ctx, arg)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error) {
+	ctx, end := store.observer.StartQuery(ctx, "CreateAPIKey")
+	res, err := store.next.CreateAPIKey(ctx, arg)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) CreateNote(ctx context.Context, arg CreateNoteParams) (Note, error) {
+	ctx, end := store.observer.StartQuery(ctx, "CreateNote")
+	res, err := store.next.CreateNote(ctx, arg)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) CreateOIDCState(ctx context.Context, arg CreateOIDCStateParams) (OidcState, error) {
+	ctx, end := store.observer.StartQuery(ctx, "CreateOIDCState")
+	res, err := store.next.CreateOIDCState(ctx, arg)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) error {
+	ctx, end := store.observer.StartQuery(ctx, "CreateRecoveryCode")
+	err := store.next.CreateRecoveryCode(ctx, arg)
+	end(err)
+	return err
+}
+
+func (store *observedStore) CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error) {
+	ctx, end := store.observer.StartQuery(ctx, "CreateSession")
+	res, err := store.next.CreateSession(ctx, arg)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) CreateTags(ctx context.Context, arg CreateTagsParams) (Tag, error) {
+	ctx, end := store.observer.StartQuery(ctx, "CreateTags")
+	res, err := store.next.CreateTags(ctx, arg)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
+	ctx, end := store.observer.StartQuery(ctx, "CreateUser")
+	res, err := store.next.CreateUser(ctx, arg)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) CreateUserIdentity(ctx context.Context, arg CreateUserIdentityParams) (UserIdentity, error) {
+	ctx, end := store.observer.StartQuery(ctx, "CreateUserIdentity")
+	res, err := store.next.CreateUserIdentity(ctx, arg)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) CreateUserToken(ctx context.Context, arg CreateUserTokenParams) (UserToken, error) {
+	ctx, end := store.observer.StartQuery(ctx, "CreateUserToken")
+	res, err := store.next.CreateUserToken(ctx, arg)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) DeleteAPIKey(ctx context.Context, arg DeleteAPIKeyParams) (ApiKey, error) {
+	ctx, end := store.observer.StartQuery(ctx, "DeleteAPIKey")
+	res, err := store.next.DeleteAPIKey(ctx, arg)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) DeleteNote(ctx context.Context, noteID int32) error {
+	ctx, end := store.observer.StartQuery(ctx, "DeleteNote")
+	err := store.next.DeleteNote(ctx, noteID)
+	end(err)
+	return err
+}
+
+func (store *observedStore) DeleteRecoveryCodes(ctx context.Context, username string) error {
+	ctx, end := store.observer.StartQuery(ctx, "DeleteRecoveryCodes")
+	err := store.next.DeleteRecoveryCodes(ctx, username)
+	end(err)
+	return err
+}
+
+func (store *observedStore) DeleteTag(ctx context.Context, tagID int32) error {
+	ctx, end := store.observer.StartQuery(ctx, "DeleteTag")
+	err := store.next.DeleteTag(ctx, tagID)
+	end(err)
+	return err
+}
+
+func (store *observedStore) DeleteUserAPIKeys(ctx context.Context, username string) error {
+	ctx, end := store.observer.StartQuery(ctx, "DeleteUserAPIKeys")
+	err := store.next.DeleteUserAPIKeys(ctx, username)
+	end(err)
+	return err
+}
+
+func (store *observedStore) DeleteUserTokens(ctx context.Context, arg DeleteUserTokensParams) error {
+	ctx, end := store.observer.StartQuery(ctx, "DeleteUserTokens")
+	err := store.next.DeleteUserTokens(ctx, arg)
+	end(err)
+	return err
+}
+
+func (store *observedStore) EnableTOTP(ctx context.Context, username string) (User, error) {
+	ctx, end := store.observer.StartQuery(ctx, "EnableTOTP")
+	res, err := store.next.EnableTOTP(ctx, username)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) GetAPIKeyByHash(ctx context.Context, keyHash []byte) (ApiKey, error) {
+	ctx, end := store.observer.StartQuery(ctx, "GetAPIKeyByHash")
+	res, err := store.next.GetAPIKeyByHash(ctx, keyHash)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) GetActiveUserToken(ctx context.Context, arg GetActiveUserTokenParams) (UserToken, error) {
+	ctx, end := store.observer.StartQuery(ctx, "GetActiveUserToken")
+	res, err := store.next.GetActiveUserToken(ctx, arg)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) GetNoteById(ctx context.Context, noteID int32) (Note, error) {
+	ctx, end := store.observer.StartQuery(ctx, "GetNoteById")
+	res, err := store.next.GetNoteById(ctx, noteID)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) GetNotesForTag(ctx context.Context, arg GetNotesForTagParams) ([]GetNotesForTagRow, error) {
+	ctx, end := store.observer.StartQuery(ctx, "GetNotesForTag")
+	res, err := store.next.GetNotesForTag(ctx, arg)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) GetSession(ctx context.Context, id uuid.UUID) (Session, error) {
+	ctx, end := store.observer.StartQuery(ctx, "GetSession")
+	res, err := store.next.GetSession(ctx, id)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) GetTag(ctx context.Context, tagID int32) (Tag, error) {
+	ctx, end := store.observer.StartQuery(ctx, "GetTag")
+	res, err := store.next.GetTag(ctx, tagID)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) GetTagsForNote(ctx context.Context, noteID int32) ([]GetTagsForNoteRow, error) {
+	ctx, end := store.observer.StartQuery(ctx, "GetTagsForNote")
+	res, err := store.next.GetTagsForNote(ctx, noteID)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) GetUser(ctx context.Context, username string) (User, error) {
+	ctx, end := store.observer.StartQuery(ctx, "GetUser")
+	res, err := store.next.GetUser(ctx, username)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) GetUserIdentity(ctx context.Context, arg GetUserIdentityParams) (UserIdentity, error) {
+	ctx, end := store.observer.StartQuery(ctx, "GetUserIdentity")
+	res, err := store.next.GetUserIdentity(ctx, arg)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) GetUserQuota(ctx context.Context, username string) (UserQuota, error) {
+	ctx, end := store.observer.StartQuery(ctx, "GetUserQuota")
+	res, err := store.next.GetUserQuota(ctx, username)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) GetUserUsage(ctx context.Context, username string) (GetUserUsageRow, error) {
+	ctx, end := store.observer.StartQuery(ctx, "GetUserUsage")
+	res, err := store.next.GetUserUsage(ctx, username)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) ListAPIKeys(ctx context.Context, username string) ([]ApiKey, error) {
+	ctx, end := store.observer.StartQuery(ctx, "ListAPIKeys")
+	res, err := store.next.ListAPIKeys(ctx, username)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) ListNotes(ctx context.Context, arg ListNotesParams) ([]Note, error) {
+	ctx, end := store.observer.StartQuery(ctx, "ListNotes")
+	res, err := store.next.ListNotes(ctx, arg)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) ListTags(ctx context.Context, arg ListTagsParams) ([]Tag, error) {
+	ctx, end := store.observer.StartQuery(ctx, "ListTags")
+	res, err := store.next.ListTags(ctx, arg)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) ListUsersByEmail(ctx context.Context, email string) ([]User, error) {
+	ctx, end := store.observer.StartQuery(ctx, "ListUsersByEmail")
+	res, err := store.next.ListUsersByEmail(ctx, email)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) MarkEmailVerified(ctx context.Context, arg MarkEmailVerifiedParams) (User, error) {
+	ctx, end := store.observer.StartQuery(ctx, "MarkEmailVerified")
+	res, err := store.next.MarkEmailVerified(ctx, arg)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) RecordTOTPStep(ctx context.Context, arg RecordTOTPStepParams) (int64, error) {
+	ctx, end := store.observer.StartQuery(ctx, "RecordTOTPStep")
+	res, err := store.next.RecordTOTPStep(ctx, arg)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) RehashUserPassword(ctx context.Context, arg RehashUserPasswordParams) (int64, error) {
+	ctx, end := store.observer.StartQuery(ctx, "RehashUserPassword")
+	res, err := store.next.RehashUserPassword(ctx, arg)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) RemoveTagFromNote(ctx context.Context, arg RemoveTagFromNoteParams) error {
+	ctx, end := store.observer.StartQuery(ctx, "RemoveTagFromNote")
+	err := store.next.RemoveTagFromNote(ctx, arg)
+	end(err)
+	return err
+}
+
+func (store *observedStore) ResetUserPassword(ctx context.Context, arg ResetUserPasswordParams) (User, error) {
+	ctx, end := store.observer.StartQuery(ctx, "ResetUserPassword")
+	res, err := store.next.ResetUserPassword(ctx, arg)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) SearchNotes(ctx context.Context, arg SearchNotesParams) ([]Note, error) {
+	ctx, end := store.observer.StartQuery(ctx, "SearchNotes")
+	res, err := store.next.SearchNotes(ctx, arg)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) SetTOTPSecret(ctx context.Context, arg SetTOTPSecretParams) (User, error) {
+	ctx, end := store.observer.StartQuery(ctx, "SetTOTPSecret")
+	res, err := store.next.SetTOTPSecret(ctx, arg)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) TouchAPIKey(ctx context.Context, id int64) error {
+	ctx, end := store.observer.StartQuery(ctx, "TouchAPIKey")
+	err := store.next.TouchAPIKey(ctx, id)
+	end(err)
+	return err
+}
+
+func (store *observedStore) UpdateNote(ctx context.Context, arg UpdateNoteParams) (Note, error) {
+	ctx, end := store.observer.StartQuery(ctx, "UpdateNote")
+	res, err := store.next.UpdateNote(ctx, arg)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) UpdateTag(ctx context.Context, arg UpdateTagParams) (Tag, error) {
+	ctx, end := store.observer.StartQuery(ctx, "UpdateTag")
+	res, err := store.next.UpdateTag(ctx, arg)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) UpdateUserEmail(ctx context.Context, arg UpdateUserEmailParams) (User, error) {
+	ctx, end := store.observer.StartQuery(ctx, "UpdateUserEmail")
+	res, err := store.next.UpdateUserEmail(ctx, arg)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error) {
+	ctx, end := store.observer.StartQuery(ctx, "UpdateUserPassword")
+	res, err := store.next.UpdateUserPassword(ctx, arg)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) UpsertUserQuota(ctx context.Context, arg UpsertUserQuotaParams) (UserQuota, error) {
+	ctx, end := store.observer.StartQuery(ctx, "UpsertUserQuota")
+	res, err := store.next.UpsertUserQuota(ctx, arg)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) ChangePasswordTx(ctx context.Context, arg ChangePasswordTxParams) (User, error) {
+	ctx, end := store.observer.StartQuery(ctx, "ChangePasswordTx")
+	res, err := store.next.ChangePasswordTx(ctx, arg)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) ChangeEmailTx(ctx context.Context, arg ChangeEmailTxParams) (User, error) {
+	ctx, end := store.observer.StartQuery(ctx, "ChangeEmailTx")
+	res, err := store.next.ChangeEmailTx(ctx, arg)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) VerifyEmailTx(ctx context.Context, arg VerifyEmailTxParams) (User, error) {
+	ctx, end := store.observer.StartQuery(ctx, "VerifyEmailTx")
+	res, err := store.next.VerifyEmailTx(ctx, arg)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (User, error) {
+	ctx, end := store.observer.StartQuery(ctx, "ResetPasswordTx")
+	res, err := store.next.ResetPasswordTx(ctx, arg)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) EnableTOTPTx(ctx context.Context, arg EnableTOTPTxParams) (User, error) {
+	ctx, end := store.observer.StartQuery(ctx, "EnableTOTPTx")
+	res, err := store.next.EnableTOTPTx(ctx, arg)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) CreateOIDCUserTx(ctx context.Context, arg CreateOIDCUserTxParams) (User, error) {
+	ctx, end := store.observer.StartQuery(ctx, "CreateOIDCUserTx")
+	res, err := store.next.CreateOIDCUserTx(ctx, arg)
+	end(err)
+	return res, err
+}
+
+func (store *observedStore) Ping(ctx context.Context) error {
+	ctx, end := store.observer.StartQuery(ctx, "Ping")
+	err := store.next.Ping(ctx)
+	end(err)
+	return err
+}
+
+func (store *observedStore) MigrationVersion(ctx context.Context) (int64, bool, error) {
+	ctx, end := store.observer.StartQuery(ctx, "MigrationVersion")
+	version, dirty, err := store.next.MigrationVersion(ctx)
+	end(err)
+	return version, dirty, err
+}
@@ -0,0 +1,129 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: oidc.sql
+
+package Database
+
+import (
+	"context"
+	"time"
+)
+
+const consumeOIDCState = `-- name: ConsumeOIDCState :one
+DELETE FROM oidc_states
+WHERE state_hash = $1
+  AND expires_at > now()
+RETURNING state_hash, nonce, code_verifier, expires_at, created_at
+`
+
+func (q *Queries) ConsumeOIDCState(ctx context.Context, stateHash []byte) (OidcState, error) {
+	row := q.db.QueryRow(ctx, consumeOIDCState, stateHash)
+	var i OidcState
+	err := row.Scan(
+		&i.StateHash,
+		&i.Nonce,
+		&i.CodeVerifier,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createOIDCState = `-- name: CreateOIDCState :one
+INSERT INTO oidc_states (
+  state_hash,
+  nonce,
+  code_verifier,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING state_hash, nonce, code_verifier, expires_at, created_at
+`
+
+type CreateOIDCStateParams struct {
+	StateHash    []byte    `json:"state_hash"`
+	Nonce        string    `json:"nonce"`
+	CodeVerifier string    `json:"code_verifier"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateOIDCState(ctx context.Context, arg CreateOIDCStateParams) (OidcState, error) {
+	row := q.db.QueryRow(ctx, createOIDCState,
+		arg.StateHash,
+		arg.Nonce,
+		arg.CodeVerifier,
+		arg.ExpiresAt,
+	)
+	var i OidcState
+	err := row.Scan(
+		&i.StateHash,
+		&i.Nonce,
+		&i.CodeVerifier,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createUserIdentity = `-- name: CreateUserIdentity :one
+INSERT INTO user_identities (
+  issuer,
+  subject,
+  username,
+  email
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING issuer, subject, username, email, created_at
+`
+
+type CreateUserIdentityParams struct {
+	Issuer   string `json:"issuer"`
+	Subject  string `json:"subject"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
+func (q *Queries) CreateUserIdentity(ctx context.Context, arg CreateUserIdentityParams) (UserIdentity, error) {
+	row := q.db.QueryRow(ctx, createUserIdentity,
+		arg.Issuer,
+		arg.Subject,
+		arg.Username,
+		arg.Email,
+	)
+	var i UserIdentity
+	err := row.Scan(
+		&i.Issuer,
+		&i.Subject,
+		&i.Username,
+		&i.Email,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getUserIdentity = `-- name: GetUserIdentity :one
+SELECT issuer, subject, username, email, created_at FROM user_identities
+WHERE issuer = $1 AND subject = $2
+LIMIT 1
+`
+
+type GetUserIdentityParams struct {
+	Issuer  string `json:"issuer"`
+	Subject string `json:"subject"`
+}
+
+func (q *Queries) GetUserIdentity(ctx context.Context, arg GetUserIdentityParams) (UserIdentity, error) {
+	row := q.db.QueryRow(ctx, getUserIdentity, arg.Issuer, arg.Subject)
+	var i UserIdentity
+	err := row.Scan(
+		&i.Issuer,
+		&i.Subject,
+		&i.Username,
+		&i.Email,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -6,28 +6,61 @@ package Database
 
 import (
 	"context"
+
+	"github.com/google/uuid"
 )
 
 type Querier interface {
 	AddTagToNote(ctx context.Context, arg AddTagToNoteParams) (NoteTag, error)
+	BlockUserSessions(ctx context.Context, username string) error
+	ClaimUserTokenAttempt(ctx context.Context, arg ClaimUserTokenAttemptParams) (UserToken, error)
+	ConsumeOIDCState(ctx context.Context, stateHash []byte) (OidcState, error)
+	ConsumeRecoveryCode(ctx context.Context, arg ConsumeRecoveryCodeParams) (RecoveryCode, error)
+	ConsumeUserToken(ctx context.Context, arg ConsumeUserTokenParams) (UserToken, error)
+	CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error)
 	CreateNote(ctx context.Context, arg CreateNoteParams) (Note, error)
+	CreateOIDCState(ctx context.Context, arg CreateOIDCStateParams) (OidcState, error)
+	CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) error
+	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
 	CreateTags(ctx context.Context, arg CreateTagsParams) (Tag, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateUserIdentity(ctx context.Context, arg CreateUserIdentityParams) (UserIdentity, error)
+	CreateUserToken(ctx context.Context, arg CreateUserTokenParams) (UserToken, error)
+	DeleteAPIKey(ctx context.Context, arg DeleteAPIKeyParams) (ApiKey, error)
 	DeleteNote(ctx context.Context, noteID int32) error
-	DeleteNoteTagsByNoteId(ctx context.Context, noteID int32) error
-	DeleteNoteTagsByTagId(ctx context.Context, tagID int32) error
+	DeleteRecoveryCodes(ctx context.Context, username string) error
 	DeleteTag(ctx context.Context, tagID int32) error
+	DeleteUserAPIKeys(ctx context.Context, username string) error
+	DeleteUserTokens(ctx context.Context, arg DeleteUserTokensParams) error
+	EnableTOTP(ctx context.Context, username string) (User, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash []byte) (ApiKey, error)
+	GetActiveUserToken(ctx context.Context, arg GetActiveUserTokenParams) (UserToken, error)
 	GetNoteById(ctx context.Context, noteID int32) (Note, error)
 	GetNotesForTag(ctx context.Context, arg GetNotesForTagParams) ([]GetNotesForTagRow, error)
+	GetSession(ctx context.Context, id uuid.UUID) (Session, error)
 	GetTag(ctx context.Context, tagID int32) (Tag, error)
 	GetTagsForNote(ctx context.Context, noteID int32) ([]GetTagsForNoteRow, error)
 	GetUser(ctx context.Context, username string) (User, error)
+	GetUserIdentity(ctx context.Context, arg GetUserIdentityParams) (UserIdentity, error)
+	GetUserQuota(ctx context.Context, username string) (UserQuota, error)
+	GetUserUsage(ctx context.Context, username string) (GetUserUsageRow, error)
+	ListAPIKeys(ctx context.Context, username string) ([]ApiKey, error)
 	ListNotes(ctx context.Context, arg ListNotesParams) ([]Note, error)
 	ListTags(ctx context.Context, arg ListTagsParams) ([]Tag, error)
+	ListUsersByEmail(ctx context.Context, email string) ([]User, error)
+	MarkEmailVerified(ctx context.Context, arg MarkEmailVerifiedParams) (User, error)
+	RecordTOTPStep(ctx context.Context, arg RecordTOTPStepParams) (int64, error)
+	RehashUserPassword(ctx context.Context, arg RehashUserPasswordParams) (int64, error)
 	RemoveTagFromNote(ctx context.Context, arg RemoveTagFromNoteParams) error
+	ResetUserPassword(ctx context.Context, arg ResetUserPasswordParams) (User, error)
 	SearchNotes(ctx context.Context, arg SearchNotesParams) ([]Note, error)
+	SetTOTPSecret(ctx context.Context, arg SetTOTPSecretParams) (User, error)
+	TouchAPIKey(ctx context.Context, id int64) error
 	UpdateNote(ctx context.Context, arg UpdateNoteParams) (Note, error)
 	UpdateTag(ctx context.Context, arg UpdateTagParams) (Tag, error)
+	UpdateUserEmail(ctx context.Context, arg UpdateUserEmailParams) (User, error)
+	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error)
+	UpsertUserQuota(ctx context.Context, arg UpsertUserQuotaParams) (UserQuota, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,97 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: quotas.sql
+
+package Database
+
+import (
+	"context"
+)
+
+const getUserQuota = `-- name: GetUserQuota :one
+SELECT username, max_note_bytes, max_notes, max_tags, max_total_bytes, updated_at FROM user_quotas
+WHERE username = $1
+LIMIT 1
+`
+
+func (q *Queries) GetUserQuota(ctx context.Context, username string) (UserQuota, error) {
+	row := q.db.QueryRow(ctx, getUserQuota, username)
+	var i UserQuota
+	err := row.Scan(
+		&i.Username,
+		&i.MaxNoteBytes,
+		&i.MaxNotes,
+		&i.MaxTags,
+		&i.MaxTotalBytes,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getUserUsage = `-- name: GetUserUsage :one
+SELECT
+  (SELECT count(*) FROM notes n WHERE n.owner = $1)::bigint AS notes,
+  (SELECT COALESCE(sum(octet_length(n.title) + octet_length(n.content)), 0) FROM notes n WHERE n.owner = $1)::bigint AS total_bytes,
+  (SELECT count(*) FROM tags t WHERE t.owner = $1)::bigint AS tags
+`
+
+type GetUserUsageRow struct {
+	Notes      int64 `json:"notes"`
+	TotalBytes int64 `json:"total_bytes"`
+	Tags       int64 `json:"tags"`
+}
+
+func (q *Queries) GetUserUsage(ctx context.Context, username string) (GetUserUsageRow, error) {
+	row := q.db.QueryRow(ctx, getUserUsage, username)
+	var i GetUserUsageRow
+	err := row.Scan(&i.Notes, &i.TotalBytes, &i.Tags)
+	return i, err
+}
+
+const upsertUserQuota = `-- name: UpsertUserQuota :one
+INSERT INTO user_quotas (
+  username,
+  max_note_bytes,
+  max_notes,
+  max_tags,
+  max_total_bytes
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+ON CONFLICT (username) DO UPDATE
+SET max_note_bytes = EXCLUDED.max_note_bytes,
+    max_notes = EXCLUDED.max_notes,
+    max_tags = EXCLUDED.max_tags,
+    max_total_bytes = EXCLUDED.max_total_bytes,
+    updated_at = now()
+RETURNING username, max_note_bytes, max_notes, max_tags, max_total_bytes, updated_at
+`
+
+type UpsertUserQuotaParams struct {
+	Username      string `json:"username"`
+	MaxNoteBytes  *int64 `json:"max_note_bytes"`
+	MaxNotes      *int64 `json:"max_notes"`
+	MaxTags       *int64 `json:"max_tags"`
+	MaxTotalBytes *int64 `json:"max_total_bytes"`
+}
+
+func (q *Queries) UpsertUserQuota(ctx context.Context, arg UpsertUserQuotaParams) (UserQuota, error) {
+	row := q.db.QueryRow(ctx, upsertUserQuota,
+		arg.Username,
+		arg.MaxNoteBytes,
+		arg.MaxNotes,
+		arg.MaxTags,
+		arg.MaxTotalBytes,
+	)
+	var i UserQuota
+	err := row.Scan(
+		&i.Username,
+		&i.MaxNoteBytes,
+		&i.MaxNotes,
+		&i.MaxTags,
+		&i.MaxTotalBytes,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
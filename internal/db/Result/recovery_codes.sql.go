@@ -0,0 +1,66 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: recovery_codes.sql
+
+package Database
+
+import (
+	"context"
+)
+
+const consumeRecoveryCode = `-- name: ConsumeRecoveryCode :one
+UPDATE recovery_codes
+SET used_at = now()
+WHERE username = $1
+  AND code_hash = $2
+  AND used_at IS NULL
+RETURNING id, username, code_hash, used_at, created_at
+`
+
+type ConsumeRecoveryCodeParams struct {
+	Username string `json:"username"`
+	CodeHash []byte `json:"code_hash"`
+}
+
+func (q *Queries) ConsumeRecoveryCode(ctx context.Context, arg ConsumeRecoveryCodeParams) (RecoveryCode, error) {
+	row := q.db.QueryRow(ctx, consumeRecoveryCode, arg.Username, arg.CodeHash)
+	var i RecoveryCode
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.CodeHash,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createRecoveryCode = `-- name: CreateRecoveryCode :exec
+INSERT INTO recovery_codes (
+  username,
+  code_hash
+) VALUES (
+  $1, $2
+)
+`
+
+type CreateRecoveryCodeParams struct {
+	Username string `json:"username"`
+	CodeHash []byte `json:"code_hash"`
+}
+
+func (q *Queries) CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) error {
+	_, err := q.db.Exec(ctx, createRecoveryCode, arg.Username, arg.CodeHash)
+	return err
+}
+
+const deleteRecoveryCodes = `-- name: DeleteRecoveryCodes :exec
+DELETE FROM recovery_codes
+WHERE username = $1
+`
+
+func (q *Queries) DeleteRecoveryCodes(ctx context.Context, username string) error {
+	_, err := q.db.Exec(ctx, deleteRecoveryCodes, username)
+	return err
+}
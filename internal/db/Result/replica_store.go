@@ -0,0 +1,364 @@
+package Database
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RouteToReplica returns a Store that sends the reads of notes and tags to
+// replica, and every other call to primary. Reads of accounts, sessions,
+// keys and quotas stay on primary, so that authentication and quota checks
+// never see stale data.
+//
+// A replica lags behind its primary, so once a user writes, their reads go
+// to primary for window and they see what they wrote. user returns the
+// user a call is made for, or "" if there is none.
+//
+// Ping only fails when primary is down. When it finds replica down, reads
+// go to primary until a later Ping finds replica up again.
+func RouteToReplica(primary, replica Store, window time.Duration, user func(ctx context.Context) string) Store {
+	return &replicaStore{
+		primary:   primary,
+		replica:   replica,
+		window:    window,
+		user:      user,
+		lastWrite: make(map[string]time.Time),
+	}
+}
+
+// replicaStore does not embed Store, so a new query fails to compile until
+// it is routed too.
+type replicaStore struct {
+	primary Store
+	replica Store
+	window  time.Duration
+	user    func(ctx context.Context) string
+
+	mu        sync.Mutex
+	lastWrite map[string]time.Time
+	lastPrune time.Time
+
+	replicaDown atomic.Bool
+}
+
+var _ Store = (*replicaStore)(nil)
+
+// replicaReadsKey is the context key of the replicaReads that reads made
+// with the context report to.
+type replicaReadsKey struct{}
+
+// replicaReads records whether a read was served by a replica, which may
+// not have caught up with the latest writes.
+type replicaReads struct {
+	served bool
+}
+
+// trackReplicaReads returns a context whose reads report to the returned
+// replicaReads whether they went to a replica.
+func trackReplicaReads(ctx context.Context) (context.Context, *replicaReads) {
+	reads := &replicaReads{}
+	return context.WithValue(ctx, replicaReadsKey{}, reads), reads
+}
+
+// reader returns the store a read made with ctx goes to.
+func (store *replicaStore) reader(ctx context.Context) Store {
+	if store.replicaDown.Load() || store.readsPrimary(ctx) {
+		return store.primary
+	}
+
+	if reads, ok := ctx.Value(replicaReadsKey{}).(*replicaReads); ok {
+		reads.served = true
+	}
+	return store.replica
+}
+
+// readsPrimary reports whether the user of ctx wrote within the window.
+func (store *replicaStore) readsPrimary(ctx context.Context) bool {
+	username := store.user(ctx)
+	if username == "" {
+		return false
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	lastWrite, ok := store.lastWrite[username]
+	return ok && time.Since(lastWrite) < store.window
+}
+
+// writer returns the primary, and makes the reads of the user of ctx stick
+// to it for the window.
+func (store *replicaStore) writer(ctx context.Context) Store {
+	username := store.user(ctx)
+	if username == "" {
+		return store.primary
+	}
+
+	now := time.Now()
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.lastWrite[username] = now
+
+	// Forget the users whose window has passed, at most once a window.
+	if now.Sub(store.lastPrune) >= store.window {
+		for username, lastWrite := range store.lastWrite {
+			if now.Sub(lastWrite) >= store.window {
+				delete(store.lastWrite, username)
+			}
+		}
+		store.lastPrune = now
+	}
+	return store.primary
+}
+
+func (store *replicaStore) AddTagToNote(ctx context.Context, arg AddTagToNoteParams) (NoteTag, error) {
+	return store.writer(ctx).AddTagToNote(ctx, arg)
+}
+
+func (store *replicaStore) BlockUserSessions(ctx context.Context, username string) error {
+	return store.writer(ctx).BlockUserSessions(ctx, username)
+}
+
+func (store *replicaStore) ConsumeOIDCState(ctx context.Context, stateHash []byte) (OidcState, error) {
+	return store.writer(ctx).ConsumeOIDCState(ctx, stateHash)
+}
+
+func (store *replicaStore) ConsumeRecoveryCode(ctx context.Context, arg ConsumeRecoveryCodeParams) (RecoveryCode, error) {
+	return store.writer(ctx).ConsumeRecoveryCode(ctx, arg)
+}
+
+func (store *replicaStore) ClaimUserTokenAttempt(ctx context.Context, arg ClaimUserTokenAttemptParams) (UserToken, error) {
+	return store.writer(ctx).ClaimUserTokenAttempt(ctx, arg)
+}
+
+func (store *replicaStore) ConsumeUserToken(ctx context.Context, arg ConsumeUserTokenParams) (UserToken, error) {
+	return store.writer(ctx).ConsumeUserToken(ctx, arg)
+}
+
+func (store *replicaStore) CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error) {
+	return store.writer(ctx).CreateAPIKey(ctx, arg)
+}
+
+func (store *replicaStore) CreateNote(ctx context.Context, arg CreateNoteParams) (Note, error) {
+	return store.writer(ctx).CreateNote(ctx, arg)
+}
+
+func (store *replicaStore) CreateOIDCState(ctx context.Context, arg CreateOIDCStateParams) (OidcState, error) {
+	return store.writer(ctx).CreateOIDCState(ctx, arg)
+}
+
+func (store *replicaStore) CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) error {
+	return store.writer(ctx).CreateRecoveryCode(ctx, arg)
+}
+
+func (store *replicaStore) CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error) {
+	return store.writer(ctx).CreateSession(ctx, arg)
+}
+
+func (store *replicaStore) CreateTags(ctx context.Context, arg CreateTagsParams) (Tag, error) {
+	return store.writer(ctx).CreateTags(ctx, arg)
+}
+
+func (store *replicaStore) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
+	return store.writer(ctx).CreateUser(ctx, arg)
+}
+
+func (store *replicaStore) CreateUserIdentity(ctx context.Context, arg CreateUserIdentityParams) (UserIdentity, error) {
+	return store.writer(ctx).CreateUserIdentity(ctx, arg)
+}
+
+func (store *replicaStore) CreateUserToken(ctx context.Context, arg CreateUserTokenParams) (UserToken, error) {
+	return store.writer(ctx).CreateUserToken(ctx, arg)
+}
+
+func (store *replicaStore) DeleteAPIKey(ctx context.Context, arg DeleteAPIKeyParams) (ApiKey, error) {
+	return store.writer(ctx).DeleteAPIKey(ctx, arg)
+}
+
+func (store *replicaStore) DeleteNote(ctx context.Context, noteID int32) error {
+	return store.writer(ctx).DeleteNote(ctx, noteID)
+}
+
+func (store *replicaStore) DeleteRecoveryCodes(ctx context.Context, username string) error {
+	return store.writer(ctx).DeleteRecoveryCodes(ctx, username)
+}
+
+func (store *replicaStore) DeleteTag(ctx context.Context, tagID int32) error {
+	return store.writer(ctx).DeleteTag(ctx, tagID)
+}
+
+func (store *replicaStore) DeleteUserAPIKeys(ctx context.Context, username string) error {
+	return store.writer(ctx).DeleteUserAPIKeys(ctx, username)
+}
+
+func (store *replicaStore) DeleteUserTokens(ctx context.Context, arg DeleteUserTokensParams) error {
+	return store.writer(ctx).DeleteUserTokens(ctx, arg)
+}
+
+func (store *replicaStore) EnableTOTP(ctx context.Context, username string) (User, error) {
+	return store.writer(ctx).EnableTOTP(ctx, username)
+}
+
+func (store *replicaStore) GetAPIKeyByHash(ctx context.Context, keyHash []byte) (ApiKey, error) {
+	return store.primary.GetAPIKeyByHash(ctx, keyHash)
+}
+
+func (store *replicaStore) GetActiveUserToken(ctx context.Context, arg GetActiveUserTokenParams) (UserToken, error) {
+	return store.primary.GetActiveUserToken(ctx, arg)
+}
+
+func (store *replicaStore) GetNoteById(ctx context.Context, noteID int32) (Note, error) {
+	return store.reader(ctx).GetNoteById(ctx, noteID)
+}
+
+func (store *replicaStore) GetNotesForTag(ctx context.Context, arg GetNotesForTagParams) ([]GetNotesForTagRow, error) {
+	return store.reader(ctx).GetNotesForTag(ctx, arg)
+}
+
+func (store *replicaStore) GetSession(ctx context.Context, id uuid.UUID) (Session, error) {
+	return store.primary.GetSession(ctx, id)
+}
+
+func (store *replicaStore) GetTag(ctx context.Context, tagID int32) (Tag, error) {
+	return store.reader(ctx).GetTag(ctx, tagID)
+}
+
+func (store *replicaStore) GetTagsForNote(ctx context.Context, noteID int32) ([]GetTagsForNoteRow, error) {
+	return store.reader(ctx).GetTagsForNote(ctx, noteID)
+}
+
+func (store *replicaStore) GetUser(ctx context.Context, username string) (User, error) {
+	return store.primary.GetUser(ctx, username)
+}
+
+func (store *replicaStore) GetUserIdentity(ctx context.Context, arg GetUserIdentityParams) (UserIdentity, error) {
+	return store.primary.GetUserIdentity(ctx, arg)
+}
+
+func (store *replicaStore) GetUserQuota(ctx context.Context, username string) (UserQuota, error) {
+	return store.primary.GetUserQuota(ctx, username)
+}
+
+func (store *replicaStore) GetUserUsage(ctx context.Context, username string) (GetUserUsageRow, error) {
+	return store.primary.GetUserUsage(ctx, username)
+}
+
+func (store *replicaStore) ListAPIKeys(ctx context.Context, username string) ([]ApiKey, error) {
+	return store.primary.ListAPIKeys(ctx, username)
+}
+
+func (store *replicaStore) ListNotes(ctx context.Context, arg ListNotesParams) ([]Note, error) {
+	return store.reader(ctx).ListNotes(ctx, arg)
+}
+
+func (store *replicaStore) ListTags(ctx context.Context, arg ListTagsParams) ([]Tag, error) {
+	return store.reader(ctx).ListTags(ctx, arg)
+}
+
+func (store *replicaStore) ListUsersByEmail(ctx context.Context, email string) ([]User, error) {
+	return store.primary.ListUsersByEmail(ctx, email)
+}
+
+func (store *replicaStore) MarkEmailVerified(ctx context.Context, arg MarkEmailVerifiedParams) (User, error) {
+	return store.writer(ctx).MarkEmailVerified(ctx, arg)
+}
+
+func (store *replicaStore) RecordTOTPStep(ctx context.Context, arg RecordTOTPStepParams) (int64, error) {
+	return store.writer(ctx).RecordTOTPStep(ctx, arg)
+}
+
+func (store *replicaStore) RehashUserPassword(ctx context.Context, arg RehashUserPasswordParams) (int64, error) {
+	return store.writer(ctx).RehashUserPassword(ctx, arg)
+}
+
+func (store *replicaStore) RemoveTagFromNote(ctx context.Context, arg RemoveTagFromNoteParams) error {
+	return store.writer(ctx).RemoveTagFromNote(ctx, arg)
+}
+
+func (store *replicaStore) ResetUserPassword(ctx context.Context, arg ResetUserPasswordParams) (User, error) {
+	return store.writer(ctx).ResetUserPassword(ctx, arg)
+}
+
+func (store *replicaStore) SearchNotes(ctx context.Context, arg SearchNotesParams) ([]Note, error) {
+	return store.reader(ctx).SearchNotes(ctx, arg)
+}
+
+func (store *replicaStore) SetTOTPSecret(ctx context.Context, arg SetTOTPSecretParams) (User, error) {
+	return store.writer(ctx).SetTOTPSecret(ctx, arg)
+}
+
+func (store *replicaStore) TouchAPIKey(ctx context.Context, id int64) error {
+	return store.writer(ctx).TouchAPIKey(ctx, id)
+}
+
+func (store *replicaStore) UpdateNote(ctx context.Context, arg UpdateNoteParams) (Note, error) {
+	return store.writer(ctx).UpdateNote(ctx, arg)
+}
+
+func (store *replicaStore) UpdateTag(ctx context.Context, arg UpdateTagParams) (Tag, error) {
+	return store.writer(ctx).UpdateTag(ctx, arg)
+}
+
+func (store *replicaStore) UpdateUserEmail(ctx context.Context, arg UpdateUserEmailParams) (User, error) {
+	return store.writer(ctx).UpdateUserEmail(ctx, arg)
+}
+
+func (store *replicaStore) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error) {
+	return store.writer(ctx).UpdateUserPassword(ctx, arg)
+}
+
+func (store *replicaStore) UpsertUserQuota(ctx context.Context, arg UpsertUserQuotaParams) (UserQuota, error) {
+	return store.writer(ctx).UpsertUserQuota(ctx, arg)
+}
+
+func (store *replicaStore) ChangePasswordTx(ctx context.Context, arg ChangePasswordTxParams) (User, error) {
+	return store.writer(ctx).ChangePasswordTx(ctx, arg)
+}
+
+func (store *replicaStore) ChangeEmailTx(ctx context.Context, arg ChangeEmailTxParams) (User, error) {
+	return store.writer(ctx).ChangeEmailTx(ctx, arg)
+}
+
+func (store *replicaStore) VerifyEmailTx(ctx context.Context, arg VerifyEmailTxParams) (User, error) {
+	return store.writer(ctx).VerifyEmailTx(ctx, arg)
+}
+
+func (store *replicaStore) ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (User, error) {
+	return store.writer(ctx).ResetPasswordTx(ctx, arg)
+}
+
+func (store *replicaStore) EnableTOTPTx(ctx context.Context, arg EnableTOTPTxParams) (User, error) {
+	return store.writer(ctx).EnableTOTPTx(ctx, arg)
+}
+
+func (store *replicaStore) CreateOIDCUserTx(ctx context.Context, arg CreateOIDCUserTxParams) (User, error) {
+	return store.writer(ctx).CreateOIDCUserTx(ctx, arg)
+}
+
+// Ping checks that the primary accepts connections. The replica is checked
+// too, but as its reads can go to the primary, it being down only changes
+// where they go.
+func (store *replicaStore) Ping(ctx context.Context) error {
+	err := store.replica.Ping(ctx)
+	down := err != nil
+	if store.replicaDown.Swap(down) != down {
+		if down {
+			slog.WarnContext(ctx, "cannot reach read replica, reading from the primary", slog.Any("error", err))
+		} else {
+			slog.InfoContext(ctx, "read replica is reachable again")
+		}
+	}
+
+	return store.primary.Ping(ctx)
+}
+
+func (store *replicaStore) MigrationVersion(ctx context.Context) (int64, bool, error) {
+	return store.primary.MigrationVersion(ctx)
+}
@@ -0,0 +1,108 @@
+package Database_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockDB "github.com/nilesh0729/Notes/internal/db/Mock"
+	Database "github.com/nilesh0729/Notes/internal/db/Result"
+	"github.com/stretchr/testify/require"
+)
+
+type usernameKey struct{}
+
+func withUser(username string) context.Context {
+	return context.WithValue(context.Background(), usernameKey{}, username)
+}
+
+func requestUser(ctx context.Context) string {
+	username, _ := ctx.Value(usernameKey{}).(string)
+	return username
+}
+
+func TestRouteToReplica(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	primary := mockDB.NewMockStore(ctrl)
+	replica := mockDB.NewMockStore(ctrl)
+	store := Database.RouteToReplica(primary, replica, 50*time.Millisecond, requestUser)
+
+	listArg := Database.ListNotesParams{Owner: "alice", Limit: 5}
+
+	// Reads go to the replica, and authentication reads to the primary.
+	replica.EXPECT().ListNotes(gomock.Any(), listArg).Return(nil, nil)
+	_, err := store.ListNotes(withUser("alice"), listArg)
+	require.NoError(t, err)
+
+	primary.EXPECT().GetUser(gomock.Any(), "alice").Return(Database.User{}, nil)
+	_, err = store.GetUser(withUser("alice"), "alice")
+	require.NoError(t, err)
+
+	// After a write, the reads of its user stick to the primary for the
+	// window, while other users keep reading from the replica.
+	primary.EXPECT().CreateNote(gomock.Any(), gomock.Any()).Return(Database.Note{}, nil)
+	_, err = store.CreateNote(withUser("alice"), Database.CreateNoteParams{Owner: "alice"})
+	require.NoError(t, err)
+
+	primary.EXPECT().ListNotes(gomock.Any(), listArg).Return(nil, nil)
+	_, err = store.ListNotes(withUser("alice"), listArg)
+	require.NoError(t, err)
+
+	replica.EXPECT().ListNotes(gomock.Any(), listArg).Return(nil, nil).Times(2)
+	_, err = store.ListNotes(withUser("bob"), listArg)
+	require.NoError(t, err)
+	_, err = store.ListNotes(context.Background(), listArg)
+	require.NoError(t, err)
+
+	time.Sleep(60 * time.Millisecond)
+	replica.EXPECT().ListNotes(gomock.Any(), listArg).Return(nil, nil)
+	_, err = store.ListNotes(withUser("alice"), listArg)
+	require.NoError(t, err)
+
+	// Transactions are writes too.
+	primary.EXPECT().ChangePasswordTx(gomock.Any(), gomock.Any()).Return(Database.User{}, nil)
+	_, err = store.ChangePasswordTx(withUser("alice"), Database.ChangePasswordTxParams{})
+	require.NoError(t, err)
+
+	primary.EXPECT().GetTag(gomock.Any(), int32(1)).Return(Database.Tag{}, nil)
+	_, err = store.GetTag(withUser("alice"), 1)
+	require.NoError(t, err)
+}
+
+func TestRouteToReplicaPing(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	primary := mockDB.NewMockStore(ctrl)
+	replica := mockDB.NewMockStore(ctrl)
+	store := Database.RouteToReplica(primary, replica, time.Second, requestUser)
+
+	listArg := Database.ListNotesParams{Owner: "alice", Limit: 5}
+
+	// A replica that is down does not fail the ping, but sends the reads
+	// to the primary until it is back.
+	primary.EXPECT().Ping(gomock.Any()).Return(nil)
+	replica.EXPECT().Ping(gomock.Any()).Return(errors.New("replica down"))
+	require.NoError(t, store.Ping(context.Background()))
+
+	primary.EXPECT().ListNotes(gomock.Any(), listArg).Return(nil, nil)
+	_, err := store.ListNotes(withUser("alice"), listArg)
+	require.NoError(t, err)
+
+	primary.EXPECT().Ping(gomock.Any()).Return(nil)
+	replica.EXPECT().Ping(gomock.Any()).Return(nil)
+	require.NoError(t, store.Ping(context.Background()))
+
+	replica.EXPECT().ListNotes(gomock.Any(), listArg).Return(nil, nil)
+	_, err = store.ListNotes(withUser("alice"), listArg)
+	require.NoError(t, err)
+
+	// The primary being down does.
+	primary.EXPECT().Ping(gomock.Any()).Return(errors.New("primary down"))
+	replica.EXPECT().Ping(gomock.Any()).Return(nil)
+	require.ErrorContains(t, store.Ping(context.Background()), "primary down")
+}
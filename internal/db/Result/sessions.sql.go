@@ -0,0 +1,87 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: sessions.sql
+
+package Database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const blockUserSessions = `-- name: BlockUserSessions :exec
+UPDATE sessions
+SET is_blocked = true
+WHERE username = $1
+`
+
+func (q *Queries) BlockUserSessions(ctx context.Context, username string) error {
+	_, err := q.db.Exec(ctx, blockUserSessions, username)
+	return err
+}
+
+const createSession = `-- name: CreateSession :one
+INSERT INTO sessions (
+  id,
+  username,
+  user_agent,
+  client_ip,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+RETURNING id, username, user_agent, client_ip, is_blocked, expires_at, created_at
+`
+
+type CreateSessionParams struct {
+	ID        uuid.UUID `json:"id"`
+	Username  string    `json:"username"`
+	UserAgent string    `json:"user_agent"`
+	ClientIp  string    `json:"client_ip"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error) {
+	row := q.db.QueryRow(ctx, createSession,
+		arg.ID,
+		arg.Username,
+		arg.UserAgent,
+		arg.ClientIp,
+		arg.ExpiresAt,
+	)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.UserAgent,
+		&i.ClientIp,
+		&i.IsBlocked,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getSession = `-- name: GetSession :one
+SELECT id, username, user_agent, client_ip, is_blocked, expires_at, created_at FROM sessions
+WHERE id = $1
+LIMIT 1
+`
+
+func (q *Queries) GetSession(ctx context.Context, id uuid.UUID) (Session, error) {
+	row := q.db.QueryRow(ctx, getSession, id)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.UserAgent,
+		&i.ClientIp,
+		&i.IsBlocked,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -1,19 +1,66 @@
 package Database
 
-import "database/sql"
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
 
 type Store interface{
 	Querier
+	ChangePasswordTx(ctx context.Context, arg ChangePasswordTxParams) (User, error)
+	ChangeEmailTx(ctx context.Context, arg ChangeEmailTxParams) (User, error)
+	VerifyEmailTx(ctx context.Context, arg VerifyEmailTxParams) (User, error)
+	ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (User, error)
+	EnableTOTPTx(ctx context.Context, arg EnableTOTPTxParams) (User, error)
+	CreateOIDCUserTx(ctx context.Context, arg CreateOIDCUserTxParams) (User, error)
+	Ping(ctx context.Context) error
+	MigrationVersion(ctx context.Context) (version int64, dirty bool, err error)
 }
 
 type RealStore struct{
 	*Queries
-	db *sql.DB
+	Transactions
+	db *pgxpool.Pool
 }
 
-func ServerConn(db *sql.DB) Store {
-	return &RealStore{
+func ServerConn(db *pgxpool.Pool) Store {
+	store := &RealStore{
 		db:      db,
 		Queries: New(db),
 	}
-}
\ No newline at end of file
+	store.Transactions = NewTransactions(store.execTx)
+	return store
+}
+
+// Transactions implements the transactions of Store with queries run
+// through execTx, so that every backend shares them.
+type Transactions struct {
+	execTx func(ctx context.Context, fn func(Querier) error) error
+}
+
+// NewTransactions creates the transactions of a backend. execTx has to run
+// fn inside a database transaction, rolling back if it fails.
+func NewTransactions(execTx func(ctx context.Context, fn func(Querier) error) error) Transactions {
+	return Transactions{execTx: execTx}
+}
+
+// execTx runs fn inside a database transaction, rolling back if it fails.
+func (store *RealStore) execTx(ctx context.Context, fn func(Querier) error) error {
+	tx, err := store.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+
+	err = fn(New(tx))
+	if err != nil {
+		if rbErr := tx.Rollback(ctx); rbErr != nil {
+			return fmt.Errorf("tx err: %v, rb err: %v", err, rbErr)
+		}
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,12 @@
+package Database_test
+
+import (
+	"testing"
+
+	Database "github.com/nilesh0729/Notes/internal/db/Result"
+	"github.com/nilesh0729/Notes/internal/db/storetest"
+)
+
+func TestStore(t *testing.T) {
+	storetest.Run(t, Database.TestingStore())
+}
@@ -7,7 +7,6 @@ package Database
 
 import (
 	"context"
-	"database/sql"
 )
 
 const createTags = `-- name: CreateTags :one
@@ -21,34 +20,24 @@ RETURNING tag_id, owner, name
 `
 
 type CreateTagsParams struct {
-	Owner sql.NullString `json:"owner"`
-	Name  string         `json:"name"`
+	Owner string `json:"owner"`
+	Name  string `json:"name"`
 }
 
 func (q *Queries) CreateTags(ctx context.Context, arg CreateTagsParams) (Tag, error) {
-	row := q.db.QueryRowContext(ctx, createTags, arg.Owner, arg.Name)
+	row := q.db.QueryRow(ctx, createTags, arg.Owner, arg.Name)
 	var i Tag
 	err := row.Scan(&i.TagID, &i.Owner, &i.Name)
 	return i, err
 }
 
-const deleteNoteTagsByTagId = `-- name: DeleteNoteTagsByTagId :exec
-DELETE FROM note_tags
-WHERE tag_id = $1
-`
-
-func (q *Queries) DeleteNoteTagsByTagId(ctx context.Context, tagID int32) error {
-	_, err := q.db.ExecContext(ctx, deleteNoteTagsByTagId, tagID)
-	return err
-}
-
 const deleteTag = `-- name: DeleteTag :exec
 DELETE FROM Tags
 WHERE tag_id = $1
 `
 
 func (q *Queries) DeleteTag(ctx context.Context, tagID int32) error {
-	_, err := q.db.ExecContext(ctx, deleteTag, tagID)
+	_, err := q.db.Exec(ctx, deleteTag, tagID)
 	return err
 }
 
@@ -59,7 +48,7 @@ LIMIT 1
 `
 
 func (q *Queries) GetTag(ctx context.Context, tagID int32) (Tag, error) {
-	row := q.db.QueryRowContext(ctx, getTag, tagID)
+	row := q.db.QueryRow(ctx, getTag, tagID)
 	var i Tag
 	err := row.Scan(&i.TagID, &i.Owner, &i.Name)
 	return i, err
@@ -73,13 +62,13 @@ LIMIT $2
 `
 
 type ListTagsParams struct {
-	TagID int32          `json:"tag_id"`
-	Limit int32          `json:"limit"`
-	Owner sql.NullString `json:"owner"`
+	TagID int32  `json:"tag_id"`
+	Limit int32  `json:"limit"`
+	Owner string `json:"owner"`
 }
 
 func (q *Queries) ListTags(ctx context.Context, arg ListTagsParams) ([]Tag, error) {
-	rows, err := q.db.QueryContext(ctx, listTags, arg.TagID, arg.Limit, arg.Owner)
+	rows, err := q.db.Query(ctx, listTags, arg.TagID, arg.Limit, arg.Owner)
 	if err != nil {
 		return nil, err
 	}
@@ -92,9 +81,6 @@ func (q *Queries) ListTags(ctx context.Context, arg ListTagsParams) ([]Tag, erro
 		}
 		items = append(items, i)
 	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
@@ -114,7 +100,7 @@ type UpdateTagParams struct {
 }
 
 func (q *Queries) UpdateTag(ctx context.Context, arg UpdateTagParams) (Tag, error) {
-	row := q.db.QueryRowContext(ctx, updateTag, arg.TagID, arg.Name)
+	row := q.db.QueryRow(ctx, updateTag, arg.TagID, arg.Name)
 	var i Tag
 	err := row.Scan(&i.TagID, &i.Owner, &i.Name)
 	return i, err
@@ -0,0 +1,40 @@
+package Database
+
+import "context"
+
+type ChangeEmailTxParams struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
+// ChangeEmailTx stores the new, unverified email address of the user and
+// deletes their outstanding verification and password reset tokens, which
+// were mailed to the old address.
+func (store Transactions) ChangeEmailTx(ctx context.Context, arg ChangeEmailTxParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q Querier) error {
+		var err error
+
+		user, err = q.UpdateUserEmail(ctx, UpdateUserEmailParams{
+			Username: arg.Username,
+			Email:    arg.Email,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, purpose := range []string{TokenPurposeVerifyEmail, TokenPurposeResetPassword} {
+			err = q.DeleteUserTokens(ctx, DeleteUserTokensParams{
+				Username: arg.Username,
+				Purpose:  purpose,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	return user, err
+}
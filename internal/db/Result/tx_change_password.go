@@ -0,0 +1,44 @@
+package Database
+
+import "context"
+
+type ChangePasswordTxParams struct {
+	Username       string `json:"username"`
+	HashedPassword string `json:"hashed_password"`
+}
+
+// ChangePasswordTx stores the new password hash, blocks every existing
+// session of the user and deletes their API keys and password reset tokens,
+// so credentials issued before the change stop working.
+func (store Transactions) ChangePasswordTx(ctx context.Context, arg ChangePasswordTxParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q Querier) error {
+		var err error
+
+		user, err = q.UpdateUserPassword(ctx, UpdateUserPasswordParams{
+			Username:       arg.Username,
+			HashedPassword: arg.HashedPassword,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = q.DeleteUserTokens(ctx, DeleteUserTokensParams{
+			Username: arg.Username,
+			Purpose:  TokenPurposeResetPassword,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = q.BlockUserSessions(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+
+		return q.DeleteUserAPIKeys(ctx, arg.Username)
+	})
+
+	return user, err
+}
@@ -0,0 +1,49 @@
+package Database
+
+import "context"
+
+type CreateOIDCUserTxParams struct {
+	Username       string `json:"username"`
+	HashedPassword string `json:"hashed_password"`
+	Email          string `json:"email"`
+	Issuer         string `json:"issuer"`
+	Subject        string `json:"subject"`
+}
+
+// CreateOIDCUserTx creates a user for an OpenID Connect account that is not
+// linked to anyone yet. The email address was verified by the identity
+// provider, so it is stored as verified right away.
+func (store Transactions) CreateOIDCUserTx(ctx context.Context, arg CreateOIDCUserTxParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q Querier) error {
+		var err error
+
+		user, err = q.CreateUser(ctx, CreateUserParams{
+			Username:       arg.Username,
+			HashedPassword: arg.HashedPassword,
+			Email:          arg.Email,
+		})
+		if err != nil {
+			return err
+		}
+
+		user, err = q.MarkEmailVerified(ctx, MarkEmailVerifiedParams{
+			Username: user.Username,
+			Email:    user.Email,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = q.CreateUserIdentity(ctx, CreateUserIdentityParams{
+			Issuer:   arg.Issuer,
+			Subject:  arg.Subject,
+			Username: user.Username,
+			Email:    user.Email,
+		})
+		return err
+	})
+
+	return user, err
+}
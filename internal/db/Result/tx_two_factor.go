@@ -0,0 +1,41 @@
+package Database
+
+import "context"
+
+type EnableTOTPTxParams struct {
+	Username           string   `json:"username"`
+	RecoveryCodeHashes [][]byte `json:"recovery_code_hashes"`
+}
+
+// EnableTOTPTx turns on two-factor authentication for a user whose secret was
+// stored by SetTOTPSecret, replacing any previous recovery codes.
+func (store Transactions) EnableTOTPTx(ctx context.Context, arg EnableTOTPTxParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q Querier) error {
+		var err error
+
+		user, err = q.EnableTOTP(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+
+		err = q.DeleteRecoveryCodes(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+
+		for _, codeHash := range arg.RecoveryCodeHashes {
+			err = q.CreateRecoveryCode(ctx, CreateRecoveryCodeParams{
+				Username: arg.Username,
+				CodeHash: codeHash,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	return user, err
+}
@@ -0,0 +1,89 @@
+package Database
+
+import "context"
+
+// Purposes stored in user_tokens.purpose.
+const (
+	TokenPurposeVerifyEmail    = "verify_email"
+	TokenPurposeResetPassword  = "reset_password"
+	TokenPurposeLoginChallenge = "login_challenge"
+	TokenPurposeOIDCLogin      = "oidc_login"
+)
+
+type VerifyEmailTxParams struct {
+	TokenHash []byte `json:"token_hash"`
+}
+
+// VerifyEmailTx consumes a verification token and marks the email address it
+// was issued for as verified. It fails with ErrRecordNotFound if the token is
+// unknown, used or expired, or if the user has changed their email since.
+func (store Transactions) VerifyEmailTx(ctx context.Context, arg VerifyEmailTxParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q Querier) error {
+		token, err := q.ConsumeUserToken(ctx, ConsumeUserTokenParams{
+			TokenHash: arg.TokenHash,
+			Purpose:   TokenPurposeVerifyEmail,
+		})
+		if err != nil {
+			return err
+		}
+
+		user, err = q.MarkEmailVerified(ctx, MarkEmailVerifiedParams{
+			Username: token.Username,
+			Email:    token.Email,
+		})
+		return err
+	})
+
+	return user, err
+}
+
+type ResetPasswordTxParams struct {
+	TokenHash      []byte `json:"token_hash"`
+	HashedPassword string `json:"hashed_password"`
+}
+
+// ResetPasswordTx consumes a password reset token, stores the new password
+// hash, blocks every existing session of the user and deletes their API keys
+// and other reset tokens. It fails with ErrRecordNotFound if the token is
+// unknown, used or expired, or if the user has changed their email since.
+func (store Transactions) ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q Querier) error {
+		token, err := q.ConsumeUserToken(ctx, ConsumeUserTokenParams{
+			TokenHash: arg.TokenHash,
+			Purpose:   TokenPurposeResetPassword,
+		})
+		if err != nil {
+			return err
+		}
+
+		user, err = q.ResetUserPassword(ctx, ResetUserPasswordParams{
+			Username:       token.Username,
+			Email:          token.Email,
+			HashedPassword: arg.HashedPassword,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = q.DeleteUserTokens(ctx, DeleteUserTokensParams{
+			Username: token.Username,
+			Purpose:  TokenPurposeResetPassword,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = q.BlockUserSessions(ctx, token.Username)
+		if err != nil {
+			return err
+		}
+
+		return q.DeleteUserAPIKeys(ctx, token.Username)
+	})
+
+	return user, err
+}
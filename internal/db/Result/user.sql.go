@@ -17,7 +17,7 @@ INSERT INTO "user" (
 ) VALUES (
   $1, $2 ,$3
 )
-RETURNING username, hashed_password, email
+RETURNING username, hashed_password, email, email_verified, password_changed_at, created_at, totp_secret, totp_enabled, totp_last_step
 `
 
 type CreateUserParams struct {
@@ -27,21 +27,293 @@ type CreateUserParams struct {
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, createUser, arg.Username, arg.HashedPassword, arg.Email)
+	row := q.db.QueryRow(ctx, createUser, arg.Username, arg.HashedPassword, arg.Email)
 	var i User
-	err := row.Scan(&i.Username, &i.HashedPassword, &i.Email)
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.Email,
+		&i.EmailVerified,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.TotpLastStep,
+	)
+	return i, err
+}
+
+const enableTOTP = `-- name: EnableTOTP :one
+UPDATE "user"
+SET totp_enabled = true
+WHERE username = $1 AND totp_secret <> ''
+RETURNING username, hashed_password, email, email_verified, password_changed_at, created_at, totp_secret, totp_enabled, totp_last_step
+`
+
+func (q *Queries) EnableTOTP(ctx context.Context, username string) (User, error) {
+	row := q.db.QueryRow(ctx, enableTOTP, username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.Email,
+		&i.EmailVerified,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.TotpLastStep,
+	)
 	return i, err
 }
 
 const getUser = `-- name: GetUser :one
-SELECT username, hashed_password, email FROM "user"
+SELECT username, hashed_password, email, email_verified, password_changed_at, created_at, totp_secret, totp_enabled, totp_last_step FROM "user"
 WHERE username = $1
 LIMIT 1
 `
 
 func (q *Queries) GetUser(ctx context.Context, username string) (User, error) {
-	row := q.db.QueryRowContext(ctx, getUser, username)
+	row := q.db.QueryRow(ctx, getUser, username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.Email,
+		&i.EmailVerified,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.TotpLastStep,
+	)
+	return i, err
+}
+
+const listUsersByEmail = `-- name: ListUsersByEmail :many
+SELECT username, hashed_password, email, email_verified, password_changed_at, created_at, totp_secret, totp_enabled, totp_last_step FROM "user"
+WHERE email = $1
+ORDER BY username
+`
+
+func (q *Queries) ListUsersByEmail(ctx context.Context, email string) ([]User, error) {
+	rows, err := q.db.Query(ctx, listUsersByEmail, email)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []User{}
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.Username,
+			&i.HashedPassword,
+			&i.Email,
+			&i.EmailVerified,
+			&i.PasswordChangedAt,
+			&i.CreatedAt,
+			&i.TotpSecret,
+			&i.TotpEnabled,
+			&i.TotpLastStep,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markEmailVerified = `-- name: MarkEmailVerified :one
+UPDATE "user"
+SET email_verified = true
+WHERE username = $1 AND email = $2
+RETURNING username, hashed_password, email, email_verified, password_changed_at, created_at, totp_secret, totp_enabled, totp_last_step
+`
+
+type MarkEmailVerifiedParams struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
+func (q *Queries) MarkEmailVerified(ctx context.Context, arg MarkEmailVerifiedParams) (User, error) {
+	row := q.db.QueryRow(ctx, markEmailVerified, arg.Username, arg.Email)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.Email,
+		&i.EmailVerified,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.TotpLastStep,
+	)
+	return i, err
+}
+
+const recordTOTPStep = `-- name: RecordTOTPStep :execrows
+UPDATE "user"
+SET totp_last_step = $1
+WHERE username = $2
+  AND totp_last_step < $1
+`
+
+type RecordTOTPStepParams struct {
+	Step     int64  `json:"step"`
+	Username string `json:"username"`
+}
+
+func (q *Queries) RecordTOTPStep(ctx context.Context, arg RecordTOTPStepParams) (int64, error) {
+	result, err := q.db.Exec(ctx, recordTOTPStep, arg.Step, arg.Username)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const rehashUserPassword = `-- name: RehashUserPassword :execrows
+UPDATE "user"
+SET hashed_password = $1
+WHERE username = $2
+  AND hashed_password = $3
+`
+
+type RehashUserPasswordParams struct {
+	NewHashedPassword string `json:"new_hashed_password"`
+	Username          string `json:"username"`
+	OldHashedPassword string `json:"old_hashed_password"`
+}
+
+func (q *Queries) RehashUserPassword(ctx context.Context, arg RehashUserPasswordParams) (int64, error) {
+	result, err := q.db.Exec(ctx, rehashUserPassword, arg.NewHashedPassword, arg.Username, arg.OldHashedPassword)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const resetUserPassword = `-- name: ResetUserPassword :one
+UPDATE "user"
+SET hashed_password = $3,
+  password_changed_at = now()
+WHERE username = $1 AND email = $2
+RETURNING username, hashed_password, email, email_verified, password_changed_at, created_at, totp_secret, totp_enabled, totp_last_step
+`
+
+type ResetUserPasswordParams struct {
+	Username       string `json:"username"`
+	Email          string `json:"email"`
+	HashedPassword string `json:"hashed_password"`
+}
+
+func (q *Queries) ResetUserPassword(ctx context.Context, arg ResetUserPasswordParams) (User, error) {
+	row := q.db.QueryRow(ctx, resetUserPassword, arg.Username, arg.Email, arg.HashedPassword)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.Email,
+		&i.EmailVerified,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.TotpLastStep,
+	)
+	return i, err
+}
+
+const setTOTPSecret = `-- name: SetTOTPSecret :one
+UPDATE "user"
+SET totp_secret = $2,
+  totp_enabled = false,
+  totp_last_step = 0
+WHERE username = $1
+RETURNING username, hashed_password, email, email_verified, password_changed_at, created_at, totp_secret, totp_enabled, totp_last_step
+`
+
+type SetTOTPSecretParams struct {
+	Username   string `json:"username"`
+	TotpSecret string `json:"totp_secret"`
+}
+
+func (q *Queries) SetTOTPSecret(ctx context.Context, arg SetTOTPSecretParams) (User, error) {
+	row := q.db.QueryRow(ctx, setTOTPSecret, arg.Username, arg.TotpSecret)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.Email,
+		&i.EmailVerified,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.TotpLastStep,
+	)
+	return i, err
+}
+
+const updateUserEmail = `-- name: UpdateUserEmail :one
+UPDATE "user"
+SET email = $2,
+  email_verified = false
+WHERE username = $1
+RETURNING username, hashed_password, email, email_verified, password_changed_at, created_at, totp_secret, totp_enabled, totp_last_step
+`
+
+type UpdateUserEmailParams struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
+func (q *Queries) UpdateUserEmail(ctx context.Context, arg UpdateUserEmailParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserEmail, arg.Username, arg.Email)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.Email,
+		&i.EmailVerified,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.TotpLastStep,
+	)
+	return i, err
+}
+
+const updateUserPassword = `-- name: UpdateUserPassword :one
+UPDATE "user"
+SET hashed_password = $2,
+  password_changed_at = now()
+WHERE username = $1
+RETURNING username, hashed_password, email, email_verified, password_changed_at, created_at, totp_secret, totp_enabled, totp_last_step
+`
+
+type UpdateUserPasswordParams struct {
+	Username       string `json:"username"`
+	HashedPassword string `json:"hashed_password"`
+}
+
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserPassword, arg.Username, arg.HashedPassword)
 	var i User
-	err := row.Scan(&i.Username, &i.HashedPassword, &i.Email)
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.Email,
+		&i.EmailVerified,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.TotpLastStep,
+	)
 	return i, err
 }
@@ -0,0 +1,167 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: user_tokens.sql
+
+package Database
+
+import (
+	"context"
+	"time"
+)
+
+const claimUserTokenAttempt = `-- name: ClaimUserTokenAttempt :one
+UPDATE user_tokens
+SET attempts = attempts + 1
+WHERE token_hash = $1
+  AND purpose = $2
+  AND used_at IS NULL
+  AND expires_at > now()
+  AND attempts < $3
+RETURNING id, username, purpose, token_hash, email, expires_at, used_at, created_at, attempts
+`
+
+type ClaimUserTokenAttemptParams struct {
+	TokenHash   []byte `json:"token_hash"`
+	Purpose     string `json:"purpose"`
+	MaxAttempts int32  `json:"max_attempts"`
+}
+
+func (q *Queries) ClaimUserTokenAttempt(ctx context.Context, arg ClaimUserTokenAttemptParams) (UserToken, error) {
+	row := q.db.QueryRow(ctx, claimUserTokenAttempt, arg.TokenHash, arg.Purpose, arg.MaxAttempts)
+	var i UserToken
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Purpose,
+		&i.TokenHash,
+		&i.Email,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+		&i.Attempts,
+	)
+	return i, err
+}
+
+const consumeUserToken = `-- name: ConsumeUserToken :one
+UPDATE user_tokens
+SET used_at = now()
+WHERE token_hash = $1
+  AND purpose = $2
+  AND used_at IS NULL
+  AND expires_at > now()
+RETURNING id, username, purpose, token_hash, email, expires_at, used_at, created_at, attempts
+`
+
+type ConsumeUserTokenParams struct {
+	TokenHash []byte `json:"token_hash"`
+	Purpose   string `json:"purpose"`
+}
+
+func (q *Queries) ConsumeUserToken(ctx context.Context, arg ConsumeUserTokenParams) (UserToken, error) {
+	row := q.db.QueryRow(ctx, consumeUserToken, arg.TokenHash, arg.Purpose)
+	var i UserToken
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Purpose,
+		&i.TokenHash,
+		&i.Email,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+		&i.Attempts,
+	)
+	return i, err
+}
+
+const createUserToken = `-- name: CreateUserToken :one
+INSERT INTO user_tokens (
+  username,
+  purpose,
+  token_hash,
+  email,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+RETURNING id, username, purpose, token_hash, email, expires_at, used_at, created_at, attempts
+`
+
+type CreateUserTokenParams struct {
+	Username  string    `json:"username"`
+	Purpose   string    `json:"purpose"`
+	TokenHash []byte    `json:"token_hash"`
+	Email     string    `json:"email"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateUserToken(ctx context.Context, arg CreateUserTokenParams) (UserToken, error) {
+	row := q.db.QueryRow(ctx, createUserToken,
+		arg.Username,
+		arg.Purpose,
+		arg.TokenHash,
+		arg.Email,
+		arg.ExpiresAt,
+	)
+	var i UserToken
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Purpose,
+		&i.TokenHash,
+		&i.Email,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+		&i.Attempts,
+	)
+	return i, err
+}
+
+const deleteUserTokens = `-- name: DeleteUserTokens :exec
+DELETE FROM user_tokens
+WHERE username = $1 AND purpose = $2
+`
+
+type DeleteUserTokensParams struct {
+	Username string `json:"username"`
+	Purpose  string `json:"purpose"`
+}
+
+func (q *Queries) DeleteUserTokens(ctx context.Context, arg DeleteUserTokensParams) error {
+	_, err := q.db.Exec(ctx, deleteUserTokens, arg.Username, arg.Purpose)
+	return err
+}
+
+const getActiveUserToken = `-- name: GetActiveUserToken :one
+SELECT id, username, purpose, token_hash, email, expires_at, used_at, created_at, attempts FROM user_tokens
+WHERE token_hash = $1
+  AND purpose = $2
+  AND used_at IS NULL
+  AND expires_at > now()
+LIMIT 1
+`
+
+type GetActiveUserTokenParams struct {
+	TokenHash []byte `json:"token_hash"`
+	Purpose   string `json:"purpose"`
+}
+
+func (q *Queries) GetActiveUserToken(ctx context.Context, arg GetActiveUserTokenParams) (UserToken, error) {
+	row := q.db.QueryRow(ctx, getActiveUserToken, arg.TokenHash, arg.Purpose)
+	var i UserToken
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Purpose,
+		&i.TokenHash,
+		&i.Email,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+		&i.Attempts,
+	)
+	return i, err
+}
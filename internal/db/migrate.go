@@ -0,0 +1,222 @@
+// Package db embeds the schema migrations of every storage backend and
+// applies them with golang-migrate, so the binary needs no migration files
+// or tools at run time. The Postgres migrations are in migrate_files, the
+// SQLite ones in sqlite/migrate_files.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
+	pgxmigrate "github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	sqlitemigrate "github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// Drivers DB_DRIVER selects the storage backend with.
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+//go:embed migrate_files/*.sql sqlite/migrate_files/*.sql
+var migrationFiles embed.FS
+
+// migrationDirs holds the migrations of each driver.
+var migrationDirs = map[string]string{
+	DriverPostgres: "migrate_files",
+	DriverSQLite:   "sqlite/migrate_files",
+}
+
+// Migrator applies the embedded migrations to a database. It holds one
+// connection of the pool it was created from until it is closed.
+type Migrator struct {
+	migrate *migrate.Migrate
+}
+
+// NewMigrator creates a migrator for the Postgres database of pool.
+// Closing it does not close pool.
+func NewMigrator(ctx context.Context, pool *pgxpool.Pool) (*Migrator, error) {
+	// golang-migrate speaks database/sql. The adapter borrows connections
+	// from pool, and closing it leaves pool open.
+	db := stdlib.OpenDBFromPool(pool)
+	err := db.PingContext(ctx)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	driver, err := pgxmigrate.WithInstance(db, &pgxmigrate.Config{})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return newMigrator(DriverPostgres, driver)
+}
+
+// NewSQLiteMigrator creates a migrator for a SQLite database. Closing it
+// does not close db.
+func NewSQLiteMigrator(ctx context.Context, db *sql.DB) (*Migrator, error) {
+	err := db.PingContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	driver, err := sqlitemigrate.WithInstance(db, &sqlitemigrate.Config{})
+	if err != nil {
+		return nil, err
+	}
+
+	return newMigrator(DriverSQLite, keepOpen{driver})
+}
+
+func newMigrator(driverName string, driver database.Driver) (*Migrator, error) {
+	source, err := iofs.New(migrationFiles, migrationDirs[driverName])
+	if err != nil {
+		driver.Close()
+		return nil, err
+	}
+
+	m, err := migrate.NewWithInstance("iofs", source, driverName, driver)
+	if err != nil {
+		driver.Close()
+		return nil, err
+	}
+	m.Log = migrateLogger{}
+
+	return &Migrator{migrate: m}, nil
+}
+
+// keepOpen stops a driver from closing the database it was created for.
+type keepOpen struct {
+	database.Driver
+}
+
+func (keepOpen) Close() error {
+	return nil
+}
+
+// Up applies all pending migrations, or the next n if n > 0.
+func (migrator *Migrator) Up(n int) error {
+	var err error
+	if n > 0 {
+		err = migrator.migrate.Steps(n)
+	} else {
+		err = migrator.migrate.Up()
+	}
+	return ignoreNoChange(err)
+}
+
+// Down reverts the last n migrations. Reverting everything has to be asked
+// for explicitly with DownAll.
+func (migrator *Migrator) Down(n int) error {
+	if n < 1 {
+		return fmt.Errorf("cannot revert %d migrations", n)
+	}
+	return ignoreNoChange(migrator.migrate.Steps(-n))
+}
+
+// DownAll reverts every migration.
+func (migrator *Migrator) DownAll() error {
+	return ignoreNoChange(migrator.migrate.Down())
+}
+
+// Version returns the version the database is migrated to, and whether the
+// migration to it failed half way. It is 0 if no migration has run.
+func (migrator *Migrator) Version() (version uint, dirty bool, err error) {
+	version, dirty, err = migrator.migrate.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
+
+// Force records version as the current one and clears the dirty flag,
+// without running any migration. It is used to recover from a migration
+// that failed half way, once the database has been repaired by hand.
+func (migrator *Migrator) Force(version int) error {
+	return migrator.migrate.Force(version)
+}
+
+// Close releases the connection of the migrator.
+func (migrator *Migrator) Close() error {
+	sourceErr, dbErr := migrator.migrate.Close()
+	return errors.Join(sourceErr, dbErr)
+}
+
+// MigrateUp applies all pending migrations to the Postgres database of pool.
+func MigrateUp(ctx context.Context, pool *pgxpool.Pool) error {
+	migrator, err := NewMigrator(ctx, pool)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+
+	return migrator.Up(0)
+}
+
+// MigrateUpSQLite applies all pending migrations to a SQLite database.
+func MigrateUpSQLite(ctx context.Context, db *sql.DB) error {
+	migrator, err := NewSQLiteMigrator(ctx, db)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+
+	return migrator.Up(0)
+}
+
+// LatestVersion returns the version of the newest embedded migration of
+// driver, the schema its queries are written against.
+func LatestVersion(driver string) uint {
+	dir, ok := migrationDirs[driver]
+	if !ok {
+		panic(fmt.Sprintf("no migrations for driver %q", driver))
+	}
+
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		panic(err)
+	}
+
+	var latest uint
+	for _, entry := range entries {
+		prefix, _, _ := strings.Cut(entry.Name(), "_")
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			panic(fmt.Sprintf("migration %s has no version", entry.Name()))
+		}
+		latest = max(latest, uint(version))
+	}
+	return latest
+}
+
+func ignoreNoChange(err error) error {
+	if errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+	return err
+}
+
+// migrateLogger passes the progress of migrations to the default logger.
+type migrateLogger struct{}
+
+func (migrateLogger) Printf(format string, v ...any) {
+	slog.Info("migrate: " + strings.TrimSpace(fmt.Sprintf(format, v...)))
+}
+
+func (migrateLogger) Verbose() bool {
+	return false
+}
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS "sessions";
+
+ALTER TABLE "user"
+  DROP COLUMN IF EXISTS "created_at",
+  DROP COLUMN IF EXISTS "password_changed_at",
+  DROP COLUMN IF EXISTS "email_verified";
@@ -0,0 +1,16 @@
+CREATE TABLE "user_tokens" (
+  "id" bigint GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "purpose" varchar NOT NULL,
+  "token_hash" bytea UNIQUE NOT NULL,
+  "email" varchar NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "used_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON TABLE "user_tokens" IS 'Single-use email verification and password reset tokens, stored as SHA-256 hashes';
+
+CREATE INDEX ON "user_tokens" ("username", "purpose");
+
+ALTER TABLE "user_tokens" ADD FOREIGN KEY ("username") REFERENCES "user" ("username");
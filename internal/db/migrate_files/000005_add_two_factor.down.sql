@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "recovery_codes";
+
+ALTER TABLE "user"
+  DROP COLUMN IF EXISTS "totp_enabled",
+  DROP COLUMN IF EXISTS "totp_secret";
@@ -0,0 +1,17 @@
+ALTER TABLE "user"
+  ADD COLUMN "totp_secret" varchar NOT NULL DEFAULT '',
+  ADD COLUMN "totp_enabled" boolean NOT NULL DEFAULT false;
+
+CREATE TABLE "recovery_codes" (
+  "id" bigint GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "code_hash" bytea NOT NULL,
+  "used_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON TABLE "recovery_codes" IS 'One-time 2FA recovery codes, stored as SHA-256 hashes';
+
+CREATE UNIQUE INDEX ON "recovery_codes" ("username", "code_hash");
+
+ALTER TABLE "recovery_codes" ADD FOREIGN KEY ("username") REFERENCES "user" ("username");
@@ -0,0 +1,24 @@
+CREATE TABLE "oidc_states" (
+  "state_hash" bytea PRIMARY KEY,
+  "nonce" varchar NOT NULL,
+  "code_verifier" varchar NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON TABLE "oidc_states" IS 'Pending OpenID Connect logins, keyed by the SHA-256 hash of the state parameter';
+
+CREATE TABLE "user_identities" (
+  "issuer" varchar NOT NULL,
+  "subject" varchar NOT NULL,
+  "username" varchar NOT NULL,
+  "email" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("issuer", "subject")
+);
+
+COMMENT ON TABLE "user_identities" IS 'External OpenID Connect accounts linked to local users';
+
+CREATE INDEX ON "user_identities" ("username");
+
+ALTER TABLE "user_identities" ADD FOREIGN KEY ("username") REFERENCES "user" ("username");
@@ -0,0 +1,17 @@
+CREATE TABLE "api_keys" (
+  "id" bigint GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "name" varchar NOT NULL,
+  "prefix" varchar NOT NULL,
+  "key_hash" bytea UNIQUE NOT NULL,
+  "scope" varchar NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "last_used_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON TABLE "api_keys" IS 'Personal API keys, stored as SHA-256 hashes';
+
+CREATE INDEX ON "api_keys" ("username");
+
+ALTER TABLE "api_keys" ADD FOREIGN KEY ("username") REFERENCES "user" ("username");
@@ -0,0 +1,23 @@
+DROP INDEX IF EXISTS "note_tags_tag_id_idx";
+DROP INDEX IF EXISTS "tags_owner_tag_id_idx";
+DROP INDEX IF EXISTS "notes_owner_created_at_idx";
+DROP INDEX IF EXISTS "notes_owner_note_id_idx";
+
+ALTER TABLE "note_tags"
+  DROP CONSTRAINT IF EXISTS "note_tags_note_id_fkey",
+  DROP CONSTRAINT IF EXISTS "note_tags_tag_id_fkey";
+
+ALTER TABLE "note_tags" ADD FOREIGN KEY ("note_id") REFERENCES "notes" ("note_id");
+ALTER TABLE "note_tags" ADD FOREIGN KEY ("tag_id") REFERENCES "tags" ("tag_id");
+
+ALTER TABLE "tags"
+  ALTER COLUMN "owner" DROP NOT NULL;
+
+ALTER TABLE "notes"
+  ALTER COLUMN "owner" DROP NOT NULL,
+  ALTER COLUMN "title" DROP NOT NULL,
+  ALTER COLUMN "content" DROP NOT NULL,
+  ALTER COLUMN "pinned" DROP NOT NULL,
+  ALTER COLUMN "archived" DROP NOT NULL,
+  ALTER COLUMN "created_at" DROP NOT NULL,
+  ALTER COLUMN "updated_at" DROP NOT NULL;
@@ -0,0 +1,56 @@
+-- Notes and tags without an owner cannot be reached through the API, but
+-- they are still someone's data, so they are not dropped here. Instead the
+-- migration stops until they have been given an owner or removed by hand.
+DO $$
+DECLARE
+  ownerless_notes bigint;
+  ownerless_tags bigint;
+BEGIN
+  SELECT count(*) INTO ownerless_notes FROM "notes" WHERE "owner" IS NULL;
+  SELECT count(*) INTO ownerless_tags FROM "tags" WHERE "owner" IS NULL;
+
+  IF ownerless_notes > 0 OR ownerless_tags > 0 THEN
+    RAISE EXCEPTION '% notes and % tags have no owner', ownerless_notes, ownerless_tags
+      USING HINT = 'Set their owner, or delete them together with their note_tags rows, '
+        'then run "api migrate force 7" and migrate again.';
+  END IF;
+END
+$$;
+
+UPDATE "notes" SET
+  "title" = COALESCE("title", ''),
+  "content" = COALESCE("content", ''),
+  "pinned" = COALESCE("pinned", false),
+  "archived" = COALESCE("archived", false),
+  "created_at" = COALESCE("created_at", "updated_at", CURRENT_TIMESTAMP),
+  "updated_at" = COALESCE("updated_at", "created_at", CURRENT_TIMESTAMP)
+WHERE "title" IS NULL
+   OR "content" IS NULL
+   OR "pinned" IS NULL
+   OR "archived" IS NULL
+   OR "created_at" IS NULL
+   OR "updated_at" IS NULL;
+
+ALTER TABLE "notes"
+  ALTER COLUMN "owner" SET NOT NULL,
+  ALTER COLUMN "title" SET NOT NULL,
+  ALTER COLUMN "content" SET NOT NULL,
+  ALTER COLUMN "pinned" SET NOT NULL,
+  ALTER COLUMN "archived" SET NOT NULL,
+  ALTER COLUMN "created_at" SET NOT NULL,
+  ALTER COLUMN "updated_at" SET NOT NULL;
+
+ALTER TABLE "tags"
+  ALTER COLUMN "owner" SET NOT NULL;
+
+ALTER TABLE "note_tags"
+  DROP CONSTRAINT "note_tags_note_id_fkey",
+  DROP CONSTRAINT "note_tags_tag_id_fkey";
+
+ALTER TABLE "note_tags" ADD FOREIGN KEY ("note_id") REFERENCES "notes" ("note_id") ON DELETE CASCADE;
+ALTER TABLE "note_tags" ADD FOREIGN KEY ("tag_id") REFERENCES "tags" ("tag_id") ON DELETE CASCADE;
+
+CREATE INDEX "notes_owner_note_id_idx" ON "notes" ("owner", "note_id");
+CREATE INDEX "notes_owner_created_at_idx" ON "notes" ("owner", "created_at" DESC);
+CREATE INDEX "tags_owner_tag_id_idx" ON "tags" ("owner", "tag_id");
+CREATE INDEX "note_tags_tag_id_idx" ON "note_tags" ("tag_id");
@@ -0,0 +1,12 @@
+CREATE TABLE "user_quotas" (
+  "username" varchar PRIMARY KEY,
+  "max_note_bytes" bigint,
+  "max_notes" bigint,
+  "max_tags" bigint,
+  "max_total_bytes" bigint,
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON TABLE "user_quotas" IS 'Per-user overrides of the configured quotas; NULL keeps the default, 0 lifts the limit';
+
+ALTER TABLE "user_quotas" ADD FOREIGN KEY ("username") REFERENCES "user" ("username") ON DELETE CASCADE;
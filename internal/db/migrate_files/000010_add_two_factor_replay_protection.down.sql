@@ -0,0 +1,3 @@
+ALTER TABLE "user_tokens" DROP COLUMN IF EXISTS "attempts";
+
+ALTER TABLE "user" DROP COLUMN IF EXISTS "totp_last_step";
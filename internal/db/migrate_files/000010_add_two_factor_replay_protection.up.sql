@@ -0,0 +1,5 @@
+ALTER TABLE "user" ADD COLUMN "totp_last_step" bigint NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN "user"."totp_last_step" IS 'Time step of the last accepted TOTP code; codes of this or an earlier step are rejected';
+
+ALTER TABLE "user_tokens" ADD COLUMN "attempts" integer NOT NULL DEFAULT 0;
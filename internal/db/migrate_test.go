@@ -0,0 +1,45 @@
+package db
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddedMigrations(t *testing.T) {
+	for driver, dir := range migrationDirs {
+		t.Run(driver, func(t *testing.T) {
+			entries, err := fs.ReadDir(migrationFiles, dir)
+			require.NoError(t, err)
+
+			ups := make(map[string]bool)
+			downs := make(map[string]bool)
+			for _, entry := range entries {
+				name := entry.Name()
+				switch {
+				case strings.HasSuffix(name, ".up.sql"):
+					ups[strings.TrimSuffix(name, ".up.sql")] = true
+				case strings.HasSuffix(name, ".down.sql"):
+					downs[strings.TrimSuffix(name, ".down.sql")] = true
+				default:
+					t.Fatalf("unexpected file %s", name)
+				}
+			}
+
+			// Every migration can be reverted, and versions have no gaps.
+			require.Equal(t, ups, downs)
+			require.Equal(t, uint(len(ups)), LatestVersion(driver))
+
+			source, err := iofs.New(migrationFiles, dir)
+			require.NoError(t, err)
+			defer source.Close()
+
+			first, err := source.First()
+			require.NoError(t, err)
+			require.Equal(t, uint(1), first)
+		})
+	}
+}
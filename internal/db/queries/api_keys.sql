@@ -0,0 +1,36 @@
+-- name: CreateAPIKey :one
+INSERT INTO api_keys (
+  username,
+  name,
+  prefix,
+  key_hash,
+  scope,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+RETURNING *;
+
+-- name: GetAPIKeyByHash :one
+SELECT * FROM api_keys
+WHERE key_hash = $1
+LIMIT 1;
+
+-- name: ListAPIKeys :many
+SELECT * FROM api_keys
+WHERE username = $1
+ORDER BY id;
+
+-- name: TouchAPIKey :exec
+UPDATE api_keys
+SET last_used_at = now()
+WHERE id = $1;
+
+-- name: DeleteAPIKey :one
+DELETE FROM api_keys
+WHERE id = $1 AND username = $2
+RETURNING *;
+
+-- name: DeleteUserAPIKeys :exec
+DELETE FROM api_keys
+WHERE username = $1;
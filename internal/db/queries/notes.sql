@@ -21,9 +21,9 @@ LIMIT $2;
 
 -- name: SearchNotes :many
 SELECT * FROM notes
-WHERE (title ILIKE '%' || $1 || '%' OR content ILIKE '%' || $1 || '%') AND owner = $4
-ORDER BY created_at DESC
-LIMIT $2 OFFSET $3;
+WHERE (title ILIKE '%' || sqlc.arg(search)::text || '%' OR content ILIKE '%' || sqlc.arg(search)::text || '%') AND owner = sqlc.arg(owner)
+ORDER BY created_at DESC, note_id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
 
 -- name: UpdateNote :one
 UPDATE notes
@@ -34,8 +34,4 @@ RETURNING *;
 
 -- name: DeleteNote :exec
 DELETE FROM notes
-WHERE note_id = $1;
-
--- name: DeleteNoteTagsByNoteId :exec
-DELETE FROM note_tags
 WHERE note_id = $1;
\ No newline at end of file
@@ -0,0 +1,32 @@
+-- name: CreateOIDCState :one
+INSERT INTO oidc_states (
+  state_hash,
+  nonce,
+  code_verifier,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: ConsumeOIDCState :one
+DELETE FROM oidc_states
+WHERE state_hash = $1
+  AND expires_at > now()
+RETURNING *;
+
+-- name: CreateUserIdentity :one
+INSERT INTO user_identities (
+  issuer,
+  subject,
+  username,
+  email
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: GetUserIdentity :one
+SELECT * FROM user_identities
+WHERE issuer = $1 AND subject = $2
+LIMIT 1;
@@ -0,0 +1,28 @@
+-- name: GetUserQuota :one
+SELECT * FROM user_quotas
+WHERE username = $1
+LIMIT 1;
+
+-- name: UpsertUserQuota :one
+INSERT INTO user_quotas (
+  username,
+  max_note_bytes,
+  max_notes,
+  max_tags,
+  max_total_bytes
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+ON CONFLICT (username) DO UPDATE
+SET max_note_bytes = EXCLUDED.max_note_bytes,
+    max_notes = EXCLUDED.max_notes,
+    max_tags = EXCLUDED.max_tags,
+    max_total_bytes = EXCLUDED.max_total_bytes,
+    updated_at = now()
+RETURNING *;
+
+-- name: GetUserUsage :one
+SELECT
+  (SELECT count(*) FROM notes n WHERE n.owner = sqlc.arg(username))::bigint AS notes,
+  (SELECT COALESCE(sum(octet_length(n.title) + octet_length(n.content)), 0) FROM notes n WHERE n.owner = sqlc.arg(username))::bigint AS total_bytes,
+  (SELECT count(*) FROM tags t WHERE t.owner = sqlc.arg(username))::bigint AS tags;
@@ -0,0 +1,21 @@
+-- name: CreateSession :one
+INSERT INTO sessions (
+  id,
+  username,
+  user_agent,
+  client_ip,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+RETURNING *;
+
+-- name: GetSession :one
+SELECT * FROM sessions
+WHERE id = $1
+LIMIT 1;
+
+-- name: BlockUserSessions :exec
+UPDATE sessions
+SET is_blocked = true
+WHERE username = $1;
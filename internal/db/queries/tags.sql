@@ -26,8 +26,4 @@ RETURNING *;
 
 -- name: DeleteTag :exec
 DELETE FROM Tags
-WHERE tag_id = $1;
-
--- name: DeleteNoteTagsByTagId :exec
-DELETE FROM note_tags
 WHERE tag_id = $1;
\ No newline at end of file
@@ -13,3 +13,62 @@ SELECT * FROM "user"
 WHERE username = $1
 LIMIT 1;
 
+
+
+-- name: UpdateUserEmail :one
+UPDATE "user"
+SET email = $2,
+  email_verified = false
+WHERE username = $1
+RETURNING *;
+
+-- name: UpdateUserPassword :one
+UPDATE "user"
+SET hashed_password = $2,
+  password_changed_at = now()
+WHERE username = $1
+RETURNING *;
+
+-- name: ResetUserPassword :one
+UPDATE "user"
+SET hashed_password = $3,
+  password_changed_at = now()
+WHERE username = $1 AND email = $2
+RETURNING *;
+
+-- name: ListUsersByEmail :many
+SELECT * FROM "user"
+WHERE email = $1
+ORDER BY username;
+
+-- name: MarkEmailVerified :one
+UPDATE "user"
+SET email_verified = true
+WHERE username = $1 AND email = $2
+RETURNING *;
+
+-- name: SetTOTPSecret :one
+UPDATE "user"
+SET totp_secret = $2,
+  totp_enabled = false,
+  totp_last_step = 0
+WHERE username = $1
+RETURNING *;
+
+-- name: EnableTOTP :one
+UPDATE "user"
+SET totp_enabled = true
+WHERE username = $1 AND totp_secret <> ''
+RETURNING *;
+
+-- name: RehashUserPassword :execrows
+UPDATE "user"
+SET hashed_password = sqlc.arg(new_hashed_password)
+WHERE username = sqlc.arg(username)
+  AND hashed_password = sqlc.arg(old_hashed_password);
+
+-- name: RecordTOTPStep :execrows
+UPDATE "user"
+SET totp_last_step = sqlc.arg(step)
+WHERE username = sqlc.arg(username)
+  AND totp_last_step < sqlc.arg(step);
@@ -0,0 +1,42 @@
+-- name: CreateUserToken :one
+INSERT INTO user_tokens (
+  username,
+  purpose,
+  token_hash,
+  email,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+RETURNING *;
+
+-- name: ConsumeUserToken :one
+UPDATE user_tokens
+SET used_at = now()
+WHERE token_hash = $1
+  AND purpose = $2
+  AND used_at IS NULL
+  AND expires_at > now()
+RETURNING *;
+
+-- name: GetActiveUserToken :one
+SELECT * FROM user_tokens
+WHERE token_hash = $1
+  AND purpose = $2
+  AND used_at IS NULL
+  AND expires_at > now()
+LIMIT 1;
+
+-- name: ClaimUserTokenAttempt :one
+UPDATE user_tokens
+SET attempts = attempts + 1
+WHERE token_hash = sqlc.arg(token_hash)
+  AND purpose = sqlc.arg(purpose)
+  AND used_at IS NULL
+  AND expires_at > now()
+  AND attempts < sqlc.arg(max_attempts)
+RETURNING *;
+
+-- name: DeleteUserTokens :exec
+DELETE FROM user_tokens
+WHERE username = $1 AND purpose = $2;
@@ -0,0 +1,175 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: api_keys.sql
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const createAPIKey = `-- name: CreateAPIKey :one
+INSERT INTO api_keys (
+  username,
+  name,
+  prefix,
+  key_hash,
+  scope,
+  expires_at
+) VALUES (
+  ?, ?, ?, ?, ?, ?
+)
+RETURNING id, username, name, prefix, key_hash, scope, expires_at, last_used_at, created_at
+`
+
+type CreateAPIKeyParams struct {
+	Username  string    `json:"username"`
+	Name      string    `json:"name"`
+	Prefix    string    `json:"prefix"`
+	KeyHash   []byte    `json:"key_hash"`
+	Scope     string    `json:"scope"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error) {
+	row := q.db.QueryRowContext(ctx, createAPIKey,
+		arg.Username,
+		arg.Name,
+		arg.Prefix,
+		arg.KeyHash,
+		arg.Scope,
+		arg.ExpiresAt,
+	)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Name,
+		&i.Prefix,
+		&i.KeyHash,
+		&i.Scope,
+		&i.ExpiresAt,
+		&i.LastUsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteAPIKey = `-- name: DeleteAPIKey :one
+DELETE FROM api_keys
+WHERE id = ? AND username = ?
+RETURNING id, username, name, prefix, key_hash, scope, expires_at, last_used_at, created_at
+`
+
+type DeleteAPIKeyParams struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+}
+
+func (q *Queries) DeleteAPIKey(ctx context.Context, arg DeleteAPIKeyParams) (ApiKey, error) {
+	row := q.db.QueryRowContext(ctx, deleteAPIKey, arg.ID, arg.Username)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Name,
+		&i.Prefix,
+		&i.KeyHash,
+		&i.Scope,
+		&i.ExpiresAt,
+		&i.LastUsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteUserAPIKeys = `-- name: DeleteUserAPIKeys :exec
+DELETE FROM api_keys
+WHERE username = ?
+`
+
+func (q *Queries) DeleteUserAPIKeys(ctx context.Context, username string) error {
+	_, err := q.db.ExecContext(ctx, deleteUserAPIKeys, username)
+	return err
+}
+
+const getAPIKeyByHash = `-- name: GetAPIKeyByHash :one
+SELECT id, username, name, prefix, key_hash, scope, expires_at, last_used_at, created_at FROM api_keys
+WHERE key_hash = ?
+LIMIT 1
+`
+
+func (q *Queries) GetAPIKeyByHash(ctx context.Context, keyHash []byte) (ApiKey, error) {
+	row := q.db.QueryRowContext(ctx, getAPIKeyByHash, keyHash)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Name,
+		&i.Prefix,
+		&i.KeyHash,
+		&i.Scope,
+		&i.ExpiresAt,
+		&i.LastUsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listAPIKeys = `-- name: ListAPIKeys :many
+SELECT id, username, name, prefix, key_hash, scope, expires_at, last_used_at, created_at FROM api_keys
+WHERE username = ?
+ORDER BY id
+`
+
+func (q *Queries) ListAPIKeys(ctx context.Context, username string) ([]ApiKey, error) {
+	rows, err := q.db.QueryContext(ctx, listAPIKeys, username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ApiKey{}
+	for rows.Next() {
+		var i ApiKey
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.Name,
+			&i.Prefix,
+			&i.KeyHash,
+			&i.Scope,
+			&i.ExpiresAt,
+			&i.LastUsedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const touchAPIKey = `-- name: TouchAPIKey :exec
+UPDATE api_keys
+SET last_used_at = ?1
+WHERE id = ?2
+`
+
+type TouchAPIKeyParams struct {
+	LastUsedAt sql.NullTime `json:"last_used_at"`
+	ID         int64        `json:"id"`
+}
+
+func (q *Queries) TouchAPIKey(ctx context.Context, arg TouchAPIKeyParams) error {
+	_, err := q.db.ExecContext(ctx, touchAPIKey, arg.LastUsedAt, arg.ID)
+	return err
+}
@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS "api_keys";
+DROP TABLE IF EXISTS "user_identities";
+DROP TABLE IF EXISTS "oidc_states";
+DROP TABLE IF EXISTS "recovery_codes";
+DROP TABLE IF EXISTS "user_tokens";
+DROP TABLE IF EXISTS "sessions";
+DROP TABLE IF EXISTS "note_tags";
+DROP TABLE IF EXISTS "tags";
+DROP TABLE IF EXISTS "notes";
+DROP TABLE IF EXISTS "user";
@@ -0,0 +1,111 @@
+-- The SQLite schema matches the Postgres one after all of its migrations.
+-- Times are stored as UTC text, which sorts chronologically.
+
+CREATE TABLE "user" (
+  "username" TEXT PRIMARY KEY,
+  "hashed_password" TEXT NOT NULL,
+  "email" TEXT NOT NULL,
+  "email_verified" BOOLEAN NOT NULL DEFAULT false,
+  "password_changed_at" DATETIME NOT NULL DEFAULT '0001-01-01 00:00:00',
+  "created_at" DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+  "totp_secret" TEXT NOT NULL DEFAULT '',
+  "totp_enabled" BOOLEAN NOT NULL DEFAULT false
+);
+
+CREATE TABLE "notes" (
+  "note_id" INTEGER PRIMARY KEY AUTOINCREMENT,
+  "owner" TEXT NOT NULL REFERENCES "user" ("username"),
+  "title" TEXT NOT NULL,
+  "content" TEXT NOT NULL,
+  "pinned" BOOLEAN NOT NULL DEFAULT false,
+  "archived" BOOLEAN NOT NULL DEFAULT false,
+  "created_at" DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+  "updated_at" DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE INDEX "notes_owner_note_id_idx" ON "notes" ("owner", "note_id");
+CREATE INDEX "notes_owner_created_at_idx" ON "notes" ("owner", "created_at" DESC);
+
+CREATE TABLE "tags" (
+  "tag_id" INTEGER PRIMARY KEY AUTOINCREMENT,
+  "owner" TEXT NOT NULL REFERENCES "user" ("username"),
+  "name" TEXT NOT NULL
+);
+
+CREATE INDEX "tags_owner_tag_id_idx" ON "tags" ("owner", "tag_id");
+
+CREATE TABLE "note_tags" (
+  "note_id" INTEGER NOT NULL REFERENCES "notes" ("note_id") ON DELETE CASCADE,
+  "tag_id" INTEGER NOT NULL REFERENCES "tags" ("tag_id") ON DELETE CASCADE,
+  PRIMARY KEY ("note_id", "tag_id")
+);
+
+CREATE INDEX "note_tags_tag_id_idx" ON "note_tags" ("tag_id");
+
+CREATE TABLE "sessions" (
+  "id" TEXT PRIMARY KEY,
+  "username" TEXT NOT NULL REFERENCES "user" ("username"),
+  "user_agent" TEXT NOT NULL,
+  "client_ip" TEXT NOT NULL,
+  "is_blocked" BOOLEAN NOT NULL DEFAULT false,
+  "expires_at" DATETIME NOT NULL,
+  "created_at" DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE INDEX "sessions_username_idx" ON "sessions" ("username");
+
+CREATE TABLE "user_tokens" (
+  "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+  "username" TEXT NOT NULL REFERENCES "user" ("username"),
+  "purpose" TEXT NOT NULL,
+  "token_hash" BLOB UNIQUE NOT NULL,
+  "email" TEXT NOT NULL,
+  "expires_at" DATETIME NOT NULL,
+  "used_at" DATETIME,
+  "created_at" DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE INDEX "user_tokens_username_purpose_idx" ON "user_tokens" ("username", "purpose");
+
+CREATE TABLE "recovery_codes" (
+  "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+  "username" TEXT NOT NULL REFERENCES "user" ("username"),
+  "code_hash" BLOB NOT NULL,
+  "used_at" DATETIME,
+  "created_at" DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE UNIQUE INDEX "recovery_codes_username_code_hash_idx" ON "recovery_codes" ("username", "code_hash");
+
+CREATE TABLE "oidc_states" (
+  "state_hash" BLOB PRIMARY KEY,
+  "nonce" TEXT NOT NULL,
+  "code_verifier" TEXT NOT NULL,
+  "expires_at" DATETIME NOT NULL,
+  "created_at" DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE TABLE "user_identities" (
+  "issuer" TEXT NOT NULL,
+  "subject" TEXT NOT NULL,
+  "username" TEXT NOT NULL REFERENCES "user" ("username"),
+  "email" TEXT NOT NULL,
+  "created_at" DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+  PRIMARY KEY ("issuer", "subject")
+);
+
+CREATE INDEX "user_identities_username_idx" ON "user_identities" ("username");
+
+CREATE TABLE "api_keys" (
+  "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+  "username" TEXT NOT NULL REFERENCES "user" ("username"),
+  "name" TEXT NOT NULL,
+  "prefix" TEXT NOT NULL,
+  "key_hash" BLOB UNIQUE NOT NULL,
+  "scope" TEXT NOT NULL,
+  "expires_at" DATETIME NOT NULL,
+  "last_used_at" DATETIME,
+  "created_at" DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE INDEX "api_keys_username_idx" ON "api_keys" ("username");
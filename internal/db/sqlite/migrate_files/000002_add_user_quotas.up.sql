@@ -0,0 +1,8 @@
+CREATE TABLE "user_quotas" (
+  "username" TEXT PRIMARY KEY REFERENCES "user" ("username") ON DELETE CASCADE,
+  "max_note_bytes" INTEGER,
+  "max_notes" INTEGER,
+  "max_tags" INTEGER,
+  "max_total_bytes" INTEGER,
+  "updated_at" DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
@@ -0,0 +1,3 @@
+ALTER TABLE user_tokens DROP COLUMN attempts;
+
+ALTER TABLE "user" DROP COLUMN totp_last_step;
@@ -0,0 +1,3 @@
+ALTER TABLE "user" ADD COLUMN totp_last_step INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE user_tokens ADD COLUMN attempts INTEGER NOT NULL DEFAULT 0;
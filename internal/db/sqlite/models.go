@@ -0,0 +1,113 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ApiKey struct {
+	ID         int64        `json:"id"`
+	Username   string       `json:"username"`
+	Name       string       `json:"name"`
+	Prefix     string       `json:"prefix"`
+	KeyHash    []byte       `json:"key_hash"`
+	Scope      string       `json:"scope"`
+	ExpiresAt  time.Time    `json:"expires_at"`
+	LastUsedAt sql.NullTime `json:"last_used_at"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+type Note struct {
+	NoteID    int32     `json:"note_id"`
+	Owner     string    `json:"owner"`
+	Title     string    `json:"title"`
+	Content   string    `json:"content"`
+	Pinned    bool      `json:"pinned"`
+	Archived  bool      `json:"archived"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type NoteTag struct {
+	NoteID int32 `json:"note_id"`
+	TagID  int32 `json:"tag_id"`
+}
+
+type OidcState struct {
+	StateHash    []byte    `json:"state_hash"`
+	Nonce        string    `json:"nonce"`
+	CodeVerifier string    `json:"code_verifier"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type RecoveryCode struct {
+	ID        int64        `json:"id"`
+	Username  string       `json:"username"`
+	CodeHash  []byte       `json:"code_hash"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type Session struct {
+	ID        uuid.UUID `json:"id"`
+	Username  string    `json:"username"`
+	UserAgent string    `json:"user_agent"`
+	ClientIp  string    `json:"client_ip"`
+	IsBlocked bool      `json:"is_blocked"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Tag struct {
+	TagID int32  `json:"tag_id"`
+	Owner string `json:"owner"`
+	Name  string `json:"name"`
+}
+
+type User struct {
+	Username          string    `json:"username"`
+	HashedPassword    string    `json:"hashed_password"`
+	Email             string    `json:"email"`
+	EmailVerified     bool      `json:"email_verified"`
+	PasswordChangedAt time.Time `json:"password_changed_at"`
+	CreatedAt         time.Time `json:"created_at"`
+	TotpSecret        string    `json:"totp_secret"`
+	TotpEnabled       bool      `json:"totp_enabled"`
+	TotpLastStep      int64     `json:"totp_last_step"`
+}
+
+type UserIdentity struct {
+	Issuer    string    `json:"issuer"`
+	Subject   string    `json:"subject"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type UserQuota struct {
+	Username      string        `json:"username"`
+	MaxNoteBytes  sql.NullInt64 `json:"max_note_bytes"`
+	MaxNotes      sql.NullInt64 `json:"max_notes"`
+	MaxTags       sql.NullInt64 `json:"max_tags"`
+	MaxTotalBytes sql.NullInt64 `json:"max_total_bytes"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+}
+
+type UserToken struct {
+	ID        int64        `json:"id"`
+	Username  string       `json:"username"`
+	Purpose   string       `json:"purpose"`
+	TokenHash []byte       `json:"token_hash"`
+	Email     string       `json:"email"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at"`
+	Attempts  int64        `json:"attempts"`
+}
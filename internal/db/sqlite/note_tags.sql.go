@@ -0,0 +1,135 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: note_tags.sql
+
+package sqlite
+
+import (
+	"context"
+	"time"
+)
+
+const addTagToNote = `-- name: AddTagToNote :one
+INSERT INTO note_tags (note_id, tag_id)
+VALUES (?, ?)
+ON CONFLICT DO NOTHING
+RETURNING note_id, tag_id
+`
+
+type AddTagToNoteParams struct {
+	NoteID int32 `json:"note_id"`
+	TagID  int32 `json:"tag_id"`
+}
+
+func (q *Queries) AddTagToNote(ctx context.Context, arg AddTagToNoteParams) (NoteTag, error) {
+	row := q.db.QueryRowContext(ctx, addTagToNote, arg.NoteID, arg.TagID)
+	var i NoteTag
+	err := row.Scan(&i.NoteID, &i.TagID)
+	return i, err
+}
+
+const getNotesForTag = `-- name: GetNotesForTag :many
+SELECT n.note_id, n.title, n.owner, n.content, n.pinned, n.archived, n.created_at, n.updated_at
+FROM notes n
+INNER JOIN note_tags nt ON n.note_id = nt.note_id
+WHERE nt.tag_id = ?1 AND n.owner = ?2
+`
+
+type GetNotesForTagParams struct {
+	TagID int32  `json:"tag_id"`
+	Owner string `json:"owner"`
+}
+
+type GetNotesForTagRow struct {
+	NoteID    int32     `json:"note_id"`
+	Title     string    `json:"title"`
+	Owner     string    `json:"owner"`
+	Content   string    `json:"content"`
+	Pinned    bool      `json:"pinned"`
+	Archived  bool      `json:"archived"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (q *Queries) GetNotesForTag(ctx context.Context, arg GetNotesForTagParams) ([]GetNotesForTagRow, error) {
+	rows, err := q.db.QueryContext(ctx, getNotesForTag, arg.TagID, arg.Owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetNotesForTagRow{}
+	for rows.Next() {
+		var i GetNotesForTagRow
+		if err := rows.Scan(
+			&i.NoteID,
+			&i.Title,
+			&i.Owner,
+			&i.Content,
+			&i.Pinned,
+			&i.Archived,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTagsForNote = `-- name: GetTagsForNote :many
+SELECT t.tag_id, t.name
+FROM tags t
+INNER JOIN note_tags nt ON t.tag_id = nt.tag_id
+WHERE nt.note_id = ?
+`
+
+type GetTagsForNoteRow struct {
+	TagID int32  `json:"tag_id"`
+	Name  string `json:"name"`
+}
+
+func (q *Queries) GetTagsForNote(ctx context.Context, noteID int32) ([]GetTagsForNoteRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTagsForNote, noteID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetTagsForNoteRow{}
+	for rows.Next() {
+		var i GetTagsForNoteRow
+		if err := rows.Scan(&i.TagID, &i.Name); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const removeTagFromNote = `-- name: RemoveTagFromNote :exec
+DELETE FROM note_tags
+WHERE note_id = ? AND tag_id = ?
+`
+
+type RemoveTagFromNoteParams struct {
+	NoteID int32 `json:"note_id"`
+	TagID  int32 `json:"tag_id"`
+}
+
+func (q *Queries) RemoveTagFromNote(ctx context.Context, arg RemoveTagFromNoteParams) error {
+	_, err := q.db.ExecContext(ctx, removeTagFromNote, arg.NoteID, arg.TagID)
+	return err
+}
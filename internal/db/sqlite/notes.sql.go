@@ -0,0 +1,209 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: notes.sql
+
+package sqlite
+
+import (
+	"context"
+	"time"
+)
+
+const createNote = `-- name: CreateNote :one
+INSERT INTO notes (
+  owner,
+  title,
+  content
+) VALUES (
+  ?, ?, ?
+)
+RETURNING note_id, owner, title, content, pinned, archived, created_at, updated_at
+`
+
+type CreateNoteParams struct {
+	Owner   string `json:"owner"`
+	Title   string `json:"title"`
+	Content string `json:"content"`
+}
+
+func (q *Queries) CreateNote(ctx context.Context, arg CreateNoteParams) (Note, error) {
+	row := q.db.QueryRowContext(ctx, createNote, arg.Owner, arg.Title, arg.Content)
+	var i Note
+	err := row.Scan(
+		&i.NoteID,
+		&i.Owner,
+		&i.Title,
+		&i.Content,
+		&i.Pinned,
+		&i.Archived,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const deleteNote = `-- name: DeleteNote :exec
+DELETE FROM notes
+WHERE note_id = ?
+`
+
+func (q *Queries) DeleteNote(ctx context.Context, noteID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteNote, noteID)
+	return err
+}
+
+const getNoteById = `-- name: GetNoteById :one
+SELECT note_id, owner, title, content, pinned, archived, created_at, updated_at FROM notes
+WHERE note_id = ?
+LIMIT 1
+`
+
+func (q *Queries) GetNoteById(ctx context.Context, noteID int32) (Note, error) {
+	row := q.db.QueryRowContext(ctx, getNoteById, noteID)
+	var i Note
+	err := row.Scan(
+		&i.NoteID,
+		&i.Owner,
+		&i.Title,
+		&i.Content,
+		&i.Pinned,
+		&i.Archived,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listNotes = `-- name: ListNotes :many
+SELECT note_id, owner, title, content, pinned, archived, created_at, updated_at FROM notes
+WHERE note_id > ?1 AND owner = ?2
+ORDER BY note_id
+LIMIT ?3
+`
+
+type ListNotesParams struct {
+	NoteID int32  `json:"note_id"`
+	Owner  string `json:"owner"`
+	Limit  int64  `json:"limit"`
+}
+
+func (q *Queries) ListNotes(ctx context.Context, arg ListNotesParams) ([]Note, error) {
+	rows, err := q.db.QueryContext(ctx, listNotes, arg.NoteID, arg.Owner, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Note{}
+	for rows.Next() {
+		var i Note
+		if err := rows.Scan(
+			&i.NoteID,
+			&i.Owner,
+			&i.Title,
+			&i.Content,
+			&i.Pinned,
+			&i.Archived,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchNotes = `-- name: SearchNotes :many
+SELECT note_id, owner, title, content, pinned, archived, created_at, updated_at FROM notes
+WHERE (title LIKE '%' || CAST(?1 AS TEXT) || '%' OR content LIKE '%' || CAST(?1 AS TEXT) || '%') AND owner = ?2
+ORDER BY created_at DESC, note_id DESC
+LIMIT ?4 OFFSET ?3
+`
+
+type SearchNotesParams struct {
+	Search string `json:"search"`
+	Owner  string `json:"owner"`
+	Offset int64  `json:"offset"`
+	Limit  int64  `json:"limit"`
+}
+
+func (q *Queries) SearchNotes(ctx context.Context, arg SearchNotesParams) ([]Note, error) {
+	rows, err := q.db.QueryContext(ctx, searchNotes,
+		arg.Search,
+		arg.Owner,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Note{}
+	for rows.Next() {
+		var i Note
+		if err := rows.Scan(
+			&i.NoteID,
+			&i.Owner,
+			&i.Title,
+			&i.Content,
+			&i.Pinned,
+			&i.Archived,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateNote = `-- name: UpdateNote :one
+UPDATE notes
+SET title = ?1,
+  content = ?2,
+  updated_at = ?3
+WHERE note_id = ?4
+RETURNING note_id, owner, title, content, pinned, archived, created_at, updated_at
+`
+
+type UpdateNoteParams struct {
+	Title   string    `json:"title"`
+	Content string    `json:"content"`
+	Now     time.Time `json:"now"`
+	NoteID  int32     `json:"note_id"`
+}
+
+func (q *Queries) UpdateNote(ctx context.Context, arg UpdateNoteParams) (Note, error) {
+	row := q.db.QueryRowContext(ctx, updateNote,
+		arg.Title,
+		arg.Content,
+		arg.Now,
+		arg.NoteID,
+	)
+	var i Note
+	err := row.Scan(
+		&i.NoteID,
+		&i.Owner,
+		&i.Title,
+		&i.Content,
+		&i.Pinned,
+		&i.Archived,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
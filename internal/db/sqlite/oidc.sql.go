@@ -0,0 +1,134 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: oidc.sql
+
+package sqlite
+
+import (
+	"context"
+	"time"
+)
+
+const consumeOIDCState = `-- name: ConsumeOIDCState :one
+DELETE FROM oidc_states
+WHERE state_hash = ?1
+  AND expires_at > ?2
+RETURNING state_hash, nonce, code_verifier, expires_at, created_at
+`
+
+type ConsumeOIDCStateParams struct {
+	StateHash []byte    `json:"state_hash"`
+	Now       time.Time `json:"now"`
+}
+
+func (q *Queries) ConsumeOIDCState(ctx context.Context, arg ConsumeOIDCStateParams) (OidcState, error) {
+	row := q.db.QueryRowContext(ctx, consumeOIDCState, arg.StateHash, arg.Now)
+	var i OidcState
+	err := row.Scan(
+		&i.StateHash,
+		&i.Nonce,
+		&i.CodeVerifier,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createOIDCState = `-- name: CreateOIDCState :one
+INSERT INTO oidc_states (
+  state_hash,
+  nonce,
+  code_verifier,
+  expires_at
+) VALUES (
+  ?, ?, ?, ?
+)
+RETURNING state_hash, nonce, code_verifier, expires_at, created_at
+`
+
+type CreateOIDCStateParams struct {
+	StateHash    []byte    `json:"state_hash"`
+	Nonce        string    `json:"nonce"`
+	CodeVerifier string    `json:"code_verifier"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateOIDCState(ctx context.Context, arg CreateOIDCStateParams) (OidcState, error) {
+	row := q.db.QueryRowContext(ctx, createOIDCState,
+		arg.StateHash,
+		arg.Nonce,
+		arg.CodeVerifier,
+		arg.ExpiresAt,
+	)
+	var i OidcState
+	err := row.Scan(
+		&i.StateHash,
+		&i.Nonce,
+		&i.CodeVerifier,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createUserIdentity = `-- name: CreateUserIdentity :one
+INSERT INTO user_identities (
+  issuer,
+  subject,
+  username,
+  email
+) VALUES (
+  ?, ?, ?, ?
+)
+RETURNING issuer, subject, username, email, created_at
+`
+
+type CreateUserIdentityParams struct {
+	Issuer   string `json:"issuer"`
+	Subject  string `json:"subject"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
+func (q *Queries) CreateUserIdentity(ctx context.Context, arg CreateUserIdentityParams) (UserIdentity, error) {
+	row := q.db.QueryRowContext(ctx, createUserIdentity,
+		arg.Issuer,
+		arg.Subject,
+		arg.Username,
+		arg.Email,
+	)
+	var i UserIdentity
+	err := row.Scan(
+		&i.Issuer,
+		&i.Subject,
+		&i.Username,
+		&i.Email,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getUserIdentity = `-- name: GetUserIdentity :one
+SELECT issuer, subject, username, email, created_at FROM user_identities
+WHERE issuer = ? AND subject = ?
+LIMIT 1
+`
+
+type GetUserIdentityParams struct {
+	Issuer  string `json:"issuer"`
+	Subject string `json:"subject"`
+}
+
+func (q *Queries) GetUserIdentity(ctx context.Context, arg GetUserIdentityParams) (UserIdentity, error) {
+	row := q.db.QueryRowContext(ctx, getUserIdentity, arg.Issuer, arg.Subject)
+	var i UserIdentity
+	err := row.Scan(
+		&i.Issuer,
+		&i.Subject,
+		&i.Username,
+		&i.Email,
+		&i.CreatedAt,
+	)
+	return i, err
+}
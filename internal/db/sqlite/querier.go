@@ -0,0 +1,492 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	Database "github.com/nilesh0729/Notes/internal/db/Result"
+)
+
+// querier runs the queries of Database.Querier on SQLite. It converts
+// between the types sqlc generates for both backends, passes the current
+// time to queries that use now() on Postgres, and stores every time in UTC
+// so that they compare as text.
+type querier struct {
+	q *Queries
+}
+
+func now() time.Time {
+	return time.Now().UTC()
+}
+
+func (querier querier) AddTagToNote(ctx context.Context, arg Database.AddTagToNoteParams) (Database.NoteTag, error) {
+	noteTag, err := querier.q.AddTagToNote(ctx, AddTagToNoteParams{
+		NoteID: arg.NoteID,
+		TagID:  arg.TagID,
+	})
+	return Database.NoteTag(noteTag), dbError(err)
+}
+
+func (querier querier) BlockUserSessions(ctx context.Context, username string) error {
+	return dbError(querier.q.BlockUserSessions(ctx, username))
+}
+
+func (querier querier) ConsumeOIDCState(ctx context.Context, stateHash []byte) (Database.OidcState, error) {
+	state, err := querier.q.ConsumeOIDCState(ctx, ConsumeOIDCStateParams{
+		StateHash: stateHash,
+		Now:       now(),
+	})
+	return Database.OidcState(state), dbError(err)
+}
+
+func (querier querier) ConsumeRecoveryCode(ctx context.Context, arg Database.ConsumeRecoveryCodeParams) (Database.RecoveryCode, error) {
+	code, err := querier.q.ConsumeRecoveryCode(ctx, ConsumeRecoveryCodeParams{
+		UsedAt:   sql.NullTime{Time: now(), Valid: true},
+		Username: arg.Username,
+		CodeHash: arg.CodeHash,
+	})
+	return recoveryCode(code), dbError(err)
+}
+
+func (querier querier) ClaimUserTokenAttempt(ctx context.Context, arg Database.ClaimUserTokenAttemptParams) (Database.UserToken, error) {
+	token, err := querier.q.ClaimUserTokenAttempt(ctx, ClaimUserTokenAttemptParams{
+		TokenHash:   arg.TokenHash,
+		Purpose:     arg.Purpose,
+		Now:         now(),
+		MaxAttempts: int64(arg.MaxAttempts),
+	})
+	return userToken(token), dbError(err)
+}
+
+func (querier querier) ConsumeUserToken(ctx context.Context, arg Database.ConsumeUserTokenParams) (Database.UserToken, error) {
+	t := now()
+	token, err := querier.q.ConsumeUserToken(ctx, ConsumeUserTokenParams{
+		UsedAt:    sql.NullTime{Time: t, Valid: true},
+		TokenHash: arg.TokenHash,
+		Purpose:   arg.Purpose,
+		Now:       t,
+	})
+	return userToken(token), dbError(err)
+}
+
+func (querier querier) CreateAPIKey(ctx context.Context, arg Database.CreateAPIKeyParams) (Database.ApiKey, error) {
+	key, err := querier.q.CreateAPIKey(ctx, CreateAPIKeyParams{
+		Username:  arg.Username,
+		Name:      arg.Name,
+		Prefix:    arg.Prefix,
+		KeyHash:   arg.KeyHash,
+		Scope:     arg.Scope,
+		ExpiresAt: arg.ExpiresAt.UTC(),
+	})
+	return apiKey(key), dbError(err)
+}
+
+func (querier querier) CreateNote(ctx context.Context, arg Database.CreateNoteParams) (Database.Note, error) {
+	note, err := querier.q.CreateNote(ctx, CreateNoteParams{
+		Owner:   arg.Owner,
+		Title:   arg.Title,
+		Content: arg.Content,
+	})
+	return Database.Note(note), dbError(err)
+}
+
+func (querier querier) CreateOIDCState(ctx context.Context, arg Database.CreateOIDCStateParams) (Database.OidcState, error) {
+	state, err := querier.q.CreateOIDCState(ctx, CreateOIDCStateParams{
+		StateHash:    arg.StateHash,
+		Nonce:        arg.Nonce,
+		CodeVerifier: arg.CodeVerifier,
+		ExpiresAt:    arg.ExpiresAt.UTC(),
+	})
+	return Database.OidcState(state), dbError(err)
+}
+
+func (querier querier) CreateRecoveryCode(ctx context.Context, arg Database.CreateRecoveryCodeParams) error {
+	return dbError(querier.q.CreateRecoveryCode(ctx, CreateRecoveryCodeParams{
+		Username: arg.Username,
+		CodeHash: arg.CodeHash,
+	}))
+}
+
+func (querier querier) CreateSession(ctx context.Context, arg Database.CreateSessionParams) (Database.Session, error) {
+	session, err := querier.q.CreateSession(ctx, CreateSessionParams{
+		ID:        arg.ID,
+		Username:  arg.Username,
+		UserAgent: arg.UserAgent,
+		ClientIp:  arg.ClientIp,
+		ExpiresAt: arg.ExpiresAt.UTC(),
+	})
+	return Database.Session(session), dbError(err)
+}
+
+func (querier querier) CreateTags(ctx context.Context, arg Database.CreateTagsParams) (Database.Tag, error) {
+	tag, err := querier.q.CreateTags(ctx, CreateTagsParams{
+		Owner: arg.Owner,
+		Name:  arg.Name,
+	})
+	return Database.Tag(tag), dbError(err)
+}
+
+func (querier querier) CreateUser(ctx context.Context, arg Database.CreateUserParams) (Database.User, error) {
+	user, err := querier.q.CreateUser(ctx, CreateUserParams{
+		Username:       arg.Username,
+		HashedPassword: arg.HashedPassword,
+		Email:          arg.Email,
+	})
+	return Database.User(user), dbError(err)
+}
+
+func (querier querier) CreateUserIdentity(ctx context.Context, arg Database.CreateUserIdentityParams) (Database.UserIdentity, error) {
+	identity, err := querier.q.CreateUserIdentity(ctx, CreateUserIdentityParams{
+		Issuer:   arg.Issuer,
+		Subject:  arg.Subject,
+		Username: arg.Username,
+		Email:    arg.Email,
+	})
+	return Database.UserIdentity(identity), dbError(err)
+}
+
+func (querier querier) CreateUserToken(ctx context.Context, arg Database.CreateUserTokenParams) (Database.UserToken, error) {
+	token, err := querier.q.CreateUserToken(ctx, CreateUserTokenParams{
+		Username:  arg.Username,
+		Purpose:   arg.Purpose,
+		TokenHash: arg.TokenHash,
+		Email:     arg.Email,
+		ExpiresAt: arg.ExpiresAt.UTC(),
+	})
+	return userToken(token), dbError(err)
+}
+
+func (querier querier) DeleteAPIKey(ctx context.Context, arg Database.DeleteAPIKeyParams) (Database.ApiKey, error) {
+	key, err := querier.q.DeleteAPIKey(ctx, DeleteAPIKeyParams{
+		ID:       arg.ID,
+		Username: arg.Username,
+	})
+	return apiKey(key), dbError(err)
+}
+
+func (querier querier) DeleteNote(ctx context.Context, noteID int32) error {
+	return dbError(querier.q.DeleteNote(ctx, noteID))
+}
+
+func (querier querier) DeleteRecoveryCodes(ctx context.Context, username string) error {
+	return dbError(querier.q.DeleteRecoveryCodes(ctx, username))
+}
+
+func (querier querier) DeleteTag(ctx context.Context, tagID int32) error {
+	return dbError(querier.q.DeleteTag(ctx, tagID))
+}
+
+func (querier querier) DeleteUserAPIKeys(ctx context.Context, username string) error {
+	return dbError(querier.q.DeleteUserAPIKeys(ctx, username))
+}
+
+func (querier querier) DeleteUserTokens(ctx context.Context, arg Database.DeleteUserTokensParams) error {
+	return dbError(querier.q.DeleteUserTokens(ctx, DeleteUserTokensParams{
+		Username: arg.Username,
+		Purpose:  arg.Purpose,
+	}))
+}
+
+func (querier querier) EnableTOTP(ctx context.Context, username string) (Database.User, error) {
+	user, err := querier.q.EnableTOTP(ctx, username)
+	return Database.User(user), dbError(err)
+}
+
+func (querier querier) GetAPIKeyByHash(ctx context.Context, keyHash []byte) (Database.ApiKey, error) {
+	key, err := querier.q.GetAPIKeyByHash(ctx, keyHash)
+	return apiKey(key), dbError(err)
+}
+
+func (querier querier) GetActiveUserToken(ctx context.Context, arg Database.GetActiveUserTokenParams) (Database.UserToken, error) {
+	token, err := querier.q.GetActiveUserToken(ctx, GetActiveUserTokenParams{
+		TokenHash: arg.TokenHash,
+		Purpose:   arg.Purpose,
+		Now:       now(),
+	})
+	return userToken(token), dbError(err)
+}
+
+func (querier querier) GetNoteById(ctx context.Context, noteID int32) (Database.Note, error) {
+	note, err := querier.q.GetNoteById(ctx, noteID)
+	return Database.Note(note), dbError(err)
+}
+
+func (querier querier) GetNotesForTag(ctx context.Context, arg Database.GetNotesForTagParams) ([]Database.GetNotesForTagRow, error) {
+	rows, err := querier.q.GetNotesForTag(ctx, GetNotesForTagParams{
+		TagID: arg.TagID,
+		Owner: arg.Owner,
+	})
+	return convertAll(rows, func(row GetNotesForTagRow) Database.GetNotesForTagRow {
+		return Database.GetNotesForTagRow(row)
+	}), dbError(err)
+}
+
+func (querier querier) GetSession(ctx context.Context, id uuid.UUID) (Database.Session, error) {
+	session, err := querier.q.GetSession(ctx, id)
+	return Database.Session(session), dbError(err)
+}
+
+func (querier querier) GetTag(ctx context.Context, tagID int32) (Database.Tag, error) {
+	tag, err := querier.q.GetTag(ctx, tagID)
+	return Database.Tag(tag), dbError(err)
+}
+
+func (querier querier) GetTagsForNote(ctx context.Context, noteID int32) ([]Database.GetTagsForNoteRow, error) {
+	rows, err := querier.q.GetTagsForNote(ctx, noteID)
+	return convertAll(rows, func(row GetTagsForNoteRow) Database.GetTagsForNoteRow {
+		return Database.GetTagsForNoteRow(row)
+	}), dbError(err)
+}
+
+func (querier querier) GetUser(ctx context.Context, username string) (Database.User, error) {
+	user, err := querier.q.GetUser(ctx, username)
+	return Database.User(user), dbError(err)
+}
+
+func (querier querier) GetUserIdentity(ctx context.Context, arg Database.GetUserIdentityParams) (Database.UserIdentity, error) {
+	identity, err := querier.q.GetUserIdentity(ctx, GetUserIdentityParams{
+		Issuer:  arg.Issuer,
+		Subject: arg.Subject,
+	})
+	return Database.UserIdentity(identity), dbError(err)
+}
+
+func (querier querier) GetUserQuota(ctx context.Context, username string) (Database.UserQuota, error) {
+	quota, err := querier.q.GetUserQuota(ctx, username)
+	return userQuota(quota), dbError(err)
+}
+
+func (querier querier) GetUserUsage(ctx context.Context, username string) (Database.GetUserUsageRow, error) {
+	usage, err := querier.q.GetUserUsage(ctx, username)
+	return Database.GetUserUsageRow(usage), dbError(err)
+}
+
+func (querier querier) ListAPIKeys(ctx context.Context, username string) ([]Database.ApiKey, error) {
+	keys, err := querier.q.ListAPIKeys(ctx, username)
+	return convertAll(keys, apiKey), dbError(err)
+}
+
+func (querier querier) ListNotes(ctx context.Context, arg Database.ListNotesParams) ([]Database.Note, error) {
+	notes, err := querier.q.ListNotes(ctx, ListNotesParams{
+		NoteID: arg.NoteID,
+		Owner:  arg.Owner,
+		Limit:  int64(arg.Limit),
+	})
+	return convertAll(notes, note), dbError(err)
+}
+
+func (querier querier) ListTags(ctx context.Context, arg Database.ListTagsParams) ([]Database.Tag, error) {
+	tags, err := querier.q.ListTags(ctx, ListTagsParams{
+		TagID: arg.TagID,
+		Owner: arg.Owner,
+		Limit: int64(arg.Limit),
+	})
+	return convertAll(tags, func(tag Tag) Database.Tag {
+		return Database.Tag(tag)
+	}), dbError(err)
+}
+
+func (querier querier) ListUsersByEmail(ctx context.Context, email string) ([]Database.User, error) {
+	users, err := querier.q.ListUsersByEmail(ctx, email)
+	return convertAll(users, func(user User) Database.User {
+		return Database.User(user)
+	}), dbError(err)
+}
+
+func (querier querier) MarkEmailVerified(ctx context.Context, arg Database.MarkEmailVerifiedParams) (Database.User, error) {
+	user, err := querier.q.MarkEmailVerified(ctx, MarkEmailVerifiedParams{
+		Username: arg.Username,
+		Email:    arg.Email,
+	})
+	return Database.User(user), dbError(err)
+}
+
+func (querier querier) RecordTOTPStep(ctx context.Context, arg Database.RecordTOTPStepParams) (int64, error) {
+	rows, err := querier.q.RecordTOTPStep(ctx, RecordTOTPStepParams{
+		Step:     arg.Step,
+		Username: arg.Username,
+	})
+	return rows, dbError(err)
+}
+
+func (querier querier) RehashUserPassword(ctx context.Context, arg Database.RehashUserPasswordParams) (int64, error) {
+	rows, err := querier.q.RehashUserPassword(ctx, RehashUserPasswordParams{
+		NewHashedPassword: arg.NewHashedPassword,
+		Username:          arg.Username,
+		OldHashedPassword: arg.OldHashedPassword,
+	})
+	return rows, dbError(err)
+}
+
+func (querier querier) RemoveTagFromNote(ctx context.Context, arg Database.RemoveTagFromNoteParams) error {
+	return dbError(querier.q.RemoveTagFromNote(ctx, RemoveTagFromNoteParams{
+		NoteID: arg.NoteID,
+		TagID:  arg.TagID,
+	}))
+}
+
+func (querier querier) ResetUserPassword(ctx context.Context, arg Database.ResetUserPasswordParams) (Database.User, error) {
+	user, err := querier.q.ResetUserPassword(ctx, ResetUserPasswordParams{
+		HashedPassword: arg.HashedPassword,
+		Now:            now(),
+		Username:       arg.Username,
+		Email:          arg.Email,
+	})
+	return Database.User(user), dbError(err)
+}
+
+func (querier querier) SearchNotes(ctx context.Context, arg Database.SearchNotesParams) ([]Database.Note, error) {
+	notes, err := querier.q.SearchNotes(ctx, SearchNotesParams{
+		Search: arg.Search,
+		Owner:  arg.Owner,
+		Offset: int64(arg.Offset),
+		Limit:  int64(arg.Limit),
+	})
+	return convertAll(notes, note), dbError(err)
+}
+
+func (querier querier) SetTOTPSecret(ctx context.Context, arg Database.SetTOTPSecretParams) (Database.User, error) {
+	user, err := querier.q.SetTOTPSecret(ctx, SetTOTPSecretParams{
+		TotpSecret: arg.TotpSecret,
+		Username:   arg.Username,
+	})
+	return Database.User(user), dbError(err)
+}
+
+func (querier querier) TouchAPIKey(ctx context.Context, id int64) error {
+	return dbError(querier.q.TouchAPIKey(ctx, TouchAPIKeyParams{
+		LastUsedAt: sql.NullTime{Time: now(), Valid: true},
+		ID:         id,
+	}))
+}
+
+func (querier querier) UpdateNote(ctx context.Context, arg Database.UpdateNoteParams) (Database.Note, error) {
+	note, err := querier.q.UpdateNote(ctx, UpdateNoteParams{
+		Title:   arg.Title,
+		Content: arg.Content,
+		Now:     now(),
+		NoteID:  arg.NoteID,
+	})
+	return Database.Note(note), dbError(err)
+}
+
+func (querier querier) UpdateTag(ctx context.Context, arg Database.UpdateTagParams) (Database.Tag, error) {
+	tag, err := querier.q.UpdateTag(ctx, UpdateTagParams{
+		Name:  arg.Name,
+		TagID: arg.TagID,
+	})
+	return Database.Tag(tag), dbError(err)
+}
+
+func (querier querier) UpdateUserEmail(ctx context.Context, arg Database.UpdateUserEmailParams) (Database.User, error) {
+	user, err := querier.q.UpdateUserEmail(ctx, UpdateUserEmailParams{
+		Email:    arg.Email,
+		Username: arg.Username,
+	})
+	return Database.User(user), dbError(err)
+}
+
+func (querier querier) UpdateUserPassword(ctx context.Context, arg Database.UpdateUserPasswordParams) (Database.User, error) {
+	user, err := querier.q.UpdateUserPassword(ctx, UpdateUserPasswordParams{
+		HashedPassword: arg.HashedPassword,
+		Now:            now(),
+		Username:       arg.Username,
+	})
+	return Database.User(user), dbError(err)
+}
+
+func (querier querier) UpsertUserQuota(ctx context.Context, arg Database.UpsertUserQuotaParams) (Database.UserQuota, error) {
+	quota, err := querier.q.UpsertUserQuota(ctx, UpsertUserQuotaParams{
+		Username:      arg.Username,
+		MaxNoteBytes:  nullInt64(arg.MaxNoteBytes),
+		MaxNotes:      nullInt64(arg.MaxNotes),
+		MaxTags:       nullInt64(arg.MaxTags),
+		MaxTotalBytes: nullInt64(arg.MaxTotalBytes),
+		UpdatedAt:     now(),
+	})
+	return userQuota(quota), dbError(err)
+}
+
+func note(note Note) Database.Note {
+	return Database.Note(note)
+}
+
+func apiKey(key ApiKey) Database.ApiKey {
+	return Database.ApiKey{
+		ID:         key.ID,
+		Username:   key.Username,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		KeyHash:    key.KeyHash,
+		Scope:      key.Scope,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: timestamptz(key.LastUsedAt),
+		CreatedAt:  key.CreatedAt,
+	}
+}
+
+func recoveryCode(code RecoveryCode) Database.RecoveryCode {
+	return Database.RecoveryCode{
+		ID:        code.ID,
+		Username:  code.Username,
+		CodeHash:  code.CodeHash,
+		UsedAt:    timestamptz(code.UsedAt),
+		CreatedAt: code.CreatedAt,
+	}
+}
+
+func userToken(token UserToken) Database.UserToken {
+	return Database.UserToken{
+		ID:        token.ID,
+		Username:  token.Username,
+		Purpose:   token.Purpose,
+		TokenHash: token.TokenHash,
+		Email:     token.Email,
+		ExpiresAt: token.ExpiresAt,
+		UsedAt:    timestamptz(token.UsedAt),
+		CreatedAt: token.CreatedAt,
+		Attempts:  int32(token.Attempts),
+	}
+}
+
+func userQuota(quota UserQuota) Database.UserQuota {
+	return Database.UserQuota{
+		Username:      quota.Username,
+		MaxNoteBytes:  optionalInt64(quota.MaxNoteBytes),
+		MaxNotes:      optionalInt64(quota.MaxNotes),
+		MaxTags:       optionalInt64(quota.MaxTags),
+		MaxTotalBytes: optionalInt64(quota.MaxTotalBytes),
+		UpdatedAt:     quota.UpdatedAt,
+	}
+}
+
+func optionalInt64(n sql.NullInt64) *int64 {
+	if !n.Valid {
+		return nil
+	}
+	return &n.Int64
+}
+
+func nullInt64(n *int64) sql.NullInt64 {
+	if n == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: *n, Valid: true}
+}
+
+func timestamptz(t sql.NullTime) pgtype.Timestamptz {
+	return pgtype.Timestamptz{Time: t.Time, Valid: t.Valid}
+}
+
+// convertAll converts every item, keeping empty slices non-nil the way sqlc
+// returns them.
+func convertAll[From, To any](items []From, convert func(From) To) []To {
+	res := make([]To, len(items))
+	for i, item := range items {
+		res[i] = convert(item)
+	}
+	return res
+}
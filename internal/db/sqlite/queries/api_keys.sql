@@ -0,0 +1,36 @@
+-- name: CreateAPIKey :one
+INSERT INTO api_keys (
+  username,
+  name,
+  prefix,
+  key_hash,
+  scope,
+  expires_at
+) VALUES (
+  ?, ?, ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: GetAPIKeyByHash :one
+SELECT * FROM api_keys
+WHERE key_hash = ?
+LIMIT 1;
+
+-- name: ListAPIKeys :many
+SELECT * FROM api_keys
+WHERE username = ?
+ORDER BY id;
+
+-- name: TouchAPIKey :exec
+UPDATE api_keys
+SET last_used_at = sqlc.arg(last_used_at)
+WHERE id = sqlc.arg(id);
+
+-- name: DeleteAPIKey :one
+DELETE FROM api_keys
+WHERE id = ? AND username = ?
+RETURNING *;
+
+-- name: DeleteUserAPIKeys :exec
+DELETE FROM api_keys
+WHERE username = ?;
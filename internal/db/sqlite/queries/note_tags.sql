@@ -0,0 +1,21 @@
+-- name: AddTagToNote :one
+INSERT INTO note_tags (note_id, tag_id)
+VALUES (?, ?)
+ON CONFLICT DO NOTHING
+RETURNING note_id, tag_id;
+
+-- name: GetTagsForNote :many
+SELECT t.tag_id, t.name
+FROM tags t
+INNER JOIN note_tags nt ON t.tag_id = nt.tag_id
+WHERE nt.note_id = ?;
+
+-- name: GetNotesForTag :many
+SELECT n.note_id, n.title, n.owner, n.content, n.pinned, n.archived, n.created_at, n.updated_at
+FROM notes n
+INNER JOIN note_tags nt ON n.note_id = nt.note_id
+WHERE nt.tag_id = sqlc.arg(tag_id) AND n.owner = sqlc.arg(owner);
+
+-- name: RemoveTagFromNote :exec
+DELETE FROM note_tags
+WHERE note_id = ? AND tag_id = ?;
@@ -0,0 +1,38 @@
+-- name: CreateNote :one
+INSERT INTO notes (
+  owner,
+  title,
+  content
+) VALUES (
+  ?, ?, ?
+)
+RETURNING *;
+
+-- name: GetNoteById :one
+SELECT * FROM notes
+WHERE note_id = ?
+LIMIT 1;
+
+-- name: ListNotes :many
+SELECT * FROM notes
+WHERE note_id > sqlc.arg(note_id) AND owner = sqlc.arg(owner)
+ORDER BY note_id
+LIMIT sqlc.arg(limit);
+
+-- name: SearchNotes :many
+SELECT * FROM notes
+WHERE (title LIKE '%' || CAST(sqlc.arg(search) AS TEXT) || '%' OR content LIKE '%' || CAST(sqlc.arg(search) AS TEXT) || '%') AND owner = sqlc.arg(owner)
+ORDER BY created_at DESC, note_id DESC
+LIMIT sqlc.arg(limit) OFFSET sqlc.arg(offset);
+
+-- name: UpdateNote :one
+UPDATE notes
+SET title = sqlc.arg(title),
+  content = sqlc.arg(content),
+  updated_at = sqlc.arg(now)
+WHERE note_id = sqlc.arg(note_id)
+RETURNING *;
+
+-- name: DeleteNote :exec
+DELETE FROM notes
+WHERE note_id = ?;
@@ -0,0 +1,32 @@
+-- name: CreateOIDCState :one
+INSERT INTO oidc_states (
+  state_hash,
+  nonce,
+  code_verifier,
+  expires_at
+) VALUES (
+  ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: ConsumeOIDCState :one
+DELETE FROM oidc_states
+WHERE state_hash = sqlc.arg(state_hash)
+  AND expires_at > sqlc.arg(now)
+RETURNING *;
+
+-- name: CreateUserIdentity :one
+INSERT INTO user_identities (
+  issuer,
+  subject,
+  username,
+  email
+) VALUES (
+  ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: GetUserIdentity :one
+SELECT * FROM user_identities
+WHERE issuer = ? AND subject = ?
+LIMIT 1;
@@ -0,0 +1,34 @@
+-- name: GetUserQuota :one
+SELECT * FROM user_quotas
+WHERE username = ?
+LIMIT 1;
+
+-- name: UpsertUserQuota :one
+INSERT INTO user_quotas (
+  username,
+  max_note_bytes,
+  max_notes,
+  max_tags,
+  max_total_bytes,
+  updated_at
+) VALUES (
+  sqlc.arg(username),
+  sqlc.arg(max_note_bytes),
+  sqlc.arg(max_notes),
+  sqlc.arg(max_tags),
+  sqlc.arg(max_total_bytes),
+  sqlc.arg(updated_at)
+)
+ON CONFLICT (username) DO UPDATE
+SET max_note_bytes = excluded.max_note_bytes,
+    max_notes = excluded.max_notes,
+    max_tags = excluded.max_tags,
+    max_total_bytes = excluded.max_total_bytes,
+    updated_at = excluded.updated_at
+RETURNING *;
+
+-- name: GetUserUsage :one
+SELECT
+  CAST((SELECT count(*) FROM notes n WHERE n.owner = sqlc.arg(username)) AS INTEGER) AS notes,
+  CAST((SELECT COALESCE(sum(length(CAST(n.title AS BLOB)) + length(CAST(n.content AS BLOB))), 0) FROM notes n WHERE n.owner = sqlc.arg(username)) AS INTEGER) AS total_bytes,
+  CAST((SELECT count(*) FROM tags t WHERE t.owner = sqlc.arg(username)) AS INTEGER) AS tags;
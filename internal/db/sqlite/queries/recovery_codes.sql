@@ -0,0 +1,19 @@
+-- name: CreateRecoveryCode :exec
+INSERT INTO recovery_codes (
+  username,
+  code_hash
+) VALUES (
+  ?, ?
+);
+
+-- name: DeleteRecoveryCodes :exec
+DELETE FROM recovery_codes
+WHERE username = ?;
+
+-- name: ConsumeRecoveryCode :one
+UPDATE recovery_codes
+SET used_at = sqlc.arg(used_at)
+WHERE username = sqlc.arg(username)
+  AND code_hash = sqlc.arg(code_hash)
+  AND used_at IS NULL
+RETURNING *;
@@ -0,0 +1,29 @@
+-- name: CreateTags :one
+INSERT INTO tags (
+  owner,
+  name
+) VALUES (
+  ?, ?
+)
+RETURNING *;
+
+-- name: GetTag :one
+SELECT * FROM tags
+WHERE tag_id = ?
+LIMIT 1;
+
+-- name: ListTags :many
+SELECT * FROM tags
+WHERE tag_id > sqlc.arg(tag_id) AND owner = sqlc.arg(owner)
+ORDER BY tag_id
+LIMIT sqlc.arg(limit);
+
+-- name: UpdateTag :one
+UPDATE tags
+SET name = sqlc.arg(name)
+WHERE tag_id = sqlc.arg(tag_id)
+RETURNING *;
+
+-- name: DeleteTag :exec
+DELETE FROM tags
+WHERE tag_id = ?;
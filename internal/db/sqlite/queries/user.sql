@@ -0,0 +1,72 @@
+-- name: CreateUser :one
+INSERT INTO "user" (
+  username,
+  hashed_password,
+  email
+) VALUES (
+  ?, ?, ?
+)
+RETURNING *;
+
+-- name: GetUser :one
+SELECT * FROM "user"
+WHERE username = ?
+LIMIT 1;
+
+-- name: UpdateUserEmail :one
+UPDATE "user"
+SET email = sqlc.arg(email),
+  email_verified = false
+WHERE username = sqlc.arg(username)
+RETURNING *;
+
+-- name: UpdateUserPassword :one
+UPDATE "user"
+SET hashed_password = sqlc.arg(hashed_password),
+  password_changed_at = sqlc.arg(now)
+WHERE username = sqlc.arg(username)
+RETURNING *;
+
+-- name: ResetUserPassword :one
+UPDATE "user"
+SET hashed_password = sqlc.arg(hashed_password),
+  password_changed_at = sqlc.arg(now)
+WHERE username = sqlc.arg(username) AND email = sqlc.arg(email)
+RETURNING *;
+
+-- name: ListUsersByEmail :many
+SELECT * FROM "user"
+WHERE email = ?
+ORDER BY username;
+
+-- name: MarkEmailVerified :one
+UPDATE "user"
+SET email_verified = true
+WHERE username = ? AND email = ?
+RETURNING *;
+
+-- name: SetTOTPSecret :one
+UPDATE "user"
+SET totp_secret = sqlc.arg(totp_secret),
+  totp_enabled = false,
+  totp_last_step = 0
+WHERE username = sqlc.arg(username)
+RETURNING *;
+
+-- name: EnableTOTP :one
+UPDATE "user"
+SET totp_enabled = true
+WHERE username = ? AND totp_secret <> ''
+RETURNING *;
+
+-- name: RehashUserPassword :execrows
+UPDATE "user"
+SET hashed_password = sqlc.arg(new_hashed_password)
+WHERE username = sqlc.arg(username)
+  AND hashed_password = sqlc.arg(old_hashed_password);
+
+-- name: RecordTOTPStep :execrows
+UPDATE "user"
+SET totp_last_step = sqlc.arg(step)
+WHERE username = sqlc.arg(username)
+  AND totp_last_step < sqlc.arg(step);
@@ -0,0 +1,42 @@
+-- name: CreateUserToken :one
+INSERT INTO user_tokens (
+  username,
+  purpose,
+  token_hash,
+  email,
+  expires_at
+) VALUES (
+  ?, ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: ConsumeUserToken :one
+UPDATE user_tokens
+SET used_at = sqlc.arg(used_at)
+WHERE token_hash = sqlc.arg(token_hash)
+  AND purpose = sqlc.arg(purpose)
+  AND used_at IS NULL
+  AND expires_at > sqlc.arg(now)
+RETURNING *;
+
+-- name: GetActiveUserToken :one
+SELECT * FROM user_tokens
+WHERE token_hash = sqlc.arg(token_hash)
+  AND purpose = sqlc.arg(purpose)
+  AND used_at IS NULL
+  AND expires_at > sqlc.arg(now)
+LIMIT 1;
+
+-- name: ClaimUserTokenAttempt :one
+UPDATE user_tokens
+SET attempts = attempts + 1
+WHERE token_hash = sqlc.arg(token_hash)
+  AND purpose = sqlc.arg(purpose)
+  AND used_at IS NULL
+  AND expires_at > sqlc.arg(now)
+  AND attempts < sqlc.arg(max_attempts)
+RETURNING *;
+
+-- name: DeleteUserTokens :exec
+DELETE FROM user_tokens
+WHERE username = ? AND purpose = ?;
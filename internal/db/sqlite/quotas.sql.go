@@ -0,0 +1,107 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: quotas.sql
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const getUserQuota = `-- name: GetUserQuota :one
+SELECT username, max_note_bytes, max_notes, max_tags, max_total_bytes, updated_at FROM user_quotas
+WHERE username = ?
+LIMIT 1
+`
+
+func (q *Queries) GetUserQuota(ctx context.Context, username string) (UserQuota, error) {
+	row := q.db.QueryRowContext(ctx, getUserQuota, username)
+	var i UserQuota
+	err := row.Scan(
+		&i.Username,
+		&i.MaxNoteBytes,
+		&i.MaxNotes,
+		&i.MaxTags,
+		&i.MaxTotalBytes,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getUserUsage = `-- name: GetUserUsage :one
+SELECT
+  CAST((SELECT count(*) FROM notes n WHERE n.owner = ?1) AS INTEGER) AS notes,
+  CAST((SELECT COALESCE(sum(length(CAST(n.title AS BLOB)) + length(CAST(n.content AS BLOB))), 0) FROM notes n WHERE n.owner = ?1) AS INTEGER) AS total_bytes,
+  CAST((SELECT count(*) FROM tags t WHERE t.owner = ?1) AS INTEGER) AS tags
+`
+
+type GetUserUsageRow struct {
+	Notes      int64 `json:"notes"`
+	TotalBytes int64 `json:"total_bytes"`
+	Tags       int64 `json:"tags"`
+}
+
+func (q *Queries) GetUserUsage(ctx context.Context, username string) (GetUserUsageRow, error) {
+	row := q.db.QueryRowContext(ctx, getUserUsage, username)
+	var i GetUserUsageRow
+	err := row.Scan(&i.Notes, &i.TotalBytes, &i.Tags)
+	return i, err
+}
+
+const upsertUserQuota = `-- name: UpsertUserQuota :one
+INSERT INTO user_quotas (
+  username,
+  max_note_bytes,
+  max_notes,
+  max_tags,
+  max_total_bytes,
+  updated_at
+) VALUES (
+  ?1,
+  ?2,
+  ?3,
+  ?4,
+  ?5,
+  ?6
+)
+ON CONFLICT (username) DO UPDATE
+SET max_note_bytes = excluded.max_note_bytes,
+    max_notes = excluded.max_notes,
+    max_tags = excluded.max_tags,
+    max_total_bytes = excluded.max_total_bytes,
+    updated_at = excluded.updated_at
+RETURNING username, max_note_bytes, max_notes, max_tags, max_total_bytes, updated_at
+`
+
+type UpsertUserQuotaParams struct {
+	Username      string        `json:"username"`
+	MaxNoteBytes  sql.NullInt64 `json:"max_note_bytes"`
+	MaxNotes      sql.NullInt64 `json:"max_notes"`
+	MaxTags       sql.NullInt64 `json:"max_tags"`
+	MaxTotalBytes sql.NullInt64 `json:"max_total_bytes"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+}
+
+func (q *Queries) UpsertUserQuota(ctx context.Context, arg UpsertUserQuotaParams) (UserQuota, error) {
+	row := q.db.QueryRowContext(ctx, upsertUserQuota,
+		arg.Username,
+		arg.MaxNoteBytes,
+		arg.MaxNotes,
+		arg.MaxTags,
+		arg.MaxTotalBytes,
+		arg.UpdatedAt,
+	)
+	var i UserQuota
+	err := row.Scan(
+		&i.Username,
+		&i.MaxNoteBytes,
+		&i.MaxNotes,
+		&i.MaxTags,
+		&i.MaxTotalBytes,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,68 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: recovery_codes.sql
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+)
+
+const consumeRecoveryCode = `-- name: ConsumeRecoveryCode :one
+UPDATE recovery_codes
+SET used_at = ?1
+WHERE username = ?2
+  AND code_hash = ?3
+  AND used_at IS NULL
+RETURNING id, username, code_hash, used_at, created_at
+`
+
+type ConsumeRecoveryCodeParams struct {
+	UsedAt   sql.NullTime `json:"used_at"`
+	Username string       `json:"username"`
+	CodeHash []byte       `json:"code_hash"`
+}
+
+func (q *Queries) ConsumeRecoveryCode(ctx context.Context, arg ConsumeRecoveryCodeParams) (RecoveryCode, error) {
+	row := q.db.QueryRowContext(ctx, consumeRecoveryCode, arg.UsedAt, arg.Username, arg.CodeHash)
+	var i RecoveryCode
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.CodeHash,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createRecoveryCode = `-- name: CreateRecoveryCode :exec
+INSERT INTO recovery_codes (
+  username,
+  code_hash
+) VALUES (
+  ?, ?
+)
+`
+
+type CreateRecoveryCodeParams struct {
+	Username string `json:"username"`
+	CodeHash []byte `json:"code_hash"`
+}
+
+func (q *Queries) CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) error {
+	_, err := q.db.ExecContext(ctx, createRecoveryCode, arg.Username, arg.CodeHash)
+	return err
+}
+
+const deleteRecoveryCodes = `-- name: DeleteRecoveryCodes :exec
+DELETE FROM recovery_codes
+WHERE username = ?
+`
+
+func (q *Queries) DeleteRecoveryCodes(ctx context.Context, username string) error {
+	_, err := q.db.ExecContext(ctx, deleteRecoveryCodes, username)
+	return err
+}
@@ -0,0 +1,143 @@
+// Package sqlite implements Database.Store on SQLite, for running the
+// server without a Postgres instance. Its queries mirror the Postgres ones
+// and are generated by sqlc from the queries and migrate_files directories.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	Database "github.com/nilesh0729/Notes/internal/db/Result"
+	"github.com/nilesh0729/Notes/internal/util"
+	sqlite3 "modernc.org/sqlite"
+	sqlite3lib "modernc.org/sqlite/lib"
+)
+
+// DriverName is the database/sql driver the backend uses.
+const DriverName = "sqlite"
+
+// Store implements Database.Store on a SQLite database.
+type Store struct {
+	querier
+	Database.Transactions
+	db *sql.DB
+}
+
+// NewStore creates a Store on db, which has to be opened by Open.
+func NewStore(db *sql.DB) Database.Store {
+	store := &Store{
+		querier: querier{q: New(db)},
+		db:      db,
+	}
+	store.Transactions = Database.NewTransactions(store.execTx)
+	return store
+}
+
+// execTx runs fn inside a database transaction, rolling back if it fails.
+func (store *Store) execTx(ctx context.Context, fn func(Database.Querier) error) error {
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return dbError(err)
+	}
+
+	err = fn(querier{q: New(tx)})
+	if err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("tx err: %v, rb err: %v", err, rbErr)
+		}
+		return err
+	}
+
+	return dbError(tx.Commit())
+}
+
+// Ping checks that the database file can be read.
+func (store *Store) Ping(ctx context.Context) error {
+	return store.db.PingContext(ctx)
+}
+
+// MigrationVersion returns the schema version golang-migrate recorded, and
+// whether the migration to it failed half way.
+func (store *Store) MigrationVersion(ctx context.Context) (version int64, dirty bool, err error) {
+	err = store.db.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) || isMissingTable(err) {
+		// No migration has run yet.
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
+
+// Open opens the SQLite database at config.DBSource, a file path or
+// "file:" URI. Foreign keys are enforced, writers wait for each other
+// instead of failing with SQLITE_BUSY, and transactions take the write
+// lock when they begin so that they cannot deadlock upgrading it.
+func Open(ctx context.Context, config util.Config) (*sql.DB, error) {
+	dsn := config.DBSource
+	if strings.Contains(dsn, "?") {
+		dsn += "&"
+	} else {
+		dsn += "?"
+	}
+	dsn += "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_time_format=sqlite&_txlock=immediate"
+
+	db, err := sql.Open(DriverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.DBMaxOpenConns > 0 {
+		db.SetMaxOpenConns(config.DBMaxOpenConns)
+	}
+	db.SetMaxIdleConns(max(config.DBMinConns, 2))
+	db.SetConnMaxLifetime(config.DBConnMaxLifetime)
+	db.SetConnMaxIdleTime(config.DBConnMaxIdleTime)
+
+	ctx, cancel := context.WithTimeout(ctx, config.DBConnectTimeout)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// constraintError reports a constraint violation with the SQLSTATE code
+// Postgres would use, so Database.ErrorCode works on both backends.
+type constraintError struct {
+	code string
+	err  error
+}
+
+func (e *constraintError) Error() string    { return e.err.Error() }
+func (e *constraintError) Unwrap() error    { return e.err }
+func (e *constraintError) SQLState() string { return e.code }
+
+// dbError translates the errors of the SQLite driver into those the rest of
+// the server expects from a Store.
+func dbError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return Database.ErrRecordNotFound
+	}
+
+	var sqliteErr *sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		switch sqliteErr.Code() {
+		case sqlite3lib.SQLITE_CONSTRAINT_UNIQUE, sqlite3lib.SQLITE_CONSTRAINT_PRIMARYKEY:
+			return &constraintError{code: Database.UniqueViolation, err: err}
+		case sqlite3lib.SQLITE_CONSTRAINT_FOREIGNKEY:
+			return &constraintError{code: Database.ForeignKeyViolation, err: err}
+		}
+	}
+	return err
+}
+
+func isMissingTable(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "no such table")
+}
@@ -0,0 +1,45 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nilesh0729/Notes/internal/db"
+	"github.com/nilesh0729/Notes/internal/db/storetest"
+	"github.com/nilesh0729/Notes/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStore(t *testing.T) *Store {
+	config := util.Config{
+		DBDriver:         db.DriverSQLite,
+		DBSource:         filepath.Join(t.TempDir(), "notes.db"),
+		DBConnectTimeout: time.Second,
+	}
+	conn, err := Open(context.Background(), config)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	err = db.MigrateUpSQLite(context.Background(), conn)
+	require.NoError(t, err)
+
+	return NewStore(conn).(*Store)
+}
+
+func TestStore(t *testing.T) {
+	storetest.Run(t, newTestStore(t))
+}
+
+func TestPingAndMigrationVersion(t *testing.T) {
+	store := newTestStore(t)
+
+	err := store.Ping(context.Background())
+	require.NoError(t, err)
+
+	version, dirty, err := store.MigrationVersion(context.Background())
+	require.NoError(t, err)
+	require.False(t, dirty)
+	require.Equal(t, int64(db.LatestVersion(db.DriverSQLite)), version)
+}
@@ -0,0 +1,110 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: tags.sql
+
+package sqlite
+
+import (
+	"context"
+)
+
+const createTags = `-- name: CreateTags :one
+INSERT INTO tags (
+  owner,
+  name
+) VALUES (
+  ?, ?
+)
+RETURNING tag_id, owner, name
+`
+
+type CreateTagsParams struct {
+	Owner string `json:"owner"`
+	Name  string `json:"name"`
+}
+
+func (q *Queries) CreateTags(ctx context.Context, arg CreateTagsParams) (Tag, error) {
+	row := q.db.QueryRowContext(ctx, createTags, arg.Owner, arg.Name)
+	var i Tag
+	err := row.Scan(&i.TagID, &i.Owner, &i.Name)
+	return i, err
+}
+
+const deleteTag = `-- name: DeleteTag :exec
+DELETE FROM tags
+WHERE tag_id = ?
+`
+
+func (q *Queries) DeleteTag(ctx context.Context, tagID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteTag, tagID)
+	return err
+}
+
+const getTag = `-- name: GetTag :one
+SELECT tag_id, owner, name FROM tags
+WHERE tag_id = ?
+LIMIT 1
+`
+
+func (q *Queries) GetTag(ctx context.Context, tagID int32) (Tag, error) {
+	row := q.db.QueryRowContext(ctx, getTag, tagID)
+	var i Tag
+	err := row.Scan(&i.TagID, &i.Owner, &i.Name)
+	return i, err
+}
+
+const listTags = `-- name: ListTags :many
+SELECT tag_id, owner, name FROM tags
+WHERE tag_id > ?1 AND owner = ?2
+ORDER BY tag_id
+LIMIT ?3
+`
+
+type ListTagsParams struct {
+	TagID int32  `json:"tag_id"`
+	Owner string `json:"owner"`
+	Limit int64  `json:"limit"`
+}
+
+func (q *Queries) ListTags(ctx context.Context, arg ListTagsParams) ([]Tag, error) {
+	rows, err := q.db.QueryContext(ctx, listTags, arg.TagID, arg.Owner, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Tag{}
+	for rows.Next() {
+		var i Tag
+		if err := rows.Scan(&i.TagID, &i.Owner, &i.Name); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateTag = `-- name: UpdateTag :one
+UPDATE tags
+SET name = ?1
+WHERE tag_id = ?2
+RETURNING tag_id, owner, name
+`
+
+type UpdateTagParams struct {
+	Name  string `json:"name"`
+	TagID int32  `json:"tag_id"`
+}
+
+func (q *Queries) UpdateTag(ctx context.Context, arg UpdateTagParams) (Tag, error) {
+	row := q.db.QueryRowContext(ctx, updateTag, arg.Name, arg.TagID)
+	var i Tag
+	err := row.Scan(&i.TagID, &i.Owner, &i.Name)
+	return i, err
+}
@@ -0,0 +1,330 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: user.sql
+
+package sqlite
+
+import (
+	"context"
+	"time"
+)
+
+const createUser = `-- name: CreateUser :one
+INSERT INTO "user" (
+  username,
+  hashed_password,
+  email
+) VALUES (
+  ?, ?, ?
+)
+RETURNING username, hashed_password, email, email_verified, password_changed_at, created_at, totp_secret, totp_enabled, totp_last_step
+`
+
+type CreateUserParams struct {
+	Username       string `json:"username"`
+	HashedPassword string `json:"hashed_password"`
+	Email          string `json:"email"`
+}
+
+func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, createUser, arg.Username, arg.HashedPassword, arg.Email)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.Email,
+		&i.EmailVerified,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.TotpLastStep,
+	)
+	return i, err
+}
+
+const enableTOTP = `-- name: EnableTOTP :one
+UPDATE "user"
+SET totp_enabled = true
+WHERE username = ? AND totp_secret <> ''
+RETURNING username, hashed_password, email, email_verified, password_changed_at, created_at, totp_secret, totp_enabled, totp_last_step
+`
+
+func (q *Queries) EnableTOTP(ctx context.Context, username string) (User, error) {
+	row := q.db.QueryRowContext(ctx, enableTOTP, username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.Email,
+		&i.EmailVerified,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.TotpLastStep,
+	)
+	return i, err
+}
+
+const getUser = `-- name: GetUser :one
+SELECT username, hashed_password, email, email_verified, password_changed_at, created_at, totp_secret, totp_enabled, totp_last_step FROM "user"
+WHERE username = ?
+LIMIT 1
+`
+
+func (q *Queries) GetUser(ctx context.Context, username string) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUser, username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.Email,
+		&i.EmailVerified,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.TotpLastStep,
+	)
+	return i, err
+}
+
+const listUsersByEmail = `-- name: ListUsersByEmail :many
+SELECT username, hashed_password, email, email_verified, password_changed_at, created_at, totp_secret, totp_enabled, totp_last_step FROM "user"
+WHERE email = ?
+ORDER BY username
+`
+
+func (q *Queries) ListUsersByEmail(ctx context.Context, email string) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, listUsersByEmail, email)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []User{}
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.Username,
+			&i.HashedPassword,
+			&i.Email,
+			&i.EmailVerified,
+			&i.PasswordChangedAt,
+			&i.CreatedAt,
+			&i.TotpSecret,
+			&i.TotpEnabled,
+			&i.TotpLastStep,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markEmailVerified = `-- name: MarkEmailVerified :one
+UPDATE "user"
+SET email_verified = true
+WHERE username = ? AND email = ?
+RETURNING username, hashed_password, email, email_verified, password_changed_at, created_at, totp_secret, totp_enabled, totp_last_step
+`
+
+type MarkEmailVerifiedParams struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
+func (q *Queries) MarkEmailVerified(ctx context.Context, arg MarkEmailVerifiedParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, markEmailVerified, arg.Username, arg.Email)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.Email,
+		&i.EmailVerified,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.TotpLastStep,
+	)
+	return i, err
+}
+
+const recordTOTPStep = `-- name: RecordTOTPStep :execrows
+UPDATE "user"
+SET totp_last_step = ?1
+WHERE username = ?2
+  AND totp_last_step < ?1
+`
+
+type RecordTOTPStepParams struct {
+	Step     int64  `json:"step"`
+	Username string `json:"username"`
+}
+
+func (q *Queries) RecordTOTPStep(ctx context.Context, arg RecordTOTPStepParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, recordTOTPStep, arg.Step, arg.Username)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const rehashUserPassword = `-- name: RehashUserPassword :execrows
+UPDATE "user"
+SET hashed_password = ?1
+WHERE username = ?2
+  AND hashed_password = ?3
+`
+
+type RehashUserPasswordParams struct {
+	NewHashedPassword string `json:"new_hashed_password"`
+	Username          string `json:"username"`
+	OldHashedPassword string `json:"old_hashed_password"`
+}
+
+func (q *Queries) RehashUserPassword(ctx context.Context, arg RehashUserPasswordParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, rehashUserPassword, arg.NewHashedPassword, arg.Username, arg.OldHashedPassword)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const resetUserPassword = `-- name: ResetUserPassword :one
+UPDATE "user"
+SET hashed_password = ?1,
+  password_changed_at = ?2
+WHERE username = ?3 AND email = ?4
+RETURNING username, hashed_password, email, email_verified, password_changed_at, created_at, totp_secret, totp_enabled, totp_last_step
+`
+
+type ResetUserPasswordParams struct {
+	HashedPassword string    `json:"hashed_password"`
+	Now            time.Time `json:"now"`
+	Username       string    `json:"username"`
+	Email          string    `json:"email"`
+}
+
+func (q *Queries) ResetUserPassword(ctx context.Context, arg ResetUserPasswordParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, resetUserPassword,
+		arg.HashedPassword,
+		arg.Now,
+		arg.Username,
+		arg.Email,
+	)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.Email,
+		&i.EmailVerified,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.TotpLastStep,
+	)
+	return i, err
+}
+
+const setTOTPSecret = `-- name: SetTOTPSecret :one
+UPDATE "user"
+SET totp_secret = ?1,
+  totp_enabled = false,
+  totp_last_step = 0
+WHERE username = ?2
+RETURNING username, hashed_password, email, email_verified, password_changed_at, created_at, totp_secret, totp_enabled, totp_last_step
+`
+
+type SetTOTPSecretParams struct {
+	TotpSecret string `json:"totp_secret"`
+	Username   string `json:"username"`
+}
+
+func (q *Queries) SetTOTPSecret(ctx context.Context, arg SetTOTPSecretParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, setTOTPSecret, arg.TotpSecret, arg.Username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.Email,
+		&i.EmailVerified,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.TotpLastStep,
+	)
+	return i, err
+}
+
+const updateUserEmail = `-- name: UpdateUserEmail :one
+UPDATE "user"
+SET email = ?1,
+  email_verified = false
+WHERE username = ?2
+RETURNING username, hashed_password, email, email_verified, password_changed_at, created_at, totp_secret, totp_enabled, totp_last_step
+`
+
+type UpdateUserEmailParams struct {
+	Email    string `json:"email"`
+	Username string `json:"username"`
+}
+
+func (q *Queries) UpdateUserEmail(ctx context.Context, arg UpdateUserEmailParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserEmail, arg.Email, arg.Username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.Email,
+		&i.EmailVerified,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.TotpLastStep,
+	)
+	return i, err
+}
+
+const updateUserPassword = `-- name: UpdateUserPassword :one
+UPDATE "user"
+SET hashed_password = ?1,
+  password_changed_at = ?2
+WHERE username = ?3
+RETURNING username, hashed_password, email, email_verified, password_changed_at, created_at, totp_secret, totp_enabled, totp_last_step
+`
+
+type UpdateUserPasswordParams struct {
+	HashedPassword string    `json:"hashed_password"`
+	Now            time.Time `json:"now"`
+	Username       string    `json:"username"`
+}
+
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserPassword, arg.HashedPassword, arg.Now, arg.Username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.Email,
+		&i.EmailVerified,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.TotpLastStep,
+	)
+	return i, err
+}
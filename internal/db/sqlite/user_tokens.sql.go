@@ -0,0 +1,182 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: user_tokens.sql
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const claimUserTokenAttempt = `-- name: ClaimUserTokenAttempt :one
+UPDATE user_tokens
+SET attempts = attempts + 1
+WHERE token_hash = ?1
+  AND purpose = ?2
+  AND used_at IS NULL
+  AND expires_at > ?3
+  AND attempts < ?4
+RETURNING id, username, purpose, token_hash, email, expires_at, used_at, created_at, attempts
+`
+
+type ClaimUserTokenAttemptParams struct {
+	TokenHash   []byte    `json:"token_hash"`
+	Purpose     string    `json:"purpose"`
+	Now         time.Time `json:"now"`
+	MaxAttempts int64     `json:"max_attempts"`
+}
+
+func (q *Queries) ClaimUserTokenAttempt(ctx context.Context, arg ClaimUserTokenAttemptParams) (UserToken, error) {
+	row := q.db.QueryRowContext(ctx, claimUserTokenAttempt,
+		arg.TokenHash,
+		arg.Purpose,
+		arg.Now,
+		arg.MaxAttempts,
+	)
+	var i UserToken
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Purpose,
+		&i.TokenHash,
+		&i.Email,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+		&i.Attempts,
+	)
+	return i, err
+}
+
+const consumeUserToken = `-- name: ConsumeUserToken :one
+UPDATE user_tokens
+SET used_at = ?1
+WHERE token_hash = ?2
+  AND purpose = ?3
+  AND used_at IS NULL
+  AND expires_at > ?4
+RETURNING id, username, purpose, token_hash, email, expires_at, used_at, created_at, attempts
+`
+
+type ConsumeUserTokenParams struct {
+	UsedAt    sql.NullTime `json:"used_at"`
+	TokenHash []byte       `json:"token_hash"`
+	Purpose   string       `json:"purpose"`
+	Now       time.Time    `json:"now"`
+}
+
+func (q *Queries) ConsumeUserToken(ctx context.Context, arg ConsumeUserTokenParams) (UserToken, error) {
+	row := q.db.QueryRowContext(ctx, consumeUserToken,
+		arg.UsedAt,
+		arg.TokenHash,
+		arg.Purpose,
+		arg.Now,
+	)
+	var i UserToken
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Purpose,
+		&i.TokenHash,
+		&i.Email,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+		&i.Attempts,
+	)
+	return i, err
+}
+
+const createUserToken = `-- name: CreateUserToken :one
+INSERT INTO user_tokens (
+  username,
+  purpose,
+  token_hash,
+  email,
+  expires_at
+) VALUES (
+  ?, ?, ?, ?, ?
+)
+RETURNING id, username, purpose, token_hash, email, expires_at, used_at, created_at, attempts
+`
+
+type CreateUserTokenParams struct {
+	Username  string    `json:"username"`
+	Purpose   string    `json:"purpose"`
+	TokenHash []byte    `json:"token_hash"`
+	Email     string    `json:"email"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateUserToken(ctx context.Context, arg CreateUserTokenParams) (UserToken, error) {
+	row := q.db.QueryRowContext(ctx, createUserToken,
+		arg.Username,
+		arg.Purpose,
+		arg.TokenHash,
+		arg.Email,
+		arg.ExpiresAt,
+	)
+	var i UserToken
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Purpose,
+		&i.TokenHash,
+		&i.Email,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+		&i.Attempts,
+	)
+	return i, err
+}
+
+const deleteUserTokens = `-- name: DeleteUserTokens :exec
+DELETE FROM user_tokens
+WHERE username = ? AND purpose = ?
+`
+
+type DeleteUserTokensParams struct {
+	Username string `json:"username"`
+	Purpose  string `json:"purpose"`
+}
+
+func (q *Queries) DeleteUserTokens(ctx context.Context, arg DeleteUserTokensParams) error {
+	_, err := q.db.ExecContext(ctx, deleteUserTokens, arg.Username, arg.Purpose)
+	return err
+}
+
+const getActiveUserToken = `-- name: GetActiveUserToken :one
+SELECT id, username, purpose, token_hash, email, expires_at, used_at, created_at, attempts FROM user_tokens
+WHERE token_hash = ?1
+  AND purpose = ?2
+  AND used_at IS NULL
+  AND expires_at > ?3
+LIMIT 1
+`
+
+type GetActiveUserTokenParams struct {
+	TokenHash []byte    `json:"token_hash"`
+	Purpose   string    `json:"purpose"`
+	Now       time.Time `json:"now"`
+}
+
+func (q *Queries) GetActiveUserToken(ctx context.Context, arg GetActiveUserTokenParams) (UserToken, error) {
+	row := q.db.QueryRowContext(ctx, getActiveUserToken, arg.TokenHash, arg.Purpose, arg.Now)
+	var i UserToken
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Purpose,
+		&i.TokenHash,
+		&i.Email,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+		&i.Attempts,
+	)
+	return i, err
+}
@@ -0,0 +1,86 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	Database "github.com/nilesh0729/Notes/internal/db/Result"
+	"github.com/nilesh0729/Notes/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func (s suite) createRandomAPIKey(t *testing.T, user Database.User) Database.ApiKey {
+	arg := Database.CreateAPIKeyParams{
+		Username:  user.Username,
+		Name:      util.RandomString(8),
+		Prefix:    "nk_" + util.RandomString(8),
+		KeyHash:   util.HashToken(util.RandomString(32)),
+		Scope:     "read-only",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	key, err := s.store.CreateAPIKey(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, key.ID)
+	require.Equal(t, arg.Username, key.Username)
+	require.Equal(t, arg.Name, key.Name)
+	require.Equal(t, arg.Prefix, key.Prefix)
+	require.Equal(t, arg.KeyHash, key.KeyHash)
+	require.Equal(t, arg.Scope, key.Scope)
+	require.WithinDuration(t, arg.ExpiresAt, key.ExpiresAt, time.Second)
+	require.False(t, key.LastUsedAt.Valid)
+
+	return key
+}
+
+func (s suite) TestGetAPIKeyByHash(t *testing.T) {
+	key1 := s.createRandomAPIKey(t, s.randomUser(t))
+
+	key2, err := s.store.GetAPIKeyByHash(context.Background(), key1.KeyHash)
+	require.NoError(t, err)
+	require.Equal(t, key1.ID, key2.ID)
+
+	_, err = s.store.GetAPIKeyByHash(context.Background(), util.HashToken(util.RandomString(32)))
+	require.EqualError(t, err, Database.ErrRecordNotFound.Error())
+}
+
+func (s suite) TestTouchAPIKey(t *testing.T) {
+	key1 := s.createRandomAPIKey(t, s.randomUser(t))
+
+	err := s.store.TouchAPIKey(context.Background(), key1.ID)
+	require.NoError(t, err)
+
+	key2, err := s.store.GetAPIKeyByHash(context.Background(), key1.KeyHash)
+	require.NoError(t, err)
+	require.True(t, key2.LastUsedAt.Valid)
+	require.WithinDuration(t, time.Now(), key2.LastUsedAt.Time, time.Minute)
+}
+
+func (s suite) TestListAndDeleteAPIKeys(t *testing.T) {
+	user := s.randomUser(t)
+	for i := 0; i < 3; i++ {
+		s.createRandomAPIKey(t, user)
+	}
+
+	keys, err := s.store.ListAPIKeys(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Len(t, keys, 3)
+
+	// Keys of other users cannot be deleted.
+	_, err = s.store.DeleteAPIKey(context.Background(), Database.DeleteAPIKeyParams{
+		ID:       keys[0].ID,
+		Username: s.randomUser(t).Username,
+	})
+	require.EqualError(t, err, Database.ErrRecordNotFound.Error())
+
+	_, err = s.store.DeleteAPIKey(context.Background(), Database.DeleteAPIKeyParams{
+		ID:       keys[0].ID,
+		Username: user.Username,
+	})
+	require.NoError(t, err)
+
+	keys, err = s.store.ListAPIKeys(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+}
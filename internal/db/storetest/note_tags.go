@@ -0,0 +1,182 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+
+	Database "github.com/nilesh0729/Notes/internal/db/Result"
+	"github.com/stretchr/testify/require"
+)
+
+func (s suite) createRandomNoteTag(t *testing.T, note Database.Note, tag Database.Tag) Database.NoteTag {
+	arg := Database.AddTagToNoteParams{
+		NoteID: note.NoteID,
+		TagID:  tag.TagID,
+	}
+
+	noteTag, err := s.store.AddTagToNote(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.Equal(t, noteTag.NoteID, note.NoteID)
+	require.Equal(t, noteTag.TagID, tag.TagID)
+
+	return noteTag
+}
+
+func (s suite) TestAddTagToNote(t *testing.T) {
+	note := s.createRandomNote(t)
+	tag := s.createRandomTags(t)
+	s.createRandomNoteTag(t, note, tag)
+}
+
+func (s suite) TestGetNotesForTag(t *testing.T) {
+	user := s.randomUser(t)
+
+	note1 := s.createNoteForUser(t, user)
+	note2 := s.createNoteForUser(t, user)
+	tag1 := s.createTagForUser(t, user)
+
+	// Create another user's note (should not be returned even if tagged)
+	otherUser := s.randomUser(t)
+	otherNote := s.createNoteForUser(t, otherUser)
+	_ = otherNote
+
+	noteTag1 := s.createRandomNoteTag(t, note1, tag1)
+	require.NotEmpty(t, noteTag1)
+
+	noteTag2 := s.createRandomNoteTag(t, note2, tag1)
+	require.NotEmpty(t, noteTag2)
+
+	arg := Database.GetNotesForTagParams{
+		TagID: tag1.TagID,
+		Owner: user.Username,
+	}
+	notes, err := s.store.GetNotesForTag(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, notes)
+
+	require.Len(t, notes, 2)
+
+	expected := map[int64]bool{
+		int64(note1.NoteID): true,
+		int64(note2.NoteID): true,
+	}
+
+	retrievedNoteIDs := map[int64]bool{}
+	for _, note := range notes {
+		retrievedNoteIDs[int64(note.NoteID)] = true
+	}
+
+	require.Equal(t, expected, retrievedNoteIDs)
+}
+
+func (s suite) createNoteForUser(t *testing.T, user Database.User) Database.Note {
+	arg := Database.CreateNoteParams{
+		Owner:   user.Username,
+		Title:   "title",
+		Content: "content",
+	}
+	note, err := s.store.CreateNote(context.Background(), arg)
+	require.NoError(t, err)
+	return note
+}
+
+func (s suite) createTagForUser(t *testing.T, user Database.User) Database.Tag {
+	arg := Database.CreateTagsParams{
+		Owner: user.Username,
+		Name:  "tagname",
+	}
+	tag, err := s.store.CreateTags(context.Background(), arg)
+	require.NoError(t, err)
+	return tag
+}
+
+func (s suite) TestGetTagsForNote(t *testing.T) {
+	note1 := s.createRandomNote(t)
+	tag1 := s.createRandomTags(t)
+	tag2 := s.createRandomTags(t)
+
+	_ = s.createRandomTags(t)
+
+	notetag1 := s.createRandomNoteTag(t, note1, tag1)
+	require.NotEmpty(t, notetag1)
+
+	notetag2 := s.createRandomNoteTag(t, note1, tag2)
+	require.NotEmpty(t, notetag2)
+
+	tags, err := s.store.GetTagsForNote(context.Background(), note1.NoteID)
+	require.NoError(t, err)
+	require.NotEmpty(t, tags)
+
+	require.Len(t, tags, 2)
+
+	expected := map[int64]bool{
+		int64(tag1.TagID): true,
+		int64(tag2.TagID): true,
+	}
+
+	retrievedTagIDs := map[int64]bool{}
+	for _, tag := range tags {
+		retrievedTagIDs[int64(tag.TagID)] = true
+	}
+
+	require.Equal(t, expected, retrievedTagIDs)
+
+}
+
+func (s suite) TestRemoveTagFromNote(t *testing.T) {
+	note1 := s.createRandomNote(t)
+	tag1 := s.createRandomTags(t)
+
+	notetag := s.createRandomNoteTag(t, note1, tag1)
+	require.NotEmpty(t, notetag)
+
+	arg := Database.RemoveTagFromNoteParams{
+		NoteID: note1.NoteID,
+		TagID:  tag1.TagID,
+	}
+
+	err := s.store.RemoveTagFromNote(context.Background(), arg)
+	require.NoError(t, err)
+
+	argGet := Database.GetNotesForTagParams{
+		TagID: tag1.TagID,
+		Owner: note1.Owner,
+	}
+	note, err := s.store.GetNotesForTag(context.Background(), argGet)
+	require.NoError(t, err)
+	require.Empty(t, note)
+
+	tag, err := s.store.GetTagsForNote(context.Background(), note1.NoteID)
+	require.NoError(t, err)
+	require.Empty(t, tag)
+}
+
+func (s suite) TestDeleteNoteRemovesNoteTags(t *testing.T) {
+	note := s.createRandomNote(t)
+	tag := s.createRandomTags(t)
+	s.createRandomNoteTag(t, note, tag)
+
+	err := s.store.DeleteNote(context.Background(), note.NoteID)
+	require.NoError(t, err)
+
+	notes, err := s.store.GetNotesForTag(context.Background(), Database.GetNotesForTagParams{
+		TagID: tag.TagID,
+		Owner: note.Owner,
+	})
+	require.NoError(t, err)
+	require.Empty(t, notes)
+}
+
+func (s suite) TestDeleteTagRemovesNoteTags(t *testing.T) {
+	note := s.createRandomNote(t)
+	tag := s.createRandomTags(t)
+	s.createRandomNoteTag(t, note, tag)
+
+	err := s.store.DeleteTag(context.Background(), tag.TagID)
+	require.NoError(t, err)
+
+	tags, err := s.store.GetTagsForNote(context.Background(), note.NoteID)
+	require.NoError(t, err)
+	require.Empty(t, tags)
+}
@@ -0,0 +1,179 @@
+package storetest
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	Database "github.com/nilesh0729/Notes/internal/db/Result"
+	"github.com/nilesh0729/Notes/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func (s suite) createRandomNote(t *testing.T) Database.Note {
+	user := s.randomUser(t)
+	arg := Database.CreateNoteParams{
+		Owner:   user.Username,
+		Title:   util.RandomString(6),
+		Content: util.RandomString(8),
+	}
+	note, err := s.store.CreateNote(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, note)
+
+	require.Equal(t, note.Owner, arg.Owner)
+	require.Equal(t, note.Title, arg.Title)
+	require.Equal(t, note.Content, arg.Content)
+
+	require.NotZero(t, note.NoteID)
+
+	require.False(t, note.Pinned)
+	require.False(t, note.Archived)
+
+	return note
+
+}
+
+func (s suite) TestCreateNote(t *testing.T) {
+	s.createRandomNote(t)
+}
+
+func (s suite) TestGetNoteById(t *testing.T) {
+	note1 := s.createRandomNote(t)
+	note, err := s.store.GetNoteById(context.Background(), note1.NoteID)
+	require.NoError(t, err)
+	require.NotEmpty(t, note)
+
+	require.Equal(t, note.Owner, note1.Owner)
+	require.Equal(t, note.NoteID, note1.NoteID)
+	require.Equal(t, note.Title, note1.Title)
+	require.Equal(t, note.Content, note1.Content)
+	require.Equal(t, note.Archived, note1.Archived)
+	require.Equal(t, note.Pinned, note1.Pinned)
+
+	require.WithinDuration(t, note.CreatedAt, note1.CreatedAt, time.Second)
+	require.WithinDuration(t, note.UpdatedAt, note1.UpdatedAt, time.Second)
+}
+
+func (s suite) TestListNotes(t *testing.T) {
+	var Cnotes []Database.Note
+	user := s.randomUser(t)
+
+	for i := 0; i < 10; i++ {
+		arg := Database.CreateNoteParams{
+			Owner:   user.Username,
+			Title:   util.RandomString(6),
+			Content: util.RandomString(8),
+		}
+		note, err := s.store.CreateNote(context.Background(), arg)
+		require.NoError(t, err)
+		Cnotes = append(Cnotes, note)
+	}
+	StartsAfterId := Cnotes[0].NoteID - 1
+
+	arg := Database.ListNotesParams{
+		NoteID: StartsAfterId,
+		Limit:  5,
+		Owner:  user.Username,
+	}
+
+	Notes, err := s.store.ListNotes(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, Notes)
+
+	require.Len(t, Notes, 5)
+
+	for i, note := range Notes {
+		require.NotEmpty(t, note)
+
+		expected := Cnotes[i]
+
+		require.Equal(t, expected.Owner, note.Owner)
+		require.Equal(t, expected.Title, note.Title)
+		require.Equal(t, expected.Content, expected.Content)
+		require.Equal(t, expected.Archived, expected.Archived)
+		require.Equal(t, expected.Pinned, expected.Pinned)
+	}
+}
+
+func (s suite) TestUpdateNote(t *testing.T) {
+	note1 := s.createRandomNote(t)
+	arg := Database.UpdateNoteParams{
+		NoteID:  note1.NoteID,
+		Title:   util.RandomString(6),
+		Content: util.RandomString(10),
+	}
+	note2, err := s.store.UpdateNote(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, note2)
+
+	require.Equal(t, note2.Owner, note1.Owner)
+	require.Equal(t, note2.NoteID, note1.NoteID)
+	require.Equal(t, note2.Title, arg.Title)
+	require.Equal(t, note2.Content, arg.Content)
+
+	require.Equal(t, note2.Archived, note1.Archived)
+	require.Equal(t, note2.Pinned, note1.Pinned)
+
+	require.WithinDuration(t, note1.CreatedAt, note2.CreatedAt, time.Second)
+	require.WithinDuration(t, time.Now(), note2.UpdatedAt, time.Second)
+
+	require.True(t, note2.UpdatedAt.After(note1.CreatedAt))
+}
+
+func (s suite) TestDeleteNote(t *testing.T) {
+	note1 := s.createRandomNote(t)
+	err := s.store.DeleteNote(context.Background(), note1.NoteID)
+	require.NoError(t, err)
+
+	note2, err := s.store.GetNoteById(context.Background(), note1.NoteID)
+	require.EqualError(t, err, Database.ErrRecordNotFound.Error())
+	require.Empty(t, note2)
+}
+
+func (s suite) TestSearchNotes(t *testing.T) {
+	user := s.randomUser(t)
+	word := util.RandomString(8)
+
+	var matching []Database.Note
+	for _, arg := range []Database.CreateNoteParams{
+		{Owner: user.Username, Title: "about " + word, Content: "content"},
+		{Owner: user.Username, Title: "title", Content: "mentions " + strings.ToUpper(word) + " in passing"},
+		{Owner: user.Username, Title: strings.ToUpper(word[:1]) + word[1:], Content: word},
+	} {
+		note, err := s.store.CreateNote(context.Background(), arg)
+		require.NoError(t, err)
+		matching = append(matching, note)
+	}
+	s.createNoteForUser(t, user)
+	otherUser := s.randomUser(t)
+	_, err := s.store.CreateNote(context.Background(), Database.CreateNoteParams{
+		Owner:   otherUser.Username,
+		Title:   word,
+		Content: word,
+	})
+	require.NoError(t, err)
+
+	// Matches ignore case, and the newest note comes first.
+	notes, err := s.store.SearchNotes(context.Background(), Database.SearchNotesParams{
+		Search: word,
+		Owner:  user.Username,
+		Limit:  10,
+	})
+	require.NoError(t, err)
+	require.Len(t, notes, 3)
+	for i, note := range notes {
+		require.Equal(t, matching[len(matching)-1-i].NoteID, note.NoteID)
+	}
+
+	notes, err = s.store.SearchNotes(context.Background(), Database.SearchNotesParams{
+		Search: word,
+		Owner:  user.Username,
+		Limit:  1,
+		Offset: 1,
+	})
+	require.NoError(t, err)
+	require.Len(t, notes, 1)
+	require.Equal(t, matching[1].NoteID, notes[0].NoteID)
+}
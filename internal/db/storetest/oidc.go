@@ -0,0 +1,68 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	Database "github.com/nilesh0729/Notes/internal/db/Result"
+	"github.com/nilesh0729/Notes/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func (s suite) TestConsumeOIDCStateOnce(t *testing.T) {
+	arg := Database.CreateOIDCStateParams{
+		StateHash:    util.HashToken(util.RandomString(32)),
+		Nonce:        util.RandomString(16),
+		CodeVerifier: util.RandomString(43),
+		ExpiresAt:    time.Now().Add(time.Minute),
+	}
+	_, err := s.store.CreateOIDCState(context.Background(), arg)
+	require.NoError(t, err)
+
+	state, err := s.store.ConsumeOIDCState(context.Background(), arg.StateHash)
+	require.NoError(t, err)
+	require.Equal(t, arg.Nonce, state.Nonce)
+	require.Equal(t, arg.CodeVerifier, state.CodeVerifier)
+
+	_, err = s.store.ConsumeOIDCState(context.Background(), arg.StateHash)
+	require.EqualError(t, err, Database.ErrRecordNotFound.Error())
+}
+
+func (s suite) TestConsumeExpiredOIDCState(t *testing.T) {
+	arg := Database.CreateOIDCStateParams{
+		StateHash:    util.HashToken(util.RandomString(32)),
+		Nonce:        util.RandomString(16),
+		CodeVerifier: util.RandomString(43),
+		ExpiresAt:    time.Now().Add(-time.Minute),
+	}
+	_, err := s.store.CreateOIDCState(context.Background(), arg)
+	require.NoError(t, err)
+
+	_, err = s.store.ConsumeOIDCState(context.Background(), arg.StateHash)
+	require.EqualError(t, err, Database.ErrRecordNotFound.Error())
+}
+
+func (s suite) TestCreateOIDCUserTx(t *testing.T) {
+	arg := Database.CreateOIDCUserTxParams{
+		Username:       util.RandomOwner(),
+		HashedPassword: util.RandomString(32),
+		Email:          util.RandomEmail(),
+		Issuer:         "https://idp.example.com",
+		Subject:        util.RandomString(12),
+	}
+
+	user, err := s.store.CreateOIDCUserTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Username, user.Username)
+	require.Equal(t, arg.Email, user.Email)
+	require.True(t, user.EmailVerified)
+
+	identity, err := s.store.GetUserIdentity(context.Background(), Database.GetUserIdentityParams{
+		Issuer:  arg.Issuer,
+		Subject: arg.Subject,
+	})
+	require.NoError(t, err)
+	require.Equal(t, user.Username, identity.Username)
+	require.Equal(t, user.Email, identity.Email)
+}
@@ -0,0 +1,75 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+
+	Database "github.com/nilesh0729/Notes/internal/db/Result"
+	"github.com/stretchr/testify/require"
+)
+
+func (s suite) TestUpsertUserQuota(t *testing.T) {
+	user := s.randomUser(t)
+
+	_, err := s.store.GetUserQuota(context.Background(), user.Username)
+	require.ErrorIs(t, err, Database.ErrRecordNotFound)
+
+	maxNotes, maxTags, maxTotalBytes := int64(5), int64(0), int64(1024)
+
+	arg := Database.UpsertUserQuotaParams{
+		Username: user.Username,
+		MaxNotes: &maxNotes,
+		MaxTags:  &maxTags,
+	}
+	quota, err := s.store.UpsertUserQuota(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, user.Username, quota.Username)
+	require.Nil(t, quota.MaxNoteBytes)
+	require.Equal(t, arg.MaxNotes, quota.MaxNotes)
+	require.Equal(t, arg.MaxTags, quota.MaxTags)
+	require.Nil(t, quota.MaxTotalBytes)
+
+	arg = Database.UpsertUserQuotaParams{
+		Username:      user.Username,
+		MaxTotalBytes: &maxTotalBytes,
+	}
+	_, err = s.store.UpsertUserQuota(context.Background(), arg)
+	require.NoError(t, err)
+
+	quota, err = s.store.GetUserQuota(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Nil(t, quota.MaxNotes)
+	require.Nil(t, quota.MaxTags)
+	require.Equal(t, arg.MaxTotalBytes, quota.MaxTotalBytes)
+}
+
+func (s suite) TestUpsertUserQuotaUnknownUser(t *testing.T) {
+	_, err := s.store.UpsertUserQuota(context.Background(), Database.UpsertUserQuotaParams{
+		Username: "unknown-" + s.randomUser(t).Username,
+	})
+	require.Equal(t, Database.ForeignKeyViolation, Database.ErrorCode(err))
+}
+
+func (s suite) TestGetUserUsage(t *testing.T) {
+	user := s.randomUser(t)
+
+	usage, err := s.store.GetUserUsage(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Zero(t, usage)
+
+	s.createNoteForUser(t, user)
+	_, err = s.store.CreateNote(context.Background(), Database.CreateNoteParams{
+		Owner:   user.Username,
+		Title:   "é",
+		Content: "日本",
+	})
+	require.NoError(t, err)
+	s.createTagForUser(t, user)
+	s.createRandomNote(t)
+
+	usage, err = s.store.GetUserUsage(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), usage.Notes)
+	require.Equal(t, int64(1), usage.Tags)
+	require.Equal(t, int64(len("title")+len("content")+len("é")+len("日本")), usage.TotalBytes)
+}
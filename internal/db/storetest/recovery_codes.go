@@ -0,0 +1,41 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+
+	Database "github.com/nilesh0729/Notes/internal/db/Result"
+	"github.com/nilesh0729/Notes/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func (s suite) TestEnableTOTPTx(t *testing.T) {
+	user := s.randomUser(t)
+
+	_, err := s.store.EnableTOTPTx(context.Background(), Database.EnableTOTPTxParams{Username: user.Username})
+	require.EqualError(t, err, Database.ErrRecordNotFound.Error())
+
+	pending, err := s.store.SetTOTPSecret(context.Background(), Database.SetTOTPSecretParams{
+		Username:   user.Username,
+		TotpSecret: util.RandomString(32),
+	})
+	require.NoError(t, err)
+	require.False(t, pending.TotpEnabled)
+
+	codes := [][]byte{util.HashToken(util.RandomString(16)), util.HashToken(util.RandomString(16))}
+	enabled, err := s.store.EnableTOTPTx(context.Background(), Database.EnableTOTPTxParams{
+		Username:           user.Username,
+		RecoveryCodeHashes: codes,
+	})
+	require.NoError(t, err)
+	require.True(t, enabled.TotpEnabled)
+	require.Equal(t, pending.TotpSecret, enabled.TotpSecret)
+
+	arg := Database.ConsumeRecoveryCodeParams{Username: user.Username, CodeHash: codes[0]}
+	code, err := s.store.ConsumeRecoveryCode(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, code.UsedAt.Valid)
+
+	_, err = s.store.ConsumeRecoveryCode(context.Background(), arg)
+	require.EqualError(t, err, Database.ErrRecordNotFound.Error())
+}
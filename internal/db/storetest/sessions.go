@@ -0,0 +1,79 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	Database "github.com/nilesh0729/Notes/internal/db/Result"
+	"github.com/nilesh0729/Notes/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func (s suite) createRandomSession(t *testing.T, user Database.User) Database.Session {
+	arg := Database.CreateSessionParams{
+		ID:        uuid.New(),
+		Username:  user.Username,
+		UserAgent: util.RandomString(10),
+		ClientIp:  "127.0.0.1",
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+
+	session, err := s.store.CreateSession(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, session)
+
+	require.Equal(t, arg.ID, session.ID)
+	require.Equal(t, arg.Username, session.Username)
+	require.Equal(t, arg.UserAgent, session.UserAgent)
+	require.Equal(t, arg.ClientIp, session.ClientIp)
+	require.False(t, session.IsBlocked)
+	require.WithinDuration(t, arg.ExpiresAt, session.ExpiresAt, time.Second)
+
+	return session
+}
+
+func (s suite) TestGetSession(t *testing.T) {
+	session1 := s.createRandomSession(t, s.randomUser(t))
+
+	session2, err := s.store.GetSession(context.Background(), session1.ID)
+	require.NoError(t, err)
+	require.Equal(t, session1.ID, session2.ID)
+	require.Equal(t, session1.Username, session2.Username)
+
+	_, err = s.store.GetSession(context.Background(), uuid.New())
+	require.EqualError(t, err, Database.ErrRecordNotFound.Error())
+}
+
+func (s suite) TestChangePasswordTx(t *testing.T) {
+	user := s.randomUser(t)
+	session1 := s.createRandomSession(t, user)
+	session2 := s.createRandomSession(t, user)
+	s.createRandomAPIKey(t, user)
+	resetToken := s.createRandomUserToken(t, user, Database.TokenPurposeResetPassword, time.Minute)
+
+	arg := Database.ChangePasswordTxParams{
+		Username:       user.Username,
+		HashedPassword: util.RandomString(8),
+	}
+	updated, err := s.store.ChangePasswordTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.HashedPassword, updated.HashedPassword)
+
+	for _, session := range []Database.Session{session1, session2} {
+		got, err := s.store.GetSession(context.Background(), session.ID)
+		require.NoError(t, err)
+		require.True(t, got.IsBlocked)
+	}
+
+	keys, err := s.store.ListAPIKeys(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Empty(t, keys)
+
+	_, err = s.store.GetActiveUserToken(context.Background(), Database.GetActiveUserTokenParams{
+		TokenHash: resetToken.TokenHash,
+		Purpose:   Database.TokenPurposeResetPassword,
+	})
+	require.EqualError(t, err, Database.ErrRecordNotFound.Error())
+}
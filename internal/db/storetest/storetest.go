@@ -0,0 +1,31 @@
+// Package storetest holds the tests every Database.Store backend has to
+// pass, so that the queries behave the same on each of them.
+package storetest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	Database "github.com/nilesh0729/Notes/internal/db/Result"
+)
+
+// suite runs the tests against store. Each of its Test methods is one test.
+type suite struct {
+	store Database.Store
+}
+
+// Run runs the whole suite against store, which has to be migrated to the
+// latest schema. Every test creates the users it needs, so store may be
+// shared with other tests.
+func Run(t *testing.T, store Database.Store) {
+	s := reflect.ValueOf(suite{store: store})
+	for i := 0; i < s.NumMethod(); i++ {
+		name := s.Type().Method(i).Name
+		if !strings.HasPrefix(name, "Test") {
+			continue
+		}
+		test := s.Method(i).Interface().(func(*testing.T))
+		t.Run(strings.TrimPrefix(name, "Test"), test)
+	}
+}
@@ -0,0 +1,108 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+
+	Database "github.com/nilesh0729/Notes/internal/db/Result"
+	"github.com/nilesh0729/Notes/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func (s suite) createRandomTags(t *testing.T) Database.Tag {
+	user := s.randomUser(t)
+	arg := Database.CreateTagsParams{
+		Owner: user.Username,
+		Name:  util.RandomString(5),
+	}
+	tag, err := s.store.CreateTags(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, tag)
+
+	require.Equal(t, tag.Name, arg.Name)
+	require.Equal(t, tag.Owner, arg.Owner)
+	require.NotZero(t, tag.TagID)
+
+	return tag
+
+}
+
+func (s suite) TestCreateTags(t *testing.T) {
+	s.createRandomTags(t)
+}
+
+func (s suite) TestGetTag(t *testing.T) {
+	tag1 := s.createRandomTags(t)
+	tag, err := s.store.GetTag(context.Background(), tag1.TagID)
+	require.NoError(t, err)
+	require.NotEmpty(t, tag)
+
+	require.Equal(t, tag.Owner, tag1.Owner)
+	require.Equal(t, tag.TagID, tag1.TagID)
+	require.Equal(t, tag.Name, tag1.Name)
+}
+
+func (s suite) TestListTags(t *testing.T) {
+	var CreatedTags []Database.Tag
+	user := s.randomUser(t)
+
+	for i := 0; i < 10; i++ {
+		arg := Database.CreateTagsParams{
+			Owner: user.Username,
+			Name:  util.RandomString(5),
+		}
+		tag, err := s.store.CreateTags(context.Background(), arg)
+		require.NoError(t, err)
+		CreatedTags = append(CreatedTags, tag)
+	}
+
+	TagStartingPoint := CreatedTags[0].TagID - 1
+	arg := Database.ListTagsParams{
+		TagID: TagStartingPoint,
+		Limit: 5,
+		Owner: user.Username,
+	}
+
+	tags, err := s.store.ListTags(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, tags)
+	require.Len(t, tags, 5)
+
+	for i, tag := range tags {
+		require.NotEmpty(t, tag)
+
+		expected := CreatedTags[i]
+
+		require.Equal(t, expected.Owner, tag.Owner)
+		require.Equal(t, expected.Name, tag.Name)
+		require.Equal(t, expected.TagID, tag.TagID)
+	}
+}
+
+func (s suite) TestUpdateTag(t *testing.T) {
+	tag1 := s.createRandomTags(t)
+
+	arg := Database.UpdateTagParams{
+		TagID: tag1.TagID,
+		Name:  util.RandomString(6),
+	}
+	tag2, err := s.store.UpdateTag(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, tag2)
+
+	require.Equal(t, tag1.Owner, tag2.Owner)
+	require.Equal(t, tag1.TagID, tag2.TagID)
+	require.Equal(t, tag2.Name, arg.Name)
+}
+
+func (s suite) TestDeleteTag(t *testing.T) {
+	tag1 := s.createRandomTags(t)
+
+	err := s.store.DeleteTag(context.Background(), tag1.TagID)
+	require.NoError(t, err)
+
+	tag2, err := s.store.GetTag(context.Background(), tag1.TagID)
+	require.EqualError(t, err, Database.ErrRecordNotFound.Error())
+	require.Empty(t, tag2)
+
+}
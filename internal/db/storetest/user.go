@@ -0,0 +1,158 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	Database "github.com/nilesh0729/Notes/internal/db/Result"
+	"github.com/nilesh0729/Notes/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func (s suite) TestCreateUse(t *testing.T) {
+	arg := Database.CreateUserParams{
+		Username:       util.RandomString(5),
+		HashedPassword: util.RandomString(8),
+		Email:          util.RandomEmail(),
+	}
+	user, err := s.store.CreateUser(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, user)
+
+	require.Equal(t, user.Username, arg.Username)
+	require.Equal(t, user.HashedPassword, arg.HashedPassword)
+	require.Equal(t, user.Email, arg.Email)
+
+}
+
+func (s suite) TestCreateUserDuplicate(t *testing.T) {
+	user := s.randomUser(t)
+
+	_, err := s.store.CreateUser(context.Background(), Database.CreateUserParams{
+		Username:       user.Username,
+		HashedPassword: util.RandomString(8),
+		Email:          util.RandomEmail(),
+	})
+	require.Error(t, err)
+	require.Equal(t, Database.UniqueViolation, Database.ErrorCode(err))
+
+	require.Empty(t, Database.ErrorCode(Database.ErrRecordNotFound))
+}
+
+func (s suite) TestGetUser(t *testing.T) {
+
+	user1 := s.randomUser(t)
+	user2, err := s.store.GetUser(context.Background(), user1.Username)
+	require.NoError(t, err)
+	require.NotEmpty(t, user2)
+
+	require.Equal(t, user1.Username, user2.Username)
+	require.Equal(t, user1.HashedPassword, user2.HashedPassword)
+	require.Equal(t, user1.Email, user2.Email)
+}
+
+func (s suite) randomUser(t *testing.T) Database.User {
+
+	arg := Database.CreateUserParams{
+		Username:       util.RandomString(5),
+		HashedPassword: util.RandomString(8),
+		Email:          util.RandomEmail(),
+	}
+
+	user, err := s.store.CreateUser(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, user)
+
+	require.Equal(t, user.Username, arg.Username)
+	require.Equal(t, user.HashedPassword, arg.HashedPassword)
+	require.Equal(t, user.Email, arg.Email)
+
+	return user
+}
+
+func (s suite) TestUpdateUserEmail(t *testing.T) {
+	user1 := s.randomUser(t)
+
+	arg := Database.UpdateUserEmailParams{
+		Username: user1.Username,
+		Email:    util.RandomEmail(),
+	}
+	user2, err := s.store.UpdateUserEmail(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.Equal(t, user1.Username, user2.Username)
+	require.Equal(t, arg.Email, user2.Email)
+	require.False(t, user2.EmailVerified)
+	require.Equal(t, user1.HashedPassword, user2.HashedPassword)
+}
+
+func (s suite) TestUpdateUserPassword(t *testing.T) {
+	user1 := s.randomUser(t)
+
+	arg := Database.UpdateUserPasswordParams{
+		Username:       user1.Username,
+		HashedPassword: util.RandomString(8),
+	}
+	user2, err := s.store.UpdateUserPassword(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.Equal(t, user1.Username, user2.Username)
+	require.Equal(t, arg.HashedPassword, user2.HashedPassword)
+	require.WithinDuration(t, time.Now(), user2.PasswordChangedAt, time.Second)
+}
+
+func (s suite) TestRehashUserPassword(t *testing.T) {
+	user1 := s.randomUser(t)
+
+	arg := Database.RehashUserPasswordParams{
+		NewHashedPassword: util.RandomString(8),
+		Username:          user1.Username,
+		OldHashedPassword: user1.HashedPassword,
+	}
+	rows, err := s.store.RehashUserPassword(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rows)
+
+	user2, err := s.store.GetUser(context.Background(), user1.Username)
+	require.NoError(t, err)
+	require.Equal(t, arg.NewHashedPassword, user2.HashedPassword)
+	require.WithinDuration(t, user1.PasswordChangedAt, user2.PasswordChangedAt, time.Millisecond)
+
+	// The hash changed meanwhile, so a second rehash must not overwrite it.
+	rows, err = s.store.RehashUserPassword(context.Background(), arg)
+	require.NoError(t, err)
+	require.Zero(t, rows)
+}
+
+func (s suite) TestRecordTOTPStep(t *testing.T) {
+	user := s.randomUser(t)
+
+	arg := Database.RecordTOTPStepParams{Username: user.Username, Step: 100}
+	rows, err := s.store.RecordTOTPStep(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rows)
+
+	// A code of the same or an earlier step has been used already.
+	rows, err = s.store.RecordTOTPStep(context.Background(), arg)
+	require.NoError(t, err)
+	require.Zero(t, rows)
+
+	arg.Step = 99
+	rows, err = s.store.RecordTOTPStep(context.Background(), arg)
+	require.NoError(t, err)
+	require.Zero(t, rows)
+
+	arg.Step = 101
+	rows, err = s.store.RecordTOTPStep(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rows)
+
+	// A new secret starts over.
+	updated, err := s.store.SetTOTPSecret(context.Background(), Database.SetTOTPSecretParams{
+		Username:   user.Username,
+		TotpSecret: util.RandomString(16),
+	})
+	require.NoError(t, err)
+	require.Zero(t, updated.TotpLastStep)
+}
@@ -0,0 +1,211 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	Database "github.com/nilesh0729/Notes/internal/db/Result"
+	"github.com/nilesh0729/Notes/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func (s suite) createRandomUserToken(t *testing.T, user Database.User, purpose string, duration time.Duration) Database.UserToken {
+	arg := Database.CreateUserTokenParams{
+		Username:  user.Username,
+		Purpose:   purpose,
+		TokenHash: util.HashToken(util.RandomString(32)),
+		Email:     user.Email,
+		ExpiresAt: time.Now().Add(duration),
+	}
+
+	token, err := s.store.CreateUserToken(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, token.ID)
+	require.Equal(t, arg.TokenHash, token.TokenHash)
+	require.False(t, token.UsedAt.Valid)
+
+	return token
+}
+
+func (s suite) TestConsumeUserTokenOnce(t *testing.T) {
+	token := s.createRandomUserToken(t, s.randomUser(t), Database.TokenPurposeVerifyEmail, time.Minute)
+
+	arg := Database.ConsumeUserTokenParams{
+		TokenHash: token.TokenHash,
+		Purpose:   Database.TokenPurposeVerifyEmail,
+	}
+	consumed, err := s.store.ConsumeUserToken(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, consumed.UsedAt.Valid)
+
+	_, err = s.store.ConsumeUserToken(context.Background(), arg)
+	require.EqualError(t, err, Database.ErrRecordNotFound.Error())
+}
+
+func (s suite) TestConsumeUserTokenWrongPurpose(t *testing.T) {
+	token := s.createRandomUserToken(t, s.randomUser(t), Database.TokenPurposeVerifyEmail, time.Minute)
+
+	_, err := s.store.ConsumeUserToken(context.Background(), Database.ConsumeUserTokenParams{
+		TokenHash: token.TokenHash,
+		Purpose:   Database.TokenPurposeResetPassword,
+	})
+	require.EqualError(t, err, Database.ErrRecordNotFound.Error())
+}
+
+func (s suite) TestConsumeExpiredUserToken(t *testing.T) {
+	token := s.createRandomUserToken(t, s.randomUser(t), Database.TokenPurposeResetPassword, -time.Minute)
+
+	_, err := s.store.ConsumeUserToken(context.Background(), Database.ConsumeUserTokenParams{
+		TokenHash: token.TokenHash,
+		Purpose:   Database.TokenPurposeResetPassword,
+	})
+	require.EqualError(t, err, Database.ErrRecordNotFound.Error())
+}
+
+func (s suite) TestClaimUserTokenAttempt(t *testing.T) {
+	token := s.createRandomUserToken(t, s.randomUser(t), Database.TokenPurposeLoginChallenge, time.Minute)
+
+	arg := Database.ClaimUserTokenAttemptParams{
+		TokenHash:   token.TokenHash,
+		Purpose:     Database.TokenPurposeLoginChallenge,
+		MaxAttempts: 2,
+	}
+	for i := int32(1); i <= 2; i++ {
+		claimed, err := s.store.ClaimUserTokenAttempt(context.Background(), arg)
+		require.NoError(t, err)
+		require.Equal(t, i, claimed.Attempts)
+	}
+
+	_, err := s.store.ClaimUserTokenAttempt(context.Background(), arg)
+	require.EqualError(t, err, Database.ErrRecordNotFound.Error())
+}
+
+func (s suite) TestClaimConsumedUserTokenAttempt(t *testing.T) {
+	token := s.createRandomUserToken(t, s.randomUser(t), Database.TokenPurposeLoginChallenge, time.Minute)
+
+	_, err := s.store.ConsumeUserToken(context.Background(), Database.ConsumeUserTokenParams{
+		TokenHash: token.TokenHash,
+		Purpose:   Database.TokenPurposeLoginChallenge,
+	})
+	require.NoError(t, err)
+
+	_, err = s.store.ClaimUserTokenAttempt(context.Background(), Database.ClaimUserTokenAttemptParams{
+		TokenHash:   token.TokenHash,
+		Purpose:     Database.TokenPurposeLoginChallenge,
+		MaxAttempts: 5,
+	})
+	require.EqualError(t, err, Database.ErrRecordNotFound.Error())
+}
+
+func (s suite) TestVerifyEmailTx(t *testing.T) {
+	user := s.randomUser(t)
+	token := s.createRandomUserToken(t, user, Database.TokenPurposeVerifyEmail, time.Minute)
+
+	verified, err := s.store.VerifyEmailTx(context.Background(), Database.VerifyEmailTxParams{TokenHash: token.TokenHash})
+	require.NoError(t, err)
+	require.True(t, verified.EmailVerified)
+	require.Equal(t, user.Email, verified.Email)
+}
+
+func (s suite) TestVerifyEmailTxAfterEmailChange(t *testing.T) {
+	user := s.randomUser(t)
+	token := s.createRandomUserToken(t, user, Database.TokenPurposeVerifyEmail, time.Minute)
+
+	_, err := s.store.UpdateUserEmail(context.Background(), Database.UpdateUserEmailParams{
+		Username: user.Username,
+		Email:    util.RandomEmail(),
+	})
+	require.NoError(t, err)
+
+	_, err = s.store.VerifyEmailTx(context.Background(), Database.VerifyEmailTxParams{TokenHash: token.TokenHash})
+	require.EqualError(t, err, Database.ErrRecordNotFound.Error())
+}
+
+func (s suite) TestResetPasswordTx(t *testing.T) {
+	user := s.randomUser(t)
+	session := s.createRandomSession(t, user)
+	s.createRandomAPIKey(t, user)
+	token := s.createRandomUserToken(t, user, Database.TokenPurposeResetPassword, time.Minute)
+
+	arg := Database.ResetPasswordTxParams{
+		TokenHash:      token.TokenHash,
+		HashedPassword: util.RandomString(8),
+	}
+	updated, err := s.store.ResetPasswordTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.HashedPassword, updated.HashedPassword)
+
+	got, err := s.store.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, got.IsBlocked)
+
+	keys, err := s.store.ListAPIKeys(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Empty(t, keys)
+
+	_, err = s.store.ResetPasswordTx(context.Background(), arg)
+	require.EqualError(t, err, Database.ErrRecordNotFound.Error())
+}
+
+func (s suite) TestResetPasswordTxRevokesOtherTokens(t *testing.T) {
+	user := s.randomUser(t)
+	token1 := s.createRandomUserToken(t, user, Database.TokenPurposeResetPassword, time.Minute)
+	token2 := s.createRandomUserToken(t, user, Database.TokenPurposeResetPassword, time.Minute)
+
+	_, err := s.store.ResetPasswordTx(context.Background(), Database.ResetPasswordTxParams{
+		TokenHash:      token1.TokenHash,
+		HashedPassword: util.RandomString(8),
+	})
+	require.NoError(t, err)
+
+	_, err = s.store.ResetPasswordTx(context.Background(), Database.ResetPasswordTxParams{
+		TokenHash:      token2.TokenHash,
+		HashedPassword: util.RandomString(8),
+	})
+	require.EqualError(t, err, Database.ErrRecordNotFound.Error())
+}
+
+func (s suite) TestResetPasswordTxAfterEmailChange(t *testing.T) {
+	user := s.randomUser(t)
+	token := s.createRandomUserToken(t, user, Database.TokenPurposeResetPassword, time.Minute)
+
+	_, err := s.store.UpdateUserEmail(context.Background(), Database.UpdateUserEmailParams{
+		Username: user.Username,
+		Email:    util.RandomEmail(),
+	})
+	require.NoError(t, err)
+
+	_, err = s.store.ResetPasswordTx(context.Background(), Database.ResetPasswordTxParams{
+		TokenHash:      token.TokenHash,
+		HashedPassword: util.RandomString(8),
+	})
+	require.EqualError(t, err, Database.ErrRecordNotFound.Error())
+
+	got, err := s.store.GetUser(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, user.HashedPassword, got.HashedPassword)
+}
+
+func (s suite) TestChangeEmailTx(t *testing.T) {
+	user := s.randomUser(t)
+	verifyToken := s.createRandomUserToken(t, user, Database.TokenPurposeVerifyEmail, time.Minute)
+	resetToken := s.createRandomUserToken(t, user, Database.TokenPurposeResetPassword, time.Minute)
+
+	arg := Database.ChangeEmailTxParams{
+		Username: user.Username,
+		Email:    util.RandomEmail(),
+	}
+	updated, err := s.store.ChangeEmailTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Email, updated.Email)
+	require.False(t, updated.EmailVerified)
+
+	for _, token := range []Database.UserToken{verifyToken, resetToken} {
+		_, err = s.store.GetActiveUserToken(context.Background(), Database.GetActiveUserTokenParams{
+			TokenHash: token.TokenHash,
+			Purpose:   token.Purpose,
+		})
+		require.EqualError(t, err, Database.ErrRecordNotFound.Error())
+	}
+}
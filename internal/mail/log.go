@@ -0,0 +1,31 @@
+package mail
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// LogMailer writes every message to w instead of delivering it. It is meant
+// for local development and tests.
+type LogMailer struct {
+	mu   sync.Mutex
+	w    io.Writer
+	from string
+}
+
+func NewLogMailer(w io.Writer, from string) Mailer {
+	return &LogMailer{w: w, from: from}
+}
+
+func (mailer *LogMailer) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	mailer.mu.Lock()
+	defer mailer.mu.Unlock()
+
+	_, err := mailer.w.Write(append(format(mailer.from, msg), "\r\n\r\n"...))
+	return err
+}
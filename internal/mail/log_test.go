@@ -0,0 +1,54 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/nilesh0729/Notes/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogMailer(t *testing.T) {
+	var buf bytes.Buffer
+	mailer := NewLogMailer(&buf, "Notes <no-reply@example.com>")
+
+	msg := Message{
+		To:      util.RandomEmail(),
+		Subject: util.RandomString(10),
+		Body:    "line one\nline two",
+	}
+	err := mailer.Send(context.Background(), msg)
+	require.NoError(t, err)
+
+	out := buf.String()
+	require.Contains(t, out, "From: Notes <no-reply@example.com>\r\n")
+	require.Contains(t, out, "To: "+msg.To+"\r\n")
+	require.Contains(t, out, "Subject: "+msg.Subject+"\r\n")
+	require.Contains(t, out, "line one\r\nline two")
+}
+
+func TestLogMailerCanceledContext(t *testing.T) {
+	var buf bytes.Buffer
+	mailer := NewLogMailer(&buf, "no-reply@example.com")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := mailer.Send(ctx, Message{To: util.RandomEmail()})
+	require.ErrorIs(t, err, context.Canceled)
+	require.Zero(t, buf.Len())
+}
+
+func TestEnvelopeAddress(t *testing.T) {
+	addr, err := envelopeAddress("Notes <no-reply@example.com>")
+	require.NoError(t, err)
+	require.Equal(t, "no-reply@example.com", addr)
+
+	addr, err = envelopeAddress("no-reply@example.com")
+	require.NoError(t, err)
+	require.Equal(t, "no-reply@example.com", addr)
+
+	_, err = envelopeAddress("Notes >no-reply@example.com<")
+	require.Error(t, err)
+}
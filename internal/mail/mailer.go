@@ -0,0 +1,43 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/nilesh0729/Notes/internal/util"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// NewMailer picks the SMTP mailer when an SMTP host is configured. Otherwise
+// messages are written to the configured log file, or to stderr.
+func NewMailer(config util.Config) (Mailer, error) {
+	if config.SMTPHost != "" {
+		return NewSMTPMailer(
+			config.SMTPHost,
+			config.SMTPPort,
+			config.SMTPUsername,
+			config.SMTPPassword,
+			config.MailFrom,
+		), nil
+	}
+
+	if config.MailLogFile != "" {
+		file, err := os.OpenFile(config.MailLogFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("cannot open mail log file: %w", err)
+		}
+		return NewLogMailer(file, config.MailFrom), nil
+	}
+
+	return NewLogMailer(os.Stderr, config.MailFrom), nil
+}
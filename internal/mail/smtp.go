@@ -0,0 +1,155 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+)
+
+type SMTPMailer struct {
+	addr string
+	host string
+	auth smtp.Auth
+	from string
+}
+
+func NewSMTPMailer(host string, port int, username, password, from string) Mailer {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &SMTPMailer{
+		addr: net.JoinHostPort(host, strconv.Itoa(port)),
+		host: host,
+		auth: auth,
+		from: from,
+	}
+}
+
+// Send delivers msg the way smtp.SendMail does, upgrading to TLS when the
+// server offers it, but gives up once ctx is done.
+func (mailer *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	sender, err := envelopeAddress(mailer.from)
+	if err != nil {
+		return err
+	}
+
+	err = mailer.send(ctx, sender, msg)
+	if err != nil {
+		return fmt.Errorf("failed to send mail to %s: %w", msg.To, err)
+	}
+	return nil
+}
+
+func (mailer *SMTPMailer) send(ctx context.Context, sender string, msg Message) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", mailer.addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// The SMTP client does not take a context: bound the exchange by its
+	// deadline, and unblock it by closing the connection when it is cancelled.
+	if deadline, ok := ctx.Deadline(); ok {
+		err = conn.SetDeadline(deadline)
+		if err != nil {
+			return err
+		}
+	}
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	client, err := smtp.NewClient(conn, mailer.host)
+	if err != nil {
+		return contextError(ctx, err)
+	}
+	defer client.Close()
+
+	err = mailer.deliver(client, sender, msg)
+	return contextError(ctx, err)
+}
+
+func (mailer *SMTPMailer) deliver(client *smtp.Client, sender string, msg Message) error {
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		err := client.StartTLS(&tls.Config{ServerName: mailer.host})
+		if err != nil {
+			return err
+		}
+	}
+
+	if mailer.auth != nil {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return fmt.Errorf("server does not support AUTH")
+		}
+		err := client.Auth(mailer.auth)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := client.Mail(sender)
+	if err != nil {
+		return err
+	}
+	err = client.Rcpt(msg.To)
+	if err != nil {
+		return err
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(format(mailer.from, msg))
+	if err != nil {
+		return err
+	}
+	err = w.Close()
+	if err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// contextError prefers the error of ctx, if it is done, over err, which is
+// then most likely the result of the connection being cut short.
+func contextError(ctx context.Context, err error) error {
+	if err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// envelopeAddress extracts the bare address from a "Name <addr>" header value.
+func envelopeAddress(from string) (string, error) {
+	if i := strings.LastIndex(from, "<"); i >= 0 {
+		j := strings.LastIndex(from, ">")
+		if j < i {
+			return "", fmt.Errorf("invalid sender address %q", from)
+		}
+		return from[i+1 : j], nil
+	}
+	return from, nil
+}
+
+// format renders msg as a plain text RFC 5322 message.
+func format(from string, msg Message) []byte {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "From: %s\r\n", from)
+	fmt.Fprintf(&sb, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&sb, "Subject: %s\r\n", msg.Subject)
+	sb.WriteString("MIME-Version: 1.0\r\n")
+	sb.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	sb.WriteString("\r\n")
+	sb.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+
+	return []byte(sb.String())
+}
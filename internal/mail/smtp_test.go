@@ -0,0 +1,43 @@
+package mail
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/nilesh0729/Notes/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSMTPMailerCancelled(t *testing.T) {
+	// The server accepts connections but never greets the client.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	mailer := NewSMTPMailer(addr.IP.String(), addr.Port, "", "", "Notes <no-reply@example.com>")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	err = mailer.Send(ctx, Message{
+		To:      util.RandomEmail(),
+		Subject: util.RandomString(10),
+		Body:    util.RandomString(20),
+	})
+	require.ErrorIs(t, err, context.Canceled)
+	require.Less(t, time.Since(start), 5*time.Second)
+}
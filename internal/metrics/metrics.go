@@ -0,0 +1,157 @@
+// Package metrics holds the Prometheus metrics of the service. They are
+// registered with the default registry and served by Handler.
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	Database "github.com/nilesh0729/Notes/internal/db/Result"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "notes"
+
+var (
+	// HTTPRequests counts handled requests by method, route template and
+	// status code.
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Number of HTTP requests handled.",
+	}, []string{"method", "route", "status"})
+
+	// HTTPRequestDuration is the time taken to handle requests.
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time taken to handle HTTP requests.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// DBQueryDuration is the time taken by Store calls, by query name.
+	DBQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Time taken by database queries and transactions.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"query"})
+
+	// DBQueryErrors counts failed Store calls, by query name. A query that
+	// finds no rows has not failed.
+	DBQueryErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_query_errors_total",
+		Help:      "Number of database queries and transactions that failed.",
+	}, []string{"query"})
+
+	// LoginFailures counts rejected login attempts by reason.
+	LoginFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "login_failures_total",
+		Help:      "Number of rejected login attempts.",
+	}, []string{"reason"})
+
+	// TokenVerificationFailures counts requests whose access token or API
+	// key was rejected, by reason.
+	TokenVerificationFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "token_verification_failures_total",
+		Help:      "Number of requests rejected for their credentials.",
+	}, []string{"reason"})
+
+	// RateLimitedRequests counts requests rejected for exceeding the rate
+	// limit of their route group.
+	RateLimitedRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limited_requests_total",
+		Help:      "Number of requests rejected by the rate limiter.",
+	}, []string{"group"})
+)
+
+// Reasons a login can fail for.
+const (
+	LoginUnknownUser    = "unknown_user"
+	LoginWrongPassword  = "wrong_password"
+	LoginWrongTwoFactor = "wrong_two_factor_code"
+	LoginThrottled      = "throttled"
+)
+
+// Reasons the credentials of a request can be rejected for.
+const (
+	TokenMissing         = "missing"
+	TokenMalformed       = "malformed"
+	TokenUnsupportedType = "unsupported_type"
+	TokenExpired         = "expired"
+	TokenInvalid         = "invalid"
+	TokenSessionNotFound = "session_not_found"
+	TokenSessionRevoked  = "session_revoked"
+	TokenInvalidAPIKey   = "invalid_api_key"
+)
+
+// Handler serves the metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ObserveHTTPRequest records a handled request. route is the route template,
+// such as "/notes/:id", so that paths with IDs share their series. Methods
+// other than the standard ones are counted as "OTHER", since clients can send
+// any method they like.
+func ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	if route == "" {
+		route = "unmatched"
+	}
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+	default:
+		method = "OTHER"
+	}
+	code := strconv.Itoa(status)
+	HTTPRequests.WithLabelValues(method, route, code).Inc()
+	HTTPRequestDuration.WithLabelValues(method, route, code).Observe(duration.Seconds())
+}
+
+// Roles of the connection pools passed to RegisterDBStats.
+const (
+	PoolPrimary = "primary"
+	PoolReplica = "replica"
+)
+
+// RegisterDBStats exports the stats of the connection pool with role.
+func RegisterDBStats(pool *pgxpool.Pool, role string) error {
+	return prometheus.Register(newPoolCollector(pool, role))
+}
+
+// RegisterSQLDBStats exports the stats of a database/sql connection pool,
+// used by the SQLite backend.
+func RegisterSQLDBStats(db *sql.DB) error {
+	return prometheus.Register(collectors.NewDBStatsCollector(db, "sqlite"))
+}
+
+// InstrumentStore returns a Store that records the latency and failures of
+// every call made to store.
+func InstrumentStore(store Database.Store) Database.Store {
+	return Database.ObserveStore(store, queryObserver{})
+}
+
+type queryObserver struct{}
+
+func (queryObserver) StartQuery(ctx context.Context, query string) (context.Context, func(error)) {
+	start := time.Now()
+	return ctx, func(err error) {
+		DBQueryDuration.WithLabelValues(query).Observe(time.Since(start).Seconds())
+		if err != nil && !errors.Is(err, Database.ErrRecordNotFound) {
+			DBQueryErrors.WithLabelValues(query).Inc()
+		}
+	}
+}
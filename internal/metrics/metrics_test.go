@@ -0,0 +1,123 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgxpool"
+	mockDB "github.com/nilesh0729/Notes/internal/db/Mock"
+	Database "github.com/nilesh0729/Notes/internal/db/Result"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+)
+
+// sampleCount returns the number of observations in a histogram series.
+func sampleCount(t *testing.T, observer prometheus.Observer) uint64 {
+	var m dto.Metric
+	err := observer.(prometheus.Metric).Write(&m)
+	require.NoError(t, err)
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestInstrumentStore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockDB.NewMockStore(ctrl)
+	gomock.InOrder(
+		store.EXPECT().GetNoteById(gomock.Any(), int32(1)).Return(Database.Note{NoteID: 1}, nil),
+		store.EXPECT().GetNoteById(gomock.Any(), int32(2)).Return(Database.Note{}, Database.ErrRecordNotFound),
+		store.EXPECT().GetNoteById(gomock.Any(), int32(3)).Return(Database.Note{}, sql.ErrConnDone),
+	)
+	instrumented := InstrumentStore(store)
+
+	durations := DBQueryDuration.WithLabelValues("GetNoteById")
+	failures := DBQueryErrors.WithLabelValues("GetNoteById")
+	countBefore := sampleCount(t, durations)
+	failuresBefore := testutil.ToFloat64(failures)
+
+	note, err := instrumented.GetNoteById(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), note.NoteID)
+
+	_, err = instrumented.GetNoteById(context.Background(), 2)
+	require.ErrorIs(t, err, Database.ErrRecordNotFound)
+
+	_, err = instrumented.GetNoteById(context.Background(), 3)
+	require.ErrorIs(t, err, sql.ErrConnDone)
+
+	require.Equal(t, countBefore+3, sampleCount(t, durations))
+	require.Equal(t, failuresBefore+1, testutil.ToFloat64(failures))
+}
+
+func TestInstrumentStoreTx(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	txErr := errors.New("tx failed")
+	store := mockDB.NewMockStore(ctrl)
+	store.EXPECT().ChangePasswordTx(gomock.Any(), gomock.Any()).Return(Database.User{}, txErr)
+
+	failures := DBQueryErrors.WithLabelValues("ChangePasswordTx")
+	before := testutil.ToFloat64(failures)
+
+	_, err := InstrumentStore(store).ChangePasswordTx(context.Background(), Database.ChangePasswordTxParams{})
+	require.ErrorIs(t, err, txErr)
+	require.Equal(t, before+1, testutil.ToFloat64(failures))
+}
+
+func TestObserveHTTPRequest(t *testing.T) {
+	matched := HTTPRequests.WithLabelValues(http.MethodGet, "/notes/:id", "200")
+	unmatched := HTTPRequests.WithLabelValues(http.MethodGet, "unmatched", "404")
+	matchedBefore := testutil.ToFloat64(matched)
+	unmatchedBefore := testutil.ToFloat64(unmatched)
+
+	ObserveHTTPRequest(http.MethodGet, "/notes/:id", http.StatusOK, time.Millisecond)
+	ObserveHTTPRequest(http.MethodGet, "", http.StatusNotFound, time.Millisecond)
+
+	require.Equal(t, matchedBefore+1, testutil.ToFloat64(matched))
+	require.Equal(t, unmatchedBefore+1, testutil.ToFloat64(unmatched))
+}
+
+func TestObserveHTTPRequestOtherMethod(t *testing.T) {
+	other := HTTPRequests.WithLabelValues("OTHER", "unmatched", "404")
+	before := testutil.ToFloat64(other)
+
+	ObserveHTTPRequest("PROPFIND", "", http.StatusNotFound, time.Millisecond)
+	ObserveHTTPRequest("MADEUP", "", http.StatusNotFound, time.Millisecond)
+
+	require.Equal(t, before+2, testutil.ToFloat64(other))
+	require.False(t, HTTPRequests.DeleteLabelValues("PROPFIND", "unmatched", "404"))
+}
+
+func TestRegisterDBStats(t *testing.T) {
+	// The pool connects lazily, so no database is needed.
+	pool, err := pgxpool.New(context.Background(), "postgres://localhost/notes?sslmode=disable")
+	require.NoError(t, err)
+	defer pool.Close()
+
+	err = RegisterDBStats(pool, PoolPrimary)
+	require.NoError(t, err)
+
+	// A replica pool is exported next to the primary one.
+	err = RegisterDBStats(pool, PoolReplica)
+	require.NoError(t, err)
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+
+	names := make([]string, len(families))
+	for i, family := range families {
+		names[i] = family.GetName()
+	}
+	require.Contains(t, names, "notes_db_pool_connections")
+	require.Contains(t, names, "notes_db_pool_max_connections")
+	require.Contains(t, names, "notes_db_pool_acquires_total")
+}
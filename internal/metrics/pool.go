@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// poolCollector exports the stats of a pgx connection pool, read when the
+// metrics are scraped. They are labelled with the role of the pool, as the
+// server may have one for the primary and one for a read replica.
+type poolCollector struct {
+	pool *pgxpool.Pool
+
+	acquiredConns    *prometheus.Desc
+	idleConns        *prometheus.Desc
+	totalConns       *prometheus.Desc
+	maxConns         *prometheus.Desc
+	acquires         *prometheus.Desc
+	acquireDuration  *prometheus.Desc
+	emptyAcquires    *prometheus.Desc
+	canceledAcquires *prometheus.Desc
+}
+
+func newPoolCollector(pool *pgxpool.Pool, role string) *poolCollector {
+	labels := prometheus.Labels{"role": role}
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, nil, labels)
+	}
+
+	return &poolCollector{
+		pool:             pool,
+		acquiredConns:    desc("acquired_connections", "Number of connections currently in use."),
+		idleConns:        desc("idle_connections", "Number of idle connections."),
+		totalConns:       desc("connections", "Number of open connections, including ones being established."),
+		maxConns:         desc("max_connections", "Maximum number of connections the pool opens."),
+		acquires:         desc("acquires_total", "Number of connections acquired from the pool."),
+		acquireDuration:  desc("acquire_duration_seconds_total", "Total time spent waiting to acquire connections."),
+		emptyAcquires:    desc("empty_acquires_total", "Number of acquires that had to wait because the pool had no idle connection."),
+		canceledAcquires: desc("canceled_acquires_total", "Number of acquires canceled by their context."),
+	}
+}
+
+func (collector *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(collector, ch)
+}
+
+func (collector *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := collector.pool.Stat()
+
+	gauge := func(desc *prometheus.Desc, value float64) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value)
+	}
+	counter := func(desc *prometheus.Desc, value float64) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, value)
+	}
+
+	gauge(collector.acquiredConns, float64(stat.AcquiredConns()))
+	gauge(collector.idleConns, float64(stat.IdleConns()))
+	gauge(collector.totalConns, float64(stat.TotalConns()))
+	gauge(collector.maxConns, float64(stat.MaxConns()))
+	counter(collector.acquires, float64(stat.AcquireCount()))
+	counter(collector.acquireDuration, stat.AcquireDuration().Seconds())
+	counter(collector.emptyAcquires, float64(stat.EmptyAcquireCount()))
+	counter(collector.canceledAcquires, float64(stat.CanceledAcquireCount()))
+}
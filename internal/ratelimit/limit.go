@@ -0,0 +1,100 @@
+// Package ratelimit counts requests against token buckets, kept in process
+// or in Redis for servers running more than one instance.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit allows Requests per Period. The bucket holds up to Requests tokens,
+// so they may come in one burst, and refills at Requests per Period.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// ParseLimit parses a limit written as "requests/period", e.g. "60/1m".
+// An empty limit, or one of "0" or "none", allows every request.
+func ParseLimit(s string) (Limit, error) {
+	if s == "" || s == "0" || s == "none" {
+		return Limit{}, nil
+	}
+
+	requests, period, ok := strings.Cut(s, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("rate limit %q is not requests/period", s)
+	}
+
+	n, err := strconv.Atoi(requests)
+	if err != nil || n < 1 {
+		return Limit{}, fmt.Errorf("rate limit %q does not allow a positive number of requests", s)
+	}
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return Limit{}, fmt.Errorf("rate limit %q does not have a positive period", s)
+	}
+	return Limit{Requests: n, Period: d}, nil
+}
+
+// Enabled reports whether limit rejects any request.
+func (limit Limit) Enabled() bool {
+	return limit.Requests > 0
+}
+
+func (limit Limit) String() string {
+	if !limit.Enabled() {
+		return "none"
+	}
+	return fmt.Sprintf("%d/%s", limit.Requests, limit.Period)
+}
+
+// rate returns the tokens added to a bucket per nanosecond.
+func (limit Limit) rate() float64 {
+	return float64(limit.Requests) / float64(limit.Period)
+}
+
+// Result is what a Limiter decided about a request.
+type Result struct {
+	Allowed bool
+	// Remaining is the number of requests allowed right after this one.
+	Remaining int
+	// RetryAfter is the time until the next request is allowed, if this one
+	// was not.
+	RetryAfter time.Duration
+	// ResetAfter is the time until the bucket is full again.
+	ResetAfter time.Duration
+}
+
+// Limiter counts the requests made with each key.
+type Limiter interface {
+	// Allow takes a token from the bucket of key, if there is one.
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// refill returns the tokens of a bucket that had tokens elapsed ago, and
+// takes one from it if it can. A bucket seen for the first time is full.
+func refill(tokens float64, elapsed time.Duration, limit Limit) (float64, bool) {
+	tokens = min(float64(limit.Requests), tokens+float64(max(elapsed, 0))*limit.rate())
+	if tokens < 1 {
+		return tokens, false
+	}
+	return tokens - 1, true
+}
+
+// result describes a bucket left with tokens.
+func result(allowed bool, tokens float64, limit Limit) Result {
+	res := Result{
+		Allowed:    allowed,
+		Remaining:  int(math.Floor(tokens)),
+		ResetAfter: time.Duration((float64(limit.Requests) - tokens) / limit.rate()),
+	}
+	if !allowed {
+		res.RetryAfter = time.Duration((1 - tokens) / limit.rate())
+	}
+	return res
+}
@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLimit(t *testing.T) {
+	valid := map[string]Limit{
+		"":        {},
+		"0":       {},
+		"none":    {},
+		"60/1m":   {Requests: 60, Period: time.Minute},
+		"5/10s":   {Requests: 5, Period: 10 * time.Second},
+		"1/24h0m": {Requests: 1, Period: 24 * time.Hour},
+	}
+	for s, want := range valid {
+		limit, err := ParseLimit(s)
+		require.NoError(t, err, s)
+		require.Equal(t, want, limit, s)
+	}
+
+	for _, s := range []string{"60", "60/", "x/1m", "0/1m", "-1/1m", "60/0s", "60/x"} {
+		_, err := ParseLimit(s)
+		require.Error(t, err, s)
+	}
+}
+
+// testLimiter checks the token bucket of limiter, whose clock is set with
+// setNow.
+func testLimiter(t *testing.T, limiter Limiter, setNow func(time.Time)) {
+	ctx := context.Background()
+	limit := Limit{Requests: 3, Period: 3 * time.Second}
+	now := time.Now()
+	setNow(now)
+
+	// The bucket starts full.
+	for i := 2; i >= 0; i-- {
+		res, err := limiter.Allow(ctx, "a", limit)
+		require.NoError(t, err)
+		require.True(t, res.Allowed)
+		require.Equal(t, i, res.Remaining)
+	}
+
+	res, err := limiter.Allow(ctx, "a", limit)
+	require.NoError(t, err)
+	require.False(t, res.Allowed)
+	require.Zero(t, res.Remaining)
+	require.InDelta(t, time.Second, res.RetryAfter, float64(10*time.Millisecond))
+	require.InDelta(t, 3*time.Second, res.ResetAfter, float64(10*time.Millisecond))
+
+	// Other keys have buckets of their own.
+	res, err = limiter.Allow(ctx, "b", limit)
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+
+	// A token is added every second.
+	setNow(now.Add(1500 * time.Millisecond))
+	res, err = limiter.Allow(ctx, "a", limit)
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+	require.Zero(t, res.Remaining)
+
+	res, err = limiter.Allow(ctx, "a", limit)
+	require.NoError(t, err)
+	require.False(t, res.Allowed)
+	require.InDelta(t, 500*time.Millisecond, res.RetryAfter, float64(10*time.Millisecond))
+
+	// The bucket never holds more than the limit.
+	setNow(now.Add(time.Hour))
+	res, err = limiter.Allow(ctx, "a", limit)
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+	require.Equal(t, 2, res.Remaining)
+}
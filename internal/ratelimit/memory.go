@@ -0,0 +1,71 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often Memory forgets the buckets that are full.
+const sweepInterval = time.Minute
+
+// Memory keeps the buckets in process, so every instance of the server
+// counts its own requests.
+type Memory struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	full    time.Time
+}
+
+// NewMemory creates an empty Memory limiter.
+func NewMemory() *Memory {
+	return &Memory{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Allow takes a token from the bucket of key, if there is one.
+func (limiter *Memory) Allow(_ context.Context, key string, limit Limit) (Result, error) {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+
+	now := limiter.now()
+	limiter.sweep(now)
+
+	b, ok := limiter.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Requests), updated: now}
+		limiter.buckets[key] = b
+	}
+
+	tokens, allowed := refill(b.tokens, now.Sub(b.updated), limit)
+	res := result(allowed, tokens, limit)
+
+	b.tokens = tokens
+	b.updated = now
+	b.full = now.Add(res.ResetAfter)
+	return res, nil
+}
+
+// sweep forgets the buckets that have filled up again, at most once per
+// sweepInterval. A new bucket starts full, so this changes no result.
+func (limiter *Memory) sweep(now time.Time) {
+	if now.Sub(limiter.lastSweep) < sweepInterval {
+		return
+	}
+	limiter.lastSweep = now
+
+	for key, b := range limiter.buckets {
+		if !now.Before(b.full) {
+			delete(limiter.buckets, key)
+		}
+	}
+}
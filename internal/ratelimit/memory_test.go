@@ -0,0 +1,29 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemory(t *testing.T) {
+	limiter := NewMemory()
+	testLimiter(t, limiter, func(now time.Time) {
+		limiter.now = func() time.Time { return now }
+	})
+}
+
+func TestMemorySweep(t *testing.T) {
+	limiter := NewMemory()
+	now := time.Now()
+	limiter.now = func() time.Time { return now }
+
+	limit := Limit{Requests: 1, Period: time.Second}
+	limiter.Allow(t.Context(), "a", limit)
+	limiter.Allow(t.Context(), "b", limit)
+
+	now = now.Add(sweepInterval)
+	limiter.Allow(t.Context(), "c", limit)
+	if len(limiter.buckets) != 1 {
+		t.Fatalf("got %d buckets after the sweep, want 1", len(limiter.buckets))
+	}
+}
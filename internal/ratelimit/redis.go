@@ -0,0 +1,76 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// keyPrefix keeps the buckets apart from other data in the same Redis
+// database.
+const keyPrefix = "notes:ratelimit:"
+
+// takeToken refills the bucket KEYS[1] of a limit of ARGV[1] requests per
+// ARGV[2] milliseconds at time ARGV[3] (in milliseconds), and takes a token
+// from it if it can, as refill does. It returns whether it did, and the
+// tokens left. The bucket expires once it would be full again.
+var takeToken = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = capacity / tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local state = redis.call("HMGET", KEYS[1], "tokens", "updated")
+local tokens = tonumber(state[1]) or capacity
+local updated = tonumber(state[2]) or now
+
+tokens = math.min(capacity, tokens + math.max(0, now - updated) * rate)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "updated", tostring(now))
+redis.call("PEXPIRE", KEYS[1], math.ceil((capacity - tokens) / rate) + 1)
+return {allowed, tostring(tokens)}
+`)
+
+// Redis keeps the buckets in Redis, so that every instance of the server
+// shares them. The clocks of the instances are assumed to agree.
+type Redis struct {
+	client redis.UniversalClient
+	now    func() time.Time
+}
+
+// NewRedis creates a limiter keeping its buckets with client.
+func NewRedis(client redis.UniversalClient) *Redis {
+	return &Redis{client: client, now: time.Now}
+}
+
+// Allow takes a token from the bucket of key, if there is one.
+func (limiter *Redis) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	res, err := takeToken.Run(ctx, limiter.client, []string{keyPrefix + key},
+		limit.Requests,
+		limit.Period.Milliseconds(),
+		limiter.now().UnixMilli(),
+	).Slice()
+	if err != nil {
+		return Result{}, err
+	}
+
+	if len(res) != 2 {
+		return Result{}, fmt.Errorf("unexpected reply %v", res)
+	}
+	allowed, ok := res[0].(int64)
+	if !ok {
+		return Result{}, fmt.Errorf("unexpected reply %v", res)
+	}
+	tokens, err := strconv.ParseFloat(fmt.Sprint(res[1]), 64)
+	if err != nil {
+		return Result{}, err
+	}
+	return result(allowed == 1, tokens, limit), nil
+}
@@ -0,0 +1,28 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedis(t *testing.T) {
+	server := miniredis.RunT(t)
+	limiter := NewRedis(redis.NewClient(&redis.Options{Addr: server.Addr(), MaxRetries: -1}))
+	testLimiter(t, limiter, func(now time.Time) {
+		limiter.now = func() time.Time { return now }
+	})
+
+	// Buckets expire once they are full again.
+	require.True(t, server.Exists(keyPrefix+"b"))
+	server.FastForward(2 * time.Second)
+	require.False(t, server.Exists(keyPrefix+"b"))
+
+	server.Close()
+	_, err := limiter.Allow(context.Background(), "a", Limit{Requests: 1, Period: time.Second})
+	require.Error(t, err)
+}
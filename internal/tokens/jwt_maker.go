@@ -1,7 +1,6 @@
 package tokens
 
 import (
-	"errors"
 	"fmt"
 	"time"
 
@@ -14,25 +13,27 @@ const (
 
 type JWTMaker struct {
 	secretKey string
+	claims    ClaimsConfig
 }
 
-func NewJWTMaker(secretkey string) (Maker, error) {
+func NewJWTMaker(secretkey string, claims ClaimsConfig) (Maker, error) {
 	if len(secretkey) < minSecretKeySize {
 		return nil, fmt.Errorf("the size of the secret key must be  atleast %d characters", minSecretKeySize)
 	}
-	return &JWTMaker{secretKey: secretkey}, nil
+	return &JWTMaker{secretKey: secretkey, claims: claims}, nil
 }
 
-func (maker *JWTMaker) CreateToken(username string, Duration time.Duration) (string, error){
+func (maker *JWTMaker) CreateToken(username string, Duration time.Duration, scopes ...string) (string, *Payload, error){
 
-	payload, err := NewPayload(username,Duration)
+	payload, err := maker.claims.newPayload(username, Duration, scopes)
 	if err != nil{
-		return "", err
+		return "", nil, err
 	}
 
 	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, payload)
 
-	return jwtToken.SignedString([]byte(maker.secretKey))
+	token, err := jwtToken.SignedString([]byte(maker.secretKey))
+	return token, payload, err
 }
 
 func (maker *JWTMaker) VerifyToken(token string)(*Payload, error){
@@ -43,16 +44,19 @@ func (maker *JWTMaker) VerifyToken(token string)(*Payload, error){
 		}
 		return []byte(maker.secretKey), nil
 	}
-	jwttoken, err := jwt.ParseWithClaims(token, &Payload{}, keyFunc)
+	// Claims are checked by ClaimsConfig.validate, the same as for PASETOs.
+	jwttoken, err := jwt.ParseWithClaims(token, &Payload{}, keyFunc, jwt.WithoutClaimsValidation())
 	if err != nil{
-		if errors.Is(err, jwt.ErrTokenExpired){
-			return nil, ErrExpiredToken
-		}
 		return nil, ErrInvalidToken
 	}
 	Payload, ok := jwttoken.Claims.(*Payload)
 	if !ok {
 		return nil, ErrInvalidToken
 	}
+
+	err = maker.claims.validate(Payload)
+	if err != nil {
+		return nil, err
+	}
 	return Payload, nil
 }
@@ -0,0 +1,80 @@
+package tokens
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWTPublicMaker signs JWTs with the current key of a KeyRing (RS256 or
+// ES256) and verifies them with whichever key their kid header names.
+type JWTPublicMaker struct {
+	ring   *KeyRing
+	method jwt.SigningMethod
+	claims ClaimsConfig
+}
+
+func NewJWTPublicMaker(ring *KeyRing, claims ClaimsConfig) (Maker, error) {
+	var method jwt.SigningMethod
+	switch ring.Algorithm() {
+	case AlgorithmRS256:
+		method = jwt.SigningMethodRS256
+	case AlgorithmES256:
+		method = jwt.SigningMethodES256
+	default:
+		return nil, fmt.Errorf("JWTs cannot be signed with %s keys", ring.Algorithm())
+	}
+
+	return &JWTPublicMaker{ring: ring, method: method, claims: claims}, nil
+}
+
+func (maker *JWTPublicMaker) CreateToken(username string, duration time.Duration, scopes ...string) (string, *Payload, error) {
+	payload, err := maker.claims.newPayload(username, duration, scopes)
+	if err != nil {
+		return "", nil, err
+	}
+
+	jwtToken := jwt.NewWithClaims(maker.method, payload)
+	jwtToken.Header["kid"] = maker.ring.Current().ID
+
+	token, err := jwtToken.SignedString(maker.ring.signer)
+	return token, payload, err
+}
+
+func (maker *JWTPublicMaker) VerifyToken(token string) (*Payload, error) {
+	keyFunc := func(token *jwt.Token) (interface{}, error) {
+		kid, ok := token.Header["kid"].(string)
+		if !ok {
+			return nil, ErrInvalidToken
+		}
+		key, ok := maker.ring.Key(kid)
+		if !ok {
+			return nil, ErrInvalidToken
+		}
+		return key.Key, nil
+	}
+
+	jwtToken, err := jwt.ParseWithClaims(token, &Payload{}, keyFunc,
+		jwt.WithValidMethods([]string{maker.method.Alg()}),
+		jwt.WithoutClaimsValidation(),
+	)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	payload, ok := jwtToken.Claims.(*Payload)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	err = maker.claims.validate(payload)
+	if err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+func (maker *JWTPublicMaker) KeyRing() *KeyRing {
+	return maker.ring
+}
@@ -0,0 +1,116 @@
+package tokens
+
+import (
+	"crypto"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/nilesh0729/Notes/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWTPublicMaker(t *testing.T) {
+	for _, key := range []crypto.Signer{newRSAKey(t), newECDSAKey(t)} {
+		ring, err := NewKeyRing(key)
+		require.NoError(t, err)
+
+		maker, err := NewJWTPublicMaker(ring, ClaimsConfig{})
+		require.NoError(t, err)
+
+		username := util.RandomOwner()
+		duration := time.Minute
+
+		issuedAt := time.Now()
+		expiredAt := issuedAt.Add(duration)
+
+		token, payload, err := maker.CreateToken(username, duration)
+		require.NoError(t, err)
+		require.NotEmpty(t, token)
+		require.NotEmpty(t, payload)
+
+		payload, err = maker.VerifyToken(token)
+		require.NoError(t, err)
+		require.NotZero(t, payload.ID)
+		require.Equal(t, username, payload.Username)
+		require.WithinDuration(t, issuedAt, payload.IssuedAt.Local(), time.Second)
+		require.WithinDuration(t, expiredAt, payload.ExpiresAt.Local(), time.Second)
+
+		parsed, _, err := jwt.NewParser().ParseUnverified(token, &Payload{})
+		require.NoError(t, err)
+		require.Equal(t, ring.Algorithm(), parsed.Method.Alg())
+		require.Equal(t, ring.Current().ID, parsed.Header["kid"])
+	}
+}
+
+func TestJWTPublicMakerRotation(t *testing.T) {
+	oldKey := newECDSAKey(t)
+
+	oldRing, err := NewKeyRing(oldKey)
+	require.NoError(t, err)
+	oldMaker, err := NewJWTPublicMaker(oldRing, ClaimsConfig{})
+	require.NoError(t, err)
+
+	token, _, err := oldMaker.CreateToken(util.RandomOwner(), time.Minute)
+	require.NoError(t, err)
+
+	// Tokens signed with the previous key stay valid after rotation.
+	ring, err := NewKeyRing(newECDSAKey(t), oldKey.Public())
+	require.NoError(t, err)
+	maker, err := NewJWTPublicMaker(ring, ClaimsConfig{})
+	require.NoError(t, err)
+
+	_, err = maker.VerifyToken(token)
+	require.NoError(t, err)
+
+	// Once the previous key is dropped they are rejected.
+	ring, err = NewKeyRing(newECDSAKey(t))
+	require.NoError(t, err)
+	maker, err = NewJWTPublicMaker(ring, ClaimsConfig{})
+	require.NoError(t, err)
+
+	_, err = maker.VerifyToken(token)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+}
+
+func TestExpiredJWTPublicToken(t *testing.T) {
+	ring, err := NewKeyRing(newRSAKey(t))
+	require.NoError(t, err)
+	maker, err := NewJWTPublicMaker(ring, ClaimsConfig{})
+	require.NoError(t, err)
+
+	token, _, err := maker.CreateToken(util.RandomOwner(), -time.Minute)
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyToken(token)
+	require.EqualError(t, err, ErrExpiredToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestJWTPublicMakerRejectsHMAC(t *testing.T) {
+	ring, err := NewKeyRing(newRSAKey(t))
+	require.NoError(t, err)
+	maker, err := NewJWTPublicMaker(ring, ClaimsConfig{})
+	require.NoError(t, err)
+
+	payload, err := NewPayload(util.RandomOwner(), time.Minute)
+	require.NoError(t, err)
+
+	// An HS256 token keyed with the public key must not be accepted.
+	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, payload)
+	jwtToken.Header["kid"] = ring.Current().ID
+	token, err := jwtToken.SignedString([]byte(ring.Current().ID))
+	require.NoError(t, err)
+
+	payload, err = maker.VerifyToken(token)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestJWTPublicMakerRejectsEd25519(t *testing.T) {
+	ring, err := NewKeyRing(newEd25519Key(t))
+	require.NoError(t, err)
+
+	_, err = NewJWTPublicMaker(ring, ClaimsConfig{})
+	require.Error(t, err)
+}
@@ -0,0 +1,200 @@
+package tokens
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/go-jose/go-jose/v4"
+)
+
+// Signature algorithms of the keys in a KeyRing, named as in JWA.
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmES256 = "ES256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+const minRSAKeyBits = 2048
+
+// PublicKey is a verification key together with its key ID.
+type PublicKey struct {
+	ID        string
+	Algorithm string
+	Key       crypto.PublicKey
+}
+
+// KeyRing holds the private key new tokens are signed with, and the public
+// keys tokens are verified with: the current one and any previous ones that
+// are still accepted while their tokens expire. Keys are identified by their
+// RFC 7638 thumbprint, which is put into every token as its kid.
+type KeyRing struct {
+	signer  crypto.Signer
+	current PublicKey
+	keys    map[string]PublicKey
+	order   []string
+}
+
+// NewKeyRing creates a key ring signing with current. Previous keys must use
+// the same algorithm as the current one.
+func NewKeyRing(current crypto.Signer, previous ...crypto.PublicKey) (*KeyRing, error) {
+	currentKey, err := newPublicKey(current.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	ring := &KeyRing{
+		signer:  current,
+		current: currentKey,
+		keys:    make(map[string]PublicKey),
+	}
+	ring.add(currentKey)
+
+	for _, key := range previous {
+		previousKey, err := newPublicKey(key)
+		if err != nil {
+			return nil, err
+		}
+		if previousKey.Algorithm != currentKey.Algorithm {
+			return nil, fmt.Errorf("previous key %s uses %s, but the current key uses %s", previousKey.ID, previousKey.Algorithm, currentKey.Algorithm)
+		}
+		ring.add(previousKey)
+	}
+
+	return ring, nil
+}
+
+// LoadKeyRing reads a PEM encoded private key from currentFile and PEM
+// encoded public (or private) keys from previousFiles.
+func LoadKeyRing(currentFile string, previousFiles ...string) (*KeyRing, error) {
+	key, err := readPEMKey(currentFile)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%s does not contain a private key", currentFile)
+	}
+
+	previous := make([]crypto.PublicKey, 0, len(previousFiles))
+	for _, file := range previousFiles {
+		key, err := readPEMKey(file)
+		if err != nil {
+			return nil, err
+		}
+		if signer, ok := key.(crypto.Signer); ok {
+			key = signer.Public()
+		}
+		previous = append(previous, key)
+	}
+
+	return NewKeyRing(signer, previous...)
+}
+
+func (ring *KeyRing) add(key PublicKey) {
+	if _, ok := ring.keys[key.ID]; ok {
+		return
+	}
+	ring.keys[key.ID] = key
+	ring.order = append(ring.order, key.ID)
+}
+
+// Algorithm returns the signature algorithm shared by all keys in the ring.
+func (ring *KeyRing) Algorithm() string {
+	return ring.current.Algorithm
+}
+
+// Current returns the key new tokens are signed with.
+func (ring *KeyRing) Current() PublicKey {
+	return ring.current
+}
+
+// Key returns the verification key with the given ID.
+func (ring *KeyRing) Key(id string) (PublicKey, bool) {
+	key, ok := ring.keys[id]
+	return key, ok
+}
+
+// JWKS returns the public keys of the ring as a JSON Web Key Set, current key
+// first, for publishing at /.well-known/jwks.json.
+func (ring *KeyRing) JWKS() jose.JSONWebKeySet {
+	set := jose.JSONWebKeySet{Keys: make([]jose.JSONWebKey, 0, len(ring.order))}
+	for _, id := range ring.order {
+		key := ring.keys[id]
+		set.Keys = append(set.Keys, jose.JSONWebKey{
+			Key:       key.Key,
+			KeyID:     key.ID,
+			Algorithm: key.Algorithm,
+			Use:       "sig",
+		})
+	}
+	return set
+}
+
+func newPublicKey(key crypto.PublicKey) (PublicKey, error) {
+	var algorithm string
+
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		if key.N.BitLen() < minRSAKeyBits {
+			return PublicKey{}, fmt.Errorf("RSA keys must have at least %d bits", minRSAKeyBits)
+		}
+		algorithm = AlgorithmRS256
+	case *ecdsa.PublicKey:
+		if key.Curve != elliptic.P256() {
+			return PublicKey{}, errors.New("ECDSA keys must use the P-256 curve")
+		}
+		algorithm = AlgorithmES256
+	case ed25519.PublicKey:
+		algorithm = AlgorithmEdDSA
+	default:
+		return PublicKey{}, fmt.Errorf("unsupported key type %T", key)
+	}
+
+	jwk := jose.JSONWebKey{Key: key}
+	thumbprint, err := jwk.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return PublicKey{}, err
+	}
+
+	return PublicKey{
+		ID:        base64.RawURLEncoding.EncodeToString(thumbprint),
+		Algorithm: algorithm,
+		Key:       key,
+	}, nil
+}
+
+// readPEMKey parses the first PEM block of file as a PKCS #8, PKCS #1 or SEC 1
+// private key, or as a PKIX public key.
+func readPEMKey(file string) (interface{}, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s does not contain a PEM encoded key", file)
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%s contains an unsupported PEM block %q", file, block.Type)
+	}
+}
@@ -0,0 +1,126 @@
+package tokens
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newRSAKey(t *testing.T) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return key
+}
+
+func newECDSAKey(t *testing.T) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return key
+}
+
+func newEd25519Key(t *testing.T) ed25519.PrivateKey {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return key
+}
+
+func writePEM(t *testing.T, blockType string, der []byte) string {
+	file := filepath.Join(t.TempDir(), "key.pem")
+	err := os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600)
+	require.NoError(t, err)
+	return file
+}
+
+func TestKeyRing(t *testing.T) {
+	current := newECDSAKey(t)
+	previous := newECDSAKey(t)
+
+	ring, err := NewKeyRing(current, previous.Public())
+	require.NoError(t, err)
+	require.Equal(t, AlgorithmES256, ring.Algorithm())
+	require.NotEmpty(t, ring.Current().ID)
+
+	// Key IDs are derived from the key, so they survive restarts.
+	again, err := NewKeyRing(current)
+	require.NoError(t, err)
+	require.Equal(t, ring.Current().ID, again.Current().ID)
+
+	jwks := ring.JWKS()
+	require.Len(t, jwks.Keys, 2)
+	require.Equal(t, ring.Current().ID, jwks.Keys[0].KeyID)
+	for _, key := range jwks.Keys {
+		require.True(t, key.IsPublic())
+		require.Equal(t, AlgorithmES256, key.Algorithm)
+		require.Equal(t, "sig", key.Use)
+
+		found, ok := ring.Key(key.KeyID)
+		require.True(t, ok)
+		require.Equal(t, key.KeyID, found.ID)
+	}
+}
+
+func TestKeyRingRejectsMixedAlgorithms(t *testing.T) {
+	_, err := NewKeyRing(newECDSAKey(t), newRSAKey(t).Public())
+	require.Error(t, err)
+}
+
+func TestKeyRingRejectsWeakKeys(t *testing.T) {
+	weak, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	_, err = NewKeyRing(weak)
+	require.Error(t, err)
+
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	_, err = NewKeyRing(p384)
+	require.Error(t, err)
+}
+
+func TestLoadKeyRing(t *testing.T) {
+	current := newEd25519Key(t)
+	previous := newEd25519Key(t)
+
+	der, err := x509.MarshalPKCS8PrivateKey(current)
+	require.NoError(t, err)
+	currentFile := writePEM(t, "PRIVATE KEY", der)
+
+	der, err = x509.MarshalPKIXPublicKey(previous.Public())
+	require.NoError(t, err)
+	previousFile := writePEM(t, "PUBLIC KEY", der)
+
+	ring, err := LoadKeyRing(currentFile, previousFile)
+	require.NoError(t, err)
+	require.Equal(t, AlgorithmEdDSA, ring.Algorithm())
+	require.Len(t, ring.JWKS().Keys, 2)
+
+	expected, err := NewKeyRing(current, previous.Public())
+	require.NoError(t, err)
+	require.Equal(t, expected.Current().ID, ring.Current().ID)
+
+	// Only a private key can be the current key.
+	_, err = LoadKeyRing(previousFile)
+	require.Error(t, err)
+
+	_, err = LoadKeyRing(filepath.Join(t.TempDir(), "missing.pem"))
+	require.Error(t, err)
+}
+
+func TestLoadKeyRingPKCS1(t *testing.T) {
+	key := newRSAKey(t)
+	file := writePEM(t, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(key))
+
+	ring, err := LoadKeyRing(file)
+	require.NoError(t, err)
+	require.Equal(t, AlgorithmRS256, ring.Algorithm())
+	require.Equal(t, crypto.PublicKey(&key.PublicKey), ring.Current().Key)
+}
@@ -2,7 +2,22 @@ package tokens
 
 import "time"
 
+// Token types that can be selected in the configuration.
+const (
+	TypePaseto       = "paseto"
+	TypeJWT          = "jwt"
+	TypePasetoPublic = "paseto-public"
+	TypeJWTPublic    = "jwt-public"
+)
+
 type Maker interface {
-	CreateToken(username string, Duration time.Duration) (string, error)
+	CreateToken(username string, Duration time.Duration, scopes ...string) (string, *Payload, error)
 	VerifyToken(token string)(*Payload, error)
 }
+
+// PublicKeyMaker is a Maker whose tokens anyone can verify with the public
+// keys of its KeyRing.
+type PublicKeyMaker interface {
+	Maker
+	KeyRing() *KeyRing
+}
@@ -11,9 +11,10 @@ import (
 type PasetoMaker struct {
 	paseto        *paseto.V2
 	symmetrickKey []byte
+	claims        ClaimsConfig
 }
 
-func NewPasetoMaker(symmetric_key string) (Maker, error) {
+func NewPasetoMaker(symmetric_key string, claims ClaimsConfig) (Maker, error) {
 	if len(symmetric_key) != chacha20poly1305.KeySize {
 		return nil, fmt.Errorf("Invalid Key Size: keySize must be %d", chacha20poly1305.KeySize)
 	}
@@ -21,17 +22,19 @@ func NewPasetoMaker(symmetric_key string) (Maker, error) {
 	maker := &PasetoMaker{
 		paseto:        paseto.NewV2(),
 		symmetrickKey: []byte(symmetric_key),
+		claims:        claims,
 	}
 
 	return maker, nil
 }
 
-func (maker *PasetoMaker) CreateToken(username string, duration time.Duration)(string, error){
-	payload, err := NewPayload(username, duration)
+func (maker *PasetoMaker) CreateToken(username string, duration time.Duration, scopes ...string)(string, *Payload, error){
+	payload, err := maker.claims.newPayload(username, duration, scopes)
 	if err != nil{
-		return "", err
+		return "", nil, err
 	}
-	return maker.paseto.Encrypt(maker.symmetrickKey, payload, nil)
+	token, err := maker.paseto.Encrypt(maker.symmetrickKey, payload, nil)
+	return token, payload, err
 }
 
 func (maker *PasetoMaker) VerifyToken(token string)(*Payload, error){
@@ -41,9 +44,9 @@ func (maker *PasetoMaker) VerifyToken(token string)(*Payload, error){
 	if err != nil{
 		return nil, ErrInvalidToken
 	}
-	err = payload.valid()
+	err = maker.claims.validate(payload)
 	if err != nil{
-		return nil, ErrExpiredToken
+		return nil, err
 	}
 
 	return payload, nil
@@ -0,0 +1,173 @@
+package tokens
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// pasetoFooter is the unencrypted footer of v4.public tokens. It names the
+// key the token was signed with.
+type pasetoFooter struct {
+	KeyID string `json:"kid"`
+}
+
+// PasetoPublicMaker signs v4.public PASETOs with the current Ed25519 key of
+// a KeyRing and verifies them with whichever key their footer names.
+type PasetoPublicMaker struct {
+	ring       *KeyRing
+	secretKey  paseto.V4AsymmetricSecretKey
+	publicKeys map[string]paseto.V4AsymmetricPublicKey
+	claims     ClaimsConfig
+}
+
+func NewPasetoPublicMaker(ring *KeyRing, claims ClaimsConfig) (Maker, error) {
+	if ring.Algorithm() != AlgorithmEdDSA {
+		return nil, fmt.Errorf("v4.public PASETOs cannot be signed with %s keys", ring.Algorithm())
+	}
+
+	secretKey, err := paseto.NewV4AsymmetricSecretKeyFromEd25519(ring.signer.(ed25519.PrivateKey))
+	if err != nil {
+		return nil, err
+	}
+
+	publicKeys := make(map[string]paseto.V4AsymmetricPublicKey, len(ring.keys))
+	for id, key := range ring.keys {
+		publicKeys[id], err = paseto.NewV4AsymmetricPublicKeyFromEd25519(key.Key.(ed25519.PublicKey))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &PasetoPublicMaker{
+		ring:       ring,
+		secretKey:  secretKey,
+		publicKeys: publicKeys,
+		claims:     claims,
+	}, nil
+}
+
+func (maker *PasetoPublicMaker) CreateToken(username string, duration time.Duration, scopes ...string) (string, *Payload, error) {
+	payload, err := maker.claims.newPayload(username, duration, scopes)
+	if err != nil {
+		return "", nil, err
+	}
+
+	footer, err := json.Marshal(pasetoFooter{KeyID: maker.ring.Current().ID})
+	if err != nil {
+		return "", nil, err
+	}
+
+	token := paseto.NewToken()
+	token.SetJti(payload.ID)
+	token.SetIssuedAt(payload.IssuedAt.Time)
+	token.SetNotBefore(payload.NotBefore.Time)
+	token.SetExpiration(payload.ExpiresAt.Time)
+	if payload.Issuer != "" {
+		token.SetIssuer(payload.Issuer)
+	}
+	if len(payload.Audience) > 0 {
+		token.SetAudience(payload.Audience[0])
+	}
+	err = token.Set("username", payload.Username)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(payload.Scopes) > 0 {
+		err = token.Set("scopes", payload.Scopes)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+	token.SetFooter(footer)
+
+	return token.V4Sign(maker.secretKey, nil), payload, nil
+}
+
+func (maker *PasetoPublicMaker) VerifyToken(token string) (*Payload, error) {
+	parser := paseto.NewParserWithoutExpiryCheck()
+
+	rawFooter, err := parser.UnsafeParseFooter(paseto.V4Public, token)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	var footer pasetoFooter
+	err = json.Unmarshal(rawFooter, &footer)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	publicKey, ok := maker.publicKeys[footer.KeyID]
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	parsed, err := parser.ParseV4Public(publicKey, token, nil)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	payload, err := pasetoPayload(parsed)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	err = maker.claims.validate(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
+
+func (maker *PasetoPublicMaker) KeyRing() *KeyRing {
+	return maker.ring
+}
+
+// pasetoPayload reads a Payload from the claims of token. Only username,
+// jti, iat and exp are required; the others are left empty when missing.
+func pasetoPayload(token *paseto.Token) (*Payload, error) {
+	username, err := token.GetString("username")
+	if err != nil {
+		return nil, err
+	}
+	id, err := token.GetJti()
+	if err != nil {
+		return nil, err
+	}
+	issuedAt, err := token.GetIssuedAt()
+	if err != nil {
+		return nil, err
+	}
+	expiresAt, err := token.GetExpiration()
+	if err != nil {
+		return nil, err
+	}
+
+	payload := &Payload{
+		Username: username,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        id,
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	if notBefore, err := token.GetNotBefore(); err == nil {
+		payload.NotBefore = jwt.NewNumericDate(notBefore)
+	}
+	if issuer, err := token.GetIssuer(); err == nil {
+		payload.Issuer = issuer
+	}
+	if audience, err := token.GetAudience(); err == nil {
+		payload.Audience = jwt.ClaimStrings{audience}
+	}
+	if token.Get("scopes", &payload.Scopes) != nil {
+		payload.Scopes = nil
+	}
+
+	return payload, nil
+}
@@ -0,0 +1,109 @@
+package tokens
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nilesh0729/Notes/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasetoPublicMaker(t *testing.T) {
+	ring, err := NewKeyRing(newEd25519Key(t))
+	require.NoError(t, err)
+
+	maker, err := NewPasetoPublicMaker(ring, ClaimsConfig{})
+	require.NoError(t, err)
+
+	username := util.RandomOwner()
+	duration := time.Minute
+
+	issuedAt := time.Now()
+	expiredAt := issuedAt.Add(duration)
+
+	token, payload, err := maker.CreateToken(username, duration)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(token, "v4.public."))
+	require.NotEmpty(t, payload)
+
+	verified, err := maker.VerifyToken(token)
+	require.NoError(t, err)
+	require.Equal(t, payload.ID, verified.ID)
+	require.Equal(t, username, verified.Username)
+	require.WithinDuration(t, issuedAt, verified.IssuedAt.Local(), time.Second)
+	require.WithinDuration(t, expiredAt, verified.ExpiresAt.Local(), time.Second)
+}
+
+func TestPasetoPublicMakerRotation(t *testing.T) {
+	oldKey := newEd25519Key(t)
+
+	oldRing, err := NewKeyRing(oldKey)
+	require.NoError(t, err)
+	oldMaker, err := NewPasetoPublicMaker(oldRing, ClaimsConfig{})
+	require.NoError(t, err)
+
+	token, _, err := oldMaker.CreateToken(util.RandomOwner(), time.Minute)
+	require.NoError(t, err)
+
+	ring, err := NewKeyRing(newEd25519Key(t), oldKey.Public())
+	require.NoError(t, err)
+	maker, err := NewPasetoPublicMaker(ring, ClaimsConfig{})
+	require.NoError(t, err)
+
+	_, err = maker.VerifyToken(token)
+	require.NoError(t, err)
+
+	ring, err = NewKeyRing(newEd25519Key(t))
+	require.NoError(t, err)
+	maker, err = NewPasetoPublicMaker(ring, ClaimsConfig{})
+	require.NoError(t, err)
+
+	_, err = maker.VerifyToken(token)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+}
+
+func TestExpiredPasetoPublicToken(t *testing.T) {
+	ring, err := NewKeyRing(newEd25519Key(t))
+	require.NoError(t, err)
+	maker, err := NewPasetoPublicMaker(ring, ClaimsConfig{})
+	require.NoError(t, err)
+
+	token, _, err := maker.CreateToken(util.RandomOwner(), -time.Minute)
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyToken(token)
+	require.EqualError(t, err, ErrExpiredToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestTamperedPasetoPublicToken(t *testing.T) {
+	ring, err := NewKeyRing(newEd25519Key(t))
+	require.NoError(t, err)
+	maker, err := NewPasetoPublicMaker(ring, ClaimsConfig{})
+	require.NoError(t, err)
+
+	token, _, err := maker.CreateToken(util.RandomOwner(), time.Minute)
+	require.NoError(t, err)
+
+	parts := strings.Split(token, ".")
+	body := []byte(parts[2])
+	if body[0] == 'A' {
+		body[0] = 'B'
+	} else {
+		body[0] = 'A'
+	}
+	parts[2] = string(body)
+
+	payload, err := maker.VerifyToken(strings.Join(parts, "."))
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestPasetoPublicMakerRejectsECDSA(t *testing.T) {
+	ring, err := NewKeyRing(newECDSAKey(t))
+	require.NoError(t, err)
+
+	_, err = NewPasetoPublicMaker(ring, ClaimsConfig{})
+	require.Error(t, err)
+}
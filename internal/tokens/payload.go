@@ -2,6 +2,7 @@ package tokens
 
 import (
 	"errors"
+	"slices"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -9,36 +10,87 @@ import (
 )
 
 var (
-	ErrInvalidToken = errors.New("token is invalid")
-	ErrExpiredToken = errors.New("token has expired")
+	ErrInvalidToken     = errors.New("token is invalid")
+	ErrExpiredToken     = errors.New("token has expired")
+	ErrTokenNotValidYet = errors.New("token is not valid yet")
 )
 
 type Payload struct {
-	Username string `json:"username"`
+	Username string   `json:"username"`
+	Scopes   []string `json:"scopes,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func NewPayload(username string, duration time.Duration) (*Payload, error) {
+func NewPayload(username string, duration time.Duration, scopes ...string) (*Payload, error) {
 	TokenId, err := uuid.NewRandom()
 	if err != nil {
 		return nil, err
 	}
 
+	now := time.Now()
 	Payload := &Payload{
 		Username: username,
+		Scopes:   scopes,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ID:        TokenId.String(),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(duration)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(duration)),
 		},
 	}
 	return Payload, nil
 }
 
-func (Payload *Payload) valid() error {
-	if time.Now().After(Payload.RegisteredClaims.ExpiresAt.Time) {
-		return  ErrExpiredToken
+// HasScope reports whether the token was granted scope.
+func (payload *Payload) HasScope(scope string) bool {
+	return slices.Contains(payload.Scopes, scope)
+}
+
+// ClaimsConfig holds the registered claims every maker puts into new tokens,
+// and checks when verifying them. Leeway is the clock skew tolerated for the
+// expiry and not-before times.
+type ClaimsConfig struct {
+	Issuer   string
+	Audience string
+	Leeway   time.Duration
+}
+
+// newPayload creates a payload carrying the configured issuer and audience.
+func (config ClaimsConfig) newPayload(username string, duration time.Duration, scopes []string) (*Payload, error) {
+	payload, err := NewPayload(username, duration, scopes...)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+
+	payload.Issuer = config.Issuer
+	if config.Audience != "" {
+		payload.Audience = jwt.ClaimStrings{config.Audience}
+	}
+	return payload, nil
 }
 
+// validate checks the claims of a payload whose signature or encryption has
+// already been verified. All makers go through it, so a token is judged the
+// same way whatever its format.
+func (config ClaimsConfig) validate(payload *Payload) error {
+	now := time.Now()
+
+	if payload.ExpiresAt == nil {
+		return ErrInvalidToken
+	}
+	if now.After(payload.ExpiresAt.Add(config.Leeway)) {
+		return ErrExpiredToken
+	}
+	if payload.NotBefore != nil && now.Add(config.Leeway).Before(payload.NotBefore.Time) {
+		return ErrTokenNotValidYet
+	}
+
+	if config.Issuer != "" && payload.Issuer != config.Issuer {
+		return ErrInvalidToken
+	}
+	if config.Audience != "" && !slices.Contains(payload.Audience, config.Audience) {
+		return ErrInvalidToken
+	}
+
+	return nil
+}
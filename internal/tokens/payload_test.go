@@ -0,0 +1,159 @@
+package tokens
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/nilesh0729/Notes/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+// makerFactories return, for every token format, a function creating makers
+// that share one key but differ in their ClaimsConfig.
+func makerFactories(t *testing.T) map[string]func(claims ClaimsConfig) Maker {
+	secret := util.RandomString(32)
+
+	jwtRing, err := NewKeyRing(newECDSAKey(t))
+	require.NoError(t, err)
+	pasetoRing, err := NewKeyRing(newEd25519Key(t))
+	require.NoError(t, err)
+
+	must := func(maker Maker, err error) Maker {
+		require.NoError(t, err)
+		return maker
+	}
+
+	return map[string]func(claims ClaimsConfig) Maker{
+		TypePaseto: func(claims ClaimsConfig) Maker {
+			return must(NewPasetoMaker(secret, claims))
+		},
+		TypeJWT: func(claims ClaimsConfig) Maker {
+			return must(NewJWTMaker(secret, claims))
+		},
+		TypePasetoPublic: func(claims ClaimsConfig) Maker {
+			return must(NewPasetoPublicMaker(pasetoRing, claims))
+		},
+		TypeJWTPublic: func(claims ClaimsConfig) Maker {
+			return must(NewJWTPublicMaker(jwtRing, claims))
+		},
+	}
+}
+
+func TestMakersValidateClaims(t *testing.T) {
+	claims := ClaimsConfig{Issuer: "notes", Audience: "notes-api"}
+
+	testCases := []struct {
+		name     string
+		create   ClaimsConfig
+		verify   ClaimsConfig
+		duration time.Duration
+		err      error
+	}{
+		{
+			name:     "OK",
+			create:   claims,
+			verify:   claims,
+			duration: time.Minute,
+		},
+		{
+			name:     "WrongIssuer",
+			create:   ClaimsConfig{Issuer: "someone-else", Audience: claims.Audience},
+			verify:   claims,
+			duration: time.Minute,
+			err:      ErrInvalidToken,
+		},
+		{
+			name:     "WrongAudience",
+			create:   ClaimsConfig{Issuer: claims.Issuer, Audience: "another-api"},
+			verify:   claims,
+			duration: time.Minute,
+			err:      ErrInvalidToken,
+		},
+		{
+			name:     "MissingAudience",
+			create:   ClaimsConfig{Issuer: claims.Issuer},
+			verify:   claims,
+			duration: time.Minute,
+			err:      ErrInvalidToken,
+		},
+		{
+			name:     "ExpiredWithinLeeway",
+			create:   claims,
+			verify:   ClaimsConfig{Issuer: claims.Issuer, Audience: claims.Audience, Leeway: time.Minute},
+			duration: -10 * time.Second,
+		},
+		{
+			name:     "ExpiredBeyondLeeway",
+			create:   claims,
+			verify:   ClaimsConfig{Issuer: claims.Issuer, Audience: claims.Audience, Leeway: 5 * time.Second},
+			duration: -10 * time.Second,
+			err:      ErrExpiredToken,
+		},
+	}
+
+	for tokenType, newMaker := range makerFactories(t) {
+		for _, tc := range testCases {
+			t.Run(tokenType+"/"+tc.name, func(t *testing.T) {
+				token, _, err := newMaker(tc.create).CreateToken(util.RandomOwner(), tc.duration)
+				require.NoError(t, err)
+
+				payload, err := newMaker(tc.verify).VerifyToken(token)
+				if tc.err != nil {
+					require.EqualError(t, err, tc.err.Error())
+					require.Nil(t, payload)
+					return
+				}
+				require.NoError(t, err)
+				require.Equal(t, tc.create.Issuer, payload.Issuer)
+				require.Equal(t, jwt.ClaimStrings{tc.create.Audience}, payload.Audience)
+				require.NotNil(t, payload.NotBefore)
+			})
+		}
+	}
+}
+
+func TestMakersCarryScopes(t *testing.T) {
+	for tokenType, newMaker := range makerFactories(t) {
+		t.Run(tokenType, func(t *testing.T) {
+			maker := newMaker(ClaimsConfig{})
+
+			token, _, err := maker.CreateToken(util.RandomOwner(), time.Minute, "notes:read", "notes:write")
+			require.NoError(t, err)
+
+			payload, err := maker.VerifyToken(token)
+			require.NoError(t, err)
+			require.Equal(t, []string{"notes:read", "notes:write"}, payload.Scopes)
+			require.True(t, payload.HasScope("notes:read"))
+			require.False(t, payload.HasScope("account"))
+
+			token, _, err = maker.CreateToken(util.RandomOwner(), time.Minute)
+			require.NoError(t, err)
+
+			payload, err = maker.VerifyToken(token)
+			require.NoError(t, err)
+			require.Empty(t, payload.Scopes)
+		})
+	}
+}
+
+func TestValidateNotBefore(t *testing.T) {
+	payload, err := NewPayload(util.RandomOwner(), time.Hour)
+	require.NoError(t, err)
+	payload.NotBefore = jwt.NewNumericDate(time.Now().Add(10 * time.Second))
+
+	err = ClaimsConfig{}.validate(payload)
+	require.EqualError(t, err, ErrTokenNotValidYet.Error())
+
+	err = ClaimsConfig{Leeway: time.Minute}.validate(payload)
+	require.NoError(t, err)
+}
+
+func TestValidateRequiresExpiry(t *testing.T) {
+	payload, err := NewPayload(util.RandomOwner(), time.Hour)
+	require.NoError(t, err)
+	payload.ExpiresAt = nil
+
+	err = ClaimsConfig{}.validate(payload)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+}
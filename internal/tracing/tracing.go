@@ -0,0 +1,121 @@
+// Package tracing sets up OpenTelemetry tracing for the service and traces
+// the queries made through a Database.Store.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	Database "github.com/nilesh0729/Notes/internal/db/Result"
+	"github.com/nilesh0729/Notes/internal/util"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Exporters spans can be sent to.
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// ScopeName is the instrumentation scope of the spans made by this package.
+const ScopeName = "github.com/nilesh0729/Notes/internal/tracing"
+
+// Setup installs the global tracer provider selected by
+// config.TracingExporter and the W3C trace context and baggage propagators.
+// The returned function flushes and stops the provider.
+//
+// With the "otlp" exporter, config.TracingOTLPEndpoint is the host:port of
+// an OTLP/HTTP collector; if it is empty, the standard OTEL_EXPORTER_OTLP_*
+// environment variables are used.
+func Setup(ctx context.Context, config util.Config) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var exporter sdktrace.SpanExporter
+	switch config.TracingExporter {
+	case "", ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stderr))
+	case ExporterOTLP:
+		var options []otlptracehttp.Option
+		if config.TracingOTLPEndpoint != "" {
+			options = append(options, otlptracehttp.WithEndpoint(config.TracingOTLPEndpoint))
+			if config.TracingOTLPInsecure {
+				options = append(options, otlptracehttp.WithInsecure())
+			}
+		}
+		exporter, err = otlptracehttp.New(ctx, options...)
+	default:
+		return nil, fmt.Errorf("unsupported tracing exporter %q", config.TracingExporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot create %s exporter: %w", config.TracingExporter, err)
+	}
+
+	provider := NewTracerProvider(config, sdktrace.WithBatcher(exporter))
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// NewTracerProvider creates a tracer provider for the service that samples
+// config.TracingSampleRatio of new traces and follows the sampling decision
+// of incoming ones.
+func NewTracerProvider(config util.Config, options ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	serviceName := config.TracingServiceName
+	if serviceName == "" {
+		serviceName = "notes"
+	}
+
+	options = append([]sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(serviceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.TracingSampleRatio))),
+	}, options...)
+
+	return sdktrace.NewTracerProvider(options...)
+}
+
+// TraceStore returns a Store that makes a client span for every call made to
+// store, named after its sqlc query or transaction.
+func TraceStore(store Database.Store, provider trace.TracerProvider) Database.Store {
+	return Database.ObserveStore(store, queryTracer{
+		tracer: provider.Tracer(ScopeName),
+	})
+}
+
+type queryTracer struct {
+	tracer trace.Tracer
+}
+
+func (t queryTracer) StartQuery(ctx context.Context, query string) (context.Context, func(error)) {
+	ctx, span := t.tracer.Start(ctx, query,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			attribute.String("db.operation.name", query),
+		),
+	)
+	return ctx, func(err error) {
+		// A query that finds no rows has not failed.
+		if err != nil && !errors.Is(err, Database.ErrRecordNotFound) {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
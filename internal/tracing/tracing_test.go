@@ -0,0 +1,105 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mockDB "github.com/nilesh0729/Notes/internal/db/Mock"
+	Database "github.com/nilesh0729/Notes/internal/db/Result"
+	"github.com/nilesh0729/Notes/internal/util"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newTestProvider(t *testing.T) (*sdktrace.TracerProvider, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := NewTracerProvider(util.Config{TracingSampleRatio: 1}, sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() {
+		require.NoError(t, provider.Shutdown(context.Background()))
+	})
+	return provider, exporter
+}
+
+func TestTraceStore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockDB.NewMockStore(ctrl)
+	store.EXPECT().
+		ListNotes(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, _ Database.ListNotesParams) ([]Database.Note, error) {
+			// The query runs inside its own span.
+			require.True(t, trace.SpanContextFromContext(ctx).IsValid())
+			return []Database.Note{{NoteID: 1}}, nil
+		})
+	store.EXPECT().
+		GetTagsForNote(gomock.Any(), gomock.Eq(int32(1))).
+		Times(1).
+		Return(nil, sql.ErrConnDone)
+	store.EXPECT().
+		GetNoteById(gomock.Any(), gomock.Eq(int32(2))).
+		Times(1).
+		Return(Database.Note{}, Database.ErrRecordNotFound)
+
+	provider, exporter := newTestProvider(t)
+	traced := TraceStore(store, provider)
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "request")
+	_, err := traced.ListNotes(ctx, Database.ListNotesParams{})
+	require.NoError(t, err)
+	_, err = traced.GetTagsForNote(ctx, 1)
+	require.ErrorIs(t, err, sql.ErrConnDone)
+	_, err = traced.GetNoteById(ctx, 2)
+	require.ErrorIs(t, err, Database.ErrRecordNotFound)
+	parent.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 4)
+
+	byName := make(map[string]tracetest.SpanStub)
+	for _, span := range spans {
+		byName[span.Name] = span
+	}
+
+	for _, name := range []string{"ListNotes", "GetTagsForNote", "GetNoteById"} {
+		span, ok := byName[name]
+		require.True(t, ok, name)
+		require.Equal(t, trace.SpanKindClient, span.SpanKind)
+		require.Equal(t, parent.SpanContext().SpanID(), span.Parent.SpanID())
+		require.Equal(t, parent.SpanContext().TraceID(), span.SpanContext.TraceID())
+	}
+
+	require.Equal(t, codes.Unset, byName["ListNotes"].Status.Code)
+	require.Equal(t, codes.Error, byName["GetTagsForNote"].Status.Code)
+	require.Len(t, byName["GetTagsForNote"].Events, 1)
+	require.Equal(t, codes.Unset, byName["GetNoteById"].Status.Code)
+}
+
+func TestSetup(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+	})
+
+	shutdown, err := Setup(context.Background(), util.Config{TracingExporter: ExporterNone})
+	require.NoError(t, err)
+	require.NoError(t, shutdown(context.Background()))
+	require.Equal(t, previous, otel.GetTracerProvider())
+
+	shutdown, err = Setup(context.Background(), util.Config{TracingExporter: ExporterStdout, TracingSampleRatio: 1})
+	require.NoError(t, err)
+	require.IsType(t, &sdktrace.TracerProvider{}, otel.GetTracerProvider())
+	require.NoError(t, shutdown(context.Background()))
+
+	require.ElementsMatch(t, []string{"traceparent", "tracestate", "baggage"}, otel.GetTextMapPropagator().Fields())
+
+	_, err = Setup(context.Background(), util.Config{TracingExporter: "zipkin"})
+	require.Error(t, err)
+}
@@ -1,48 +1,438 @@
 package util
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
 )
 
+// Environments a Config can be loaded for. They select the defaults of the
+// CORS and security header settings.
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
+)
+
+// Config is the configuration of the server. Every setting is named by the
+// mapstructure tag of its field. Settings tagged secret can be read from a
+// file instead, and are hidden when the config is printed redacted.
 type Config struct {
+	// Environment is EnvDevelopment (the default) or EnvProduction.
+	Environment string `mapstructure:"ENVIRONMENT"`
+
+	// DBDriver selects the storage backend: "postgres" (the default) or
+	// "sqlite", with DBSource the path of the database file.
 	DBDriver            string        `mapstructure:"DB_DRIVER"`
-	DBSource            string        `mapstructure:"DB_SOURCE"`
+	DBSource            string        `mapstructure:"DB_SOURCE" secret:"true"`
 	ServerAddress       string        `mapstructure:"SERVER_ADDRESS"`
-	Secret              string        `mapstructure:"PASSWORD"`
+	Secret              string        `mapstructure:"PASSWORD" secret:"true"`
 	AccessTokenDuration time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+
+	// The connection pool keeps between DBMinConns and DBMaxOpenConns
+	// connections. At startup the database is retried for up to
+	// DBConnectTimeout before giving up.
+	DBMaxOpenConns    int           `mapstructure:"DB_MAX_OPEN_CONNS"`
+	DBMinConns        int           `mapstructure:"DB_MIN_CONNS"`
+	DBConnMaxLifetime time.Duration `mapstructure:"DB_CONN_MAX_LIFETIME"`
+	DBConnMaxIdleTime time.Duration `mapstructure:"DB_CONN_MAX_IDLE_TIME"`
+	DBConnectTimeout  time.Duration `mapstructure:"DB_CONNECT_TIMEOUT"`
+
+	// If DBReplicaSource is set, reads of notes and tags go to that read
+	// replica of the Postgres database. For DBReplicaStickiness after a
+	// user writes, their reads go to the primary so they see their write.
+	DBReplicaSource     string        `mapstructure:"DB_REPLICA_SOURCE" secret:"true"`
+	DBReplicaStickiness time.Duration `mapstructure:"DB_REPLICA_STICKINESS"`
+
+	// Notes and tags read through the Store are cached for CacheTTL by
+	// CacheBackend: "none" (the default), "memory" (an LRU of CacheSize
+	// entries) or "redis" (shared through RedisURL). A memory cache only
+	// sees the writes of its own instance, so it must not be used when
+	// several instances serve the same database.
+	CacheBackend string        `mapstructure:"CACHE_BACKEND"`
+	CacheSize    int           `mapstructure:"CACHE_SIZE"`
+	CacheTTL     time.Duration `mapstructure:"CACHE_TTL"`
+	RedisURL     string        `mapstructure:"REDIS_URL" secret:"true"`
+
+	// Requests are rate limited per route group with token buckets kept by
+	// RateLimitBackend: "memory" (per instance), "redis" (shared through
+	// RedisURL) or "none". Anonymous routes are limited per client IP, the
+	// others per user. Limits are "requests/period", e.g. "60/1m", or
+	// "none".
+	RateLimitBackend    string `mapstructure:"RATE_LIMIT_BACKEND"`
+	RateLimitPublic     string `mapstructure:"RATE_LIMIT_PUBLIC"`
+	RateLimitAccount    string `mapstructure:"RATE_LIMIT_ACCOUNT"`
+	RateLimitNotesRead  string `mapstructure:"RATE_LIMIT_NOTES_READ"`
+	RateLimitNotesWrite string `mapstructure:"RATE_LIMIT_NOTES_WRITE"`
+
+	// The client IP, which anonymous requests are rate limited and failed
+	// logins throttled by, is only taken from the X-Forwarded-For and
+	// X-Real-IP headers of requests coming from TrustedProxies (IPs or
+	// CIDRs), and X-Forwarded-Proto only tells that such a request was made
+	// over HTTPS. By default no proxy is trusted and the IP is that of the
+	// connection, as any client can send the headers.
+	TrustedProxies []string `mapstructure:"TRUSTED_PROXIES"`
+
+	// Each user may keep QuotaMaxNotes notes and QuotaMaxTags tags, of at
+	// most QuotaMaxTotalBytes of titles and contents, and a note may take
+	// at most QuotaMaxNoteBytes. 0 lifts a limit. The users in AdminUsers
+	// can override the quotas of a user through the /admin routes.
+	QuotaMaxNoteBytes  int64    `mapstructure:"QUOTA_MAX_NOTE_BYTES"`
+	QuotaMaxNotes      int64    `mapstructure:"QUOTA_MAX_NOTES"`
+	QuotaMaxTags       int64    `mapstructure:"QUOTA_MAX_TAGS"`
+	QuotaMaxTotalBytes int64    `mapstructure:"QUOTA_MAX_TOTAL_BYTES"`
+	AdminUsers         []string `mapstructure:"ADMIN_USERS"`
+
+	// Browsers may call the API from CORSAllowedOrigins, which may contain
+	// wildcards such as "https://*.example.com". If it is empty only the
+	// origin of AppBaseURL is allowed; "*" allows any origin, which is
+	// refused in production and together with CORSAllowCredentials.
+	CORSAllowedOrigins   []string      `mapstructure:"CORS_ALLOWED_ORIGINS"`
+	CORSAllowedMethods   []string      `mapstructure:"CORS_ALLOWED_METHODS"`
+	CORSAllowedHeaders   []string      `mapstructure:"CORS_ALLOWED_HEADERS"`
+	CORSAllowCredentials bool          `mapstructure:"CORS_ALLOW_CREDENTIALS"`
+	CORSMaxAge           time.Duration `mapstructure:"CORS_MAX_AGE"`
+
+	// The server speaks HTTPS with the certificate in TLSCertFile and
+	// TLSKeyFile, or with certificates for TLSAutocertDomains obtained from
+	// the ACME directory at TLSAutocertDirectoryURL (Let's Encrypt if empty)
+	// through the TLS-ALPN-01 challenge, so it has to listen on port 443.
+	// Certificates are kept in TLSAutocertCacheDir. Pointing the directory
+	// at a staging or local ACME server makes autocert testable.
+	TLSCertFile             string   `mapstructure:"TLS_CERT_FILE"`
+	TLSKeyFile              string   `mapstructure:"TLS_KEY_FILE"`
+	TLSAutocertDomains      []string `mapstructure:"TLS_AUTOCERT_DOMAINS"`
+	TLSAutocertCacheDir     string   `mapstructure:"TLS_AUTOCERT_CACHE_DIR"`
+	TLSAutocertDirectoryURL string   `mapstructure:"TLS_AUTOCERT_DIRECTORY_URL"`
+
+	// Every response carries ContentSecurityPolicy and headers against
+	// sniffing and framing. Requests made over HTTPS are also answered with
+	// Strict-Transport-Security for HSTSMaxAge, unless it is 0.
+	ContentSecurityPolicy string        `mapstructure:"CONTENT_SECURITY_POLICY"`
+	HSTSMaxAge            time.Duration `mapstructure:"HSTS_MAX_AGE"`
+
+	// AutoMigrate applies pending migrations when the server starts, instead
+	// of leaving it to the migrate subcommand.
+	AutoMigrate bool `mapstructure:"AUTO_MIGRATE"`
+
+	// On SIGINT or SIGTERM the server stops accepting connections and gives
+	// in-flight requests ShutdownTimeout to finish.
+	ShutdownTimeout time.Duration `mapstructure:"SHUTDOWN_TIMEOUT"`
+
+	// LogLevel is one of "debug", "info", "warn" or "error", and LogFormat
+	// is "json" or "text". Every request is logged at info level.
+	LogLevel  string `mapstructure:"LOG_LEVEL"`
+	LogFormat string `mapstructure:"LOG_FORMAT"`
+
+	// Prometheus metrics are served at /metrics on MetricsAddress, a listener
+	// of its own so that they are not public along with the API. They are
+	// not served if it is empty.
+	MetricsAddress string `mapstructure:"METRICS_ADDRESS"`
+
+	// Spans are exported with TracingExporter: "none", "stdout" or "otlp".
+	// The OTLP exporter sends them over HTTP to TracingOTLPEndpoint
+	// (host:port), or to the endpoint in the OTEL_EXPORTER_OTLP_* variables
+	// if it is empty. TracingSampleRatio of new traces are sampled.
+	TracingExporter     string  `mapstructure:"TRACING_EXPORTER"`
+	TracingServiceName  string  `mapstructure:"TRACING_SERVICE_NAME"`
+	TracingOTLPEndpoint string  `mapstructure:"TRACING_OTLP_ENDPOINT"`
+	TracingOTLPInsecure bool    `mapstructure:"TRACING_OTLP_INSECURE"`
+	TracingSampleRatio  float64 `mapstructure:"TRACING_SAMPLE_RATIO"`
+
+	// TokenType selects the access token format: "paseto" and "jwt" are keyed
+	// with Secret, "paseto-public" (Ed25519) and "jwt-public" (RSA or P-256)
+	// sign with TokenPrivateKeyFile and also accept the keys in
+	// TokenPreviousKeyFiles.
+	TokenType             string   `mapstructure:"TOKEN_TYPE"`
+	TokenPrivateKeyFile   string   `mapstructure:"TOKEN_PRIVATE_KEY_FILE"`
+	TokenPreviousKeyFiles []string `mapstructure:"TOKEN_PREVIOUS_KEY_FILES"`
+
+	// Every token carries TokenIssuer and TokenAudience, and tokens with
+	// other values are rejected. TokenLeeway is the clock skew allowed when
+	// checking expiry and not-before times.
+	TokenIssuer   string        `mapstructure:"TOKEN_ISSUER"`
+	TokenAudience string        `mapstructure:"TOKEN_AUDIENCE"`
+	TokenLeeway   time.Duration `mapstructure:"TOKEN_LEEWAY"`
+
+	// New passwords are hashed with PasswordHashAlgorithm ("argon2id" or
+	// "bcrypt"). Hashes made with another algorithm or weaker parameters
+	// are upgraded the next time their user logs in. Argon2Memory is in KiB.
+	PasswordHashAlgorithm string `mapstructure:"PASSWORD_HASH_ALGORITHM"`
+	BcryptCost            int    `mapstructure:"BCRYPT_COST"`
+	Argon2Memory          int    `mapstructure:"ARGON2_MEMORY"`
+	Argon2Iterations      int    `mapstructure:"ARGON2_ITERATIONS"`
+	Argon2Parallelism     int    `mapstructure:"ARGON2_PARALLELISM"`
+
+	// New passwords must have PasswordMinLength to PasswordMaxLength
+	// characters, the required character classes, must not contain the
+	// username or email, and must not be in the breached password corpus at
+	// PasswordBreachedFile (SHA-1 hashes, one per line) if one is configured.
+	PasswordMinLength     int    `mapstructure:"PASSWORD_MIN_LENGTH"`
+	PasswordMaxLength     int    `mapstructure:"PASSWORD_MAX_LENGTH"`
+	PasswordRequireUpper  bool   `mapstructure:"PASSWORD_REQUIRE_UPPER"`
+	PasswordRequireLower  bool   `mapstructure:"PASSWORD_REQUIRE_LOWER"`
+	PasswordRequireDigit  bool   `mapstructure:"PASSWORD_REQUIRE_DIGIT"`
+	PasswordRequireSymbol bool   `mapstructure:"PASSWORD_REQUIRE_SYMBOL"`
+	PasswordBreachedFile  string `mapstructure:"PASSWORD_BREACHED_FILE"`
+
+	AppBaseURL                 string        `mapstructure:"APP_BASE_URL"`
+	RequireVerifiedEmail       bool          `mapstructure:"REQUIRE_VERIFIED_EMAIL"`
+	VerifyEmailTokenDuration   time.Duration `mapstructure:"VERIFY_EMAIL_TOKEN_DURATION"`
+	ResetPasswordTokenDuration time.Duration `mapstructure:"RESET_PASSWORD_TOKEN_DURATION"`
+	LoginChallengeDuration     time.Duration `mapstructure:"LOGIN_CHALLENGE_DURATION"`
+	TOTPIssuer                 string        `mapstructure:"TOTP_ISSUER"`
+
+	// A login challenge accepts LoginChallengeMaxAttempts codes, right or
+	// wrong, after which the password has to be entered again.
+	LoginChallengeMaxAttempts int `mapstructure:"LOGIN_CHALLENGE_MAX_ATTEMPTS"`
+
+	// Failed logins are throttled per username and per client IP: each failure
+	// doubles the wait (from LoginBackoffBase up to LoginBackoffMax), and the
+	// max attempts lock the key out for LoginLockoutDuration.
+	LoginMaxAttempts     int           `mapstructure:"LOGIN_MAX_ATTEMPTS"`
+	LoginIPMaxAttempts   int           `mapstructure:"LOGIN_IP_MAX_ATTEMPTS"`
+	LoginBackoffBase     time.Duration `mapstructure:"LOGIN_BACKOFF_BASE"`
+	LoginBackoffMax      time.Duration `mapstructure:"LOGIN_BACKOFF_MAX"`
+	LoginLockoutDuration time.Duration `mapstructure:"LOGIN_LOCKOUT_DURATION"`
+
+	// Signing in through an OpenID Connect provider is enabled when
+	// OIDCIssuerURL is set. OIDCRedirectURL must point at GET /oidc/callback,
+	// which sends the browser on to APP_BASE_URL/login/oidc with a code for
+	// POST /oidc/exchange.
+	OIDCIssuerURL     string        `mapstructure:"OIDC_ISSUER_URL"`
+	OIDCClientID      string        `mapstructure:"OIDC_CLIENT_ID"`
+	OIDCClientSecret  string        `mapstructure:"OIDC_CLIENT_SECRET" secret:"true"`
+	OIDCRedirectURL   string        `mapstructure:"OIDC_REDIRECT_URL"`
+	OIDCScopes        []string      `mapstructure:"OIDC_SCOPES"`
+	OIDCStateDuration time.Duration `mapstructure:"OIDC_STATE_DURATION"`
+
+	// Mail is delivered over SMTP when SMTPHost is set, otherwise it is
+	// written to MailLogFile (or stderr) for local development.
+	MailFrom     string `mapstructure:"MAIL_FROM"`
+	SMTPHost     string `mapstructure:"SMTP_HOST"`
+	SMTPPort     int    `mapstructure:"SMTP_PORT"`
+	SMTPUsername string `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword string `mapstructure:"SMTP_PASSWORD" secret:"true"`
+	MailLogFile  string `mapstructure:"MAIL_LOG_FILE"`
 }
 
+// LoadConfig loads the config from, in increasing precedence: the
+// defaults, the YAML, TOML or JSON file named by CONFIG_FILE, the .env file
+// in path, and environment variables. The sections of the config file are
+// joined with underscores, so that
+//
+//	db:
+//	  source: postgres://...
+//
+// sets DB_SOURCE. A secret can also be read from the file named by its
+// setting with a _FILE suffix, such as PASSWORD_FILE, which is how Docker
+// and Kubernetes hand out secrets.
+//
+// The loaded config is validated, and every problem found is returned
+// together with the config, so that it can still be inspected.
 func LoadConfig(path string) (config Config, err error) {
+	v := viper.New()
+	setDefaults(v)
+
+	var errs []error
+	if file := os.Getenv("CONFIG_FILE"); file != "" {
+		errs = append(errs, mergeConfigFile(v, file))
+	}
 
-	viper.AddConfigPath(path)
+	// .env holds environment variables for local development; unlike the
+	// config file, it may set variables meant for other programs.
+	envFile := filepath.Join(path, ".env")
+	if _, statErr := os.Stat(envFile); statErr == nil {
+		v.SetConfigFile(envFile)
+		v.SetConfigType("env")
+		if mergeErr := v.MergeInConfig(); mergeErr != nil {
+			errs = append(errs, fmt.Errorf("cannot read %s: %w", envFile, mergeErr))
+		}
+	}
 
-	viper.SetConfigType("env")
-	viper.SetConfigName(".env")
+	for _, s := range configSettings() {
+		v.BindEnv(s.name)
+		if s.secret {
+			v.BindEnv(s.name + secretFileSuffix)
+		}
+	}
 
-	viper.AutomaticEnv()
-	
-	// Explicitly bind environment variables for cloud deployments
-	// This ensures Viper reads env vars even when .env file doesn't exist
-	viper.BindEnv("DB_DRIVER")
-	viper.BindEnv("DB_SOURCE")
-	viper.BindEnv("SERVER_ADDRESS")
-	viper.BindEnv("PASSWORD")
-	viper.BindEnv("ACCESS_TOKEN_DURATION")
+	// The environment may come from any layer, so the defaults it selects
+	// are only known now.
+	setEnvironmentDefaults(v, v.GetString("ENVIRONMENT"))
 
-	// Try to read config file, but ignore if it doesn't exist
-	// This allows the app to work with environment variables only (e.g., in cloud deployments)
-	err = viper.ReadInConfig()
+	errs = append(errs, readSecretFiles(v))
+
+	err = v.Unmarshal(&config)
 	if err != nil {
-		// Ignore config file not found error, continue with environment variables
-		_, ok := err.(viper.ConfigFileNotFoundError)
-		if !ok {
-			// Return only if it's a different error (not "file not found")
-			return
+		return config, errors.Join(append(errs, fmt.Errorf("cannot decode config: %w", err))...)
+	}
+
+	errs = append(errs, config.Validate())
+	return config, errors.Join(errs...)
+}
+
+func setDefaults(v *viper.Viper) {
+	v.SetDefault("ENVIRONMENT", EnvDevelopment)
+	v.SetDefault("DB_DRIVER", "postgres")
+	v.SetDefault("DB_MAX_OPEN_CONNS", 25)
+	v.SetDefault("DB_MIN_CONNS", 0)
+	v.SetDefault("DB_CONN_MAX_LIFETIME", 30*time.Minute)
+	v.SetDefault("DB_CONN_MAX_IDLE_TIME", 5*time.Minute)
+	v.SetDefault("DB_CONNECT_TIMEOUT", 30*time.Second)
+	v.SetDefault("DB_REPLICA_STICKINESS", 5*time.Second)
+	v.SetDefault("CACHE_BACKEND", "none")
+	v.SetDefault("CACHE_SIZE", 10000)
+	v.SetDefault("CACHE_TTL", time.Minute)
+	v.SetDefault("RATE_LIMIT_BACKEND", "memory")
+	v.SetDefault("RATE_LIMIT_PUBLIC", "20/1m")
+	v.SetDefault("RATE_LIMIT_ACCOUNT", "60/1m")
+	v.SetDefault("RATE_LIMIT_NOTES_READ", "600/1m")
+	v.SetDefault("RATE_LIMIT_NOTES_WRITE", "120/1m")
+	v.SetDefault("QUOTA_MAX_NOTE_BYTES", 64*1024)
+	v.SetDefault("QUOTA_MAX_NOTES", 10000)
+	v.SetDefault("QUOTA_MAX_TAGS", 500)
+	v.SetDefault("QUOTA_MAX_TOTAL_BYTES", 50*1024*1024)
+	v.SetDefault("CORS_ALLOWED_METHODS", []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"})
+	v.SetDefault("CORS_ALLOWED_HEADERS", []string{"Origin", "Content-Type", "Authorization", "If-None-Match", "X-Request-ID"})
+	v.SetDefault("CORS_MAX_AGE", 12*time.Hour)
+	v.SetDefault("TLS_AUTOCERT_CACHE_DIR", "autocert")
+	v.SetDefault("CONTENT_SECURITY_POLICY", "default-src 'none'; frame-ancestors 'none'")
+	v.SetDefault("SHUTDOWN_TIMEOUT", 15*time.Second)
+	v.SetDefault("LOG_LEVEL", "info")
+	v.SetDefault("LOG_FORMAT", "json")
+	v.SetDefault("TRACING_EXPORTER", "none")
+	v.SetDefault("TRACING_SERVICE_NAME", "notes")
+	v.SetDefault("TRACING_SAMPLE_RATIO", 1.0)
+	v.SetDefault("TOKEN_TYPE", "paseto")
+	v.SetDefault("TOKEN_ISSUER", "notes")
+	v.SetDefault("TOKEN_AUDIENCE", "notes")
+	v.SetDefault("TOKEN_LEEWAY", 30*time.Second)
+	v.SetDefault("PASSWORD_HASH_ALGORITHM", "argon2id")
+	v.SetDefault("BCRYPT_COST", 12)
+	v.SetDefault("ARGON2_MEMORY", 64*1024)
+	v.SetDefault("ARGON2_ITERATIONS", 3)
+	v.SetDefault("ARGON2_PARALLELISM", 2)
+	v.SetDefault("PASSWORD_MIN_LENGTH", 8)
+	v.SetDefault("PASSWORD_MAX_LENGTH", 128)
+	v.SetDefault("APP_BASE_URL", "http://localhost")
+	v.SetDefault("VERIFY_EMAIL_TOKEN_DURATION", 24*time.Hour)
+	v.SetDefault("RESET_PASSWORD_TOKEN_DURATION", time.Hour)
+	v.SetDefault("LOGIN_CHALLENGE_DURATION", 5*time.Minute)
+	v.SetDefault("TOTP_ISSUER", "Notes")
+	v.SetDefault("LOGIN_CHALLENGE_MAX_ATTEMPTS", 5)
+	v.SetDefault("LOGIN_MAX_ATTEMPTS", 5)
+	v.SetDefault("LOGIN_IP_MAX_ATTEMPTS", 20)
+	v.SetDefault("LOGIN_BACKOFF_BASE", time.Second)
+	v.SetDefault("LOGIN_BACKOFF_MAX", time.Minute)
+	v.SetDefault("LOGIN_LOCKOUT_DURATION", 15*time.Minute)
+	v.SetDefault("OIDC_SCOPES", []string{"openid", "email", "profile"})
+	v.SetDefault("OIDC_STATE_DURATION", 10*time.Minute)
+	v.SetDefault("MAIL_FROM", "Notes <no-reply@localhost>")
+	v.SetDefault("SMTP_PORT", 587)
+}
+
+// setEnvironmentDefaults sets the defaults that differ between environments.
+// Development also allows the Vite dev server of the frontend, while
+// production only allows the origin of APP_BASE_URL and turns on HSTS.
+func setEnvironmentDefaults(v *viper.Viper, environment string) {
+	switch environment {
+	case EnvProduction:
+		v.SetDefault("HSTS_MAX_AGE", 365*24*time.Hour)
+	default:
+		v.SetDefault("CORS_ALLOWED_ORIGINS", []string{"http://localhost", "http://localhost:5173"})
+	}
+}
+
+// secretFileSuffix turns the name of a secret setting into that of the
+// setting holding the path of a file with its value.
+const secretFileSuffix = "_FILE"
+
+// configSetting is a setting of Config.
+type configSetting struct {
+	name   string
+	secret bool
+	field  int
+}
+
+// configSettings returns the settings of Config in the order of its fields.
+func configSettings() []configSetting {
+	t := reflect.TypeFor[Config]()
+	settings := make([]configSetting, 0, t.NumField())
+	for i := range t.NumField() {
+		field := t.Field(i)
+		settings = append(settings, configSetting{
+			name:   field.Tag.Get("mapstructure"),
+			secret: field.Tag.Get("secret") == "true",
+			field:  i,
+		})
+	}
+	return settings
+}
+
+// mergeConfigFile merges the settings of the config file at path into v,
+// below environment variables. Settings the config does not have are
+// rejected, as they are most likely misspelled.
+func mergeConfigFile(v *viper.Viper, path string) error {
+	file := viper.New()
+	file.SetConfigFile(path)
+	err := file.ReadInConfig()
+	if err != nil {
+		return fmt.Errorf("cannot read config file: %w", err)
+	}
+
+	known := make(map[string]bool)
+	for _, s := range configSettings() {
+		known[s.name] = true
+		if s.secret {
+			known[s.name+secretFileSuffix] = true
+		}
+	}
+
+	var errs []error
+	settings := make(map[string]any)
+	for _, key := range file.AllKeys() {
+		name := strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+		if !known[name] {
+			errs = append(errs, fmt.Errorf("%s: unknown setting %q", path, key))
+			continue
+		}
+		settings[name] = file.Get(key)
+	}
+
+	errs = append(errs, v.MergeConfigMap(settings))
+	return errors.Join(errs...)
+}
+
+// readSecretFiles sets every secret whose _FILE setting is set to the
+// content of that file, without the trailing newline most editors add.
+func readSecretFiles(v *viper.Viper) error {
+	var errs []error
+	for _, s := range configSettings() {
+		if !s.secret {
+			continue
+		}
+		fileName := s.name + secretFileSuffix
+		path := v.GetString(fileName)
+		if path == "" {
+			continue
+		}
+		if v.IsSet(s.name) {
+			errs = append(errs, fmt.Errorf("only one of %s and %s may be set", s.name, fileName))
+			continue
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot read %s: %w", fileName, err))
+			continue
 		}
+		v.Set(s.name, strings.TrimRight(string(content), "\r\n"))
 	}
-	
-	err = viper.Unmarshal(&config)
-	return
+	return errors.Join(errs...)
 }
@@ -0,0 +1,73 @@
+package util
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// redacted replaces the value of a secret when the config is printed.
+const redacted = "REDACTED"
+
+// WriteEnv writes every setting of config to w as an environment variable,
+// in the format of a .env file. If redact is set, secrets are replaced; of
+// a URL only the password is.
+func (config Config) WriteEnv(w io.Writer, redact bool) error {
+	value := reflect.ValueOf(config)
+	for _, s := range configSettings() {
+		text := formatSetting(value.Field(s.field))
+		if redact && s.secret && text != "" {
+			text = redactSecret(text)
+		}
+
+		_, err := fmt.Fprintf(w, "%s=%s\n", s.name, quoteEnv(text))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatSetting(value reflect.Value) string {
+	switch v := value.Interface().(type) {
+	case time.Duration:
+		return v.String()
+	case []string:
+		return strings.Join(v, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// redactSecret hides secret. Database and Redis URLs keep everything but
+// their password, which helps to tell whether the right server is used.
+func redactSecret(secret string) string {
+	u, err := url.Parse(secret)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return redacted
+	}
+
+	if u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), redacted)
+		}
+	}
+	query := u.Query()
+	if query.Has("password") {
+		query.Set("password", redacted)
+		u.RawQuery = query.Encode()
+	}
+	return u.String()
+}
+
+// quoteEnv quotes value if a .env parser would not read it back as is.
+func quoteEnv(value string) string {
+	if strings.ContainsAny(value, " \t\n\r\"'#$\\") {
+		return strconv.Quote(value)
+	}
+	return value
+}
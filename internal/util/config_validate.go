@@ -0,0 +1,110 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Validate checks for settings that cannot work, so that they are reported
+// when the server starts rather than when they are first used. Every
+// problem found is returned, joined into one error.
+func (config Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+	oneOf := func(name, value string, allowed ...string) {
+		check(slices.Contains(allowed, value), "%s must be one of %s, not %q", name, strings.Join(allowed, ", "), value)
+	}
+
+	oneOf("ENVIRONMENT", config.Environment, EnvDevelopment, EnvProduction)
+
+	oneOf("DB_DRIVER", config.DBDriver, "postgres", "sqlite")
+	check(config.DBSource != "", "DB_SOURCE is required")
+	check(config.DBReplicaSource == "" || config.DBDriver == "postgres", "DB_REPLICA_SOURCE needs the postgres driver")
+	check(config.DBMaxOpenConns >= 0, "DB_MAX_OPEN_CONNS must not be negative")
+	check(config.DBMinConns >= 0, "DB_MIN_CONNS must not be negative")
+	check(config.DBMaxOpenConns == 0 || config.DBMinConns <= config.DBMaxOpenConns,
+		"DB_MIN_CONNS %d is above DB_MAX_OPEN_CONNS %d", config.DBMinConns, config.DBMaxOpenConns)
+
+	check(config.ServerAddress != "", "SERVER_ADDRESS is required")
+	check(config.MetricsAddress == "" || config.MetricsAddress != config.ServerAddress, "METRICS_ADDRESS must differ from SERVER_ADDRESS")
+	check(config.ShutdownTimeout > 0, "SHUTDOWN_TIMEOUT must be positive")
+
+	oneOf("CACHE_BACKEND", config.CacheBackend, "memory", "redis", "none")
+	check(config.CacheBackend != "memory" || config.CacheSize > 0, "CACHE_SIZE must be positive")
+	check(config.CacheBackend == "none" || config.CacheTTL > 0, "CACHE_TTL must be positive")
+	for _, proxy := range config.TrustedProxies {
+		check(validIPOrCIDR(proxy), "TRUSTED_PROXIES: %q is not an IP or CIDR", proxy)
+	}
+
+	oneOf("RATE_LIMIT_BACKEND", config.RateLimitBackend, "memory", "redis", "none", "")
+	check(config.RedisURL != "" || (config.CacheBackend != "redis" && config.RateLimitBackend != "redis"),
+		"REDIS_URL is required by the redis backends")
+
+	check(config.QuotaMaxNoteBytes >= 0, "QUOTA_MAX_NOTE_BYTES must not be negative")
+	check(config.QuotaMaxNotes >= 0, "QUOTA_MAX_NOTES must not be negative")
+	check(config.QuotaMaxTags >= 0, "QUOTA_MAX_TAGS must not be negative")
+	check(config.QuotaMaxTotalBytes >= 0, "QUOTA_MAX_TOTAL_BYTES must not be negative")
+
+	check(!slices.Contains(config.CORSAllowedOrigins, "*") || config.Environment != EnvProduction,
+		`CORS_ALLOWED_ORIGINS must not be "*" in production`)
+	check(!slices.Contains(config.CORSAllowedOrigins, "*") || !config.CORSAllowCredentials,
+		`CORS_ALLOWED_ORIGINS must not be "*" with CORS_ALLOW_CREDENTIALS`)
+	check((config.TLSCertFile == "") == (config.TLSKeyFile == ""), "TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	check(config.TLSCertFile == "" || len(config.TLSAutocertDomains) == 0, "TLS_CERT_FILE and TLS_AUTOCERT_DOMAINS cannot both be set")
+	check(config.HSTSMaxAge >= 0, "HSTS_MAX_AGE must not be negative")
+
+	_, err := NewLogger(config, io.Discard)
+	errs = append(errs, err)
+	oneOf("TRACING_EXPORTER", config.TracingExporter, "none", "stdout", "otlp", "")
+	check(config.TracingSampleRatio >= 0 && config.TracingSampleRatio <= 1, "TRACING_SAMPLE_RATIO must be between 0 and 1")
+
+	check(config.AccessTokenDuration > 0, "ACCESS_TOKEN_DURATION must be positive")
+	switch config.TokenType {
+	case "", "paseto":
+		check(len(config.Secret) == 32, "PASSWORD must be exactly 32 bytes for paseto tokens, not %d", len(config.Secret))
+	case "jwt":
+		check(len(config.Secret) >= 32, "PASSWORD must be at least 32 bytes for jwt tokens, not %d", len(config.Secret))
+	case "paseto-public", "jwt-public":
+		check(config.TokenPrivateKeyFile != "", "TOKEN_PRIVATE_KEY_FILE is required for %s tokens", config.TokenType)
+	default:
+		errs = append(errs, fmt.Errorf("TOKEN_TYPE %q is not supported", config.TokenType))
+	}
+	check(config.TokenLeeway >= 0, "TOKEN_LEEWAY must not be negative")
+
+	_, err = NewPasswordHasher(config)
+	errs = append(errs, err)
+	check(config.PasswordMinLength >= 1, "PASSWORD_MIN_LENGTH must be positive")
+	check(config.PasswordMaxLength == 0 || config.PasswordMaxLength >= config.PasswordMinLength,
+		"PASSWORD_MAX_LENGTH %d is below PASSWORD_MIN_LENGTH %d", config.PasswordMaxLength, config.PasswordMinLength)
+
+	appURL, err := url.Parse(config.AppBaseURL)
+	check(err == nil && appURL.Scheme != "" && appURL.Host != "", "APP_BASE_URL %q is not an absolute URL", config.AppBaseURL)
+
+	check(config.LoginChallengeMaxAttempts > 0, "LOGIN_CHALLENGE_MAX_ATTEMPTS must be positive")
+
+	if config.OIDCIssuerURL != "" {
+		check(config.OIDCClientID != "", "OIDC_CLIENT_ID is required with OIDC_ISSUER_URL")
+		check(config.OIDCRedirectURL != "", "OIDC_REDIRECT_URL is required with OIDC_ISSUER_URL")
+	}
+	check(config.SMTPHost == "" || config.SMTPPort > 0, "SMTP_PORT must be positive")
+
+	return errors.Join(errs...)
+}
+
+func validIPOrCIDR(s string) bool {
+	if strings.Contains(s, "/") {
+		_, err := netip.ParsePrefix(s)
+		return err == nil
+	}
+	_, err := netip.ParseAddr(s)
+	return err == nil
+}
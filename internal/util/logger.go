@@ -0,0 +1,37 @@
+package util
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Log formats a logger can be configured with.
+const (
+	LogFormatJSON = "json"
+	LogFormatText = "text"
+)
+
+// NewLogger creates a logger writing to w at config.LogLevel ("debug",
+// "info", "warn" or "error") in config.LogFormat ("json" or "text").
+func NewLogger(config Config, w io.Writer) (*slog.Logger, error) {
+	var level slog.Level
+	if config.LogLevel != "" {
+		err := level.UnmarshalText([]byte(config.LogLevel))
+		if err != nil {
+			return nil, fmt.Errorf("unsupported log level %q", config.LogLevel)
+		}
+	}
+
+	options := &slog.HandlerOptions{Level: level}
+
+	switch strings.ToLower(config.LogFormat) {
+	case "", LogFormatJSON:
+		return slog.New(slog.NewJSONHandler(w, options)), nil
+	case LogFormatText:
+		return slog.New(slog.NewTextHandler(w, options)), nil
+	default:
+		return nil, fmt.Errorf("unsupported log format %q", config.LogFormat)
+	}
+}
@@ -0,0 +1,42 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := NewLogger(Config{LogLevel: "warn", LogFormat: LogFormatJSON}, &buf)
+	require.NoError(t, err)
+
+	logger.Info("dropped")
+	logger.Warn("kept", "user", "alice")
+
+	var entry map[string]any
+	err = json.Unmarshal(buf.Bytes(), &entry)
+	require.NoError(t, err)
+	require.Equal(t, "WARN", entry["level"])
+	require.Equal(t, "kept", entry["msg"])
+	require.Equal(t, "alice", entry["user"])
+}
+
+func TestNewLoggerText(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := NewLogger(Config{LogLevel: "DEBUG", LogFormat: "TEXT"}, &buf)
+	require.NoError(t, err)
+
+	logger.Debug("hello")
+	require.Contains(t, buf.String(), "level=DEBUG msg=hello")
+}
+
+func TestNewLoggerInvalid(t *testing.T) {
+	_, err := NewLogger(Config{LogLevel: "loud"}, &bytes.Buffer{})
+	require.Error(t, err)
+
+	_, err = NewLogger(Config{LogFormat: "xml"}, &bytes.Buffer{})
+	require.Error(t, err)
+}
@@ -1,19 +1,246 @@
 package util
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"strings"
 
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
-func HashedPassword(password string)(string, error){
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err!=nil{
+// Password hashing algorithms a PasswordHasher can be configured with.
+const (
+	PasswordHashBcrypt   = "bcrypt"
+	PasswordHashArgon2id = "argon2id"
+)
+
+const (
+	// bcrypt only looks at the first 72 bytes of a password.
+	bcryptMaxPasswordLength = 72
+
+	argon2idPrefix     = "$argon2id$"
+	argon2idSaltLength = 16
+	argon2idKeyLength  = 32
+)
+
+var (
+	// ErrMismatchedPassword is returned when a password does not match a hash.
+	// It is bcrypt's own error, so callers can compare against either.
+	ErrMismatchedPassword = bcrypt.ErrMismatchedHashAndPassword
+	// ErrPasswordTooLong is returned when hashing a password longer than the
+	// 72 bytes bcrypt can take. Longer passwords are refused rather than
+	// silently truncated.
+	ErrPasswordTooLong = bcrypt.ErrPasswordTooLong
+	// ErrUnknownPasswordHash is returned for a hash in an unsupported format.
+	ErrUnknownPasswordHash = errors.New("unknown password hash format")
+)
+
+// PasswordHasher hashes passwords into a self-describing encoded form: the
+// modular crypt format for bcrypt ("$2a$10$...") and the PHC string format
+// for argon2id ("$argon2id$v=19$m=65536,t=3,p=2$salt$hash").
+type PasswordHasher interface {
+	// Hash returns the encoded hash of password.
+	Hash(password string) (string, error)
+	// Verify checks password against an encoded hash made by any supported
+	// algorithm, so hashes keep working after the configuration changes.
+	Verify(password, encoded string) error
+	// NeedsRehash reports whether encoded was made with another algorithm or
+	// with weaker parameters than the hasher currently uses.
+	NeedsRehash(encoded string) bool
+}
+
+// NewPasswordHasher creates the hasher selected by config.PasswordHashAlgorithm.
+func NewPasswordHasher(config Config) (PasswordHasher, error) {
+	switch config.PasswordHashAlgorithm {
+	case "", PasswordHashBcrypt:
+		cost := config.BcryptCost
+		if cost == 0 {
+			cost = bcrypt.DefaultCost
+		}
+		if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+		return &BcryptHasher{Cost: cost}, nil
+	case PasswordHashArgon2id:
+		if config.Argon2Iterations < 1 {
+			return nil, errors.New("argon2id needs at least one iteration")
+		}
+		if config.Argon2Parallelism < 1 || config.Argon2Parallelism > 255 {
+			return nil, errors.New("argon2id parallelism must be between 1 and 255")
+		}
+		if config.Argon2Memory < 8*config.Argon2Parallelism {
+			return nil, errors.New("argon2id memory must be at least 8 KiB per thread")
+		}
+		return &Argon2idHasher{
+			Memory:      uint32(config.Argon2Memory),
+			Iterations:  uint32(config.Argon2Iterations),
+			Parallelism: uint8(config.Argon2Parallelism),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported password hash algorithm %q", config.PasswordHashAlgorithm)
+	}
+}
+
+// BcryptHasher hashes passwords with bcrypt at Cost.
+type BcryptHasher struct {
+	Cost int
+}
+
+func (hasher *BcryptHasher) Hash(password string) (string, error) {
+	if len(password) > bcryptMaxPasswordLength {
+		return "", ErrPasswordTooLong
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), hasher.Cost)
+	if err != nil {
 		return "", fmt.Errorf("failed to hash Password: %w", err)
 	}
 	return string(hashedPassword), nil
 }
 
-func CheckPassword(password , hashedPassword string)error{
-	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
-}
\ No newline at end of file
+func (hasher *BcryptHasher) Verify(password, encoded string) error {
+	return CheckPassword(password, encoded)
+}
+
+func (hasher *BcryptHasher) NeedsRehash(encoded string) bool {
+	if !isBcryptHash(encoded) {
+		return true
+	}
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err != nil || cost < hasher.Cost
+}
+
+// Argon2idHasher hashes passwords with argon2id. Memory is in KiB.
+type Argon2idHasher struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+}
+
+func (hasher *Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, argon2idSaltLength)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash Password: %w", err)
+	}
+
+	params := argon2idParams{
+		memory:      hasher.Memory,
+		iterations:  hasher.Iterations,
+		parallelism: hasher.Parallelism,
+		salt:        salt,
+	}
+	params.key = params.derive(password, argon2idKeyLength)
+	return params.encode(), nil
+}
+
+func (hasher *Argon2idHasher) Verify(password, encoded string) error {
+	return CheckPassword(password, encoded)
+}
+
+func (hasher *Argon2idHasher) NeedsRehash(encoded string) bool {
+	params, err := decodeArgon2id(encoded)
+	if err != nil {
+		return true
+	}
+	return params.memory < hasher.Memory ||
+		params.iterations < hasher.Iterations ||
+		params.parallelism < hasher.Parallelism ||
+		len(params.key) < argon2idKeyLength
+}
+
+// HashedPassword hashes password with bcrypt at the default cost. The server
+// hashes with the PasswordHasher built from its config instead.
+func HashedPassword(password string) (string, error) {
+	return (&BcryptHasher{Cost: bcrypt.DefaultCost}).Hash(password)
+}
+
+// CheckPassword checks password against a bcrypt or argon2id hash.
+func CheckPassword(password, hashedPassword string) error {
+	switch {
+	case isBcryptHash(hashedPassword):
+		// bcrypt would compare just the first 72 bytes, which no stored
+		// hash can have been made from.
+		if len(password) > bcryptMaxPasswordLength {
+			return ErrMismatchedPassword
+		}
+		return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+	case strings.HasPrefix(hashedPassword, argon2idPrefix):
+		params, err := decodeArgon2id(hashedPassword)
+		if err != nil {
+			return err
+		}
+		key := params.derive(password, uint32(len(params.key)))
+		if subtle.ConstantTimeCompare(key, params.key) != 1 {
+			return ErrMismatchedPassword
+		}
+		return nil
+	default:
+		return ErrUnknownPasswordHash
+	}
+}
+
+func isBcryptHash(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") ||
+		strings.HasPrefix(encoded, "$2b$") ||
+		strings.HasPrefix(encoded, "$2y$")
+}
+
+type argon2idParams struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	salt        []byte
+	key         []byte
+}
+
+func (params argon2idParams) derive(password string, keyLength uint32) []byte {
+	return argon2.IDKey([]byte(password), params.salt, params.iterations, params.memory, params.parallelism, keyLength)
+}
+
+func (params argon2idParams) encode() string {
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2idPrefix,
+		argon2.Version,
+		params.memory,
+		params.iterations,
+		params.parallelism,
+		base64.RawStdEncoding.EncodeToString(params.salt),
+		base64.RawStdEncoding.EncodeToString(params.key),
+	)
+}
+
+// decodeArgon2id parses an argon2id hash in the PHC string format.
+func decodeArgon2id(encoded string) (argon2idParams, error) {
+	var params argon2idParams
+
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != PasswordHashArgon2id {
+		return params, ErrUnknownPasswordHash
+	}
+
+	var version int
+	_, err := fmt.Sscanf(parts[2], "v=%d", &version)
+	if err != nil || version != argon2.Version {
+		return params, ErrUnknownPasswordHash
+	}
+
+	_, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.iterations, &params.parallelism)
+	if err != nil || params.iterations == 0 || params.parallelism == 0 {
+		return params, ErrUnknownPasswordHash
+	}
+
+	params.salt, err = base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, ErrUnknownPasswordHash
+	}
+	params.key, err = base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(params.key) == 0 {
+		return params, ErrUnknownPasswordHash
+	}
+
+	return params, nil
+}
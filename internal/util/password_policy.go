@@ -0,0 +1,222 @@
+package util
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Codes of the rules a password can violate.
+const (
+	PasswordTooShort         = "too_short"
+	PasswordTooLong          = "too_long"
+	PasswordMissingUppercase = "missing_uppercase"
+	PasswordMissingLowercase = "missing_lowercase"
+	PasswordMissingDigit     = "missing_digit"
+	PasswordMissingSymbol    = "missing_symbol"
+	PasswordContainsUsername = "contains_username"
+	PasswordContainsEmail    = "contains_email"
+	PasswordBreached         = "breached"
+)
+
+// The shortest username or email local part a password is checked against;
+// shorter ones would reject too many unrelated passwords.
+const minPersonalInfoLength = 3
+
+// PasswordViolation describes one rule a password breaks.
+type PasswordViolation struct {
+	Code    string
+	Message string
+}
+
+// PasswordPolicy decides which new passwords are accepted. Lengths are
+// counted in characters, not bytes.
+type PasswordPolicy struct {
+	MinLength      int
+	MaxLength      int
+	RequireUpper   bool
+	RequireLower   bool
+	RequireDigit   bool
+	RequireSymbol  bool
+	BreachedCorpus *BreachedPasswordCorpus
+}
+
+// NewPasswordPolicy creates the policy described by config, loading the
+// breached password corpus from config.PasswordBreachedFile if it is set.
+func NewPasswordPolicy(config Config) (*PasswordPolicy, error) {
+	if config.PasswordMaxLength > 0 && config.PasswordMaxLength < config.PasswordMinLength {
+		return nil, fmt.Errorf("password max length %d is below the min length %d", config.PasswordMaxLength, config.PasswordMinLength)
+	}
+
+	policy := &PasswordPolicy{
+		MinLength:     config.PasswordMinLength,
+		MaxLength:     config.PasswordMaxLength,
+		RequireUpper:  config.PasswordRequireUpper,
+		RequireLower:  config.PasswordRequireLower,
+		RequireDigit:  config.PasswordRequireDigit,
+		RequireSymbol: config.PasswordRequireSymbol,
+	}
+
+	if config.PasswordBreachedFile != "" {
+		corpus, err := LoadBreachedPasswordCorpus(config.PasswordBreachedFile)
+		if err != nil {
+			return nil, err
+		}
+		policy.BreachedCorpus = corpus
+	}
+
+	return policy, nil
+}
+
+// Validate returns every rule password violates, or nil if it is accepted.
+// username and email are those of the account the password is for.
+func (policy *PasswordPolicy) Validate(password, username, email string) []PasswordViolation {
+	var violations []PasswordViolation
+
+	length := utf8.RuneCountInString(password)
+	if length < policy.MinLength {
+		violations = append(violations, PasswordViolation{
+			Code:    PasswordTooShort,
+			Message: fmt.Sprintf("must be at least %d characters long", policy.MinLength),
+		})
+	}
+	if policy.MaxLength > 0 && length > policy.MaxLength {
+		violations = append(violations, PasswordViolation{
+			Code:    PasswordTooLong,
+			Message: fmt.Sprintf("must be at most %d characters long", policy.MaxLength),
+		})
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	if policy.RequireUpper && !hasUpper {
+		violations = append(violations, PasswordViolation{Code: PasswordMissingUppercase, Message: "must contain an uppercase letter"})
+	}
+	if policy.RequireLower && !hasLower {
+		violations = append(violations, PasswordViolation{Code: PasswordMissingLowercase, Message: "must contain a lowercase letter"})
+	}
+	if policy.RequireDigit && !hasDigit {
+		violations = append(violations, PasswordViolation{Code: PasswordMissingDigit, Message: "must contain a digit"})
+	}
+	if policy.RequireSymbol && !hasSymbol {
+		violations = append(violations, PasswordViolation{Code: PasswordMissingSymbol, Message: "must contain a symbol"})
+	}
+
+	lower := strings.ToLower(password)
+	if containsPersonalInfo(lower, username) {
+		violations = append(violations, PasswordViolation{Code: PasswordContainsUsername, Message: "must not contain the username"})
+	}
+	localPart, _, _ := strings.Cut(email, "@")
+	if containsPersonalInfo(lower, email) || containsPersonalInfo(lower, localPart) {
+		violations = append(violations, PasswordViolation{Code: PasswordContainsEmail, Message: "must not contain the email address"})
+	}
+
+	if policy.BreachedCorpus != nil && policy.BreachedCorpus.Contains(password) {
+		violations = append(violations, PasswordViolation{Code: PasswordBreached, Message: "has appeared in a data breach and must not be used"})
+	}
+
+	return violations
+}
+
+func containsPersonalInfo(lowerPassword, info string) bool {
+	return utf8.RuneCountInString(info) >= minPersonalInfoLength &&
+		strings.Contains(lowerPassword, strings.ToLower(info))
+}
+
+// sha1PrefixLength is the number of hex characters of a SHA-1 hash that pick
+// its range, as in the Pwned Passwords range API.
+const sha1PrefixLength = 5
+
+// BreachedPasswordCorpus is a set of SHA-1 hashes of breached passwords,
+// bucketed by hash prefix like the k-anonymity Pwned Passwords range API, so
+// a remote range source can replace the local file without changing callers.
+type BreachedPasswordCorpus struct {
+	ranges map[string]map[string]struct{}
+}
+
+// LoadBreachedPasswordCorpus reads a corpus in the Pwned Passwords download
+// format: one upper or lower case hex SHA-1 hash per line, optionally followed
+// by ":count". Empty lines and lines starting with '#' are skipped.
+func LoadBreachedPasswordCorpus(file string) (*BreachedPasswordCorpus, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	corpus := &BreachedPasswordCorpus{ranges: make(map[string]map[string]struct{})}
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		hash, _, _ := strings.Cut(text, ":")
+		hash = strings.ToUpper(hash)
+		if len(hash) != 2*sha1.Size {
+			return nil, fmt.Errorf("%s:%d: not a SHA-1 hash", file, line)
+		}
+		if _, err := hex.DecodeString(hash); err != nil {
+			return nil, fmt.Errorf("%s:%d: not a SHA-1 hash", file, line)
+		}
+		corpus.add(hash)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return corpus, nil
+}
+
+func (corpus *BreachedPasswordCorpus) add(hash string) {
+	prefix, suffix := hash[:sha1PrefixLength], hash[sha1PrefixLength:]
+	suffixes, ok := corpus.ranges[prefix]
+	if !ok {
+		suffixes = make(map[string]struct{})
+		corpus.ranges[prefix] = suffixes
+	}
+	suffixes[suffix] = struct{}{}
+}
+
+// Range returns the hash suffixes of the breached passwords whose SHA-1 hash
+// starts with the five hex characters of prefix.
+func (corpus *BreachedPasswordCorpus) Range(prefix string) []string {
+	suffixes := corpus.ranges[strings.ToUpper(prefix)]
+	res := make([]string, 0, len(suffixes))
+	for suffix := range suffixes {
+		res = append(res, suffix)
+	}
+	return res
+}
+
+// Contains reports whether password is in the corpus. Only the prefix of its
+// hash is used for the lookup; the suffix is compared within that range.
+func (corpus *BreachedPasswordCorpus) Contains(password string) bool {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	for _, suffix := range corpus.Range(hash[:sha1PrefixLength]) {
+		if suffix == hash[sha1PrefixLength:] {
+			return true
+		}
+	}
+	return false
+}
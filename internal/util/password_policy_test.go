@@ -0,0 +1,136 @@
+package util
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func sha1Hex(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return hex.EncodeToString(sum[:])
+}
+
+func writeBreachedFile(t *testing.T, lines ...string) string {
+	file := filepath.Join(t.TempDir(), "breached.txt")
+	err := os.WriteFile(file, []byte(strings.Join(lines, "\n")), 0o600)
+	require.NoError(t, err)
+	return file
+}
+
+func violationCodes(violations []PasswordViolation) []string {
+	var codes []string
+	for _, violation := range violations {
+		codes = append(codes, violation.Code)
+	}
+	return codes
+}
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	policy := &PasswordPolicy{
+		MinLength:     8,
+		MaxLength:     16,
+		RequireUpper:  true,
+		RequireLower:  true,
+		RequireDigit:  true,
+		RequireSymbol: true,
+	}
+
+	testCases := []struct {
+		name     string
+		password string
+		codes    []string
+	}{
+		{"OK", "Correct-h0rse", nil},
+		{"TooShort", "Sh0rt!", []string{PasswordTooShort}},
+		{"TooLong", "Much-t00-long-for-this", []string{PasswordTooLong}},
+		{"CountsCharacters", "Pässwörd-1ü", nil},
+		{"MissingUppercase", "correct-h0rse", []string{PasswordMissingUppercase}},
+		{"MissingLowercase", "CORRECT-H0RSE", []string{PasswordMissingLowercase}},
+		{"MissingDigit", "Correct-horse", []string{PasswordMissingDigit}},
+		{"MissingSymbol", "Correcth0rse", []string{PasswordMissingSymbol}},
+		{"ContainsUsername", "My-Alice-pw1", []string{PasswordContainsUsername}},
+		{"ContainsEmailLocalPart", "Bob.Smith-1", []string{PasswordContainsEmail}},
+		{"Several", "alice", []string{PasswordTooShort, PasswordMissingUppercase, PasswordMissingDigit, PasswordMissingSymbol, PasswordContainsUsername}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			violations := policy.Validate(tc.password, "alice", "bob.smith@example.com")
+			require.Equal(t, tc.codes, violationCodes(violations))
+			for _, violation := range violations {
+				require.NotEmpty(t, violation.Message)
+			}
+		})
+	}
+}
+
+func TestPasswordPolicyIgnoresShortPersonalInfo(t *testing.T) {
+	policy := &PasswordPolicy{MinLength: 8}
+
+	require.Empty(t, policy.Validate("bowling-alley", "al", "bo@example.com"))
+}
+
+func TestBreachedPasswordCorpus(t *testing.T) {
+	file := writeBreachedFile(t,
+		"# breached passwords",
+		strings.ToUpper(sha1Hex("password123"))+":2254650",
+		"",
+		sha1Hex("letmein1"),
+	)
+
+	corpus, err := LoadBreachedPasswordCorpus(file)
+	require.NoError(t, err)
+
+	require.True(t, corpus.Contains("password123"))
+	require.True(t, corpus.Contains("letmein1"))
+	require.False(t, corpus.Contains("Password123"))
+	require.False(t, corpus.Contains(RandomString(12)))
+
+	hash := strings.ToUpper(sha1Hex("password123"))
+	require.Equal(t, []string{hash[5:]}, corpus.Range(strings.ToLower(hash[:5])))
+	require.Empty(t, corpus.Range("00000"))
+
+	policy := &PasswordPolicy{MinLength: 8, BreachedCorpus: corpus}
+	require.Equal(t, []string{PasswordBreached}, violationCodes(policy.Validate("password123", "alice", "alice@example.com")))
+}
+
+func TestLoadBreachedPasswordCorpusErrors(t *testing.T) {
+	_, err := LoadBreachedPasswordCorpus(filepath.Join(t.TempDir(), "missing.txt"))
+	require.Error(t, err)
+
+	file := writeBreachedFile(t, sha1Hex("password123"), "not-a-hash:12")
+	_, err = LoadBreachedPasswordCorpus(file)
+	require.ErrorContains(t, err, ":2:")
+
+	file = writeBreachedFile(t, strings.Repeat("zz", 20))
+	_, err = LoadBreachedPasswordCorpus(file)
+	require.Error(t, err)
+}
+
+func TestNewPasswordPolicy(t *testing.T) {
+	file := writeBreachedFile(t, sha1Hex("password123"))
+
+	policy, err := NewPasswordPolicy(Config{
+		PasswordMinLength:    10,
+		PasswordMaxLength:    64,
+		PasswordRequireDigit: true,
+		PasswordBreachedFile: file,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 10, policy.MinLength)
+	require.Equal(t, 64, policy.MaxLength)
+	require.True(t, policy.RequireDigit)
+	require.NotNil(t, policy.BreachedCorpus)
+
+	_, err = NewPasswordPolicy(Config{PasswordMinLength: 10, PasswordMaxLength: 8})
+	require.Error(t, err)
+
+	_, err = NewPasswordPolicy(Config{PasswordBreachedFile: filepath.Join(t.TempDir(), "missing.txt")})
+	require.Error(t, err)
+}
@@ -1,6 +1,7 @@
 package util
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -25,3 +26,119 @@ func TestHashedPassword(t *testing.T) {
 	require.NotEmpty(t, hashedPassword2)
 	require.NotEqual(t, hashedPassword, hashedPassword2)
 }
+
+func TestArgon2idHasher(t *testing.T) {
+	hasher := &Argon2idHasher{Memory: 64, Iterations: 2, Parallelism: 1}
+
+	password := RandomString(8)
+	hashedPassword, err := hasher.Hash(password)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(hashedPassword, "$argon2id$v=19$m=64,t=2,p=1$"))
+	require.Len(t, strings.Split(hashedPassword, "$"), 6)
+
+	require.NoError(t, hasher.Verify(password, hashedPassword))
+	require.ErrorIs(t, hasher.Verify(RandomString(8), hashedPassword), ErrMismatchedPassword)
+	require.False(t, hasher.NeedsRehash(hashedPassword))
+
+	hashedPassword2, err := hasher.Hash(password)
+	require.NoError(t, err)
+	require.NotEqual(t, hashedPassword, hashedPassword2)
+
+	// Unlike bcrypt, argon2id uses the whole password.
+	long := strings.Repeat("a", 100)
+	hashedLong, err := hasher.Hash(long + "b")
+	require.NoError(t, err)
+	require.ErrorIs(t, hasher.Verify(long+"c", hashedLong), ErrMismatchedPassword)
+}
+
+func TestBcryptHasherRejectsLongPasswords(t *testing.T) {
+	hasher := &BcryptHasher{Cost: bcrypt.MinCost}
+
+	_, err := hasher.Hash(strings.Repeat("a", 73))
+	require.ErrorIs(t, err, ErrPasswordTooLong)
+
+	password := strings.Repeat("a", 72)
+	hashedPassword, err := hasher.Hash(password)
+	require.NoError(t, err)
+	require.NoError(t, hasher.Verify(password, hashedPassword))
+	require.ErrorIs(t, hasher.Verify(password+"b", hashedPassword), ErrMismatchedPassword)
+}
+
+func TestPasswordHasherVerifiesEveryAlgorithm(t *testing.T) {
+	password := RandomString(8)
+
+	bcryptHasher := &BcryptHasher{Cost: bcrypt.MinCost}
+	argon2idHasher := &Argon2idHasher{Memory: 64, Iterations: 1, Parallelism: 1}
+
+	bcryptHash, err := bcryptHasher.Hash(password)
+	require.NoError(t, err)
+	argon2idHash, err := argon2idHasher.Hash(password)
+	require.NoError(t, err)
+
+	for _, hasher := range []PasswordHasher{bcryptHasher, argon2idHasher} {
+		require.NoError(t, hasher.Verify(password, bcryptHash))
+		require.NoError(t, hasher.Verify(password, argon2idHash))
+		require.ErrorIs(t, hasher.Verify(password, "plaintext"), ErrUnknownPasswordHash)
+	}
+
+	require.False(t, bcryptHasher.NeedsRehash(bcryptHash))
+	require.True(t, bcryptHasher.NeedsRehash(argon2idHash))
+	require.True(t, argon2idHasher.NeedsRehash(bcryptHash))
+	require.False(t, argon2idHasher.NeedsRehash(argon2idHash))
+}
+
+func TestPasswordHasherNeedsRehash(t *testing.T) {
+	password := RandomString(8)
+
+	bcryptHash, err := (&BcryptHasher{Cost: bcrypt.MinCost}).Hash(password)
+	require.NoError(t, err)
+	require.True(t, (&BcryptHasher{Cost: bcrypt.MinCost + 1}).NeedsRehash(bcryptHash))
+
+	argon2idHash, err := (&Argon2idHasher{Memory: 64, Iterations: 1, Parallelism: 1}).Hash(password)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name        string
+		hasher      *Argon2idHasher
+		needsRehash bool
+	}{
+		{"Same", &Argon2idHasher{Memory: 64, Iterations: 1, Parallelism: 1}, false},
+		{"Weaker", &Argon2idHasher{Memory: 32, Iterations: 1, Parallelism: 1}, false},
+		{"MoreMemory", &Argon2idHasher{Memory: 128, Iterations: 1, Parallelism: 1}, true},
+		{"MoreIterations", &Argon2idHasher{Memory: 64, Iterations: 2, Parallelism: 1}, true},
+		{"MoreParallelism", &Argon2idHasher{Memory: 64, Iterations: 1, Parallelism: 2}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.needsRehash, tc.hasher.NeedsRehash(argon2idHash))
+		})
+	}
+}
+
+func TestNewPasswordHasher(t *testing.T) {
+	hasher, err := NewPasswordHasher(Config{})
+	require.NoError(t, err)
+	require.Equal(t, &BcryptHasher{Cost: bcrypt.DefaultCost}, hasher)
+
+	hasher, err = NewPasswordHasher(Config{
+		PasswordHashAlgorithm: PasswordHashArgon2id,
+		Argon2Memory:          64 * 1024,
+		Argon2Iterations:      3,
+		Argon2Parallelism:     2,
+	})
+	require.NoError(t, err)
+	require.Equal(t, &Argon2idHasher{Memory: 64 * 1024, Iterations: 3, Parallelism: 2}, hasher)
+
+	invalid := []Config{
+		{PasswordHashAlgorithm: "md5"},
+		{PasswordHashAlgorithm: PasswordHashBcrypt, BcryptCost: 50},
+		{PasswordHashAlgorithm: PasswordHashArgon2id, Argon2Memory: 64, Argon2Parallelism: 1},
+		{PasswordHashAlgorithm: PasswordHashArgon2id, Argon2Memory: 64, Argon2Iterations: 1},
+		{PasswordHashAlgorithm: PasswordHashArgon2id, Argon2Memory: 4, Argon2Iterations: 1, Argon2Parallelism: 1},
+	}
+	for _, config := range invalid {
+		_, err := NewPasswordHasher(config)
+		require.Error(t, err)
+	}
+}
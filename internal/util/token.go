@@ -0,0 +1,66 @@
+package util
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+const (
+	opaqueTokenBytes  = 32
+	recoveryCodeBytes = 10
+)
+
+var recoveryCodeEncoding = base32.NewEncoding("abcdefghijkmnpqrstuvwxyz23456789").WithPadding(base32.NoPadding)
+
+// NewOpaqueToken returns a random URL-safe token together with its hash.
+// Only the hash should be persisted; the token itself is handed to the user.
+func NewOpaqueToken() (string, []byte, error) {
+	buf := make([]byte, opaqueTokenBytes)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	return token, HashToken(token), nil
+}
+
+// HashToken returns the SHA-256 hash under which an opaque token is stored.
+func HashToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
+
+// NewRecoveryCode returns a random code that is easy to type, such as
+// "k3m9-q7xw-2pa8-rtz4".
+func NewRecoveryCode() (string, error) {
+	buf := make([]byte, recoveryCodeBytes)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate recovery code: %w", err)
+	}
+
+	return groupRecoveryCode(recoveryCodeEncoding.EncodeToString(buf)), nil
+}
+
+// NormalizeRecoveryCode undoes the formatting users tend to add when typing a
+// recovery code, so it can be hashed and compared.
+func NormalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.NewReplacer(" ", "", "-", "").Replace(code)
+	return groupRecoveryCode(code)
+}
+
+func groupRecoveryCode(code string) string {
+	var groups []string
+	for len(code) > 0 {
+		n := min(4, len(code))
+		groups = append(groups, code[:n])
+		code = code[n:]
+	}
+	return strings.Join(groups, "-")
+}
@@ -0,0 +1,32 @@
+package util
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewOpaqueToken(t *testing.T) {
+	token1, hash1, err := NewOpaqueToken()
+	require.NoError(t, err)
+	require.NotEmpty(t, token1)
+	require.Equal(t, HashToken(token1), hash1)
+
+	token2, hash2, err := NewOpaqueToken()
+	require.NoError(t, err)
+	require.NotEqual(t, token1, token2)
+	require.NotEqual(t, hash1, hash2)
+}
+
+func TestNewRecoveryCode(t *testing.T) {
+	code, err := NewRecoveryCode()
+	require.NoError(t, err)
+	require.Regexp(t, `^[a-z2-9]{4}-[a-z2-9]{4}-[a-z2-9]{4}-[a-z2-9]{4}$`, code)
+	require.Equal(t, code, NormalizeRecoveryCode(code))
+	require.Equal(t, code, NormalizeRecoveryCode(" "+strings.ToUpper(strings.ReplaceAll(code, "-", ""))+" "))
+
+	other, err := NewRecoveryCode()
+	require.NoError(t, err)
+	require.NotEqual(t, code, other)
+}
@@ -3,7 +3,7 @@
 set -e
 
 echo "run db migration"
-/usr/bin/migrate -path /app/internal/db/migrate_files -database "$DB_SOURCE" -verbose up
+/app/main migrate up
 
 echo "start the app"
 exec "$@"